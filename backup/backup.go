@@ -0,0 +1,171 @@
+// Package backup copies the SQLite database while the bot keeps using it,
+// keeps a rotation of such copies, and puts one back in place of the
+// database.
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"tg-sunday-league/db"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// PAGES_PER_STEP is how many pages Online copies at a time. The database is
+// only locked during a step, so the bot can write between them.
+const PAGES_PER_STEP = 256
+
+// STEP_PAUSE is how long Online waits between steps.
+const STEP_PAUSE = 5 * time.Millisecond
+
+// Online copies the SQLite database src to a new file at path with SQLite's
+// backup API. The copy is a consistent snapshot, even if src is written to
+// meanwhile. Nothing is left at path if it fails.
+func Online(ctx context.Context, src *sql.DB, path string) (err error) {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	dest, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		dest.Close()
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	return destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			from, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("only SQLite databases can be backed up")
+			}
+			backup, err := destDriver.(*sqlite3.SQLiteConn).Backup("main", from, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				// Step retries by itself while the database is locked.
+				done, err := backup.Step(PAGES_PER_STEP)
+				if err != nil {
+					backup.Close()
+					return err
+				}
+				if done {
+					return backup.Finish()
+				}
+				select {
+				case <-ctx.Done():
+					backup.Close()
+					return ctx.Err()
+				case <-time.After(STEP_PAUSE):
+				}
+			}
+		})
+	})
+}
+
+// Check verifies that the SQLite database at path is intact and that its
+// schema is one this binary can run, the same or older, and returns its
+// schema version.
+func Check(ctx context.Context, path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	src, err := openReadOnly(path)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	var result string
+	if err := src.QueryRowContext(ctx, `PRAGMA quick_check`).Scan(&result); err != nil {
+		return 0, fmt.Errorf("check %s: %w", path, err)
+	}
+	if result != "ok" {
+		return 0, fmt.Errorf("%s is damaged: %s", path, result)
+	}
+
+	migrator, err := db.NewMigrator(src, db.DRIVER_SQLITE)
+	if err != nil {
+		return 0, err
+	}
+	// Pending checks that every applied migration is known and unchanged,
+	// and in dry-run mode writes nothing.
+	migrator.DryRun = true
+	if _, err := migrator.Pending(); err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	version, err := migrator.Version()
+	if err != nil {
+		return 0, err
+	}
+	if version == 0 {
+		return 0, fmt.Errorf("%s has no migrations applied, it is not a database of the bot", path)
+	}
+	return version, nil
+}
+
+// Restore replaces the SQLite database at dbPath with the backup at
+// backupPath once Check accepts it, and returns the backup's schema version
+// and where the replaced database was moved to, "" if there was none. The
+// bot must not be running.
+func Restore(ctx context.Context, backupPath, dbPath string) (int, string, error) {
+	version, err := Check(ctx, backupPath)
+	if err != nil {
+		return 0, "", err
+	}
+
+	// Copying through the backup API first means a half-written file never
+	// takes the database's place.
+	src, err := openReadOnly(backupPath)
+	if err != nil {
+		return 0, "", err
+	}
+	defer src.Close()
+	staging := dbPath + ".restoring"
+	if err := os.Remove(staging); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, "", err
+	}
+	if err := Online(ctx, src, staging); err != nil {
+		return 0, "", fmt.Errorf("copy %s: %w", backupPath, err)
+	}
+
+	previous := ""
+	if _, err := os.Stat(dbPath); err == nil {
+		previous = dbPath + ".before-restore-" + time.Now().UTC().Format(TIMESTAMP_LAYOUT)
+		// The journal files belong to the old database and would be
+		// replayed into the restored one if left behind.
+		for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+			if err := os.Rename(dbPath+suffix, previous+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+				os.Remove(staging)
+				return 0, "", fmt.Errorf("move the current database aside: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(staging, dbPath); err != nil {
+		return 0, previous, err
+	}
+	return version, previous, nil
+}
+
+func openReadOnly(path string) (*sql.DB, error) {
+	return sql.Open("sqlite3", "file:"+path+"?mode=ro")
+}
@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"tg-sunday-league/logging"
+	"time"
+)
+
+// FILE_PREFIX starts the name of every backup BackupService writes, which
+// goes on with the time of the backup in TIMESTAMP_LAYOUT and .db.
+const FILE_PREFIX = "tg_sunday_league-"
+
+// TIMESTAMP_LAYOUT is in UTC and sorts like the times it stands for.
+const TIMESTAMP_LAYOUT = "20060102T150405.000Z"
+
+type IBackupService interface {
+	// Create backs the database up and returns the path of the backup.
+	Create(ctx context.Context) (string, error)
+}
+
+// BackupService writes backups of Db to Dir and deletes all but the Keep
+// newest.
+type BackupService struct {
+	Db   *sql.DB
+	Dir  string
+	Keep int
+
+	// mu keeps a backup on demand and a scheduled one from rotating each
+	// other's file away.
+	mu sync.Mutex
+}
+
+func (s *BackupService) Create(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.Dir, FILE_PREFIX+time.Now().UTC().Format(TIMESTAMP_LAYOUT)+".db")
+	// List only sees the backup once it is complete.
+	partial := path + ".partial"
+	if err := Online(ctx, s.Db, partial); err != nil {
+		return "", fmt.Errorf("back up to %s: %w", partial, err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return "", err
+	}
+	logging.FromContext(ctx).Info("Database backed up", "path", path)
+
+	if err := s.rotate(ctx); err != nil {
+		logging.FromContext(ctx).Error("Could not delete old backups", "error", err)
+	}
+	return path, nil
+}
+
+// List returns the paths of the backups in Dir, oldest first.
+func (s *BackupService) List() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.Dir, FILE_PREFIX+"*.db"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// rotate must be called with mu held.
+func (s *BackupService) rotate(ctx context.Context) error {
+	paths, err := s.List()
+	if err != nil || len(paths) <= s.Keep {
+		return err
+	}
+	for _, path := range paths[:len(paths)-s.Keep] {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("Old backup deleted", "path", path)
+	}
+	return nil
+}
+
+// Run backs the database up every interval until ctx ends. The first backup
+// is taken once the newest one in Dir is interval old, so restarts do not
+// put it off.
+func (s *BackupService) Run(ctx context.Context, interval time.Duration) {
+	var wait time.Duration
+	if paths, err := s.List(); err == nil && len(paths) > 0 {
+		if info, err := os.Stat(paths[len(paths)-1]); err == nil {
+			wait = max(interval-time.Since(info.ModTime()), 0)
+		}
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if _, err := s.Create(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Scheduled backup failed", "error", err)
+		}
+		timer.Reset(interval)
+	}
+}
@@ -0,0 +1,172 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"tg-sunday-league/db"
+)
+
+// newDatabase returns a migrated SQLite database at path with the given
+// number of settings rows, standing in for the league data.
+func newDatabase(t *testing.T, path string, rows int) *sql.DB {
+	t.Helper()
+	dbInstance, err := db.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbInstance.Close() })
+	if err := db.SetupDatabase(dbInstance, db.DRIVER_SQLITE, false); err != nil {
+		t.Fatal(err)
+	}
+	addRows(t, dbInstance, rows)
+	return dbInstance
+}
+
+func addRows(t *testing.T, dbInstance *sql.DB, rows int) {
+	t.Helper()
+	var count int
+	if err := dbInstance.QueryRow(`SELECT COUNT(*) FROM chat_settings`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	for i := count; i < count+rows; i++ {
+		if _, err := dbInstance.Exec(`INSERT INTO chat_settings (chat_id, key, value) VALUES (?, 'currency', 'SGD')`, -1000-i); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func countRows(t *testing.T, path string) int {
+	t.Helper()
+	dbInstance, err := openReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbInstance.Close()
+	var count int
+	if err := dbInstance.QueryRow(`SELECT COUNT(*) FROM chat_settings`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestOnline(t *testing.T) {
+	dir := t.TempDir()
+	src := newDatabase(t, filepath.Join(dir, "league.db"), 500)
+	ctx := context.Background()
+
+	path := filepath.Join(dir, "copy.db")
+	if err := Online(ctx, src, path); err != nil {
+		t.Fatalf("Online: %v", err)
+	}
+	if got := countRows(t, path); got != 500 {
+		t.Errorf("backup has %d rows, want 500", got)
+	}
+	if _, err := Check(ctx, path); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+
+	if err := Online(ctx, src, path); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Online over an existing file: %v", err)
+	}
+}
+
+func TestCreateRotates(t *testing.T) {
+	dir := t.TempDir()
+	service := &BackupService{Db: newDatabase(t, filepath.Join(dir, "league.db"), 1), Dir: filepath.Join(dir, "backups"), Keep: 2}
+	ctx := context.Background()
+
+	var created []string
+	for i := 0; i < 3; i++ {
+		path, err := service.Create(ctx)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		created = append(created, path)
+	}
+	kept, err := service.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 2 || kept[0] != created[1] || kept[1] != created[2] {
+		t.Errorf("kept %v, want the two newest of %v", kept, created)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "league.db")
+	live := newDatabase(t, dbPath, 3)
+	ctx := context.Background()
+
+	backupPath := filepath.Join(dir, "backup.db")
+	if err := Online(ctx, live, backupPath); err != nil {
+		t.Fatal(err)
+	}
+	addRows(t, live, 2)
+	live.Close()
+
+	version, previous, err := Restore(ctx, backupPath, dbPath)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if version != 7 {
+		t.Errorf("version = %d, want 7", version)
+	}
+	if got := countRows(t, dbPath); got != 3 {
+		t.Errorf("restored database has %d rows, want 3", got)
+	}
+	if got := countRows(t, previous); got != 5 {
+		t.Errorf("previous database has %d rows, want 5", got)
+	}
+}
+
+func TestRestoreRejects(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "league.db")
+	newDatabase(t, dbPath, 1).Close()
+	ctx := context.Background()
+
+	notDatabase := filepath.Join(dir, "notes.db")
+	if err := os.WriteFile(notDatabase, []byte("not a database, just some text that is long enough"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.db")
+	emptyDb, err := db.Connect(empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := emptyDb.Exec(`CREATE TABLE notes (body TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	emptyDb.Close()
+	newer := filepath.Join(dir, "newer.db")
+	newerDb := newDatabase(t, newer, 1)
+	if _, err := newerDb.Exec(`INSERT INTO schema_migrations (version, name, checksum) VALUES (999, 'future', 'x')`); err != nil {
+		t.Fatal(err)
+	}
+	newerDb.Close()
+
+	tests := []struct {
+		name, path, wantErr string
+	}{
+		{"missing", filepath.Join(dir, "missing.db"), "no such file"},
+		{"not a database", notDatabase, "not a database"},
+		{"no migrations", empty, "no migrations applied"},
+		{"newer schema", newer, "newer than the binary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Restore(ctx, tt.path, dbPath)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Restore: %v, want an error mentioning %q", err, tt.wantErr)
+			}
+			if got := countRows(t, dbPath); got != 1 {
+				t.Errorf("database has %d rows after a refused restore, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"context"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/services"
+	"tg-sunday-league/web"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// handleAPI sends the admin asking a new API token of the chat in their
+// private chat with the bot, as the token manages the chat's games. Tokens
+// are only kept hashed, so each /api replaces the previous one, which stops
+// working.
+func (b *Bot) handleAPI(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
+		return
+	}
+	if !b.isAdmin(ctx, m.Chat, m.Sender) {
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
+		return
+	}
+	if b.Tokens == nil || b.WebUrl == "" {
+		b.sendError(ctx, m.Chat, services.Invalid("", services.ERR_API_UNAVAILABLE, nil))
+		return
+	}
+
+	token, err := b.Tokens.ResetChatToken(ctx, m.Chat.ID, services.TOKEN_API)
+	if err != nil {
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+
+	text := b.MessageFormater.Text(settings, "api.token", m.Chat.Title, token, web.APIURL(b.WebUrl))
+	if _, err := b.TelegramBot.Send(m.Sender, text); err != nil {
+		// Bots may only message users who started a chat with them.
+		logging.FromContext(ctx).Warn("Could not send the API token", "error", err)
+		b.sendError(ctx, m.Chat, services.Invalid("", services.ERR_API_SEND, err))
+		return
+	}
+	b.sendText(ctx, m.Chat, "api.sent")
+}
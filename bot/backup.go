@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"path/filepath"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/services"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// handleBackup backs the database up and sends the file to the owner in
+// their private chat with the bot, wherever they asked for it, so it never
+// lands in a group.
+func (b *Bot) handleBackup(ctx context.Context, m *telebot.Message) {
+	if b.OwnerId == 0 || m.Sender.ID != b.OwnerId {
+		b.sendError(ctx, m.Chat, services.PermissionDenied(services.ERR_OWNER_ONLY))
+		return
+	}
+	if b.Backups == nil {
+		b.sendError(ctx, m.Chat, services.Invalid("", services.ERR_BACKUP_UNAVAILABLE, nil))
+		return
+	}
+
+	path, err := b.Backups.Create(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not back up the database", "error", err)
+		b.sendError(ctx, m.Chat, services.Internal(services.ERR_BACKUP, err))
+		return
+	}
+	owner := &telebot.User{ID: b.OwnerId}
+	document := &telebot.Document{File: telebot.FromDisk(path), FileName: filepath.Base(path)}
+	if _, err := b.TelegramBot.Send(owner, document); err != nil {
+		logging.FromContext(ctx).Error("Could not send the backup", "error", err)
+		b.sendError(ctx, m.Chat, services.Internal(services.ERR_BACKUP_SEND, err))
+		return
+	}
+	if m.Chat.ID != b.OwnerId {
+		b.sendText(ctx, m.Chat, "backup.sent")
+	}
+}
@@ -1,6 +1,13 @@
 package bot
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"tg-sunday-league/backup"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/monitoring"
 	"tg-sunday-league/services"
 	"time"
 
@@ -11,21 +18,72 @@ type Bot struct {
 	TelegramBot     *telebot.Bot
 	MessageFormater IMessageFormater
 	GameService     services.IGameService
+	SettingsService services.ISettingsService
+	// OwnerId is the Telegram user ID of the owner of the bot, the only
+	// one who may use /backup. 0 for none.
+	OwnerId int64
+	// Backups backs the database up for /backup, nil if it cannot be.
+	Backups backup.IBackupService
+	// Tokens hands out the secret links to chats' calendars and their API
+	// tokens, served at WebUrl. Without both, /calendar only sends the next
+	// game and /api is unavailable.
+	Tokens services.ITokenService
+	WebUrl string
+	// Sessions signs users in to the dashboard at WebUrl with the links
+	// /dashboard sends, which is unavailable without both.
+	Sessions services.ISessionService
+	// Webhooks keeps the URLs /webhook registers, nil if it is unavailable.
+	Webhooks services.IWebhookService
+
+	// ctx is the parent of every handler's context and is cancelled once
+	// Shutdown gives up waiting for them.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	queue    *chatQueue
+	mu       sync.RWMutex
+	stopping bool
+	inFlight sync.WaitGroup
+
+	stop chan struct{}
+	// updateID is the ID of the update being dispatched. It is only used by
+	// the Start loop, which runs the handlers inline.
+	updateID int
 }
 
-func NewBot(token string, gameService services.IGameService, messageFormater IMessageFormater) (*Bot, error) {
+// HANDLER_TIMEOUT bounds the time a single update may spend in the services,
+// and so in the database.
+const HANDLER_TIMEOUT = 30 * time.Second
+
+// NewBot connects to the Bot API at apiURL, or to Telegram's when it is empty.
+// Updates come from poller, such as a WebhookPoller, or from long polling when
+// it is nil.
+func NewBot(token string, apiURL string, poller telebot.Poller, gameService services.IGameService, settingsService services.ISettingsService, messageFormater IMessageFormater) (*Bot, error) {
+	if poller == nil {
+		poller = &telebot.LongPoller{Timeout: 10 * time.Second}
+	}
+	// Handlers only queue the update for its chat, so they run in the order
+	// updates arrive, and the chat queues run them in parallel.
 	bot, err := telebot.NewBot(telebot.Settings{
-		Token:  token,
-		Poller: &telebot.LongPoller{Timeout: 10 * time.Second},
+		URL:         apiURL,
+		Token:       token,
+		Poller:      poller,
+		Synchronous: true,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	b := &Bot{
 		TelegramBot:     bot,
 		MessageFormater: messageFormater,
 		GameService:     gameService,
+		SettingsService: settingsService,
+		ctx:             ctx,
+		cancel:          cancel,
+		queue:           newChatQueue(),
+		stop:            make(chan struct{}, 1),
 	}
 
 	b.setupHandlers()
@@ -33,11 +91,150 @@ func NewBot(token string, gameService services.IGameService, messageFormater IMe
 }
 
 func (b *Bot) setupHandlers() {
-	b.TelegramBot.Handle(NEW.Name, b.handleNewGame)
-	b.TelegramBot.Handle(IN.Name, b.handleRegisterPlayer)
-	b.TelegramBot.Handle(OUT.Name, b.handleRegisterPlayer)
-	b.TelegramBot.Handle(HELP.Name, b.handleHelp)
-	b.TelegramBot.Handle(DETAILS.Name, b.handleDetails)
-	b.TelegramBot.Handle(PAID.Name, b.handlePaid)
-	b.TelegramBot.Handle(CANCEL.Name, b.handleCancelGame)
+	b.TelegramBot.Handle(NEW.Name, b.onMessage(NEW, b.handleNewGame))
+	b.TelegramBot.Handle(IN.Name, b.onMessage(IN, b.handleRegisterPlayer))
+	b.TelegramBot.Handle(OUT.Name, b.onMessage(OUT, b.handleRegisterPlayer))
+	b.TelegramBot.Handle(HELP.Name, b.onMessage(HELP, b.handleHelp))
+	b.TelegramBot.Handle(DETAILS.Name, b.onMessage(DETAILS, b.handleDetails))
+	b.TelegramBot.Handle(PAID.Name, b.onMessage(PAID, b.handlePaid))
+	b.TelegramBot.Handle(CANCEL.Name, b.onMessage(CANCEL, b.handleCancelGame))
+	b.TelegramBot.Handle(TIMEZONE.Name, b.onMessage(TIMEZONE, b.handleTimezone))
+	b.TelegramBot.Handle(SETTINGS.Name, b.onMessage(SETTINGS, b.handleSettings))
+	b.TelegramBot.Handle(EXPORT.Name, b.onMessage(EXPORT, b.handleExport))
+	b.TelegramBot.Handle(IMPORT.Name, b.onMessage(IMPORT, b.handleImport))
+	b.TelegramBot.Handle(CALENDAR.Name, b.onMessage(CALENDAR, b.handleCalendar))
+	b.TelegramBot.Handle(API.Name, b.onMessage(API, b.handleAPI))
+	b.TelegramBot.Handle(DASHBOARD.Name, b.onMessage(DASHBOARD, b.handleDashboard))
+	b.TelegramBot.Handle(WEBHOOK.Name, b.onMessage(WEBHOOK, b.handleWebhook))
+	b.TelegramBot.Handle(telebot.OnDocument, b.handleImportDocument)
+	b.TelegramBot.Handle(BACKUP.Name, b.onMessage(BACKUP, b.handleBackup))
+	b.TelegramBot.Handle(&settingsButton, b.onCallback(settingsButton.Unique, b.handleSettingsCallback))
+}
+
+func (b *Bot) onMessage(command Command, handler func(ctx context.Context, m *telebot.Message)) func(*telebot.Message) {
+	return func(m *telebot.Message) {
+		b.enqueue(m.Chat.ID, m.Sender, command.Name, func(ctx context.Context) { handler(ctx, m) })
+	}
+}
+
+// onCallback is onMessage for the buttons with the given unique name, which
+// is what their metrics are labelled with.
+func (b *Bot) onCallback(unique string, handler func(ctx context.Context, c *telebot.Callback)) func(*telebot.Callback) {
+	return func(c *telebot.Callback) {
+		chatID := c.Sender.ID
+		if c.Message != nil {
+			chatID = c.Message.Chat.ID
+		}
+		b.enqueue(chatID, c.Sender, "button:"+unique, func(ctx context.Context) { handler(ctx, c) })
+	}
+}
+
+// enqueue queues handle behind the other updates of the chat, with a logger
+// tagged with the update, chat, sender and command, and records it under
+// command once it is done.
+func (b *Bot) enqueue(chatID int64, sender *telebot.User, command string, handle func(ctx context.Context)) {
+	logger := slog.Default().With(
+		"update_id", b.updateID,
+		"chat_id", chatID,
+		"command", command,
+	)
+	if sender != nil {
+		logger = logger.With("user_id", sender.ID)
+	}
+
+	b.push(chatID, logger, func(ctx context.Context) {
+		start := time.Now()
+		defer monitoring.ObserveCommand(command, start)
+		defer func() {
+			logger.Info("Handled command", "duration", time.Since(start))
+		}()
+		handle(ctx)
+	})
+}
+
+// push queues handle behind the other work of the chat and counts it as in
+// flight until it returns, so Shutdown waits for it. handle gets a
+// time-bounded context carrying logger. Work pushed after Shutdown began is
+// dropped.
+func (b *Bot) push(chatID int64, logger *slog.Logger, handle func(ctx context.Context)) {
+	b.mu.RLock()
+	if b.stopping {
+		b.mu.RUnlock()
+		logger.Debug("Dropped during shutdown")
+		return
+	}
+	b.inFlight.Add(1)
+	b.mu.RUnlock()
+
+	b.queue.push(chatID, func() {
+		defer b.inFlight.Done()
+		ctx, cancel := context.WithTimeout(b.ctx, HANDLER_TIMEOUT)
+		defer cancel()
+		handle(logging.NewContext(ctx, logger))
+	})
+}
+
+// ErrPollerStopped is returned by Start when the poller stopped on its own
+// without saying why.
+var ErrPollerStopped = errors.New("poller stopped receiving updates")
+
+// Start receives updates until Shutdown and dispatches them one after the
+// other, as telebot's own Start does, remembering the ID of each so the
+// handlers can log it. It returns nil once shut down, or an error if the
+// poller gives up first, as a WebhookPoller does when Telegram refuses the
+// webhook or its listener cannot bind.
+func (b *Bot) Start() error {
+	stop := make(chan struct{})
+	polling := make(chan struct{})
+	go func() {
+		defer close(polling)
+		b.TelegramBot.Poller.Poll(b.TelegramBot, b.TelegramBot.Updates, stop)
+	}()
+
+	for {
+		select {
+		case upd := <-b.TelegramBot.Updates:
+			b.updateID = upd.ID
+			b.TelegramBot.ProcessUpdate(upd)
+		case <-polling:
+			// Pollers only return when stopped, unless they cannot go on.
+			if failed, ok := b.TelegramBot.Poller.(interface{ Err() error }); ok && failed.Err() != nil {
+				return failed.Err()
+			}
+			return ErrPollerStopped
+		case <-b.stop:
+			close(stop)
+			return nil
+		}
+	}
+}
+
+// QueueStats reports how many updates are waiting in the chat queues.
+func (b *Bot) QueueStats() QueueStats {
+	return b.queue.stats()
+}
+
+// Shutdown stops receiving updates and waits for the queued and running
+// handlers to finish. If ctx ends first their contexts are cancelled, so
+// their queries are abandoned, and ctx's error is returned. The bot must have
+// been started with Start.
+func (b *Bot) Shutdown(ctx context.Context) error {
+	b.stop <- struct{}{}
+
+	b.mu.Lock()
+	b.stopping = true
+	b.mu.Unlock()
+	defer b.cancel()
+
+	drained := make(chan struct{})
+	go func() {
+		b.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
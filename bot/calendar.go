@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"tg-sunday-league/calendar"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+	"tg-sunday-league/web"
+	"time"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// CALENDAR_RESET is the payload of /calendar that replaces the chat's link.
+const CALENDAR_RESET = "reset"
+
+// handleCalendar sends the link to subscribe to the chat's games, and the next
+// game as a calendar file to add it alone. Admins can revoke the link with
+// /calendar reset.
+func (b *Bot) handleCalendar(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
+		return
+	}
+
+	if strings.EqualFold(strings.TrimSpace(m.Payload), CALENDAR_RESET) {
+		if !b.isAdmin(ctx, m.Chat, m.Sender) {
+			return
+		}
+		if !b.calendarLinksAvailable(ctx, m.Chat) {
+			return
+		}
+		token, err := b.Tokens.ResetChatToken(ctx, m.Chat.ID, services.TOKEN_CALENDAR)
+		if err != nil {
+			b.sendError(ctx, m.Chat, err)
+			return
+		}
+		b.TelegramBot.Send(m.Chat, b.MessageFormater.Text(settings, "calendar.reset", web.CalendarURL(b.WebUrl, token)))
+		return
+	}
+
+	if b.calendarLinksAvailable(ctx, m.Chat) {
+		token, err := b.Tokens.ChatToken(ctx, m.Chat.ID, services.TOKEN_CALENDAR)
+		if err != nil {
+			b.sendError(ctx, m.Chat, err)
+			return
+		}
+		b.TelegramBot.Send(m.Chat, b.MessageFormater.Text(settings, "calendar.link", web.CalendarURL(b.WebUrl, token)))
+	}
+	b.sendNextGameCalendar(ctx, m.Chat, settings)
+}
+
+// calendarLinksAvailable reports whether the bot has a server for calendar
+// links, telling the chat if not.
+func (b *Bot) calendarLinksAvailable(ctx context.Context, chat *telebot.Chat) bool {
+	if b.Tokens == nil || b.WebUrl == "" {
+		b.sendError(ctx, chat, services.Invalid("", services.ERR_CALENDAR_UNAVAILABLE, nil))
+		return false
+	}
+	return true
+}
+
+// sendNextGameCalendar sends the chat's upcoming game as a calendar file, if
+// it has one.
+func (b *Bot) sendNextGameCalendar(ctx context.Context, chat *telebot.Chat, settings *models.ChatSettings) {
+	game, _, _, err := b.GameService.GetGameDetails(ctx, chat.ID)
+	if services.IsKind(err, services.KIND_NOT_FOUND) {
+		return
+	}
+	if err != nil {
+		b.sendError(ctx, chat, err)
+		return
+	}
+	if game.Date.Before(time.Now()) {
+		return
+	}
+
+	var file bytes.Buffer
+	if err := calendar.Write(&file, []models.Game{*game}, settings, time.Now()); err != nil {
+		logging.FromContext(ctx).Error("Could not write the calendar", "error", err)
+		b.sendError(ctx, chat, services.Internal(services.ERR_CALENDAR, err))
+		return
+	}
+	document := &telebot.Document{
+		File:     telebot.FromReader(&file),
+		FileName: calendar.FileName(game, settings.Timezone),
+		MIME:     "text/calendar",
+		Caption:  b.MessageFormater.Text(settings, "calendar.next_game"),
+	}
+	if _, err := b.TelegramBot.Send(chat, document); err != nil {
+		logging.FromContext(ctx).Error("Could not send the calendar", "error", err)
+	}
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// QueueStats is a snapshot of the per-chat command queues.
+type QueueStats struct {
+	// Chats is the number of chats with commands queued or running.
+	Chats int
+	// Depth is the number of commands queued or running over all chats.
+	Depth int
+	// MaxChatDepth is the most commands queued or running for a single chat.
+	MaxChatDepth int
+	// Processed is the number of commands finished since the bot started.
+	Processed uint64
+}
+
+// chatQueue runs the commands of each chat one at a time, in the order they
+// were pushed, while commands of different chats run in parallel. Each chat
+// with work gets a goroutine that exits once its queue is empty.
+type chatQueue struct {
+	mu sync.Mutex
+	// pending holds the commands of each chat, the running one first. A chat
+	// is only present while its goroutine runs.
+	pending   map[int64][]func()
+	processed uint64
+}
+
+func newChatQueue() *chatQueue {
+	return &chatQueue{pending: make(map[int64][]func())}
+}
+
+func (q *chatQueue) push(chatID int64, work func()) {
+	q.mu.Lock()
+	queue, running := q.pending[chatID]
+	q.pending[chatID] = append(queue, work)
+	q.mu.Unlock()
+
+	if !running {
+		go q.drain(chatID)
+	}
+}
+
+func (q *chatQueue) drain(chatID int64) {
+	q.mu.Lock()
+	for {
+		work := q.pending[chatID][0]
+		q.mu.Unlock()
+
+		run(work)
+
+		q.mu.Lock()
+		q.processed++
+		queue := q.pending[chatID][1:]
+		if len(queue) == 0 {
+			delete(q.pending, chatID)
+			q.mu.Unlock()
+			return
+		}
+		q.pending[chatID] = queue
+	}
+}
+
+// run calls work, logging a panic instead of taking down the bot, as telebot
+// does for the handlers it runs itself.
+func run(work func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Handler panicked", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	work()
+}
+
+func (q *chatQueue) stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := QueueStats{Chats: len(q.pending), Processed: q.processed}
+	for _, queue := range q.pending {
+		stats.Depth += len(queue)
+		if len(queue) > stats.MaxChatDepth {
+			stats.MaxChatDepth = len(queue)
+		}
+	}
+	return stats
+}
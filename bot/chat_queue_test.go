@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChatQueueRunsChatInOrder(t *testing.T) {
+	q := newChatQueue()
+	var mu sync.Mutex
+	var got []int
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		i := i
+		q.push(1, func() {
+			defer wg.Done()
+			mu.Lock()
+			got = append(got, i)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	for i := range got {
+		if got[i] != i {
+			t.Fatalf("ran %v, want 0 to 99 in order", got)
+		}
+	}
+}
+
+func TestChatQueueRunsChatsInParallel(t *testing.T) {
+	q := newChatQueue()
+	release := make(chan struct{})
+	blocked := make(chan struct{})
+	q.push(1, func() {
+		close(blocked)
+		<-release
+	})
+	<-blocked
+
+	done := make(chan struct{})
+	q.push(2, func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("chat 2 waited for chat 1")
+	}
+	close(release)
+}
+
+func TestChatQueueStats(t *testing.T) {
+	q := newChatQueue()
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+	block := func() {
+		started <- struct{}{}
+		<-release
+	}
+	q.push(1, block)
+	q.push(1, block)
+	q.push(1, block)
+	q.push(2, block)
+	<-started
+	<-started
+
+	want := QueueStats{Chats: 2, Depth: 4, MaxChatDepth: 3}
+	if got := q.stats(); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for q.stats().Processed < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v, want 4 processed", q.stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := q.stats(); got != (QueueStats{Processed: 4}) {
+		t.Errorf("stats after draining = %+v, want only 4 processed", got)
+	}
+}
+
+func TestChatQueueSurvivesPanic(t *testing.T) {
+	q := newChatQueue()
+	done := make(chan struct{})
+	q.push(1, func() { panic("boom") })
+	q.push(1, func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("queue stopped after a panicking command")
+	}
+}
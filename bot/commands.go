@@ -1,10 +1,13 @@
 package bot
 
 import (
-	"fmt"
-	"log"
+	"context"
 	"strings"
+	"tg-sunday-league/i18n"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
 	"tg-sunday-league/services"
+	"time"
 
 	"gopkg.in/tucnak/telebot.v2"
 )
@@ -14,35 +17,61 @@ type Command struct {
 	Description string
 }
 
+// Descriptions are keys of the message catalog.
 var (
-	HELP = Command{"/help", `Show this help message`}
-	NEW  = Command{"/new", `Create a new game with the specified date, time, location, opponent and price.
-							How to use: /new (YYYY-MM-DD, HH:MM, Location, Opponent, Price)
-							i.e: /new (2024-10-10, 11:00, Marina Bay Sands, Célavi FC, 15)`}
-	CANCEL  = Command{"/cancel", `Cancel the upcoming game`}
-	IN      = Command{"/in", `Register yourself for the upcoming game`}
-	OUT     = Command{"/out", `Mark yourself as absent for the upcoming game`}
-	DETAILS = Command{"/details", `Show the details of the game`}
-	PAID    = Command{"/paid", `Mark you as paid for the game`}
+	HELP      = Command{"/help", "command.help"}
+	NEW       = Command{"/new", "command.new"}
+	CANCEL    = Command{"/cancel", "command.cancel"}
+	IN        = Command{"/in", "command.in"}
+	OUT       = Command{"/out", "command.out"}
+	DETAILS   = Command{"/details", "command.details"}
+	PAID      = Command{"/paid", "command.paid"}
+	TIMEZONE  = Command{"/timezone", "command.timezone"}
+	SETTINGS  = Command{"/settings", "command.settings"}
+	EXPORT    = Command{"/export", "command.export"}
+	IMPORT    = Command{"/import", "command.import"}
+	CALENDAR  = Command{"/calendar", "command.calendar"}
+	API       = Command{"/api", "command.api"}
+	DASHBOARD = Command{"/dashboard", "command.dashboard"}
+	WEBHOOK   = Command{"/webhook", "command.webhook"}
+	BACKUP    = Command{"/backup", "command.backup"}
 )
-var commands = []Command{HELP, NEW, IN, OUT, DETAILS, PAID}
+
+// commands are listed by /help. BACKUP is left out, being for the owner only.
+var commands = []Command{HELP, NEW, IN, OUT, DETAILS, PAID, TIMEZONE, SETTINGS, EXPORT, IMPORT, CALENDAR, API, DASHBOARD, WEBHOOK}
 
 type IBotCommand interface {
-	handleNewGame(m *telebot.Message)
-	handleRegisterPlayer(m *telebot.Message)
-	handleHelp(m *telebot.Message)
-	handleDetails(m *telebot.Message)
-	handlePaid(m *telebot.Message)
-	handleCancelGame(m *telebot.Message)
-	isAdmin(bot *telebot.Bot, chat *telebot.Chat, user *telebot.User) bool
-	isMessageSentFromGroup(m *telebot.Message) bool
+	handleNewGame(ctx context.Context, m *telebot.Message)
+	handleRegisterPlayer(ctx context.Context, m *telebot.Message)
+	handleHelp(ctx context.Context, m *telebot.Message)
+	handleDetails(ctx context.Context, m *telebot.Message)
+	handlePaid(ctx context.Context, m *telebot.Message)
+	handleCancelGame(ctx context.Context, m *telebot.Message)
+	handleTimezone(ctx context.Context, m *telebot.Message)
+	handleSettings(ctx context.Context, m *telebot.Message)
+	handleSettingsCallback(ctx context.Context, c *telebot.Callback)
+	handleExport(ctx context.Context, m *telebot.Message)
+	handleImport(ctx context.Context, m *telebot.Message)
+	handleImportDocument(m *telebot.Message)
+	handleCalendar(ctx context.Context, m *telebot.Message)
+	handleAPI(ctx context.Context, m *telebot.Message)
+	handleDashboard(ctx context.Context, m *telebot.Message)
+	handleWebhook(ctx context.Context, m *telebot.Message)
+	handleBackup(ctx context.Context, m *telebot.Message)
+	canCreateGame(ctx context.Context, chat *telebot.Chat, user *telebot.User) bool
+	isAdmin(ctx context.Context, chat *telebot.Chat, user *telebot.User) bool
+	isChatAdmin(ctx context.Context, chat *telebot.Chat, user *telebot.User) bool
+	isMessageSentFromGroup(ctx context.Context, m *telebot.Message) bool
 }
 
-func (b *Bot) handleNewGame(m *telebot.Message) {
-	if !b.isMessageSentFromGroup(m) {
+// The build fails when Bot and IBotCommand drift apart.
+var _ IBotCommand = (*Bot)(nil)
+
+func (b *Bot) handleNewGame(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
 		return
 	}
-	if !b.isAdmin(m.Chat, m.Sender) {
+	if !b.canCreateGame(ctx, m.Chat, m.Sender) {
 		return
 	}
 
@@ -52,39 +81,47 @@ func (b *Bot) handleNewGame(m *telebot.Message) {
 	for i := range args {
 		args[i] = strings.TrimSpace(args[i])
 	}
-	fmt.Println(len(args))
-	if len(args) < 4 {
-		b.TelegramBot.Send(m.Chat, "Invalid format. Please use:\n/new (YYYY-MM-DD, HH:MM, Location, Opponent, Optional[Price])")
+	logging.FromContext(ctx).Debug("Parsed /new arguments", "count", len(args))
+	if args[0] == "" || args[0] == "/new" {
+		b.sendText(ctx, m.Chat, "new.usage")
 		return
 	}
-	game, players, absentees, err := b.GameService.CreateNewGame(m.Chat.ID, m.Sender.ID, m.Sender.FirstName, args)
+	game, players, absentees, err := b.GameService.CreateNewGame(ctx, m.Chat.ID, m.Sender.ID, m.Sender.FirstName, args)
 	if err != nil {
-		b.TelegramBot.Send(m.Chat, err.Error())
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
 		return
 	}
-	message := b.MessageFormater.GameDetailsMessage(game, players, absentees)
+	message := b.MessageFormater.GameDetailsMessage(game, players, absentees, settings)
 	b.TelegramBot.Send(m.Chat, message)
 }
 
-func (b *Bot) handleCancelGame(m *telebot.Message) {
-	if !b.isMessageSentFromGroup(m) {
+func (b *Bot) handleCancelGame(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
 		return
 	}
-	if !b.isAdmin(m.Chat, m.Sender) {
+	if !b.isAdmin(ctx, m.Chat, m.Sender) {
 		return
 	}
 
-	game, err := b.GameService.CancelGame(m.Chat.ID)
+	game, err := b.GameService.CancelGame(ctx, m.Chat.ID)
 	if err != nil {
-		b.TelegramBot.Send(m.Chat, err.Error())
+		b.sendError(ctx, m.Chat, err)
 		return
 	}
-	message := fmt.Sprintf("Game on %s has been cancelled.", game.Date.Format("2006-01-02 15:04"))
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
+		return
+	}
+	message := b.MessageFormater.CancelledGameMessage(game, settings)
 	b.TelegramBot.Send(m.Chat, message)
 }
 
-func (b *Bot) handleRegisterPlayer(m *telebot.Message) {
-	if !b.isMessageSentFromGroup(m) {
+func (b *Bot) handleRegisterPlayer(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
 		return
 	}
 	var status services.PlayerStatus
@@ -103,52 +140,157 @@ func (b *Bot) handleRegisterPlayer(m *telebot.Message) {
 	}
 	chatID := m.Chat.ID
 
-	game, players, absentees, err := b.GameService.RegisterPlayer(&chatID, &playerID, &playerName, status)
+	game, players, absentees, err := b.GameService.RegisterPlayer(ctx, &chatID, &playerID, &playerName, status)
 	if err != nil {
-		b.TelegramBot.Send(m.Chat, err.Error())
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
 		return
 	}
 
-	message := b.MessageFormater.GameDetailsMessage(game, players, absentees)
+	message := b.MessageFormater.GameDetailsMessage(game, players, absentees, settings)
 	b.TelegramBot.Send(m.Chat, message)
 }
 
-func (b *Bot) handleHelp(m *telebot.Message) {
-	b.TelegramBot.Send(m.Chat, b.MessageFormater.HelpMessage())
+func (b *Bot) handleHelp(ctx context.Context, m *telebot.Message) {
+	b.TelegramBot.Send(m.Chat, b.MessageFormater.HelpMessage(b.settingsFor(ctx, m.Chat)))
 }
 
-func (b *Bot) handleDetails(m *telebot.Message) {
-	game, players, absentees, err := b.GameService.GetGameDetails(m.Chat.ID)
+func (b *Bot) handleDetails(ctx context.Context, m *telebot.Message) {
+	game, players, absentees, err := b.GameService.GetGameDetails(ctx, m.Chat.ID)
 	if err != nil {
-		b.TelegramBot.Send(m.Chat, err.Error())
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
 		return
 	}
-	message := b.MessageFormater.GameDetailsMessage(game, players, absentees)
+	message := b.MessageFormater.GameDetailsMessage(game, players, absentees, settings)
 	b.TelegramBot.Send(m.Chat, message)
 }
 
-func (b *Bot) handlePaid(m *telebot.Message) {
-	if !b.isMessageSentFromGroup(m) {
+func (b *Bot) handlePaid(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
 		return
 	}
 	playerID := m.Sender.ID
 	chatID := m.Chat.ID
 
-	game, players, absentees, err := b.GameService.RepayGame(&chatID, &playerID)
+	game, players, absentees, err := b.GameService.RepayGame(ctx, &chatID, &playerID)
 
 	if err != nil {
-		b.TelegramBot.Send(m.Chat, err.Error())
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
 		return
 	}
 
-	message := b.MessageFormater.GameDetailsMessage(game, players, absentees)
+	message := b.MessageFormater.GameDetailsMessage(game, players, absentees, settings)
 	b.TelegramBot.Send(m.Chat, message)
 }
 
-func (b *Bot) isAdmin(chat *telebot.Chat, user *telebot.User) bool {
+func (b *Bot) handleTimezone(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
+		return
+	}
+
+	timezone := strings.TrimSpace(m.Payload)
+	if timezone == "" {
+		settings, ok := b.chatSettings(ctx, m.Chat)
+		if !ok {
+			return
+		}
+		b.TelegramBot.Send(m.Chat, b.MessageFormater.TimezoneMessage(settings))
+		return
+	}
+	if !b.isAdmin(ctx, m.Chat, m.Sender) {
+		return
+	}
+
+	settings, err := b.SettingsService.UpdateSetting(ctx, m.Chat.ID, services.SETTING_TIMEZONE, timezone)
+	if err != nil {
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	b.TelegramBot.Send(m.Chat, b.MessageFormater.TimezoneMessage(settings))
+}
+
+// chatSettings returns the chat's settings, telling the chat if they could not
+// be loaded.
+func (b *Bot) chatSettings(ctx context.Context, chat *telebot.Chat) (*models.ChatSettings, bool) {
+	settings, err := b.SettingsService.GetSettings(ctx, chat.ID)
+	if err != nil {
+		b.TelegramBot.Send(chat, b.errorMessage(ctx, err, fallbackSettings()))
+		return nil, false
+	}
+	return settings, true
+}
+
+// settingsFor returns the chat's settings, or fallbackSettings if they could
+// not be loaded, so the chat can always be told what happened.
+func (b *Bot) settingsFor(ctx context.Context, chat *telebot.Chat) *models.ChatSettings {
+	settings, err := b.SettingsService.GetSettings(ctx, chat.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not load chat settings", "error", err)
+		return fallbackSettings()
+	}
+	return settings
+}
+
+func fallbackSettings() *models.ChatSettings {
+	return &models.ChatSettings{Language: i18n.DefaultLanguage, Timezone: time.UTC}
+}
+
+func (b *Bot) sendError(ctx context.Context, chat *telebot.Chat, err error) {
+	b.TelegramBot.Send(chat, b.errorMessage(ctx, err, b.settingsFor(ctx, chat)))
+}
+
+// errorMessage renders err for the chat, counting it by kind and logging it
+// if it is not a service error.
+func (b *Bot) errorMessage(ctx context.Context, err error, settings *models.ChatSettings) string {
+	countError(err)
+	if services.AsError(err) == nil {
+		logging.FromContext(ctx).Error("Unexpected error", "error", err)
+	}
+	return b.MessageFormater.ErrorMessage(err, settings)
+}
+
+func (b *Bot) sendText(ctx context.Context, chat *telebot.Chat, key string, args ...interface{}) {
+	b.TelegramBot.Send(chat, b.MessageFormater.Text(b.settingsFor(ctx, chat), key, args...))
+}
+
+// canCreateGame reports whether user may create games in chat, which depends
+// on the chat's game_creators setting.
+func (b *Bot) canCreateGame(ctx context.Context, chat *telebot.Chat, user *telebot.User) bool {
+	creators, err := b.SettingsService.GameCreators(ctx, chat.ID)
+	if err != nil {
+		b.sendError(ctx, chat, err)
+		return false
+	}
+	if creators == services.EVERYONE {
+		return true
+	}
+	return b.isAdmin(ctx, chat, user)
+}
+
+func (b *Bot) isAdmin(ctx context.Context, chat *telebot.Chat, user *telebot.User) bool {
+	if b.isChatAdmin(ctx, chat, user) {
+		return true
+	}
+	b.sendError(ctx, chat, services.PermissionDenied(services.ERR_ADMINS_ONLY))
+	return false
+}
+
+// isChatAdmin is isAdmin without telling the chat when the user is not one.
+func (b *Bot) isChatAdmin(ctx context.Context, chat *telebot.Chat, user *telebot.User) bool {
 	admins, err := b.TelegramBot.AdminsOf(chat)
 	if err != nil {
-		log.Printf("Could not get admins of chat: %v", err)
+		logging.FromContext(ctx).Error("Could not get chat admins", "error", err)
 		return false
 	}
 	for _, admin := range admins {
@@ -156,13 +298,12 @@ func (b *Bot) isAdmin(chat *telebot.Chat, user *telebot.User) bool {
 			return true
 		}
 	}
-	b.TelegramBot.Send(chat, "Only admins of the group can create a new game.")
 	return false
 }
 
-func (b *Bot) isMessageSentFromGroup(m *telebot.Message) bool {
+func (b *Bot) isMessageSentFromGroup(ctx context.Context, m *telebot.Message) bool {
 	if !m.FromGroup() {
-		b.TelegramBot.Send(m.Chat, "This bot is intended to work for group chat only. /help for more info")
+		b.sendText(ctx, m.Chat, "group_only")
 		return false
 	}
 	return true
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/services"
+	"tg-sunday-league/web"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// handleDashboard sends whoever asks a link signing them in to the chat's
+// web dashboard, in their private chat with the bot so no one else uses it.
+func (b *Bot) handleDashboard(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
+		return
+	}
+	if b.Sessions == nil || b.WebUrl == "" {
+		b.sendError(ctx, m.Chat, services.Invalid("", services.ERR_DASHBOARD_UNAVAILABLE, nil))
+		return
+	}
+
+	code, err := b.Sessions.NewLogin(ctx, m.Chat.ID, int64(m.Sender.ID))
+	if err != nil {
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	text := b.MessageFormater.Text(settings, "dashboard.link", m.Chat.Title, web.DashboardLoginURL(b.WebUrl, code))
+	// A preview would have Telegram fetch the link, which is harmless, but
+	// is of no use either.
+	if _, err := b.TelegramBot.Send(m.Sender, text, telebot.NoPreview); err != nil {
+		logging.FromContext(ctx).Warn("Could not send the dashboard link", "error", err)
+		b.sendError(ctx, m.Chat, services.Invalid("", services.ERR_DASHBOARD_SEND, err))
+		return
+	}
+	b.sendText(ctx, m.Chat, "dashboard.sent")
+}
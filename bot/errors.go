@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"log/slog"
+	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+)
+
+// ErrorMessage is the one place errors are turned into what the chat reads.
+// Service errors are rendered from their code in the chat's language, except
+// that internal failures never show their arguments or cause, which the
+// services have already logged. Any other error is unexpected and reported as
+// a generic failure, and logged by the caller, which knows the update.
+func (m *MessageFormatter) ErrorMessage(err error, settings *models.ChatSettings) string {
+	l := localizer(settings)
+	serviceErr := services.AsError(err)
+	if serviceErr == nil {
+		return l.T("error.unknown")
+	}
+
+	switch serviceErr.Kind {
+	case services.KIND_INTERNAL:
+		return l.T(string(serviceErr.Code))
+	case services.KIND_NOT_FOUND,
+		services.KIND_CONFLICT,
+		services.KIND_VALIDATION,
+		services.KIND_PERMISSION_DENIED:
+		return l.T(string(serviceErr.Code), serviceErr.Args...)
+	}
+	slog.Error("Error of unknown kind", "error", err)
+	return l.T("error.unknown")
+}
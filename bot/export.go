@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"tg-sunday-league/export"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/services"
+	"time"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// handleExport sends the chat's games of a period, with who played and paid,
+// as a CSV or XLSX file: /export [csv|xlsx] [from] [to].
+func (b *Bot) handleExport(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
+		return
+	}
+	if !b.isAdmin(ctx, m.Chat, m.Sender) {
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
+		return
+	}
+	format, from, to, ok := parseExportArgs(m.Payload, settings.Timezone)
+	if !ok {
+		b.sendText(ctx, m.Chat, "export.usage")
+		return
+	}
+
+	report, err := b.GameService.ExportGames(ctx, m.Chat.ID, from, to)
+	if err != nil {
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	var file bytes.Buffer
+	if err := export.Write(&file, format, report, settings); err != nil {
+		logging.FromContext(ctx).Error("Could not write the export", "error", err)
+		b.sendError(ctx, m.Chat, services.Internal(services.ERR_EXPORT, err))
+		return
+	}
+	document := &telebot.Document{
+		File:     telebot.FromReader(&file),
+		FileName: export.FileName(report, format, settings),
+		Caption:  b.MessageFormater.Text(settings, "export.caption", len(report.Games)),
+	}
+	if _, err := b.TelegramBot.Send(m.Chat, document); err != nil {
+		logging.FromContext(ctx).Error("Could not send the export", "error", err)
+	}
+}
+
+// parseExportArgs reads the optional format, CSV by default, and the first
+// and last days of the period, as YYYY-MM-DD in loc. Days left out leave the
+// period open on that side.
+func parseExportArgs(payload string, loc *time.Location) (format string, from time.Time, to time.Time, ok bool) {
+	args := strings.Fields(payload)
+	format = export.FORMAT_CSV
+	if len(args) > 0 && slices.Contains(export.FORMATS, strings.ToLower(args[0])) {
+		format = strings.ToLower(args[0])
+		args = args[1:]
+	}
+	if len(args) > 2 {
+		return "", time.Time{}, time.Time{}, false
+	}
+
+	var days []time.Time
+	for _, arg := range args {
+		day, err := time.ParseInLocation(time.DateOnly, arg, loc)
+		if err != nil {
+			return "", time.Time{}, time.Time{}, false
+		}
+		days = append(days, day)
+	}
+	if len(days) > 0 {
+		from = days[0]
+	}
+	if len(days) > 1 {
+		// The last day is included.
+		to = days[1].AddDate(0, 0, 1)
+	}
+	return format, from, to, true
+}
@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"tg-sunday-league/importer"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"time"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// FIXTURES_INTERVAL is how often chats are checked for a fixture to open,
+// which bounds how long after a kickoff answers open for the next game.
+const FIXTURES_INTERVAL = time.Minute
+
+// importFixtures checks the fixture list sent with /import, or schedules it
+// with /import confirm, announcing the game opened right away if any.
+func (b *Bot) importFixtures(ctx context.Context, chat *telebot.Chat, file io.Reader, settings *models.ChatSettings, dryRun bool) {
+	in, err := importer.ReadICS(file, settings.Timezone)
+	if err != nil {
+		b.sendError(ctx, chat, err)
+		return
+	}
+	report, err := b.GameService.ImportFixtures(ctx, chat.ID, in, dryRun)
+	if err != nil {
+		b.sendError(ctx, chat, err)
+		return
+	}
+	b.TelegramBot.Send(chat, b.MessageFormater.FixturesReportMessage(report, settings))
+	if report.Opened != nil {
+		b.announceFixture(ctx, chat, report.Opened, &[]models.User{}, &[]models.User{}, settings)
+	}
+}
+
+// RunFixtures opens the next fixture of every chat whose upcoming game
+// kicked off, every interval until ctx ends.
+func (b *Bot) RunFixtures(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		b.OpenFixtures(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// OpenFixtures queues opening the next fixture of the chats without an
+// upcoming game, and announcing it, behind the updates of each chat, so it
+// never races with a /new or /cancel there.
+func (b *Bot) OpenFixtures(ctx context.Context) {
+	chats, err := b.GameService.ChatsWithFixtures(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Could not list chats with fixtures", "error", err)
+		}
+		return
+	}
+	for _, chatId := range chats {
+		b.push(chatId, slog.Default().With("chat_id", chatId), func(ctx context.Context) {
+			game, players, absentees, err := b.GameService.OpenNextFixture(ctx, chatId)
+			if err != nil || game == nil {
+				return
+			}
+			chat := &telebot.Chat{ID: chatId}
+			b.announceFixture(ctx, chat, game, players, absentees, b.settingsFor(ctx, chat))
+		})
+	}
+}
+
+func (b *Bot) announceFixture(ctx context.Context, chat *telebot.Chat, game *models.Game, players, absentees *[]models.User, settings *models.ChatSettings) {
+	text := b.MessageFormater.Text(settings, "fixtures.opened", b.MessageFormater.GameDetailsMessage(game, players, absentees, settings))
+	if _, err := b.TelegramBot.Send(chat, text); err != nil {
+		logging.FromContext(ctx).Error("Could not announce the fixture", "game_id", game.Id, "error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"tg-sunday-league/importer"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/services"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// IMPORT_CONFIRM is the argument of /import that writes the games rather than
+// only checking the file.
+const IMPORT_CONFIRM = "confirm"
+
+// handleImportDocument handles the documents sent with /import as their
+// caption, which telebot does not route as commands. Other documents are
+// ignored.
+func (b *Bot) handleImportDocument(m *telebot.Message) {
+	command, _ := splitCaption(m.Caption)
+	if command != IMPORT.Name {
+		return
+	}
+	b.onMessage(IMPORT, b.handleImport)(m)
+}
+
+// handleImport checks the CSV file of past games sent with /import, or
+// imports it with /import confirm. iCalendar files are fixture lists, which
+// are scheduled instead. Sent without a file it explains how to use it.
+func (b *Bot) handleImport(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
+		return
+	}
+	if !b.isAdmin(ctx, m.Chat, m.Sender) {
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
+		return
+	}
+	_, payload := splitCaption(m.Caption)
+	if m.Document == nil || (payload != "" && payload != IMPORT_CONFIRM) {
+		b.sendText(ctx, m.Chat, "import.usage")
+		return
+	}
+
+	file, err := b.TelegramBot.GetFile(&m.Document.File)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not download the file to import", "error", err)
+		b.sendError(ctx, m.Chat, services.Internal(services.ERR_IMPORT, err))
+		return
+	}
+	defer file.Close()
+	if importer.IsICS(m.Document.FileName, m.Document.MIME) {
+		b.importFixtures(ctx, m.Chat, file, settings, payload != IMPORT_CONFIRM)
+		return
+	}
+	in, err := importer.ReadCSV(file, settings.Timezone)
+	if err != nil {
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+
+	report, err := b.GameService.ImportGames(ctx, m.Chat.ID, in, payload != IMPORT_CONFIRM)
+	if err != nil {
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	b.TelegramBot.Send(m.Chat, b.MessageFormater.ImportReportMessage(report, settings))
+}
+
+// splitCaption splits a caption such as "/import@bot confirm" into the
+// command, without the bot's name, and the rest.
+func splitCaption(caption string) (string, string) {
+	command, payload, _ := strings.Cut(strings.TrimSpace(caption), " ")
+	command, _, _ = strings.Cut(command, "@")
+	return command, strings.TrimSpace(payload)
+}
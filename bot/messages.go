@@ -2,39 +2,170 @@ package bot
 
 import (
 	"fmt"
+	"strings"
+	"tg-sunday-league/i18n"
 	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+	"time"
 )
 
+// IMessageFormater renders every message the bot sends. Messages are written
+// in the chat's language and times in the chat's time zone, both taken from
+// its settings.
 type IMessageFormater interface {
-	GameDetailsMessage(game *models.Game, players, absentees *[]models.User) string
-	HelpMessage() string
+	GameDetailsMessage(game *models.Game, players, absentees *[]models.User, settings *models.ChatSettings) string
+	CancelledGameMessage(game *models.Game, settings *models.ChatSettings) string
+	TimezoneMessage(settings *models.ChatSettings) string
+	SettingsMessage(settings *models.ChatSettings) string
+	HelpMessage(settings *models.ChatSettings) string
+	ErrorMessage(err error, settings *models.ChatSettings) string
+	ImportReportMessage(report *services.ImportReport, settings *models.ChatSettings) string
+	FixturesReportMessage(report *services.FixturesReport, settings *models.ChatSettings) string
+	Text(settings *models.ChatSettings, key string, args ...interface{}) string
 	formatUserList(l *[]models.User) string
 }
 
 type MessageFormatter struct{}
 
-func (m *MessageFormatter) GameDetailsMessage(game *models.Game, players, absentees *[]models.User) string {
+func (m *MessageFormatter) GameDetailsMessage(game *models.Game, players, absentees *[]models.User, settings *models.ChatSettings) string {
+	l := localizer(settings)
 	playerList := m.formatUserList(players)
 	absenteesList := m.formatUserList(absentees)
-	return fmt.Sprintf("Game on %s\nLocation: %s\nOpponent: %s\nPlayers: %s\nAbsentees: %s",
-		game.Date.Format("2006-01-02 15:04"),
+	playerCount := ""
+	if settings.SquadSize > 0 {
+		playerCount = fmt.Sprintf(" (%d/%d)", len(*players), settings.SquadSize)
+	}
+	price := ""
+	if game.Price > 0 {
+		price = l.T("game.price", l.Amount(game.Price, settings.Currency))
+	}
+	return l.T("game.details",
+		game.Date,
 		game.Location,
 		game.Opponent,
+		price,
+		playerCount,
 		playerList,
 		absenteesList)
 }
 
-func (m *MessageFormatter) HelpMessage() string {
+func (m *MessageFormatter) CancelledGameMessage(game *models.Game, settings *models.ChatSettings) string {
+	return localizer(settings).T("game.cancelled", game.Date)
+}
 
-	helpText := `Bot Commands:
+func (m *MessageFormatter) TimezoneMessage(settings *models.ChatSettings) string {
+	return localizer(settings).T("timezone.current", settings.Timezone.String(), time.Now())
+}
 
-	`
+func (m *MessageFormatter) SettingsMessage(settings *models.ChatSettings) string {
+	l := localizer(settings)
+	text := l.T("settings.title") + "\n\n"
+	for _, key := range services.SettingKeys {
+		value := services.FormatSetting(settings, key)
+		if value == "" {
+			value = "-"
+		}
+		text += fmt.Sprintf("%s (%s): %s\n", l.T("setting."+string(key)), key, value)
+	}
+	text += "\n" + l.T("settings.help")
+	return text
+}
+
+func (m *MessageFormatter) HelpMessage(settings *models.ChatSettings) string {
+	l := localizer(settings)
+	helpText := l.T("help.title") + "\n\n"
 	for _, c := range commands {
-		helpText += c.Name + " - " + c.Description + "\n"
+		helpText += c.Name + " - " + l.T(c.Description) + "\n"
 	}
 	return helpText
 }
 
+// MAX_IMPORT_CONFLICTS is how many conflicts of an import are listed, to keep
+// the message within Telegram's limit.
+const MAX_IMPORT_CONFLICTS = 20
+
+func (m *MessageFormatter) ImportReportMessage(report *services.ImportReport, settings *models.ChatSettings) string {
+	l := localizer(settings)
+	if len(report.Conflicts) > 0 {
+		text := l.T("import.conflicts", len(report.Conflicts))
+		for i, conflict := range report.Conflicts {
+			if i == MAX_IMPORT_CONFLICTS {
+				text += "\n" + l.T("import.more", len(report.Conflicts)-i)
+				break
+			}
+			text += "\n" + l.T("import.line", conflict.Line, l.T(string(conflict.Code), conflict.Args...))
+		}
+		return text
+	}
+
+	text := l.T("import.done", report.Games, report.Answers)
+	if report.DryRun {
+		text = l.T("import.preview", report.Games, report.Answers)
+	}
+	if len(report.NewPlayers) > 0 {
+		text += "\n" + l.T("import.new_players", strings.Join(report.NewPlayers, ", "))
+	}
+	return text
+}
+
+func (m *MessageFormatter) FixturesReportMessage(report *services.FixturesReport, settings *models.ChatSettings) string {
+	l := localizer(settings)
+	text := l.T("fixtures.done", len(report.Added))
+	if report.DryRun {
+		text = l.T("fixtures.preview", len(report.Added))
+	}
+	text += m.formatFixtures(l, report.Added)
+	if len(report.Moved) > 0 {
+		text += "\n\n" + l.T("fixtures.moved", len(report.Moved)) + m.formatFixtures(l, report.Moved)
+	}
+	if len(report.Removed) > 0 {
+		text += "\n\n" + l.T("fixtures.removed", len(report.Removed)) + m.formatFixtures(l, report.Removed)
+	}
+	if len(report.Rescheduled) > 0 {
+		text += "\n\n" + l.T("fixtures.rescheduled") + m.formatFixtures(l, report.Rescheduled)
+	}
+	if len(report.Cancelled) > 0 {
+		text += "\n\n" + l.T("fixtures.cancelled") + m.formatFixtures(l, report.Cancelled)
+	}
+	if report.Past > 0 {
+		text += "\n" + l.T("fixtures.past", report.Past)
+	}
+	if report.Undated > 0 {
+		text += "\n" + l.T("fixtures.undated", report.Undated)
+	}
+	if report.DryRun {
+		text += "\n\n" + l.T("fixtures.confirm")
+	}
+	return text
+}
+
+// formatFixtures lists fixtures a line each, up to MAX_IMPORT_CONFLICTS.
+func (m *MessageFormatter) formatFixtures(l *i18n.Localizer, fixtures []models.Fixture) string {
+	text := ""
+	for i, fixture := range fixtures {
+		if i == MAX_IMPORT_CONFLICTS {
+			text += "\n" + l.T("import.more", len(fixtures)-i)
+			break
+		}
+		details := []string{l.DateTime(fixture.Date)}
+		for _, detail := range []string{fixture.Opponent, fixture.Location} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		text += "\n" + strings.Join(details, ", ")
+	}
+	return text
+}
+
+func (m *MessageFormatter) Text(settings *models.ChatSettings, key string, args ...interface{}) string {
+	return localizer(settings).T(key, args...)
+}
+
+func localizer(settings *models.ChatSettings) *i18n.Localizer {
+	return i18n.For(settings.Language, settings.Timezone)
+}
+
 func (m *MessageFormatter) formatUserList(l *[]models.User) string {
 	userlist := ""
 	copy_list := *l
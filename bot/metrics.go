@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"context"
+	"tg-sunday-league/monitoring"
+	"tg-sunday-league/services"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collectors returns the metrics of this bot's chat queues, to be registered
+// next to the package-wide ones in monitoring.
+func (b *Bot) Collectors() []prometheus.Collector {
+	gauge := func(name string, help string, value func(QueueStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: monitoring.NAMESPACE,
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(b.QueueStats()) })
+	}
+	return []prometheus.Collector{
+		gauge("queue_chats", "Chats with commands queued or running.",
+			func(s QueueStats) float64 { return float64(s.Chats) }),
+		gauge("queue_depth", "Commands queued or running over all chats.",
+			func(s QueueStats) float64 { return float64(s.Depth) }),
+		gauge("queue_max_chat_depth", "Most commands queued or running for a single chat.",
+			func(s QueueStats) float64 { return float64(s.MaxChatDepth) }),
+	}
+}
+
+// Ping checks that the Bot API answers, for health checks.
+func (b *Bot) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := b.TelegramBot.Raw("getMe", nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// countError records an error reported to a chat under its kind.
+func countError(err error) {
+	kind := "unknown"
+	if serviceErr := services.AsError(err); serviceErr != nil {
+		kind = string(serviceErr.Kind)
+	}
+	monitoring.ErrorsTotal.WithLabelValues(kind).Inc()
+}
@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// settingsButton is the endpoint every button of the /settings keyboard is
+// routed to. The button data says which setting to change and how.
+var settingsButton = telebot.InlineButton{Unique: "settings"}
+
+// handleSettings shows the chat settings, or changes one when called as
+// /settings <name> <value>.
+func (b *Bot) handleSettings(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
+		return
+	}
+
+	args := strings.Fields(m.Payload)
+	if len(args) == 0 {
+		settings, ok := b.chatSettings(ctx, m.Chat)
+		if !ok {
+			return
+		}
+		b.TelegramBot.Send(m.Chat, b.MessageFormater.SettingsMessage(settings), b.settingsMarkup(settings))
+		return
+	}
+	if !b.isAdmin(ctx, m.Chat, m.Sender) {
+		return
+	}
+
+	key := services.SettingKey(strings.ToLower(args[0]))
+	value := strings.TrimSpace(strings.TrimPrefix(m.Payload, args[0]))
+	settings, err := b.SettingsService.UpdateSetting(ctx, m.Chat.ID, key, value)
+	if err != nil {
+		b.sendError(ctx, m.Chat, err)
+		return
+	}
+	b.TelegramBot.Send(m.Chat, b.MessageFormater.SettingsMessage(settings), b.settingsMarkup(settings))
+}
+
+func (b *Bot) handleSettingsCallback(ctx context.Context, c *telebot.Callback) {
+	if c.Message == nil {
+		b.TelegramBot.Respond(c, &telebot.CallbackResponse{})
+		return
+	}
+	chatID := c.Message.Chat.ID
+	settings, err := b.SettingsService.GetSettings(ctx, chatID)
+	if err != nil {
+		b.TelegramBot.Respond(c, &telebot.CallbackResponse{Text: b.errorMessage(ctx, err, fallbackSettings())})
+		return
+	}
+	if !b.isChatAdmin(ctx, c.Message.Chat, c.Sender) {
+		err = services.PermissionDenied(services.ERR_SETTINGS_ADMINS_ONLY)
+		b.TelegramBot.Respond(c, &telebot.CallbackResponse{Text: b.errorMessage(ctx, err, settings)})
+		return
+	}
+
+	action, arg, _ := strings.Cut(c.Data, ":")
+	key := services.SettingKey(arg)
+	var value string
+	switch action {
+	case "next":
+		value = nextOption(services.FormatSetting(settings, key), settingOptions(key))
+	case "squad":
+		delta, _ := strconv.Atoi(arg)
+		key = services.SETTING_SQUAD_SIZE
+		value = strconv.Itoa(settings.SquadSize + delta)
+	case "hint":
+		b.TelegramBot.Respond(c, &telebot.CallbackResponse{
+			Text:      b.MessageFormater.Text(settings, "settings.hint", key),
+			ShowAlert: true,
+		})
+		return
+	default:
+		b.TelegramBot.Respond(c, &telebot.CallbackResponse{})
+		return
+	}
+
+	updated, err := b.SettingsService.UpdateSetting(ctx, chatID, key, value)
+	if err != nil {
+		b.TelegramBot.Respond(c, &telebot.CallbackResponse{Text: b.errorMessage(ctx, err, settings)})
+		return
+	}
+	settings = updated
+	b.TelegramBot.Edit(c.Message, b.MessageFormater.SettingsMessage(settings), b.settingsMarkup(settings))
+	b.TelegramBot.Respond(c, &telebot.CallbackResponse{})
+}
+
+// settingsMarkup builds the keyboard under the settings message. Settings with
+// a few known values are cycled in place, the squad size is stepped and the
+// rest explain how to set them with a command.
+func (b *Bot) settingsMarkup(settings *models.ChatSettings) *telebot.ReplyMarkup {
+	button := func(data string, key string, args ...interface{}) telebot.InlineButton {
+		btn := *settingsButton.With(data)
+		btn.Text = b.MessageFormater.Text(settings, key, args...)
+		return btn
+	}
+
+	return &telebot.ReplyMarkup{
+		InlineKeyboard: [][]telebot.InlineButton{
+			{
+				button("squad:-1", "settings.button.squad_down"),
+				button("squad:1", "settings.button.squad_up"),
+			},
+			{
+				button("next:"+string(services.SETTING_LANGUAGE), "settings.button.language", settings.Language),
+				button("next:"+string(services.SETTING_GAME_CREATORS), "settings.button.game_creators", settings.GameCreators),
+			},
+			{
+				button("hint:"+string(services.SETTING_CURRENCY), "setting.currency"),
+				button("hint:"+string(services.SETTING_DEFAULT_PRICE), "setting.default_price"),
+			},
+			{
+				button("hint:"+string(services.SETTING_TIMEZONE), "setting.timezone"),
+				button("hint:"+string(services.SETTING_REMINDERS), "setting.reminders"),
+			},
+		},
+	}
+}
+
+func settingOptions(key services.SettingKey) []string {
+	switch key {
+	case services.SETTING_LANGUAGE:
+		return services.SupportedLanguages
+	case services.SETTING_GAME_CREATORS:
+		return []string{string(services.ADMINS), string(services.EVERYONE)}
+	}
+	return nil
+}
+
+func nextOption(current string, options []string) string {
+	for i, option := range options {
+		if option == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	if len(options) == 0 {
+		return current
+	}
+	return options[0]
+}
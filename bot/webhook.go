@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// SECRET_TOKEN_HEADER carries the webhook's secret token on every update
+// Telegram posts.
+const SECRET_TOKEN_HEADER = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookPoller receives the updates Telegram posts to PublicURL on an HTTP
+// listener of its own, instead of long polling for them. Requests without the
+// secret token are rejected. With TLSCert and TLSKey set the listener serves
+// TLS itself, otherwise it expects a reverse proxy to terminate TLS.
+type WebhookPoller struct {
+	// Listen is the address the listener binds, e.g. ":8443". When empty no
+	// listener is started and the poller must be served as an http.Handler.
+	Listen      string
+	PublicURL   string
+	SecretToken string
+	TLSCert     string
+	TLSKey      string
+	// PublicCert is uploaded to Telegram for self-signed certificates.
+	PublicCert string
+
+	mu   sync.Mutex
+	dest chan<- telebot.Update
+	stop chan struct{}
+	err  error
+}
+
+// Poll registers the webhook with Telegram and serves it until stop is
+// closed. It returns early if Telegram refuses the webhook or the listener
+// fails, leaving the reason in Err.
+func (p *WebhookPoller) Poll(b *telebot.Bot, dest chan telebot.Update, stop chan struct{}) {
+	p.mu.Lock()
+	p.dest = dest
+	p.stop = stop
+	p.err = nil
+	p.mu.Unlock()
+
+	if err := p.setWebhook(b); err != nil {
+		p.fail(fmt.Errorf("set webhook: %w", err))
+		return
+	}
+	slog.Info("Webhook set", "url", p.PublicURL)
+
+	if p.Listen == "" {
+		<-stop
+		return
+	}
+
+	server := &http.Server{Addr: p.Listen, Handler: p}
+	go func() {
+		<-stop
+		server.Shutdown(context.Background())
+	}()
+
+	var err error
+	if p.TLSCert != "" {
+		err = server.ListenAndServeTLS(p.TLSCert, p.TLSKey)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		p.fail(fmt.Errorf("webhook listener: %w", err))
+	}
+}
+
+// Err returns why Poll returned before stop was closed, nil if it did not.
+func (p *WebhookPoller) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *WebhookPoller) fail(err error) {
+	slog.Error("Webhook stopped", "error", err)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// ServeHTTP hands the posted update to the bot once the secret token checks
+// out.
+func (p *WebhookPoller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := r.Header.Get(SECRET_TOKEN_HEADER)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(p.SecretToken)) != 1 {
+		http.Error(w, "invalid secret token", http.StatusUnauthorized)
+		return
+	}
+
+	var update telebot.Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid update", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	dest, stop := p.dest, p.stop
+	p.mu.Unlock()
+	if dest == nil {
+		http.Error(w, "bot is not running", http.StatusServiceUnavailable)
+		return
+	}
+	select {
+	case dest <- update:
+	case <-stop:
+		http.Error(w, "bot is stopping", http.StatusServiceUnavailable)
+	case <-r.Context().Done():
+	}
+}
+
+// setWebhook registers the webhook. The telebot.Webhook registration cannot
+// send a secret token, so the call is made here.
+func (p *WebhookPoller) setWebhook(b *telebot.Bot) error {
+	params := map[string]string{
+		"url":          p.PublicURL,
+		"secret_token": p.SecretToken,
+	}
+	if p.PublicCert == "" {
+		_, err := b.Raw("setWebhook", params)
+		return err
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for key, value := range params {
+		form.WriteField(key, value)
+	}
+	cert, err := os.Open(p.PublicCert)
+	if err != nil {
+		return err
+	}
+	defer cert.Close()
+	part, err := form.CreateFormFile("certificate", filepath.Base(p.PublicCert))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, cert); err != nil {
+		return err
+	}
+	form.Close()
+
+	resp, err := http.Post(b.URL+"/bot"+b.Token+"/setWebhook", form.FormDataContentType(), &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Ok          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Ok {
+		return fmt.Errorf("telegram: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/services"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// Subcommands of /webhook. Without one, it lists the chat's webhooks.
+const (
+	WEBHOOK_ADD    = "add"
+	WEBHOOK_REMOVE = "remove"
+)
+
+// handleWebhook manages the URLs the chat's game events are sent to, for
+// admins only. The secret of a webhook added is sent to the admin in their
+// private chat, keeping it out of the group.
+func (b *Bot) handleWebhook(ctx context.Context, m *telebot.Message) {
+	if !b.isMessageSentFromGroup(ctx, m) {
+		return
+	}
+	if !b.isAdmin(ctx, m.Chat, m.Sender) {
+		return
+	}
+	settings, ok := b.chatSettings(ctx, m.Chat)
+	if !ok {
+		return
+	}
+	if b.Webhooks == nil {
+		b.sendError(ctx, m.Chat, services.Invalid("", services.ERR_WEBHOOKS_UNAVAILABLE, nil))
+		return
+	}
+
+	args := strings.Fields(m.Payload)
+	switch {
+	case len(args) == 0:
+		webhooks, err := b.Webhooks.ListWebhooks(ctx, m.Chat.ID)
+		if err != nil {
+			b.sendError(ctx, m.Chat, err)
+			return
+		}
+		if len(webhooks) == 0 {
+			b.sendText(ctx, m.Chat, "webhook.none")
+			return
+		}
+		var lines []string
+		for _, webhook := range webhooks {
+			lines = append(lines, "- "+redactUrl(webhook.Url))
+		}
+		b.sendText(ctx, m.Chat, "webhook.list", strings.Join(lines, "\n"))
+	case len(args) == 2 && strings.EqualFold(args[0], WEBHOOK_ADD):
+		webhook, err := b.Webhooks.AddWebhook(ctx, m.Chat.ID, args[1])
+		if err != nil {
+			b.sendError(ctx, m.Chat, err)
+			return
+		}
+		text := b.MessageFormater.Text(settings, "webhook.secret", m.Chat.Title, webhook.Url, webhook.Secret)
+		if _, err := b.TelegramBot.Send(m.Sender, text, telebot.NoPreview); err != nil {
+			// A webhook whose secret no one has is of no use, so it goes.
+			logging.FromContext(ctx).Warn("Could not send the webhook secret", "error", err)
+			if err := b.Webhooks.RemoveWebhook(ctx, m.Chat.ID, webhook.Url); err != nil {
+				logging.FromContext(ctx).Error("Could not remove the webhook without secret", "error", err)
+			}
+			b.sendError(ctx, m.Chat, services.Invalid("", services.ERR_WEBHOOK_SEND, err))
+			return
+		}
+		b.sendText(ctx, m.Chat, "webhook.added")
+	case len(args) == 2 && strings.EqualFold(args[0], WEBHOOK_REMOVE):
+		if err := b.Webhooks.RemoveWebhook(ctx, m.Chat.ID, args[1]); err != nil {
+			b.sendError(ctx, m.Chat, err)
+			return
+		}
+		b.sendText(ctx, m.Chat, "webhook.removed")
+	default:
+		b.sendError(ctx, m.Chat, services.Invalid("", services.ERR_WEBHOOK_USAGE, nil))
+	}
+}
+
+// redactUrl leaves the path and query out of rawUrl, which may hold a
+// secret, as Discord's webhook URLs do.
+func redactUrl(rawUrl string) string {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return "?"
+	}
+	redacted := parsed.Scheme + "://" + parsed.Host
+	if strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" {
+		redacted += "/…"
+	}
+	return redacted
+}
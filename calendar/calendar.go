@@ -0,0 +1,157 @@
+// Package calendar writes the games of a chat as iCalendar (RFC 5545) files,
+// for calendar apps to subscribe to or to add a game from.
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"tg-sunday-league/i18n"
+	"tg-sunday-league/models"
+	"time"
+	"unicode/utf8"
+)
+
+// CONTENT_TYPE is the media type iCalendar files are served with.
+const CONTENT_TYPE = "text/calendar; charset=utf-8"
+
+// PRODUCT_ID names the program that wrote a calendar, as PRODID.
+const PRODUCT_ID = "-//tg-sunday-league//Games//EN"
+
+// UID_DOMAIN makes the UIDs of games unique beyond this bot, as RFC 5545
+// recommends.
+const UID_DOMAIN = "tg-sunday-league"
+
+// GAME_LENGTH is how long games are shown to last, as they only have a
+// kickoff.
+const GAME_LENGTH = 2 * time.Hour
+
+// REFRESH_INTERVAL is how often apps subscribed to a calendar are asked to
+// fetch it again.
+const REFRESH_INTERVAL = time.Hour
+
+// MAX_LINE is the length in octets past which lines are folded.
+const MAX_LINE = 75
+
+const timestampLayout = "20060102T150405Z"
+
+// Write writes games to w as a calendar with an event per game, texts in the
+// chat's language. Cancelled games are kept with STATUS:CANCELLED, and every
+// change to a game bumps its SEQUENCE, so subscribed apps update their copy
+// rather than adding another. now is the time the calendar is written at.
+func Write(w io.Writer, games []models.Game, settings *models.ChatSettings, now time.Time) error {
+	l := i18n.For(settings.Language, settings.Timezone)
+	c := &contentWriter{w: bufio.NewWriter(w)}
+
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", PRODUCT_ID)
+	c.line("CALSCALE", "GREGORIAN")
+	c.line("METHOD", "PUBLISH")
+	c.line("X-WR-CALNAME", escape(l.T("calendar.name")))
+	c.line("X-WR-TIMEZONE", l.Location.String())
+	c.line("REFRESH-INTERVAL;VALUE=DURATION", duration(REFRESH_INTERVAL))
+	c.line("X-PUBLISHED-TTL", duration(REFRESH_INTERVAL))
+	for _, game := range games {
+		writeEvent(c, l, &game, settings.Currency, now)
+	}
+	c.line("END", "VCALENDAR")
+	return c.flush()
+}
+
+// FileName names the calendar file of game after its kickoff day in loc.
+func FileName(game *models.Game, loc *time.Location) string {
+	return "game-" + game.Date.In(loc).Format("2006-01-02") + ".ics"
+}
+
+func writeEvent(c *contentWriter, l *i18n.Localizer, game *models.Game, currency string, now time.Time) {
+	summary := l.T("calendar.summary")
+	if game.Opponent != "" {
+		summary = l.T("calendar.summary_opponent", game.Opponent)
+	}
+	status := "CONFIRMED"
+	if game.Cancelled {
+		summary = l.T("calendar.cancelled", summary)
+		status = "CANCELLED"
+	}
+	var description []string
+	if game.Price > 0 {
+		description = append(description, l.T("calendar.price", l.Amount(game.Price, currency)))
+	}
+	if game.Score != "" {
+		description = append(description, l.T("calendar.score", game.Score))
+	}
+
+	c.line("BEGIN", "VEVENT")
+	c.line("UID", game.Id.String()+"@"+UID_DOMAIN)
+	c.line("DTSTAMP", timestamp(now))
+	c.line("DTSTART", timestamp(game.Date))
+	c.line("DTEND", timestamp(game.Date.Add(GAME_LENGTH)))
+	c.line("SEQUENCE", fmt.Sprint(game.Sequence))
+	c.line("STATUS", status)
+	c.line("SUMMARY", escape(summary))
+	if game.Location != "" {
+		c.line("LOCATION", escape(game.Location))
+	}
+	if len(description) > 0 {
+		c.line("DESCRIPTION", escape(strings.Join(description, "\n")))
+	}
+	c.line("END", "VEVENT")
+}
+
+// contentWriter writes content lines ended by CRLF and folded at MAX_LINE
+// octets, keeping the first error.
+type contentWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (c *contentWriter) line(name string, value string) {
+	line := name + ":" + value
+	limit := MAX_LINE
+	for len(line) > limit {
+		// Folds go between characters, never inside one.
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		c.write(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// The space starting a continuation line counts towards its length.
+		limit = MAX_LINE - 1
+	}
+	c.write(line + "\r\n")
+}
+
+func (c *contentWriter) write(s string) {
+	if c.err == nil {
+		_, c.err = c.w.WriteString(s)
+	}
+}
+
+func (c *contentWriter) flush() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.w.Flush()
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "")
+
+// escape writes s as a TEXT value.
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+// duration writes d, a whole number of minutes, as a DURATION value.
+func duration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	}
+	return fmt.Sprintf("PT%dM", d/time.Minute)
+}
@@ -0,0 +1,97 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var singapore, _ = time.LoadLocation("Asia/Singapore")
+
+func TestWrite(t *testing.T) {
+	games := []models.Game{
+		{Id: uuid.MustParse("11111111-1111-1111-1111-111111111111"), Price: 12, Date: time.Date(2030, time.March, 10, 3, 0, 0, 0, time.UTC), Location: "Kallang; Pitch 2", Opponent: "Rovers", Score: "3-1"},
+		{Id: uuid.MustParse("22222222-2222-2222-2222-222222222222"), Date: time.Date(2030, time.March, 17, 3, 0, 0, 0, time.UTC), Cancelled: true, Sequence: 1},
+	}
+	settings := &models.ChatSettings{Currency: "SGD", Timezone: singapore, Language: "en"}
+
+	var out bytes.Buffer
+	if err := Write(&out, games, settings, time.Date(2030, time.March, 12, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//tg-sunday-league//Games//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Football games",
+		"X-WR-TIMEZONE:Asia/Singapore",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H",
+		"X-PUBLISHED-TTL:PT1H",
+		"BEGIN:VEVENT",
+		"UID:11111111-1111-1111-1111-111111111111@tg-sunday-league",
+		"DTSTAMP:20300312T000000Z",
+		"DTSTART:20300310T030000Z",
+		"DTEND:20300310T050000Z",
+		"SEQUENCE:0",
+		"STATUS:CONFIRMED",
+		"SUMMARY:Football vs Rovers",
+		`LOCATION:Kallang\; Pitch 2`,
+		`DESCRIPTION:Price: 12.00 SGD\nScore: 3-1`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:22222222-2222-2222-2222-222222222222@tg-sunday-league",
+		"DTSTAMP:20300312T000000Z",
+		"DTSTART:20300317T030000Z",
+		"DTEND:20300317T050000Z",
+		"SEQUENCE:1",
+		"STATUS:CANCELLED",
+		"SUMMARY:Cancelled: Football game",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if out.String() != want {
+		t.Errorf("Write =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	games := []models.Game{{Id: uuid.New(), Date: time.Now(), Location: strings.Repeat("Estádio ", 30)}}
+	var out bytes.Buffer
+	if err := Write(&out, games, &models.ChatSettings{Timezone: time.UTC}, time.Now()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var location strings.Builder
+	inLocation := false
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\r\n"), "\r\n") {
+		if len(line) > MAX_LINE {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		switch {
+		case strings.HasPrefix(line, "LOCATION:"):
+			inLocation = true
+			location.WriteString(strings.TrimPrefix(line, "LOCATION:"))
+		case inLocation && strings.HasPrefix(line, " "):
+			location.WriteString(line[1:])
+		default:
+			inLocation = false
+		}
+	}
+	if location.String() != strings.Repeat("Estádio ", 30) {
+		t.Errorf("unfolded location = %q", location.String())
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape("a,b;c\\d\r\ne\nf"); got != `a\,b\;c\\d\ne\nf` {
+		t.Errorf("escape = %q", got)
+	}
+}
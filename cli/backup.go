@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"tg-sunday-league/backup"
+	"tg-sunday-league/db"
+)
+
+// backupDatabase copies the SQLite database with SQLite's backup API, so the
+// bot can keep running meanwhile.
+func backupDatabase(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error {
+	cfg, err := loadStorageConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected the file to write the backup to, got %d arguments", fs.NArg())
+	}
+	if cfg.DbDriver != db.DRIVER_SQLITE {
+		return fmt.Errorf("only SQLite databases can be backed up, use pg_dump for Postgres")
+	}
+
+	dbInstance, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer dbInstance.Close()
+	// Unlike the other admin commands, backup takes schemas that are behind,
+	// to be backed up before migrate.
+	path := fs.Arg(0)
+	if err := backup.Online(ctx, dbInstance, path); err != nil {
+		return fmt.Errorf("back up to %s: %w", path, err)
+	}
+	fmt.Fprintf(out, "Backed up %s to %s\n", cfg.SqlliteDbPath, path)
+	return nil
+}
+
+// restore puts a backup in place of the SQLite database once it is checked
+// to be intact and of a schema the binary can run.
+func restore(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error {
+	cfg, err := loadStorageConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected the backup file to restore, got %d arguments", fs.NArg())
+	}
+	if cfg.DbDriver != db.DRIVER_SQLITE {
+		return fmt.Errorf("only SQLite databases can be restored, use pg_restore for Postgres")
+	}
+
+	version, previous, err := backup.Restore(ctx, fs.Arg(0), cfg.SqlliteDbPath)
+	if err != nil {
+		return err
+	}
+	if previous != "" {
+		fmt.Fprintf(out, "Moved the replaced database to %s\n", previous)
+	}
+	fmt.Fprintf(out, "Restored %s from %s at schema version %d\n", cfg.SqlliteDbPath, fs.Arg(0), version)
+	return nil
+}
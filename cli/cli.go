@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"tg-sunday-league/i18n"
+	"tg-sunday-league/services"
+	"time"
+)
+
+// NAME is the name of the binary in usage messages.
+const NAME = "tg-sunday-league"
+
+// command is one of the commands of the binary. run registers its own flags
+// on fs and parses args with it, along with the configuration flags, and
+// writes its output to out.
+type command struct {
+	// name is the words selecting the command, such as "games list".
+	name string
+	// usage describes the arguments after the flags.
+	usage   string
+	summary string
+	run     func(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error
+}
+
+// commands lists the commands in the order usage shows them. The first one
+// runs when no command is given.
+var commands = []command{
+	{"serve", "", "run the bot (the default)", serve},
+	{"migrate", "", "apply the pending schema migrations, or revert some with -down", migrate},
+	{"games list", "", "list every game of a chat", listGames},
+	{"game show", "<game-id>", "show a game with its players", showGame},
+	{"export", "[file]", "write the games of a chat with who played and paid as CSV or XLSX, to the output without a file", exportGames},
+	{"import", "<file>", "import past games of a chat from a CSV file in one transaction, or check it with -dry-run", importGames},
+	{"player merge", "<from-player-id> <into-player-id>", "merge a duplicate player into another and delete it", mergePlayers},
+	{"backup", "<file>", "copy the SQLite database to file while the bot runs", backupDatabase},
+	{"restore", "<file>", "replace the SQLite database with a backup, with the bot stopped", restore},
+}
+
+// Run runs the command args name with the rest of args, or serve if args
+// starts with a flag or is empty, and returns the exit status.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		usage(stdout)
+		return 0
+	}
+	cmd, rest, ok := find(args)
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", strings.Join(args, " "))
+		usage(stderr)
+		return 2
+	}
+
+	fs := newFlagSet(cmd)
+	fs.SetOutput(stderr)
+	if err := cmd.run(ctx, fs, rest, stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "%s: %s\n", cmd.name, describeError(err))
+		return 1
+	}
+	return 0
+}
+
+func find(args []string) (command, []string, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return commands[0], args, true
+	}
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", NAME)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun %s <command> -h for the flags of a command.\n", NAME)
+}
+
+// newFlagSet returns the flag set of cmd, which reports parse errors instead
+// of exiting.
+func newFlagSet(cmd command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		summary := strings.ToUpper(cmd.summary[:1]) + cmd.summary[1:]
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] %s\n\n%s.\n\nFlags:\n", NAME, cmd.name, cmd.usage, summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// describeError renders service errors as the bot would in English, with
+// their cause, which the chat is never shown but an operator needs.
+func describeError(err error) string {
+	serviceErr := services.AsError(err)
+	if serviceErr == nil {
+		return err.Error()
+	}
+	msg := i18n.For(i18n.DefaultLanguage, time.UTC).T(string(serviceErr.Code), serviceErr.Args...)
+	if serviceErr.Err != nil {
+		msg += " (" + serviceErr.Err.Error() + ")"
+	}
+	return msg
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"tg-sunday-league/db"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// run runs the command line args against the SQLite database at path and
+// returns its exit status, output and errors.
+func run(t *testing.T, path string, args ...string) (int, string, string) {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("SQL_LITE_DB_PATH", path)
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func wantOutput(t *testing.T, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("output does not contain %q:\n%s", w, out)
+		}
+	}
+}
+
+func openDatabase(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	dbInstance, err := db.Connect(path)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { dbInstance.Close() })
+	return dbInstance
+}
+
+func TestAdminCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "league.db")
+	if code, out, errs := run(t, path, "migrate"); code != 0 {
+		t.Fatalf("migrate exited with %d: %s", code, errs)
+	} else {
+		wantOutput(t, out, "Applied 0001_initial", "Schema version 7")
+	}
+
+	ctx := context.Background()
+	repo := &repositories.GameRepository{Db: openDatabase(t, path)}
+	game := &models.Game{Id: uuid.New(), ChatId: -1001, Date: time.Date(2030, time.March, 10, 11, 0, 0, 0, time.UTC), Location: "Kallang", Opponent: "Rovers", Price: 12}
+	ana := &models.User{Id: uuid.New(), UserId: 10, Name: "Ana", Status: "ATTENDING"}
+	duplicate := &models.User{Id: uuid.New(), UserId: 10, Name: "Ana B", Status: "ATTENDING"}
+	if _, err := repo.InsertGame(ctx, game); err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+	for _, user := range []*models.User{ana, duplicate} {
+		if _, err := repo.InsertUser(ctx, user); err != nil {
+			t.Fatalf("InsertUser: %v", err)
+		}
+	}
+	if _, err := repo.InsertGamePlayer(ctx, game, duplicate); err != nil {
+		t.Fatalf("InsertGamePlayer: %v", err)
+	}
+
+	code, out, errs := run(t, path, "games", "list", "-chat", strconv.FormatInt(game.ChatId, 10))
+	if code != 0 {
+		t.Fatalf("games list exited with %d: %s", code, errs)
+	}
+	wantOutput(t, out, game.Id.String(), "2030-03-10 11:00 UTC", "Rovers", "Kallang", "upcoming")
+
+	code, out, errs = run(t, path, "game", "show", game.Id.String())
+	if code != 0 {
+		t.Fatalf("game show exited with %d: %s", code, errs)
+	}
+	wantOutput(t, out, "Kallang", duplicate.Id.String(), "Ana B")
+
+	code, out, errs = run(t, path, "export", "-chat", strconv.FormatInt(game.ChatId, 10), "-from", "2030-03-10", "-to", "2030-03-10")
+	if code != 0 {
+		t.Fatalf("export exited with %d: %s", code, errs)
+	}
+	wantOutput(t, out, "Game ID,Kickoff", game.Id.String()+",2030-03-10 11:00,Rovers,Kallang,12.00,,upcoming,Ana B,10,attending,no")
+
+	code, _, errs = run(t, path, "player", "merge", duplicate.Id.String(), ana.Id.String())
+	if code != 0 {
+		t.Fatalf("player merge exited with %d: %s", code, errs)
+	}
+	players, err := repo.GetGamePlayers(ctx, game.Id)
+	if err != nil || len(players) != 1 || players[0].Id != ana.Id {
+		t.Errorf("players after merge = %+v, %v; want only Ana", players, err)
+	}
+
+	backupPath := filepath.Join(t.TempDir(), "backup.db")
+	if code, _, errs := run(t, path, "backup", backupPath); code != 0 {
+		t.Fatalf("backup exited with %d: %s", code, errs)
+	}
+	backedUp := &repositories.GameRepository{Db: openDatabase(t, backupPath)}
+	if got, err := backedUp.GetGameById(ctx, game.Id); err != nil || got == nil {
+		t.Errorf("game in backup = %v, %v; want it copied", got, err)
+	}
+	if code, _, errs := run(t, path, "backup", backupPath); code != 1 || !strings.Contains(errs, "already exists") {
+		t.Errorf("backup over an existing file exited with %d: %s", code, errs)
+	}
+
+	if _, err := repo.CancelGame(ctx, game); err != nil {
+		t.Fatalf("CancelGame: %v", err)
+	}
+	code, out, errs = run(t, path, "restore", backupPath)
+	if code != 0 {
+		t.Fatalf("restore exited with %d: %s", code, errs)
+	}
+	wantOutput(t, out, "Moved the replaced database to "+path+".before-restore-", "at schema version 7")
+	restored := &repositories.GameRepository{Db: openDatabase(t, path)}
+	if got, err := restored.GetGameById(ctx, game.Id); err != nil || got == nil || got.Cancelled {
+		t.Errorf("game after restore = %+v, %v; want it as backed up, not cancelled", got, err)
+	}
+}
+
+func TestAdminCommandErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "league.db")
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{"unknown command", []string{"games", "delete"}, 2, "unknown command"},
+		{"unmigrated database", []string{"games", "list", "-chat", "1"}, 1, "migrate first"},
+		{"missing argument", []string{"game", "show"}, 1, "expected a game ID"},
+		{"bad flag", []string{"migrate", "-steps", "1"}, 1, "flag provided but not defined"},
+		{"bad export format", []string{"export", "-chat", "1", "-format", "pdf"}, 1, "-format must be csv or xlsx"},
+		{"import without file", []string{"import", "-chat", "1"}, 1, "expected the CSV file to import"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errs := run(t, path, tt.args...)
+			if code != tt.wantCode || !strings.Contains(errs, tt.wantErr) {
+				t.Errorf("exited with %d: %s\nwant %d and %q", code, errs, tt.wantCode, tt.wantErr)
+			}
+		})
+	}
+
+	if code, _, errs := run(t, path, "migrate"); code != 0 {
+		t.Fatalf("migrate exited with %d: %s", code, errs)
+	}
+	code, _, errs := run(t, path, "game", "show", uuid.NewString())
+	if code != 1 || !strings.Contains(errs, "No existing game.") {
+		t.Errorf("game show of an unknown game exited with %d: %s", code, errs)
+	}
+}
+
+func TestCheckSchemaWritesNothing(t *testing.T) {
+	dbInstance := openDatabase(t, filepath.Join(t.TempDir(), "league.db"))
+	err := checkSchema(context.Background(), dbInstance, db.DRIVER_SQLITE)
+	if err == nil || !strings.Contains(err.Error(), "migrate first") {
+		t.Fatalf("checkSchema = %v, want the database refused", err)
+	}
+	var tables int
+	if err := dbInstance.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		t.Fatalf("count tables: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("database has %d tables after the check, want none", tables)
+	}
+}
+
+func TestImportCommand(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "league.db")
+	if code, _, errs := run(t, path, "migrate"); code != 0 {
+		t.Fatalf("migrate exited with %d: %s", code, errs)
+	}
+	file := filepath.Join(dir, "history.csv")
+	history := "Kickoff,Opponent,Score,Player,Telegram ID,Paid\n" +
+		"2023-03-05 11:00,Rovers,3-1,Ana,10,yes\n" +
+		"2023-03-05 11:00,Rovers,3-1,Bea,20,no\n"
+	if err := os.WriteFile(file, []byte(history), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, out, errs := run(t, path, "import", "-chat", "-1001", "-dry-run", file)
+	if code != 0 {
+		t.Fatalf("import -dry-run exited with %d: %s", code, errs)
+	}
+	wantOutput(t, out, "Games to import: 1, answers: 2", "New players: Ana, Bea")
+
+	code, out, errs = run(t, path, "import", "-chat", "-1001", file)
+	if code != 0 {
+		t.Fatalf("import exited with %d: %s", code, errs)
+	}
+	wantOutput(t, out, "Games imported: 1, answers: 2")
+	code, out, _ = run(t, path, "games", "list", "-chat", "-1001")
+	if code != 0 {
+		t.Fatalf("games list exited with %d", code)
+	}
+	wantOutput(t, out, "2023-03-05 11:00 UTC", "Rovers", "played")
+
+	code, out, errs = run(t, path, "import", "-chat", "-1001", file)
+	if code != 1 || !strings.Contains(errs, "nothing imported") {
+		t.Errorf("second import exited with %d: %s", code, errs)
+	}
+	wantOutput(t, out, "line 2: the chat already has a game at this kickoff")
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"tg-sunday-league/export"
+	"time"
+)
+
+// exportGames writes the games of a chat as the bot's /export does, to a
+// file or to the output.
+func exportGames(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error {
+	chatID := fs.Int64("chat", 0, "ID of the chat, required")
+	format := fs.String("format", export.FORMAT_CSV, "file format: "+strings.Join(export.FORMATS, " or "))
+	fromDay := fs.String("from", "", "first day exported, as YYYY-MM-DD in the chat's time zone")
+	toDay := fs.String("to", "", "last day exported, as YYYY-MM-DD in the chat's time zone")
+	store, err := openAdminStorage(ctx, fs, args, func() error {
+		if *chatID == 0 {
+			return fmt.Errorf("-chat is required")
+		}
+		if !slices.Contains(export.FORMATS, *format) {
+			return fmt.Errorf("-format must be %s, not %q", strings.Join(export.FORMATS, " or "), *format)
+		}
+		if fs.NArg() > 1 {
+			return fmt.Errorf("expected at most the file to write to, got %d arguments", fs.NArg())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	settings, err := store.settingsService.GetSettings(ctx, *chatID)
+	if err != nil {
+		return err
+	}
+	var from, to time.Time
+	if *fromDay != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, *fromDay, settings.Timezone); err != nil {
+			return fmt.Errorf("-from: %w", err)
+		}
+	}
+	if *toDay != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, *toDay, settings.Timezone); err != nil {
+			return fmt.Errorf("-to: %w", err)
+		}
+		// The last day is included.
+		to = to.AddDate(0, 0, 1)
+	}
+
+	report, err := store.gameService.ExportGames(ctx, *chatID, from, to)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return export.Write(out, *format, report, settings)
+	}
+
+	path := fs.Arg(0)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := export.Write(f, *format, report, settings); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Exported %d games to %s\n", len(report.Games), path)
+	return nil
+}
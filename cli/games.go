@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"tg-sunday-league/export"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KICKOFF_LAYOUT shows kickoffs with their zone, as chats may use any.
+const KICKOFF_LAYOUT = "2006-01-02 15:04 MST"
+
+// openAdminStorage loads the configuration with the flags registered on fs
+// from args and opens the storage, once check accepts the flags and the
+// arguments left in fs.Args().
+func openAdminStorage(ctx context.Context, fs *flag.FlagSet, args []string, check func() error) (*storage, error) {
+	cfg, err := loadStorageConfig(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(); err != nil {
+		return nil, err
+	}
+	return openStorage(ctx, cfg)
+}
+
+func listGames(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error {
+	chatID := fs.Int64("chat", 0, "ID of the chat, required")
+	store, err := openAdminStorage(ctx, fs, args, func() error {
+		if *chatID == 0 {
+			return fmt.Errorf("-chat is required")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	settings, err := store.settingsService.GetSettings(ctx, *chatID)
+	if err != nil {
+		return err
+	}
+	games, err := store.gameService.ListGames(ctx, *chatID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKICKOFF\tOPPONENT\tLOCATION\tPRICE\tSTATUS")
+	now := time.Now()
+	for _, game := range games {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.2f\t%s\n", game.Id, game.Date.In(settings.Timezone).Format(KICKOFF_LAYOUT),
+			game.Opponent, game.Location, game.Price, export.GameStatus(&game, now))
+	}
+	return w.Flush()
+}
+
+func showGame(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error {
+	var gameId uuid.UUID
+	store, err := openAdminStorage(ctx, fs, args, func() error {
+		if fs.NArg() != 1 {
+			return fmt.Errorf("expected a game ID, got %d arguments", fs.NArg())
+		}
+		var err error
+		if gameId, err = uuid.Parse(fs.Arg(0)); err != nil {
+			return fmt.Errorf("game ID: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	game, players, absentees, err := store.gameService.GetGame(ctx, gameId)
+	if err != nil {
+		return err
+	}
+	settings, err := store.settingsService.GetSettings(ctx, game.ChatId)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Game\t%s\n", game.Id)
+	fmt.Fprintf(w, "Chat\t%d\n", game.ChatId)
+	fmt.Fprintf(w, "Kickoff\t%s\n", game.Date.In(settings.Timezone).Format(KICKOFF_LAYOUT))
+	fmt.Fprintf(w, "Opponent\t%s\n", game.Opponent)
+	fmt.Fprintf(w, "Location\t%s\n", game.Location)
+	fmt.Fprintf(w, "Price\t%.2f %s\n", game.Price, settings.Currency)
+	fmt.Fprintf(w, "Status\t%s\n", export.GameStatus(game, time.Now()))
+	if game.Score != "" {
+		fmt.Fprintf(w, "Score\t%s\n", game.Score)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PLAYER ID\tTELEGRAM ID\tNAME\tSTATUS\tPAID")
+	for _, list := range []*[]models.User{players, absentees} {
+		for _, player := range *list {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", player.Id, player.UserId, player.Name, player.Status, yesNo(player.HasPaid))
+		}
+	}
+	return w.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func mergePlayers(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error {
+	var ids [2]uuid.UUID
+	store, err := openAdminStorage(ctx, fs, args, func() error {
+		if fs.NArg() != 2 {
+			return fmt.Errorf("expected the IDs of the player to merge and of the one to keep, got %d arguments", fs.NArg())
+		}
+		for i, arg := range fs.Args() {
+			var err error
+			if ids[i], err = uuid.Parse(arg); err != nil {
+				return fmt.Errorf("player ID %s: %w", strconv.Quote(arg), err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.gameService.MergePlayers(ctx, ids[0], ids[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Merged player %s into %s\n", ids[0], ids[1])
+	return nil
+}
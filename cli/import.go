@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"tg-sunday-league/i18n"
+	"tg-sunday-league/importer"
+	"time"
+)
+
+// importGames imports past games of a chat from a CSV file, as the bot's
+// /import does, or only checks it with -dry-run.
+func importGames(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error {
+	chatID := fs.Int64("chat", 0, "ID of the chat, required")
+	dryRun := fs.Bool("dry-run", false, "only report what would be imported and the conflicts")
+	store, err := openAdminStorage(ctx, fs, args, func() error {
+		if *chatID == 0 {
+			return fmt.Errorf("-chat is required")
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("expected the CSV file to import, got %d arguments", fs.NArg())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	settings, err := store.settingsService.GetSettings(ctx, *chatID)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	in, err := importer.ReadCSV(f, settings.Timezone)
+	if err != nil {
+		return err
+	}
+
+	report, err := store.gameService.ImportGames(ctx, *chatID, in, *dryRun)
+	if err != nil {
+		return err
+	}
+	translator := i18n.For(i18n.DefaultLanguage, time.UTC)
+	for _, conflict := range report.Conflicts {
+		fmt.Fprintf(out, "line %d: %s\n", conflict.Line, translator.T(string(conflict.Code), conflict.Args...))
+	}
+	if len(report.Conflicts) > 0 {
+		return fmt.Errorf("nothing imported, %d conflicts to fix", len(report.Conflicts))
+	}
+
+	if *dryRun {
+		fmt.Fprintf(out, "Games to import: %d, answers: %d\n", report.Games, report.Answers)
+	} else {
+		fmt.Fprintf(out, "Games imported: %d, answers: %d\n", report.Games, report.Answers)
+	}
+	if len(report.NewPlayers) > 0 {
+		fmt.Fprintf(out, "New players: %s\n", strings.Join(report.NewPlayers, ", "))
+	}
+	return nil
+}
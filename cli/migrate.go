@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"tg-sunday-league/db"
+)
+
+// migrate applies or reverts migrations and prints the ones it did.
+func migrate(ctx context.Context, fs *flag.FlagSet, args []string, out io.Writer) error {
+	dryRun := fs.Bool("dry-run", false, "list the migrations without applying or reverting them")
+	down := fs.Int("down", 0, "revert the given number of applied migrations, newest first")
+	cfg, err := loadStorageConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	dbInstance, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer dbInstance.Close()
+
+	migrator, err := db.NewMigrator(dbInstance, cfg.DbDriver)
+	if err != nil {
+		return err
+	}
+	migrator.DryRun = *dryRun
+
+	applied, reverted := "Applied", "Reverted"
+	if *dryRun {
+		applied, reverted = "Would apply", "Would revert"
+	}
+	verb := applied
+	var done []db.Migration
+	if *down > 0 {
+		verb = reverted
+		done, err = migrator.Down(*down)
+	} else {
+		done, err = migrator.Up()
+	}
+	for _, migration := range done {
+		fmt.Fprintf(out, "%s %04d_%s\n", verb, migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Schema version %d\n", version)
+	return nil
+}
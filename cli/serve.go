@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"tg-sunday-league/backup"
+	"tg-sunday-league/bot"
+	"tg-sunday-league/config"
+	"tg-sunday-league/db"
+	"tg-sunday-league/hooks"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/monitoring"
+	"tg-sunday-league/services"
+	"tg-sunday-league/web"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// SHUTDOWN_TIMEOUT is how long handlers in flight get to finish on shutdown
+// before their queries are cancelled.
+const SHUTDOWN_TIMEOUT = 10 * time.Second
+
+// serve runs the bot until ctx ends, applying pending migrations first.
+func serve(ctx context.Context, fs *flag.FlagSet, args []string, _ io.Writer) error {
+	cfg, err := config.LoadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel); err != nil {
+		return err
+	}
+
+	dbInstance, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer dbInstance.Close() // Ensure the DB connection closes when the bot stops
+
+	// Bring the database schema up to date
+	if err := db.SetupDatabase(dbInstance, cfg.DbDriver, false); err != nil {
+		return fmt.Errorf("set up database: %w", err)
+	}
+
+	// Initialize repositories and services
+	store, err := newStorage(cfg, dbInstance)
+	if err != nil {
+		return err
+	}
+	messageFormatter := &bot.MessageFormatter{}
+
+	// Start the bot with service dependency
+	var poller telebot.Poller
+	if cfg.BotMode == "webhook" {
+		poller = &bot.WebhookPoller{
+			Listen:      cfg.Webhook.Listen,
+			PublicURL:   cfg.Webhook.PublicUrl,
+			SecretToken: cfg.Webhook.SecretToken,
+			TLSCert:     cfg.Webhook.TLSCert,
+			TLSKey:      cfg.Webhook.TLSKey,
+			PublicCert:  cfg.Webhook.PublicCert,
+		}
+	}
+	b, err := bot.NewBot(cfg.BotToken, cfg.TelegramApiUrl, poller, store.gameService, store.settingsService, messageFormatter)
+	if err != nil {
+		return fmt.Errorf("create bot: %w", err)
+	}
+
+	b.OwnerId = cfg.OwnerId
+	b.Tokens = store.tokenService
+	b.WebUrl = cfg.Web.PublicUrl
+	b.Sessions = store.sessionService
+	b.Webhooks = store.webhookService
+	// Everything started from here stops with ctx, or once the bot can no
+	// longer receive updates.
+	ctx, stopRunning := context.WithCancel(ctx)
+	defer stopRunning()
+	// Scheduled backups stop with ctx, and must be done before the database
+	// is closed.
+	var backupsRunning sync.WaitGroup
+	if cfg.DbDriver == db.DRIVER_SQLITE {
+		backups := &backup.BackupService{Db: dbInstance, Dir: cfg.Backup.Dir, Keep: cfg.Backup.Keep}
+		b.Backups = backups
+		if cfg.Backup.Interval > 0 {
+			backupsRunning.Add(1)
+			go func() {
+				defer backupsRunning.Done()
+				backups.Run(ctx, cfg.Backup.Interval)
+			}()
+		}
+	}
+
+	if cfg.BotMode == "polling" {
+		// Telegram refuses getUpdates while a webhook is set, as after
+		// switching back from webhook mode.
+		if err := b.TelegramBot.RemoveWebhook(); err != nil {
+			slog.Error("Could not remove webhook", "error", err)
+		}
+	}
+
+	// Serve health checks and metrics
+	store.events.Subscribe(func(ctx context.Context, event services.Event) error {
+		monitoring.GameEventsTotal.WithLabelValues(string(event.Type)).Inc()
+		return nil
+	})
+	prometheus.MustRegister(b.Collectors()...)
+	prometheus.MustRegister(monitoring.NewActiveGamesCollector(func(ctx context.Context) (map[int64]int, error) {
+		return store.games.CountUpcomingGamesByChat(ctx, time.Now())
+	}))
+	monitor := &monitoring.Server{Checks: map[string]func(ctx context.Context) error{
+		"database": dbInstance.PingContext,
+		"telegram": b.Ping,
+	}}
+	monitorServer := &http.Server{Addr: cfg.MetricsListen, Handler: monitor.Handler()}
+	go func() {
+		if err := monitorServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Metrics listener stopped", "error", err)
+		}
+	}()
+
+	// Serve the calendar links, the API and the dashboard once the bot can
+	// hand them out
+	var webServer *http.Server
+	if cfg.Web.PublicUrl != "" {
+		site := &web.Server{
+			Games:       store.gameService,
+			GameService: store.gameService,
+			Settings:    store.settingsService,
+			Tokens:      store.tokenService,
+			Sessions:    store.sessionService,
+			Secure:      strings.HasPrefix(cfg.Web.PublicUrl, "https://"),
+		}
+		webServer = &http.Server{Addr: cfg.Web.Listen, Handler: site.Handler()}
+		go func() {
+			if err := webServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("Web listener stopped", "error", err)
+			}
+		}()
+	}
+
+	// Fixtures open as games while the bot runs, and stop with ctx before the
+	// database is closed.
+	var fixturesRunning sync.WaitGroup
+	fixturesRunning.Add(1)
+	go func() {
+		defer fixturesRunning.Done()
+		b.RunFixtures(ctx, bot.FIXTURES_INTERVAL)
+	}()
+	// So are the events queued for webhooks delivered, as soon as a change
+	// queues them.
+	dispatcher := &hooks.Dispatcher{Webhooks: store.webhooks, Client: hooks.NewClient()}
+	store.events.Subscribe(func(ctx context.Context, event services.Event) error {
+		dispatcher.Wake()
+		return nil
+	})
+	var dispatcherRunning sync.WaitGroup
+	dispatcherRunning.Add(1)
+	go func() {
+		defer dispatcherRunning.Done()
+		dispatcher.Run(ctx, hooks.DISPATCH_INTERVAL)
+	}()
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- b.Start()
+	}()
+	monitor.SetReady(true)
+	slog.Info("Bot is running")
+
+	// A bot that cannot receive updates exits with the reason rather than
+	// run on looking ready.
+	var pollErr error
+	polling := true
+	select {
+	case <-ctx.Done():
+	case pollErr = <-stopped:
+		polling = false
+		slog.Error("Bot stopped receiving updates", "error", pollErr)
+	}
+	slog.Info("Shutting down")
+	monitor.SetReady(false)
+	stopRunning()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+	if err := b.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Handlers did not finish in time", "error", err)
+	}
+	if polling {
+		pollErr = <-stopped
+	}
+	if webServer != nil {
+		webServer.Shutdown(shutdownCtx)
+	}
+	backupsRunning.Wait()
+	fixturesRunning.Wait()
+	dispatcherRunning.Wait()
+	monitorServer.Shutdown(shutdownCtx)
+	if pollErr != nil {
+		return fmt.Errorf("receive updates: %w", pollErr)
+	}
+	slog.Info("Bot stopped")
+	return nil
+}
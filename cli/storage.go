@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"tg-sunday-league/config"
+	"tg-sunday-league/db"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"tg-sunday-league/services"
+	"time"
+)
+
+// storage is the database with the repositories and services on top of it,
+// set up the same way for the bot and the admin commands.
+type storage struct {
+	db              *sql.DB
+	games           repositories.IGameRepository
+	settings        repositories.ISettingsRepository
+	tokens          repositories.ITokenRepository
+	sessions        repositories.ISessionRepository
+	webhooks        repositories.IWebhookRepository
+	events          *services.EventBus
+	gameService     *services.GameService
+	settingsService *services.SettingsService
+	tokenService    *services.TokenService
+	sessionService  *services.SessionService
+	webhookService  *services.WebhookService
+}
+
+// loadStorageConfig loads the configuration of the commands that only use
+// the database, with the flags registered on fs, and sets up logging.
+func loadStorageConfig(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	cfg, err := config.LoadStorageConfig(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// connect opens the configured database without touching its schema.
+func connect(cfg *config.Config) (*sql.DB, error) {
+	var dbInstance *sql.DB
+	var err error
+	if cfg.DbDriver == db.DRIVER_POSTGRES {
+		dbInstance, err = db.ConnectPostgres(cfg.DatabaseUrl)
+	} else {
+		dbInstance, err = db.Connect(cfg.SqlliteDbPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	return dbInstance, nil
+}
+
+func newStorage(cfg *config.Config, dbInstance *sql.DB) (*storage, error) {
+	defaultLocation, err := time.LoadLocation(cfg.DefaultTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("default time zone: %w", err)
+	}
+
+	s := &storage{db: dbInstance, events: &services.EventBus{}}
+	var unitOfWork repositories.IUnitOfWork
+	if cfg.DbDriver == db.DRIVER_POSTGRES {
+		s.games = &repositories.PostgresGameRepository{Db: dbInstance}
+		s.settings = &repositories.PostgresSettingsRepository{Db: dbInstance}
+		s.tokens = &repositories.PostgresTokenRepository{Db: dbInstance}
+		s.sessions = &repositories.PostgresSessionRepository{Db: dbInstance}
+		s.webhooks = &repositories.PostgresWebhookRepository{Db: dbInstance}
+		unitOfWork = repositories.NewPostgresUnitOfWork(dbInstance)
+	} else {
+		s.games = &repositories.GameRepository{Db: dbInstance}
+		s.settings = &repositories.SettingsRepository{Db: dbInstance}
+		s.tokens = &repositories.TokenRepository{Db: dbInstance}
+		s.sessions = &repositories.SessionRepository{Db: dbInstance}
+		s.webhooks = &repositories.WebhookRepository{Db: dbInstance}
+		unitOfWork = repositories.NewSqliteUnitOfWork(dbInstance)
+	}
+	s.settingsService = &services.SettingsService{
+		SettingsRepository: s.settings,
+		Defaults:           models.ChatSettings{Timezone: defaultLocation},
+	}
+	s.gameService = &services.GameService{GameRepository: s.games, SettingsService: s.settingsService, UnitOfWork: unitOfWork, Events: s.events}
+	s.tokenService = &services.TokenService{TokenRepository: s.tokens}
+	s.sessionService = &services.SessionService{SessionRepository: s.sessions}
+	s.webhookService = &services.WebhookService{WebhookRepository: s.webhooks}
+	return s, nil
+}
+
+// openStorage is the storage of the admin commands, which refuse to run on a
+// schema that migrate has not brought up to date.
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	dbInstance, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchema(ctx, dbInstance, cfg.DbDriver); err != nil {
+		dbInstance.Close()
+		return nil, err
+	}
+	s, err := newStorage(cfg, dbInstance)
+	if err != nil {
+		dbInstance.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// checkSchema fails unless every migration of the binary has been applied.
+func checkSchema(ctx context.Context, dbInstance *sql.DB, driver string) error {
+	if err := dbInstance.PingContext(ctx); err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	migrator, err := db.NewMigrator(dbInstance, driver)
+	if err != nil {
+		return err
+	}
+	// A dry run only looks for schema_migrations rather than create it, so
+	// the check writes nothing and works with a read-only role.
+	migrator.DryRun = true
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("the database schema is %d migrations behind, run %s migrate first", len(pending), NAME)
+	}
+	return nil
+}
+
+func (s *storage) Close() error {
+	return s.db.Close()
+}
@@ -1,37 +1,404 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"tg-sunday-league/logging"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	BotToken      string
-	SqlliteDbPath string
+	BotToken        string        `yaml:"bot_token" toml:"bot_token"`
+	TelegramApiUrl  string        `yaml:"telegram_api_url" toml:"telegram_api_url"`
+	DbDriver        string        `yaml:"db_driver" toml:"db_driver"`
+	SqlliteDbPath   string        `yaml:"sqlite_path" toml:"sqlite_path"`
+	DatabaseUrl     string        `yaml:"database_url" toml:"database_url"`
+	DefaultTimezone string        `yaml:"default_timezone" toml:"default_timezone"`
+	BotMode         string        `yaml:"bot_mode" toml:"bot_mode"`
+	Webhook         WebhookConfig `yaml:"webhook" toml:"webhook"`
+	MetricsListen   string        `yaml:"metrics_listen" toml:"metrics_listen"`
+	LogLevel        string        `yaml:"log_level" toml:"log_level"`
+	OwnerId         int64         `yaml:"owner_id" toml:"owner_id"`
+	Backup          BackupConfig  `yaml:"backup" toml:"backup"`
+	Web             WebConfig     `yaml:"web" toml:"web"`
 }
 
-func LoadConfig() (*Config, error) {
-	err := godotenv.Load(".env")
+// WebhookConfig is only used when BotMode is webhook.
+type WebhookConfig struct {
+	Listen      string `yaml:"listen" toml:"listen"`
+	PublicUrl   string `yaml:"public_url" toml:"public_url"`
+	SecretToken string `yaml:"secret_token" toml:"secret_token"`
+	TLSCert     string `yaml:"tls_cert" toml:"tls_cert"`
+	TLSKey      string `yaml:"tls_key" toml:"tls_key"`
+	PublicCert  string `yaml:"public_cert" toml:"public_cert"`
+}
+
+// BackupConfig schedules the backups of a SQLite database. An Interval of 0
+// turns them off.
+type BackupConfig struct {
+	Dir      string        `yaml:"dir" toml:"dir"`
+	Interval time.Duration `yaml:"interval" toml:"interval"`
+	Keep     int           `yaml:"keep" toml:"keep"`
+}
 
+// WebConfig is the server of the links the bot hands out, such as those to
+// calendars. It only runs when PublicUrl is set.
+type WebConfig struct {
+	Listen    string `yaml:"listen" toml:"listen"`
+	PublicUrl string `yaml:"public_url" toml:"public_url"`
+}
+
+// setting is a value of the config file that the environment and a flag can
+// override.
+type setting struct {
+	// key is the name in the config file, with the keys of a section after
+	// its name and a dot.
+	key string
+	// env lists the variables read, the first one that is set wins. Names
+	// after the first are kept for older .env files.
+	env   []string
+	usage string
+	// field points to the value in c: a string, int, int64 or
+	// time.Duration.
+	field func(c *Config) any
+}
+
+var settings = []setting{
+	{"bot_token", []string{"TELEGRAM_BOT_TOKEN", "API_KEY"}, "Telegram bot token",
+		func(c *Config) any { return &c.BotToken }},
+	{"telegram_api_url", []string{"TELEGRAM_API_URL"}, "Bot API server, Telegram's when empty",
+		func(c *Config) any { return &c.TelegramApiUrl }},
+	{"db_driver", []string{"DB_DRIVER"}, "storage backend, sqlite or postgres",
+		func(c *Config) any { return &c.DbDriver }},
+	{"sqlite_path", []string{"SQL_LITE_DB_PATH"}, "SQLite database file",
+		func(c *Config) any { return &c.SqlliteDbPath }},
+	{"database_url", []string{"DATABASE_URL"}, "Postgres connection URL",
+		func(c *Config) any { return &c.DatabaseUrl }},
+	{"default_timezone", []string{"DEFAULT_TIMEZONE"}, "time zone of chats that have not chosen one",
+		func(c *Config) any { return &c.DefaultTimezone }},
+	{"bot_mode", []string{"BOT_MODE"}, "how updates are received, polling or webhook",
+		func(c *Config) any { return &c.BotMode }},
+	{"webhook.listen", []string{"WEBHOOK_LISTEN"}, "address the webhook listener binds",
+		func(c *Config) any { return &c.Webhook.Listen }},
+	{"webhook.public_url", []string{"WEBHOOK_URL"}, "public https:// URL Telegram posts updates to",
+		func(c *Config) any { return &c.Webhook.PublicUrl }},
+	{"webhook.secret_token", []string{"WEBHOOK_SECRET"}, "secret token Telegram sends with every update",
+		func(c *Config) any { return &c.Webhook.SecretToken }},
+	{"webhook.tls_cert", []string{"WEBHOOK_TLS_CERT"}, "certificate to serve the webhook with",
+		func(c *Config) any { return &c.Webhook.TLSCert }},
+	{"webhook.tls_key", []string{"WEBHOOK_TLS_KEY"}, "key of the webhook certificate",
+		func(c *Config) any { return &c.Webhook.TLSKey }},
+	{"webhook.public_cert", []string{"WEBHOOK_PUBLIC_CERT"}, "self-signed certificate to upload to Telegram",
+		func(c *Config) any { return &c.Webhook.PublicCert }},
+	{"metrics_listen", []string{"METRICS_LISTEN"}, "address health checks and metrics are served on",
+		func(c *Config) any { return &c.MetricsListen }},
+	{"log_level", []string{"LOG_LEVEL"}, "least severe level logged: " + strings.Join(logging.LEVELS, ", "),
+		func(c *Config) any { return &c.LogLevel }},
+	{"owner_id", []string{"OWNER_ID"}, "Telegram user ID of the bot owner, who may use /backup",
+		func(c *Config) any { return &c.OwnerId }},
+	{"backup.dir", []string{"BACKUP_DIR"}, "directory the SQLite backups are written to",
+		func(c *Config) any { return &c.Backup.Dir }},
+	{"backup.interval", []string{"BACKUP_INTERVAL"}, "time between SQLite backups, such as 24h, 0 for none",
+		func(c *Config) any { return &c.Backup.Interval }},
+	{"backup.keep", []string{"BACKUP_KEEP"}, "number of SQLite backups kept",
+		func(c *Config) any { return &c.Backup.Keep }},
+	{"web.listen", []string{"WEB_LISTEN"}, "address calendar links are served on",
+		func(c *Config) any { return &c.Web.Listen }},
+	{"web.public_url", []string{"WEB_PUBLIC_URL"}, "public URL calendar links start with, none are given when empty",
+		func(c *Config) any { return &c.Web.PublicUrl }},
+}
+
+// set parses value into the field of the setting in c.
+func (s setting) set(c *Config, value string) error {
+	var err error
+	switch field := s.field(c).(type) {
+	case *string:
+		*field = value
+	case *int:
+		*field, err = strconv.Atoi(value)
+	case *int64:
+		*field, err = strconv.ParseInt(value, 10, 64)
+	case *time.Duration:
+		*field, err = time.ParseDuration(value)
+	}
 	if err != nil {
-		log.Fatalf("Error loading .env file")
+		return fmt.Errorf("%s: invalid value %q", describe(s.key), value)
 	}
-	var botToken string = os.Getenv("API_KEY")
+	return nil
+}
 
-	if botToken == "" {
-		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN is not set")
-	}
-	var sqlliteDbPath string = os.Getenv("SQL_LITE_DB_PATH")
+// flagName is the command-line flag of the setting with the given key.
+func flagName(key string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(key)
+}
 
-	if sqlliteDbPath == "" {
-		return nil, fmt.Errorf("SQL_LITE_DB_PATH is not set")
+// describe names a setting the ways it can be given, for error messages.
+func describe(key string) string {
+	for _, s := range settings {
+		if s.key == key {
+			return fmt.Sprintf("%s (%s, -%s)", key, s.env[0], flagName(key))
+		}
 	}
+	return key
+}
 
+// Defaults returns the configuration used where nothing else is given.
+func Defaults() *Config {
 	return &Config{
-		BotToken:      botToken,
-		SqlliteDbPath: sqlliteDbPath,
-	}, nil
+		DbDriver:        "sqlite",
+		SqlliteDbPath:   "tg_sunday_league.db",
+		DefaultTimezone: "UTC",
+		BotMode:         "polling",
+		Webhook:         WebhookConfig{Listen: ":8443"},
+		MetricsListen:   ":9090",
+		LogLevel:        "info",
+		Backup:          BackupConfig{Dir: "backups", Interval: 24 * time.Hour, Keep: 7},
+		Web:             WebConfig{Listen: ":8080"},
+	}
+}
+
+// LoadConfig registers the configuration flags on fs, parses args with it and
+// returns the validated configuration. Each source overrides the previous
+// one: the defaults, the YAML or TOML file named by -config or CONFIG_FILE,
+// the environment, which a .env file in the working directory adds to, and
+// the flags.
+func LoadConfig(fs *flag.FlagSet, args []string) (*Config, error) {
+	return load(fs, args, true)
+}
+
+// LoadStorageConfig is LoadConfig for commands that only use the database,
+// so the settings of the bot itself, such as its token, are not checked.
+func LoadStorageConfig(fs *flag.FlagSet, args []string) (*Config, error) {
+	return load(fs, args, false)
+}
+
+func load(fs *flag.FlagSet, args []string, bot bool) (*Config, error) {
+	configFile := fs.String("config", "", "YAML or TOML config file, also read from CONFIG_FILE")
+	for _, s := range settings {
+		fs.String(flagName(s.key), "", s.usage+", also read from "+s.env[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error loading .env file: %w", err)
+	}
+
+	c := Defaults()
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := readFile(path, c); err != nil {
+			return nil, err
+		}
+	}
+
+	// Values that do not parse are reported along with the other problems.
+	var errs []error
+	for _, s := range settings {
+		for _, name := range s.env {
+			if value := os.Getenv(name); value != "" {
+				if err := s.set(c, value); err != nil {
+					errs = append(errs, err)
+				}
+				break
+			}
+		}
+	}
+
+	flags := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		flags[flagName(s.key)] = s
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if s, ok := flags[f.Name]; ok {
+			if err := s.set(c, f.Value.String()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+
+	if err := c.validate(bot); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+// readFile reads the config file at path into c, as TOML if its name ends in
+// .toml and as YAML otherwise. Unknown keys are an error, as they are most
+// likely misspelt.
+func readFile(path string, c *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		meta, err := toml.NewDecoder(f).Decode(c)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config file %s: unknown key %s", path, undecoded[0])
+		}
+		return nil
+	}
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && err != io.EOF {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every problem with the configuration at once.
+func (c *Config) Validate() error {
+	return c.validate(true)
+}
+
+// validate checks the storage settings, and the bot settings too if bot is
+// set.
+func (c *Config) validate(bot bool) error {
+	var errs []error
+
+	switch c.DbDriver {
+	case "sqlite":
+		if c.SqlliteDbPath == "" {
+			errs = append(errs, fmt.Errorf("%s is not set", describe("sqlite_path")))
+		}
+	case "postgres":
+		if c.DatabaseUrl == "" {
+			errs = append(errs, fmt.Errorf("%s is not set", describe("database_url")))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s must be sqlite or postgres, not %q", describe("db_driver"), c.DbDriver))
+	}
+
+	// LoadLocation reads "" as UTC, which is not meant to be spelt that way.
+	if _, err := time.LoadLocation(c.DefaultTimezone); err != nil || c.DefaultTimezone == "" {
+		errs = append(errs, fmt.Errorf("%s must be a time zone such as Europe/London, not %q", describe("default_timezone"), c.DefaultTimezone))
+	}
+
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("%s must be one of %s, not %q", describe("log_level"), strings.Join(logging.LEVELS, ", "), c.LogLevel))
+	}
+
+	if bot {
+		errs = append(errs, c.validateBot()...)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) validateBot() []error {
+	var errs []error
+
+	if c.BotToken == "" {
+		errs = append(errs, fmt.Errorf("%s is not set", describe("bot_token")))
+	}
+
+	switch c.BotMode {
+	case "polling":
+	case "webhook":
+		errs = append(errs, c.Webhook.validate()...)
+	default:
+		errs = append(errs, fmt.Errorf("%s must be polling or webhook, not %q", describe("bot_mode"), c.BotMode))
+	}
+
+	if err := checkAddress(c.MetricsListen); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", describe("metrics_listen"), err))
+	}
+
+	if c.OwnerId < 0 {
+		errs = append(errs, fmt.Errorf("%s must be a Telegram user ID, not %d", describe("owner_id"), c.OwnerId))
+	}
+	if c.Backup.Interval < 0 {
+		errs = append(errs, fmt.Errorf("%s must not be negative", describe("backup.interval")))
+	}
+	if c.DbDriver == "sqlite" {
+		if c.Backup.Dir == "" {
+			errs = append(errs, fmt.Errorf("%s is not set", describe("backup.dir")))
+		}
+		if c.Backup.Keep < 1 {
+			errs = append(errs, fmt.Errorf("%s must be at least 1, not %d", describe("backup.keep"), c.Backup.Keep))
+		}
+	}
+	if c.Web.PublicUrl != "" {
+		errs = append(errs, c.Web.validate()...)
+	}
+	return errs
+}
+
+// checkAddress checks that addr is a host:port to listen on, the host being
+// optional.
+func checkAddress(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+func (web *WebConfig) validate() []error {
+	var errs []error
+	if err := checkAddress(web.Listen); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", describe("web.listen"), err))
+	}
+	if u, err := url.Parse(web.PublicUrl); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("%s must be an http:// or https:// URL, not %q", describe("web.public_url"), web.PublicUrl))
+	}
+	return errs
+}
+
+// Telegram accepts 1 to 256 of these characters as a webhook secret token.
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+func (webhook *WebhookConfig) validate() []error {
+	var errs []error
+	if err := checkAddress(webhook.Listen); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", describe("webhook.listen"), err))
+	}
+	if webhook.PublicUrl == "" {
+		errs = append(errs, fmt.Errorf("%s is not set", describe("webhook.public_url")))
+	} else if !strings.HasPrefix(webhook.PublicUrl, "https://") {
+		errs = append(errs, fmt.Errorf("%s must be an https:// URL, Telegram does not post to plain HTTP", describe("webhook.public_url")))
+	}
+	if !secretTokenPattern.MatchString(webhook.SecretToken) {
+		errs = append(errs, fmt.Errorf("%s must be 1 to 256 letters, digits, _ or -", describe("webhook.secret_token")))
+	}
+	if (webhook.TLSCert == "") != (webhook.TLSKey == "") {
+		errs = append(errs, fmt.Errorf("%s and %s must be set together", describe("webhook.tls_cert"), describe("webhook.tls_key")))
+	}
+	for _, path := range []string{webhook.TLSCert, webhook.TLSKey, webhook.PublicCert} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("webhook certificate file: %w", err))
+		}
+	}
+	return errs
 }
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every configuration variable for the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	for _, s := range settings {
+		for _, name := range s.env {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+// loadEnv runs LoadConfig with env as the only configuration variables set.
+func loadEnv(t *testing.T, env map[string]string, args ...string) (*Config, error) {
+	t.Helper()
+	clearEnv(t)
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	return LoadConfig(fs, args)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaults(t *testing.T) {
+	c, err := loadEnv(t, map[string]string{"TELEGRAM_BOT_TOKEN": "123:abc"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	want := Defaults()
+	want.BotToken = "123:abc"
+	if *c != *want {
+		t.Errorf("LoadConfig = %+v, want %+v", *c, *want)
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	yamlFile := writeFile(t, "bot.yaml", `
+bot_token: from-file
+default_timezone: Europe/London
+log_level: debug
+webhook:
+  listen: ":8000"
+`)
+	tomlFile := writeFile(t, "bot.toml", `
+bot_token = "from-file"
+default_timezone = "Europe/London"
+log_level = "debug"
+
+[webhook]
+listen = ":8000"
+`)
+
+	for _, path := range []string{yamlFile, tomlFile} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			env := map[string]string{
+				"CONFIG_FILE":      path,
+				"DEFAULT_TIMEZONE": "UTC",
+				"LOG_LEVEL":        "warn",
+			}
+			c, err := loadEnv(t, env, "-log-level", "error")
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			tests := []struct {
+				name, got, want string
+			}{
+				{"file over default", c.BotToken, "from-file"},
+				{"nested file key", c.Webhook.Listen, ":8000"},
+				{"environment over file", c.DefaultTimezone, "UTC"},
+				{"flag over environment", c.LogLevel, "error"},
+				{"default", c.MetricsListen, ":9090"},
+			}
+			for _, tt := range tests {
+				if tt.got != tt.want {
+					t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	path := writeFile(t, "bot.yml", "bot_token: from-flag-file\n")
+	c, err := loadEnv(t, nil, "-config", path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if c.BotToken != "from-flag-file" {
+		t.Errorf("BotToken = %q, want %q", c.BotToken, "from-flag-file")
+	}
+}
+
+func TestLegacyTokenVariable(t *testing.T) {
+	c, err := loadEnv(t, map[string]string{"API_KEY": "old"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if c.BotToken != "old" {
+		t.Errorf("BotToken = %q, want %q", c.BotToken, "old")
+	}
+
+	c, err = loadEnv(t, map[string]string{"API_KEY": "old", "TELEGRAM_BOT_TOKEN": "new"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if c.BotToken != "new" {
+		t.Errorf("BotToken = %q, want %q", c.BotToken, "new")
+	}
+}
+
+func TestUnknownFileKeys(t *testing.T) {
+	for _, path := range []string{
+		writeFile(t, "bot.yaml", "bot_tokn: typo\n"),
+		writeFile(t, "bot.toml", "bot_tokn = \"typo\"\n"),
+	} {
+		_, err := loadEnv(t, map[string]string{"CONFIG_FILE": path})
+		if err == nil || !strings.Contains(err.Error(), "bot_tokn") {
+			t.Errorf("%s: error = %v, want one naming bot_tokn", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestValidationReportsEveryError(t *testing.T) {
+	env := map[string]string{
+		"DB_DRIVER":        "mysql",
+		"DEFAULT_TIMEZONE": "Mars/Olympus",
+		"BOT_MODE":         "webhook",
+		"WEBHOOK_URL":      "http://example.com",
+		"WEBHOOK_SECRET":   "not secret",
+		"METRICS_LISTEN":   "9090",
+		"LOG_LEVEL":        "loud",
+		"WEB_PUBLIC_URL":   "league.example",
+	}
+	_, err := loadEnv(t, env)
+	if err == nil {
+		t.Fatal("LoadConfig succeeded, want an error")
+	}
+	for _, want := range []string{
+		"bot_token (TELEGRAM_BOT_TOKEN, -bot-token) is not set",
+		"db_driver",
+		"default_timezone",
+		"webhook.public_url",
+		"webhook.secret_token",
+		"metrics_listen",
+		"log_level",
+		"web.public_url",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestDatabaseSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"sqlite without a path", map[string]string{"SQL_LITE_DB_PATH": ""}, ""},
+		{"postgres without a URL", map[string]string{"DB_DRIVER": "postgres"}, "database_url"},
+		{"postgres", map[string]string{"DB_DRIVER": "postgres", "DATABASE_URL": "postgres://localhost/league"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.env["TELEGRAM_BOT_TOKEN"] = "123:abc"
+			_, err := loadEnv(t, tt.env)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("LoadConfig: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want one mentioning %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStorageConfigSkipsBotSettings(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("BOT_MODE", "webhook")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+
+	if _, err := LoadStorageConfig(fs, []string{"-sqlite-path", "league.db"}); err != nil {
+		t.Errorf("LoadStorageConfig: %v", err)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	_, err := LoadStorageConfig(fs, []string{"-db-driver", "postgres"})
+	if err == nil || !strings.Contains(err.Error(), "database_url") {
+		t.Errorf("error = %v, want one mentioning database_url", err)
+	}
+}
+
+func TestTypedSettings(t *testing.T) {
+	path := writeFile(t, "bot.yaml", `
+owner_id: 42
+backup:
+  interval: 12h
+  keep: 3
+`)
+	c, err := loadEnv(t, map[string]string{"TELEGRAM_BOT_TOKEN": "123:abc", "CONFIG_FILE": path, "BACKUP_KEEP": "5"}, "-backup-interval", "30m")
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if c.OwnerId != 42 || c.Backup.Interval != 30*time.Minute || c.Backup.Keep != 5 || c.Backup.Dir != "backups" {
+		t.Errorf("LoadConfig = %+v, want owner 42 and backups every 30m keeping 5", *c)
+	}
+
+	env := map[string]string{"TELEGRAM_BOT_TOKEN": "123:abc", "OWNER_ID": "ana", "BACKUP_KEEP": "0"}
+	_, err = loadEnv(t, env, "-backup-interval", "daily")
+	for _, want := range []string{
+		`owner_id (OWNER_ID, -owner-id): invalid value "ana"`,
+		`backup.interval (BACKUP_INTERVAL, -backup-interval): invalid value "daily"`,
+		"backup.keep (BACKUP_KEEP, -backup-keep) must be at least 1",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want one containing %q", err, want)
+		}
+	}
+}
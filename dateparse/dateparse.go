@@ -0,0 +1,367 @@
+package dateparse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEmpty       = errors.New("no date or time given")
+	ErrMissingTime = errors.New("no kickoff time given, add one like 11am or 19:30")
+	ErrPast        = errors.New("that time is in the past")
+)
+
+// ParseError describes input that could not be turned into a kickoff time.
+// Interpreted echoes how much of the input was understood so the user can see
+// what went wrong: it is zero if nothing was, and only its date is meaningful
+// unless HasTime is set.
+type ParseError struct {
+	Input       string
+	Interpreted time.Time
+	HasTime     bool
+	Err         error
+}
+
+func (e *ParseError) Error() string {
+	if e.Interpreted.IsZero() {
+		return fmt.Sprintf("Could not understand %q: %v.", e.Input, e.Err)
+	}
+	layout := "Monday 2006-01-02"
+	if e.HasTime {
+		layout += " 15:04"
+	}
+	return fmt.Sprintf("Could not understand %q (read as %s): %v.", e.Input, e.Interpreted.Format(layout), e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var (
+	isoDate      = regexp.MustCompile(`^(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})$`)
+	numericDate  = regexp.MustCompile(`^(\d{1,2})[-/.](\d{1,2})(?:[-/.](\d{2}|\d{4}))?$`)
+	clock        = regexp.MustCompile(`^(\d{1,2})(h|[:h.]\d{2})?(am|pm|a\.m\.|p\.m\.)?$`)
+	dayOrdinal   = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	relativeDays = regexp.MustCompile(`^in (\d+) (day|days|week|weeks)$`)
+)
+
+// Parse reads a kickoff date and time such as "sunday 11am", "next sat 9:30",
+// "tomorrow 19:00", "13/10 20:00" or "2024-10-13 11:00". Relative phrases are
+// resolved against now, and the result is expressed in now's location.
+func Parse(input string, now time.Time) (time.Time, error) {
+	normalized := strings.ToLower(strings.TrimSpace(input))
+	normalized = strings.ReplaceAll(normalized, ",", " ")
+	tokens := strings.Fields(normalized)
+	if len(tokens) == 0 {
+		return time.Time{}, &ParseError{Input: input, Err: ErrEmpty}
+	}
+
+	hour, minute, dateTokens, found, err := extractTime(tokens)
+	if err != nil {
+		return time.Time{}, &ParseError{Input: input, Err: err}
+	}
+
+	dateTokens = dropFillers(dateTokens)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var day time.Time
+	var explicit bool
+	if len(dateTokens) == 0 {
+		if !found {
+			return time.Time{}, &ParseError{Input: input, Err: ErrEmpty}
+		}
+		day = today
+		if atTime(day, hour, minute).Before(now) {
+			day = day.AddDate(0, 0, 1)
+		}
+	} else {
+		day, explicit, err = parseDay(strings.Join(dateTokens, " "), today, hour, minute, found, now)
+		if err != nil {
+			return time.Time{}, &ParseError{Input: input, Err: err}
+		}
+	}
+
+	if !found {
+		return time.Time{}, &ParseError{
+			Input:       input,
+			Interpreted: day,
+			Err:         ErrMissingTime,
+		}
+	}
+
+	kickoff := atTime(day, hour, minute)
+	if explicit && kickoff.Before(now) {
+		return time.Time{}, &ParseError{
+			Input:       input,
+			Interpreted: kickoff,
+			HasTime:     true,
+			Err:         ErrPast,
+		}
+	}
+	return kickoff, nil
+}
+
+// extractTime pulls the first clock reading out of tokens and returns the
+// remaining tokens, which describe the day.
+func extractTime(tokens []string) (int, int, []string, bool, error) {
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch tok {
+		case "noon", "midday":
+			return 12, 0, without(tokens, i, 1), true, nil
+		case "midnight":
+			return 0, 0, without(tokens, i, 1), true, nil
+		}
+
+		consumed := 1
+		if i+1 < len(tokens) && isMeridiem(tokens[i+1]) {
+			tok += tokens[i+1]
+			consumed = 2
+		}
+		m := clock.FindStringSubmatch(tok)
+		if m == nil {
+			continue
+		}
+		// A bare number is a day of the month ("13 oct") and 13.10 is a
+		// date, so neither is taken as a time.
+		if m[3] == "" && (m[2] == "" || strings.HasPrefix(m[2], ".")) {
+			continue
+		}
+
+		hour, _ := strconv.Atoi(m[1])
+		minute := 0
+		if len(m[2]) > 1 {
+			minute, _ = strconv.Atoi(m[2][1:])
+		}
+		if minute > 59 {
+			return 0, 0, nil, false, fmt.Errorf("%q is not a valid time", tok)
+		}
+		switch strings.ReplaceAll(m[3], ".", "") {
+		case "am":
+			if hour < 1 || hour > 12 {
+				return 0, 0, nil, false, fmt.Errorf("%q is not a valid 12-hour time", tok)
+			}
+			if hour == 12 {
+				hour = 0
+			}
+		case "pm":
+			if hour < 1 || hour > 12 {
+				return 0, 0, nil, false, fmt.Errorf("%q is not a valid 12-hour time", tok)
+			}
+			if hour != 12 {
+				hour += 12
+			}
+		default:
+			if hour > 23 {
+				return 0, 0, nil, false, fmt.Errorf("%q is not a valid time", tok)
+			}
+		}
+		return hour, minute, without(tokens, i, consumed), true, nil
+	}
+	return 0, 0, tokens, false, nil
+}
+
+// parseDay resolves a day phrase. The returned bool reports whether the day
+// was given explicitly, in which case a past kickoff is an error rather than
+// something to roll forward.
+func parseDay(phrase string, today time.Time, hour, minute int, hasTime bool, now time.Time) (time.Time, bool, error) {
+	switch phrase {
+	case "today", "tonight":
+		return today, true, nil
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), true, nil
+	case "day after tomorrow":
+		return today.AddDate(0, 0, 2), true, nil
+	}
+
+	if m := relativeDays.FindStringSubmatch(phrase); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if strings.HasPrefix(m[2], "week") {
+			n *= 7
+		}
+		return today.AddDate(0, 0, n), true, nil
+	}
+
+	fields := strings.Fields(phrase)
+	modifier := ""
+	if len(fields) == 2 && (fields[0] == "next" || fields[0] == "this") {
+		modifier, fields = fields[0], fields[1:]
+	}
+	if len(fields) == 1 {
+		if wd, ok := weekdays[fields[0]]; ok {
+			ahead := (int(wd) - int(today.Weekday()) + 7) % 7
+			switch {
+			case modifier == "next" && ahead == 0:
+				ahead = 7
+			case modifier == "" && ahead == 0 && hasTime && atTime(today, hour, minute).Before(now):
+				ahead = 7
+			}
+			return today.AddDate(0, 0, ahead), modifier == "this", nil
+		}
+	}
+	if modifier != "" {
+		return time.Time{}, false, fmt.Errorf("%q is not a day of the week", strings.Join(fields, " "))
+	}
+
+	if m := isoDate.FindStringSubmatch(phrase); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		d, err := buildDate(year, month, day, today.Location())
+		return d, true, err
+	}
+
+	if m := numericDate.FindStringSubmatch(phrase); m != nil {
+		first, _ := strconv.Atoi(m[1])
+		second, _ := strconv.Atoi(m[2])
+		// Day-first unless that is impossible, so 13/10 and 10/13 both
+		// mean the 13th of October.
+		day, month := first, second
+		if month > 12 && day <= 12 {
+			day, month = second, first
+		}
+		if m[3] == "" {
+			d, err := nextDate(month, day, today)
+			return d, true, err
+		}
+		year, _ := strconv.Atoi(m[3])
+		if year < 100 {
+			year += 2000
+		}
+		d, err := buildDate(year, month, day, today.Location())
+		return d, true, err
+	}
+
+	return parseWrittenDate(fields, today)
+}
+
+// parseWrittenDate handles "13 oct", "13th october 2024", "oct 13" and
+// "sunday 13 oct".
+func parseWrittenDate(fields []string, today time.Time) (time.Time, bool, error) {
+	var weekday *time.Weekday
+	if len(fields) > 0 {
+		if wd, ok := weekdays[fields[0]]; ok {
+			weekday = &wd
+			fields = fields[1:]
+		}
+	}
+	fields = dropFillers(fields)
+
+	var day, year int
+	var month time.Month
+	for _, f := range fields {
+		if mo, ok := months[f]; ok && month == 0 {
+			month = mo
+			continue
+		}
+		if m := dayOrdinal.FindStringSubmatch(f); m != nil && day == 0 {
+			day, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if n, err := strconv.Atoi(f); err == nil && len(f) == 4 && year == 0 {
+			year = n
+			continue
+		}
+		return time.Time{}, false, fmt.Errorf("%q is not a date", strings.Join(fields, " "))
+	}
+	if month == 0 || day == 0 {
+		return time.Time{}, false, fmt.Errorf("%q is not a date", strings.Join(fields, " "))
+	}
+
+	var d time.Time
+	var err error
+	if year == 0 {
+		d, err = nextDate(int(month), day, today)
+	} else {
+		d, err = buildDate(year, int(month), day, today.Location())
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if weekday != nil && d.Weekday() != *weekday {
+		return time.Time{}, false, fmt.Errorf("%s is a %s, not a %s", d.Format("2006-01-02"), d.Weekday(), *weekday)
+	}
+	return d, true, nil
+}
+
+// nextDate returns the next occurrence of month/day on or after today.
+func nextDate(month, day int, today time.Time) (time.Time, error) {
+	d, err := buildDate(today.Year(), month, day, today.Location())
+	if err != nil {
+		return d, err
+	}
+	if d.Before(today) {
+		return buildDate(today.Year()+1, month, day, today.Location())
+	}
+	return d, nil
+}
+
+func buildDate(year, month, day int, loc *time.Location) (time.Time, error) {
+	if month < 1 || month > 12 {
+		return time.Time{}, fmt.Errorf("%d is not a valid month", month)
+	}
+	d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	if day < 1 || d.Day() != day {
+		return time.Time{}, fmt.Errorf("%s has no day %d", time.Month(month), day)
+	}
+	return d, nil
+}
+
+func atTime(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
+
+func isMeridiem(tok string) bool {
+	switch tok {
+	case "am", "pm", "a.m.", "p.m.":
+		return true
+	}
+	return false
+}
+
+func dropFillers(tokens []string) []string {
+	kept := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		switch tok {
+		case "at", "on", "the", "of", "@":
+			continue
+		}
+		kept = append(kept, tok)
+	}
+	return kept
+}
+
+func without(tokens []string, i, n int) []string {
+	rest := make([]string, 0, len(tokens)-n)
+	rest = append(rest, tokens[:i]...)
+	return append(rest, tokens[i+n:]...)
+}
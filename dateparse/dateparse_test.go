@@ -0,0 +1,128 @@
+package dateparse
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// now is a Wednesday evening, so a Wednesday kickoff earlier in the day has
+// passed and one later has not.
+var now = time.Date(2024, time.October, 9, 18, 0, 0, 0, time.UTC)
+
+func at(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		// Weekdays are the next one, today's only while its kickoff is ahead.
+		{"sunday 11am", at(2024, time.October, 13, 11, 0)},
+		{"sat 9:30", at(2024, time.October, 12, 9, 30)},
+		{"wednesday 11am", at(2024, time.October, 16, 11, 0)},
+		{"wednesday 19:00", at(2024, time.October, 9, 19, 0)},
+		{"next wednesday 19:00", at(2024, time.October, 16, 19, 0)},
+		{"next sat 9:30", at(2024, time.October, 12, 9, 30)},
+		{"this friday 20:00", at(2024, time.October, 11, 20, 0)},
+		{"Sunday, 11 am", at(2024, time.October, 13, 11, 0)},
+
+		{"today 20:00", at(2024, time.October, 9, 20, 0)},
+		{"tonight 8pm", at(2024, time.October, 9, 20, 0)},
+		{"tomorrow 11am", at(2024, time.October, 10, 11, 0)},
+		{"tmrw at 7:15pm", at(2024, time.October, 10, 19, 15)},
+		{"in 3 days 19:30", at(2024, time.October, 12, 19, 30)},
+		{"in 1 week 11am", at(2024, time.October, 16, 11, 0)},
+		{"in 2 weeks 11am", at(2024, time.October, 23, 11, 0)},
+
+		// A time alone is today, or tomorrow once it has passed.
+		{"11 pm", at(2024, time.October, 9, 23, 0)},
+		{"9am", at(2024, time.October, 10, 9, 0)},
+		{"tomorrow 12am", at(2024, time.October, 10, 0, 0)},
+		{"tomorrow 12pm", at(2024, time.October, 10, 12, 0)},
+		{"tomorrow noon", at(2024, time.October, 10, 12, 0)},
+		{"tomorrow 9.30pm", at(2024, time.October, 10, 21, 30)},
+		{"tomorrow 21h", at(2024, time.October, 10, 21, 0)},
+		{"tomorrow 21h15", at(2024, time.October, 10, 21, 15)},
+		{"tomorrow 0:05", at(2024, time.October, 10, 0, 5)},
+
+		{"2024-10-20 11:00", at(2024, time.October, 20, 11, 0)},
+		{"2024/10/20 11am", at(2024, time.October, 20, 11, 0)},
+		{"13/10 20:00", at(2024, time.October, 13, 20, 0)},
+		{"13.10 20:00", at(2024, time.October, 13, 20, 0)},
+		{"10/13 20:00", at(2024, time.October, 13, 20, 0)},
+		{"5/1/25 11am", at(2025, time.January, 5, 11, 0)},
+		{"1/10 11am", at(2025, time.October, 1, 11, 0)},
+
+		{"3 March 11am", at(2025, time.March, 3, 11, 0)},
+		{"March 3rd 11am", at(2025, time.March, 3, 11, 0)},
+		{"sunday 13 oct 11am", at(2024, time.October, 13, 11, 0)},
+		{"13th of october 2024 at 11am", at(2024, time.October, 13, 11, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, now)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got.Format(time.RFC1123), tt.want.Format(time.RFC1123))
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr error // nil for errors without a sentinel
+		want    string
+	}{
+		{"", ErrEmpty, `Could not understand "": no date or time given.`},
+		{"  ,  ", ErrEmpty, `Could not understand "  ,  ": no date or time given.`},
+		{"sunday", ErrMissingTime, `Could not understand "sunday" (read as Sunday 2024-10-13): no kickoff time given, add one like 11am or 19:30.`},
+		{"13 oct", ErrMissingTime, `Could not understand "13 oct" (read as Sunday 2024-10-13): no kickoff time given, add one like 11am or 19:30.`},
+		{"today 11am", ErrPast, `Could not understand "today 11am" (read as Wednesday 2024-10-09 11:00): that time is in the past.`},
+		{"this wednesday 11am", ErrPast, `Could not understand "this wednesday 11am" (read as Wednesday 2024-10-09 11:00): that time is in the past.`},
+		{"2024-10-01 11:00", ErrPast, `Could not understand "2024-10-01 11:00" (read as Tuesday 2024-10-01 11:00): that time is in the past.`},
+
+		{"31/02 11am", nil, `Could not understand "31/02 11am": February has no day 31.`},
+		{"2025-02-29 11am", nil, `Could not understand "2025-02-29 11am": February has no day 29.`},
+		{"2024-13-01 11:00", nil, `Could not understand "2024-13-01 11:00": 13 is not a valid month.`},
+		{"monday 13 oct 11am", nil, `Could not understand "monday 13 oct 11am": 2024-10-13 is a Sunday, not a Monday.`},
+		{"next payday 11am", nil, `Could not understand "next payday 11am": "payday" is not a day of the week.`},
+		{"someday 11am", nil, `Could not understand "someday 11am": "someday" is not a date.`},
+		{"sunday 25:00", nil, `Could not understand "sunday 25:00": "25:00" is not a valid time.`},
+		{"sunday 9:75", nil, `Could not understand "sunday 9:75": "9:75" is not a valid time.`},
+		{"sunday 13pm", nil, `Could not understand "sunday 13pm": "13pm" is not a valid 12-hour time.`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input, now)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want a *ParseError", tt.input, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Parse(%q) error =\n%s\nwant\n%s", tt.input, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+// The kickoff is read in now's location, whatever the date was given as.
+func TestParseUsesNowLocation(t *testing.T) {
+	singapore := time.FixedZone("SGT", 8*60*60)
+	got, err := Parse("sunday 11am", now.In(singapore))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := time.Date(2024, time.October, 13, 11, 0, 0, 0, singapore); !got.Equal(want) || got.Location() != singapore {
+		t.Errorf("Parse = %s, want %s", got, want)
+	}
+}
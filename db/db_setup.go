@@ -2,55 +2,38 @@ package db
 
 import (
 	"database/sql"
-	"log"
+	"log/slog"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// SetupDatabase initializes the SQLite database and creates the required tables
-func SetupDatabase(db *sql.DB) error {
-	tables := []string{
-		`CREATE TABLE IF NOT EXISTS users (
-			id VARCHAR(36) PRIMARY KEY,
-			user_id INTEGER NOT NULL,
-			name VARCHAR NOT NULL
-		);`,
-		`CREATE TABLE IF NOT EXISTS games (
-			id VARCHAR(36) PRIMARY KEY,
-			chat_id INTEGER,
-			opponent VARCHAR,
-			location VARCHAR,
-			price FLOAT,
-			date DATE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			created_by INTEGER,
-			is_active BOOL,
-			FOREIGN KEY (created_by) REFERENCES users(id)
-	);`,
-		`CREATE TABLE IF NOT EXISTS player_status (
-			name VARCHAR PRIMARY KEY
-	);`,
-		`CREATE TABLE IF NOT EXISTS game_players (
-			game_id VARCHAR(36),
-			user_id VARCHAR(36),
-			status VARCHAR,
-			has_paid BOOL,
-			joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (game_id) REFERENCES games(id),
-			FOREIGN KEY (user_id) REFERENCES users(id),
-			FOREIGN KEY (status) REFERENCES player_status(name),
-			PRIMARY KEY (game_id, user_id)
-	);`,
+// Database backends the bot can store its data in.
+const (
+	DRIVER_SQLITE   = "sqlite"
+	DRIVER_POSTGRES = "postgres"
+)
+
+// SetupDatabase brings the schema up to date by applying every pending
+// migration for driver. With dryRun set it only logs the migrations it would
+// apply.
+func SetupDatabase(db *sql.DB, driver string, dryRun bool) error {
+	migrator, err := NewMigrator(db, driver)
+	if err != nil {
+		slog.Error("Could not load migrations", "error", err)
+		return err
 	}
+	migrator.DryRun = dryRun
 
-	for _, table := range tables {
-		_, err := db.Exec(table)
-		if err != nil {
-			log.Printf("Error creating table: %v", err)
-			return err
-		}
+	applied, err := migrator.Up()
+	if err != nil {
+		slog.Error("Could not migrate database", "error", err)
+		return err
 	}
 
-	log.Println("Database setup completed successfully.")
+	if dryRun {
+		slog.Info("Dry run, migrations pending", "pending", len(applied))
+		return nil
+	}
+	slog.Info("Database setup completed", "applied", len(applied))
 	return nil
 }
@@ -0,0 +1,324 @@
+package db
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
+var migrationFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change. Down is empty for migrations that
+// cannot be reverted.
+//
+// Checksum is the SHA-256 of Up alone. It records what was applied, so a
+// down step found broken can still be fixed without every database that
+// applied the migration refusing to start.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// AppliedMigration is a row of the schema_migrations table.
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator applies the embedded migrations in version order and records them
+// in schema_migrations. With DryRun set it only reports what it would do.
+type Migrator struct {
+	Db         *sql.DB
+	Driver     string
+	Migrations []Migration
+	DryRun     bool
+}
+
+// NewMigrator returns a Migrator for the migrations embedded in the binary
+// for driver, each of which has its own copy of the schema in its dialect.
+func NewMigrator(db *sql.DB, driver string) (*Migrator, error) {
+	if driver != DRIVER_SQLITE && driver != DRIVER_POSTGRES {
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	migrations, err := LoadMigrations(migrationFiles, path.Join("migrations", driver))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{Db: db, Driver: driver, Migrations: migrations}, nil
+}
+
+// LoadMigrations reads <version>_<name>.up.sql and the optional matching
+// .down.sql files from dir in fsys.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		m := migrationName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.up.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, m[2])
+		}
+		if m[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up step", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Applied returns the migrations recorded in schema_migrations, oldest first.
+func (m *Migrator) Applied() ([]AppliedMigration, error) {
+	exists, err := m.ensureTable()
+	if err != nil || !exists {
+		return nil, err
+	}
+	rows, err := m.Db.Query(
+		`SELECT version, name, checksum, applied_at
+		FROM schema_migrations
+		ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+// Verify checks that every applied migration is still embedded unchanged.
+func (m *Migrator) Verify() error {
+	applied, err := m.Applied()
+	if err != nil {
+		return err
+	}
+	return m.verify(applied)
+}
+
+// Pending returns the migrations not applied yet.
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.Applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(applied); err != nil {
+		return nil, err
+	}
+
+	done := make(map[int]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	var pending []Migration
+	for _, migration := range m.Migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns the ones applied, or the ones that would be in dry-run mode.
+func (m *Migrator) Up() ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		if m.DryRun {
+			slog.Info("Would apply migration", "version", migration.Version, "name", migration.Name)
+			continue
+		}
+		err := m.apply(migration.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec(m.rebind(
+				`INSERT INTO schema_migrations (version, name, checksum)
+				VALUES (?, ?, ?)`),
+				migration.Version, migration.Name, migration.Checksum)
+			return err
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		slog.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return pending, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// the ones reverted, or the ones that would be in dry-run mode.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.Applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(applied); err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration, _ := m.find(applied[i].Version)
+		if migration.Down == "" {
+			return reverted, fmt.Errorf("migration %04d_%s cannot be reverted", migration.Version, migration.Name)
+		}
+		if m.DryRun {
+			slog.Info("Would revert migration", "version", migration.Version, "name", migration.Name)
+			reverted = append(reverted, migration)
+			continue
+		}
+		err := m.apply(migration.Down, func(tx *sql.Tx) error {
+			_, err := tx.Exec(m.rebind(`DELETE FROM schema_migrations WHERE version = ?`), migration.Version)
+			return err
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("revert migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		slog.Info("Reverted migration", "version", migration.Version, "name", migration.Name)
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// Version returns the newest applied migration version, 0 for an empty
+// database.
+func (m *Migrator) Version() (int, error) {
+	applied, err := m.Applied()
+	if err != nil || len(applied) == 0 {
+		return 0, err
+	}
+	return applied[len(applied)-1].Version, nil
+}
+
+func (m *Migrator) verify(applied []AppliedMigration) error {
+	for _, a := range applied {
+		migration, ok := m.find(a.Version)
+		if !ok {
+			return fmt.Errorf("database has migration %04d_%s applied, which this build does not know; it is newer than the binary", a.Version, a.Name)
+		}
+		if migration.Checksum != a.Checksum {
+			return fmt.Errorf("migration %04d_%s was changed after it was applied (checksum %s, applied %s)", a.Version, a.Name, migration.Checksum, a.Checksum)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.Migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+func (m *Migrator) apply(script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ensureTable creates schema_migrations if needed and reports whether it
+// exists. Nothing may be written in dry-run mode, so there a missing table
+// just means nothing was applied yet.
+func (m *Migrator) ensureTable() (bool, error) {
+	if m.DryRun {
+		query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`
+		if m.Driver == DRIVER_POSTGRES {
+			query = `SELECT COUNT(*) FROM pg_tables WHERE schemaname = current_schema() AND tablename = 'schema_migrations'`
+		}
+		var count int
+		err := m.Db.QueryRow(query).Scan(&count)
+		return count > 0, err
+	}
+	_, err := m.Db.Exec(
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR NOT NULL,
+			checksum VARCHAR NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`)
+	return err == nil, err
+}
+
+// rebind turns the ? placeholders of query into Postgres' $1, $2, ...
+func (m *Migrator) rebind(query string) string {
+	if m.Driver != DRIVER_POSTGRES {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,270 @@
+package db
+
+import (
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// migrationsFS holds three migrations, each depending on the one before.
+func migrationsFS() fstest.MapFS {
+	return fstest.MapFS{
+		"m/0001_games.up.sql":     {Data: []byte(`CREATE TABLE games (id INTEGER PRIMARY KEY);`)},
+		"m/0001_games.down.sql":   {Data: []byte(`DROP TABLE games;`)},
+		"m/0002_players.up.sql":   {Data: []byte(`CREATE TABLE players (id INTEGER PRIMARY KEY, game_id INTEGER REFERENCES games (id));`)},
+		"m/0002_players.down.sql": {Data: []byte(`DROP TABLE players;`)},
+		"m/0003_paid.up.sql":      {Data: []byte(`ALTER TABLE players ADD COLUMN paid BOOLEAN NOT NULL DEFAULT 0;`)},
+		"m/0003_paid.down.sql":    {Data: []byte(`ALTER TABLE players DROP COLUMN paid;`)},
+	}
+}
+
+func newTestMigrator(t *testing.T, db *sql.DB, fsys fstest.MapFS) *Migrator {
+	t.Helper()
+	migrations, err := LoadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	return &Migrator{Db: db, Driver: DRIVER_SQLITE, Migrations: migrations}
+}
+
+func newTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// Every connection to :memory: is a database of its own.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count); err != nil {
+		t.Fatalf("look up table %s: %v", name, err)
+	}
+	return count > 0
+}
+
+func wantVersions(t *testing.T, what string, got []Migration, want ...int) {
+	t.Helper()
+	var versions []int
+	for _, migration := range got {
+		versions = append(versions, migration.Version)
+	}
+	if !slices.Equal(versions, want) {
+		t.Errorf("%s = %v, want %v", what, versions, want)
+	}
+}
+
+func wantError(t *testing.T, what string, err error, want string) {
+	t.Helper()
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("%s error = %v, want one containing %q", what, err, want)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := migrationsFS()
+	fsys["m/0010_late.up.sql"] = &fstest.MapFile{Data: []byte(`SELECT 1;`)}
+	migrations, err := LoadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	wantVersions(t, "migrations", migrations, 1, 2, 3, 10)
+	if late := migrations[3]; late.Name != "late" || late.Down != "" {
+		t.Errorf("migration 10 = %+v, want late without a down step", late)
+	}
+	if migrations[0].Checksum == migrations[1].Checksum || len(migrations[0].Checksum) != 64 {
+		t.Errorf("checksums = %s, %s; want distinct SHA-256s", migrations[0].Checksum, migrations[1].Checksum)
+	}
+
+	// The down step is left out of the checksum, so it can be fixed.
+	edited := migrationsFS()
+	edited["m/0001_games.down.sql"] = &fstest.MapFile{Data: []byte(`DROP TABLE IF EXISTS games;`)}
+	again, err := LoadMigrations(edited, "m")
+	if err != nil || again[0].Checksum != migrations[0].Checksum {
+		t.Errorf("checksum with an edited down step = %s, %v; want %s", again[0].Checksum, err, migrations[0].Checksum)
+	}
+}
+
+func TestLoadMigrationsRefusesBadFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"BadName", "m/4_games.sql", "name must look like"},
+		{"TwoNames", "m/0001_matches.down.sql", "has two names"},
+		{"NoUpStep", "m/0004_scores.down.sql", "0004_scores has no up step"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := migrationsFS()
+			fsys[tt.file] = &fstest.MapFile{Data: []byte(`SELECT 1;`)}
+			_, err := LoadMigrations(fsys, "m")
+			wantError(t, "LoadMigrations", err, tt.want)
+		})
+	}
+}
+
+func TestMigratorUpAppliesInOrder(t *testing.T) {
+	db := newTestDatabase(t)
+	m := newTestMigrator(t, db, migrationsFS())
+
+	applied, err := m.Up()
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	wantVersions(t, "applied", applied, 1, 2, 3)
+	if _, err := db.Exec(`INSERT INTO games (id) VALUES (1); INSERT INTO players (id, game_id, paid) VALUES (1, 1, 1)`); err != nil {
+		t.Errorf("schema after Up: %v", err)
+	}
+	recorded, err := m.Applied()
+	if err != nil || len(recorded) != 3 || recorded[2].Name != "paid" || recorded[2].Checksum != m.Migrations[2].Checksum {
+		t.Errorf("Applied = %+v, %v; want the three migrations with their checksums", recorded, err)
+	}
+	if version, err := m.Version(); err != nil || version != 3 {
+		t.Errorf("Version = %d, %v; want 3", version, err)
+	}
+
+	again, err := m.Up()
+	if err != nil || len(again) != 0 {
+		t.Errorf("second Up = %+v, %v; want nothing to apply", again, err)
+	}
+}
+
+func TestMigratorUpStopsAtAFailure(t *testing.T) {
+	db := newTestDatabase(t)
+	fsys := migrationsFS()
+	fsys["m/0003_paid.up.sql"] = &fstest.MapFile{Data: []byte(`ALTER TABLE nowhere ADD COLUMN paid BOOLEAN;`)}
+	m := newTestMigrator(t, db, fsys)
+
+	applied, err := m.Up()
+	wantError(t, "Up", err, "migration 0003_paid")
+	wantVersions(t, "applied", applied, 1, 2)
+	if version, _ := m.Version(); version != 2 {
+		t.Errorf("Version = %d, want 2", version)
+	}
+}
+
+func TestMigratorRefusesAChangedMigration(t *testing.T) {
+	db := newTestDatabase(t)
+	if _, err := newTestMigrator(t, db, migrationsFS()).Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	edited := migrationsFS()
+	edited["m/0002_players.up.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE players (id INTEGER PRIMARY KEY);`)}
+	m := newTestMigrator(t, db, edited)
+	wantError(t, "Verify", m.Verify(), "migration 0002_players was changed after it was applied")
+	_, err := m.Up()
+	wantError(t, "Up", err, "was changed")
+	_, err = m.Down(1)
+	wantError(t, "Down", err, "was changed")
+}
+
+func TestMigratorRefusesAnUnknownMigration(t *testing.T) {
+	db := newTestDatabase(t)
+	if _, err := newTestMigrator(t, db, migrationsFS()).Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	older := migrationsFS()
+	delete(older, "m/0003_paid.up.sql")
+	delete(older, "m/0003_paid.down.sql")
+	m := newTestMigrator(t, db, older)
+	wantError(t, "Verify", m.Verify(), "migration 0003_paid applied, which this build does not know")
+	_, err := m.Up()
+	wantError(t, "Up", err, "newer than the binary")
+}
+
+func TestMigratorDryRunWritesNothing(t *testing.T) {
+	db := newTestDatabase(t)
+	m := newTestMigrator(t, db, migrationsFS())
+	m.DryRun = true
+
+	pending, err := m.Up()
+	if err != nil {
+		t.Fatalf("dry-run Up: %v", err)
+	}
+	wantVersions(t, "would apply", pending, 1, 2, 3)
+	for _, table := range []string{"schema_migrations", "games", "players"} {
+		if tableExists(t, db, table) {
+			t.Errorf("table %s exists after a dry run", table)
+		}
+	}
+
+	m.DryRun = false
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	m.DryRun = true
+	reverted, err := m.Down(2)
+	if err != nil {
+		t.Fatalf("dry-run Down: %v", err)
+	}
+	wantVersions(t, "would revert", reverted, 3, 2)
+	if version, _ := m.Version(); version != 3 || !tableExists(t, db, "players") {
+		t.Errorf("Version after a dry-run Down = %d, want 3 with players kept", version)
+	}
+}
+
+func TestMigratorDown(t *testing.T) {
+	db := newTestDatabase(t)
+	m := newTestMigrator(t, db, migrationsFS())
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	reverted, err := m.Down(2)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	wantVersions(t, "reverted", reverted, 3, 2)
+	if version, _ := m.Version(); version != 1 {
+		t.Errorf("Version = %d, want 1", version)
+	}
+	if tableExists(t, db, "players") || !tableExists(t, db, "games") {
+		t.Errorf("players kept or games dropped after reverting 2 steps")
+	}
+
+	pending, err := m.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	wantVersions(t, "pending", pending, 2, 3)
+	if _, err := m.Up(); err != nil {
+		t.Errorf("Up after Down: %v", err)
+	}
+
+	reverted, err = m.Down(10)
+	if err != nil {
+		t.Fatalf("Down past the first migration: %v", err)
+	}
+	wantVersions(t, "reverted", reverted, 3, 2, 1)
+	if version, _ := m.Version(); version != 0 {
+		t.Errorf("Version = %d, want 0", version)
+	}
+}
+
+func TestMigratorDownStopsAtAnIrreversibleMigration(t *testing.T) {
+	db := newTestDatabase(t)
+	fsys := migrationsFS()
+	delete(fsys, "m/0002_players.down.sql")
+	m := newTestMigrator(t, db, fsys)
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	reverted, err := m.Down(3)
+	wantError(t, "Down", err, "migration 0002_players cannot be reverted")
+	wantVersions(t, "reverted", reverted, 3)
+	if version, _ := m.Version(); version != 2 {
+		t.Errorf("Version = %d, want 2", version)
+	}
+}
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS users (
+	id UUID PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	name VARCHAR NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS games (
+	id UUID PRIMARY KEY,
+	chat_id BIGINT,
+	opponent VARCHAR,
+	location VARCHAR,
+	price DOUBLE PRECISION,
+	date TIMESTAMPTZ,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	created_by UUID,
+	is_active BOOLEAN
+);
+
+CREATE TABLE IF NOT EXISTS player_status (
+	name VARCHAR PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS game_players (
+	game_id UUID REFERENCES games(id),
+	user_id UUID REFERENCES users(id),
+	status VARCHAR,
+	has_paid BOOLEAN,
+	joined_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (game_id, user_id)
+);
@@ -0,0 +1,13 @@
+-- Revision of a game, bumped whenever it changes, so that calendar apps
+-- subscribed to a chat's games replace the copy they have.
+ALTER TABLE games ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+
+-- Secret tokens giving access to a chat's data without Telegram, such as the
+-- link to its calendar. A chat has at most one token per purpose.
+CREATE TABLE IF NOT EXISTS chat_tokens (
+	chat_id BIGINT NOT NULL,
+	purpose VARCHAR NOT NULL,
+	token VARCHAR NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (chat_id, purpose)
+);
@@ -0,0 +1,15 @@
+-- Games a chat's league scheduled, imported from its fixture list. Each one
+-- is opened as a game once the chat has no upcoming game, so players answer
+-- for one game at a time. game_id is the game opened from it, NULL while it
+-- waits.
+CREATE TABLE IF NOT EXISTS fixtures (
+	id UUID PRIMARY KEY,
+	chat_id BIGINT NOT NULL,
+	uid VARCHAR NOT NULL,
+	date TIMESTAMPTZ NOT NULL,
+	location VARCHAR NOT NULL,
+	opponent VARCHAR NOT NULL,
+	game_id UUID REFERENCES games(id),
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (chat_id, uid)
+);
@@ -0,0 +1,14 @@
+-- Sign-ins of Telegram users to the web dashboard of a chat. A login row is
+-- the one-time link /dashboard sends, redeemed for a session row kept in a
+-- cookie. Tokens are stored as their SHA-256 hashes, so the table alone
+-- signs nobody in.
+CREATE TABLE IF NOT EXISTS web_sessions (
+	token_hash VARCHAR(64) PRIMARY KEY,
+	kind VARCHAR NOT NULL,
+	chat_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS web_sessions_expires_at ON web_sessions (expires_at);
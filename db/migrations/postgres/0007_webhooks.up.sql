@@ -0,0 +1,29 @@
+-- URLs a chat registered to be sent its game events, signed with secret.
+-- The secret is kept as it is, as it signs every payload.
+CREATE TABLE IF NOT EXISTS webhooks (
+	id UUID PRIMARY KEY,
+	chat_id BIGINT NOT NULL,
+	url VARCHAR NOT NULL,
+	secret VARCHAR NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (chat_id, url)
+);
+
+-- Events waiting to be delivered to a webhook, or delivered or given up on
+-- lately. A message is due once next_attempt_at passes, until delivered_at
+-- or failed_at is set.
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+	id UUID PRIMARY KEY,
+	webhook_id UUID NOT NULL REFERENCES webhooks(id),
+	event_id UUID NOT NULL,
+	event_type VARCHAR NOT NULL,
+	payload TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL,
+	last_error VARCHAR NOT NULL DEFAULT '',
+	delivered_at TIMESTAMPTZ,
+	failed_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_outbox_next_attempt_at ON webhook_outbox (next_attempt_at);
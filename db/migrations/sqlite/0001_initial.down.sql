@@ -0,0 +1,4 @@
+DROP TABLE game_players;
+DROP TABLE player_status;
+DROP TABLE games;
+DROP TABLE users;
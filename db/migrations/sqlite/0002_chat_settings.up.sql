@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS chat_settings (
+	chat_id INTEGER NOT NULL,
+	key VARCHAR NOT NULL,
+	value VARCHAR NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (chat_id, key)
+);
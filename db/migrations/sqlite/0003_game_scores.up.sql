@@ -0,0 +1,3 @@
+-- Final score of a game as goals for and against, such as 3-1. Games from
+-- before scores were kept have none.
+ALTER TABLE games ADD COLUMN score VARCHAR;
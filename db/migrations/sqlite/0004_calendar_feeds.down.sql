@@ -0,0 +1,2 @@
+DROP TABLE chat_tokens;
+ALTER TABLE games DROP COLUMN sequence;
@@ -0,0 +1,16 @@
+-- Games a chat's league scheduled, imported from its fixture list. Each one
+-- is opened as a game once the chat has no upcoming game, so players answer
+-- for one game at a time. game_id is the game opened from it, NULL while it
+-- waits.
+CREATE TABLE IF NOT EXISTS fixtures (
+	id VARCHAR(36) PRIMARY KEY,
+	chat_id INTEGER NOT NULL,
+	uid VARCHAR NOT NULL,
+	date TIMESTAMP NOT NULL,
+	location VARCHAR NOT NULL,
+	opponent VARCHAR NOT NULL,
+	game_id VARCHAR(36),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (chat_id, uid),
+	FOREIGN KEY (game_id) REFERENCES games(id)
+);
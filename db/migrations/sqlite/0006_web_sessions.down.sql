@@ -0,0 +1 @@
+DROP TABLE web_sessions;
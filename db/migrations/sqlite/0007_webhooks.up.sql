@@ -0,0 +1,29 @@
+-- URLs a chat registered to be sent its game events, signed with secret.
+-- The secret is kept as it is, as it signs every payload.
+CREATE TABLE IF NOT EXISTS webhooks (
+	id VARCHAR(36) PRIMARY KEY,
+	chat_id INTEGER NOT NULL,
+	url VARCHAR NOT NULL,
+	secret VARCHAR NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (chat_id, url)
+);
+
+-- Events waiting to be delivered to a webhook, or delivered or given up on
+-- lately. A message is due once next_attempt_at passes, until delivered_at
+-- or failed_at is set.
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+	id VARCHAR(36) PRIMARY KEY,
+	webhook_id VARCHAR(36) NOT NULL REFERENCES webhooks(id),
+	event_id VARCHAR(36) NOT NULL,
+	event_type VARCHAR NOT NULL,
+	payload TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL,
+	last_error VARCHAR NOT NULL DEFAULT '',
+	delivered_at TIMESTAMP,
+	failed_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_outbox_next_attempt_at ON webhook_outbox (next_attempt_at);
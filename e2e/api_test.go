@@ -0,0 +1,99 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"tg-sunday-league/bot"
+	"tg-sunday-league/repositories"
+	"tg-sunday-league/services"
+	"tg-sunday-league/web"
+)
+
+// putAnswer answers for the player through the API at base, returning the
+// status and the names of the players attending.
+func putAnswer(t *testing.T, base string, token string, status string) (int, []string) {
+	t.Helper()
+	body := strings.NewReader(`{"name": "Bea", "status": "` + status + `"}`)
+	request, _ := http.NewRequest(http.MethodPut, base+"/games/next/players/"+strconv.FormatInt(player.ID, 10), body)
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("PUT answer: %v", err)
+	}
+	defer response.Body.Close()
+	var game struct {
+		Players []struct {
+			Name string `json:"name"`
+		} `json:"players"`
+	}
+	json.NewDecoder(response.Body).Decode(&game)
+	var names []string
+	for _, p := range game.Players {
+		names = append(names, p.Name)
+	}
+	return response.StatusCode, names
+}
+
+func TestAPIToken(t *testing.T) {
+	tokens := &services.TokenService{TokenRepository: repositories.NewMemoryTokenRepository()}
+	var site *httptest.Server
+	h := newHarnessWithSetup(t, nil, func(b *bot.Bot) {
+		gameService := b.GameService.(*services.GameService)
+		handler := (&web.Server{Games: gameService, GameService: gameService, Settings: b.SettingsService, Tokens: tokens}).Handler()
+		site = httptest.NewServer(handler)
+		b.Tokens = tokens
+		b.WebUrl = site.URL
+	})
+	t.Cleanup(site.Close)
+	h.send(group, admin, "/new 2099-01-04 11:00, Kallang, Rovers")
+
+	reply := h.send(group, player, "/api")
+	wantText(t, reply.Params["text"], "Only admins")
+
+	sent := len(h.requests("sendMessage"))
+	reply = h.send(group, admin, "/api")
+	if reply.Params["chat_id"] != strconv.FormatInt(admin.ID, 10) {
+		t.Fatalf("token sent to chat %s, want the admin's private chat", reply.Params["chat_id"])
+	}
+	wantText(t, reply.Params["text"], "API token of Sunday League", site.URL+"/api/v1")
+	token := strings.Split(reply.Params["text"], "\n")[1]
+	replies, err := h.server.WaitForRequests(sent+2, replyTimeout, "sendMessage")
+	if err != nil {
+		t.Fatalf("no reply in the group: %v", err)
+	}
+	wantText(t, replies[sent+1].Params["text"], "A new API token was sent to you in a private chat")
+
+	status, players := putAnswer(t, site.URL+web.API_PATH, token, "in")
+	if status != http.StatusOK || len(players) != 1 || players[0] != "Bea" {
+		t.Fatalf("PUT answer = %d %v, want Bea playing", status, players)
+	}
+	reply = h.send(group, player, "/details")
+	wantText(t, reply.Params["text"], "Bea")
+
+	// Tokens are only stored hashed, so asking again issues a new one.
+	sent = len(h.requests("sendMessage"))
+	h.send(group, admin, "/api")
+	replies, err = h.server.WaitForRequests(sent+2, replyTimeout, "sendMessage")
+	if err != nil {
+		t.Fatalf("no reply in the group: %v", err)
+	}
+	wantText(t, replies[sent+1].Params["text"], "The previous one, if any, no longer works")
+	if status, _ := putAnswer(t, site.URL+web.API_PATH, token, "out"); status != http.StatusUnauthorized {
+		t.Errorf("PUT with the replaced token = %d, want 401", status)
+	}
+	newToken := strings.Split(replies[sent].Params["text"], "\n")[1]
+	if status, players := putAnswer(t, site.URL+web.API_PATH, newToken, "out"); status != http.StatusOK || len(players) != 0 {
+		t.Errorf("PUT with the new token = %d %v, want Bea out", status, players)
+	}
+}
+
+func TestAPIWithoutServer(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(group, admin, "/api")
+	wantText(t, reply.Params["text"], "The API is not available")
+}
@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"tg-sunday-league/backup"
+	"tg-sunday-league/bot"
+	"tg-sunday-league/db"
+)
+
+func newBackupHarness(t *testing.T) *harness {
+	t.Helper()
+	dir := t.TempDir()
+	dbInstance, err := db.Connect(filepath.Join(dir, "league.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbInstance.Close() })
+	if err := db.SetupDatabase(dbInstance, db.DRIVER_SQLITE, false); err != nil {
+		t.Fatal(err)
+	}
+
+	return newHarnessWithSetup(t, nil, func(b *bot.Bot) {
+		b.OwnerId = admin.ID
+		b.Backups = &backup.BackupService{Db: dbInstance, Dir: filepath.Join(dir, "backups"), Keep: 3}
+	})
+}
+
+func TestBackupIsSentToOwnerPrivately(t *testing.T) {
+	h := newBackupHarness(t)
+
+	reply := h.send(group, admin, "/backup")
+
+	wantText(t, reply.Params["text"], "sent to you in a private chat")
+	documents := h.requests("sendDocument")
+	if len(documents) != 1 {
+		t.Fatalf("sent %d documents, want 1", len(documents))
+	}
+	if documents[0].Params["chat_id"] != strconv.FormatInt(admin.ID, 10) {
+		t.Errorf("backup sent to chat %s, want the owner's private chat %d", documents[0].Params["chat_id"], admin.ID)
+	}
+	file := documents[0].Files["document"]
+	if !strings.HasPrefix(file.Name, backup.FILE_PREFIX) || !bytes.HasPrefix(file.Content, []byte("SQLite format 3")) {
+		t.Errorf("sent %s starting with %q, want a SQLite backup", file.Name, file.Content[:min(len(file.Content), 16)])
+	}
+}
+
+func TestBackupIsOwnerOnly(t *testing.T) {
+	h := newBackupHarness(t)
+
+	reply := h.send(group, player, "/backup")
+
+	wantText(t, reply.Params["text"], "Only the owner of the bot")
+	if documents := h.requests("sendDocument"); len(documents) != 0 {
+		t.Errorf("sent %d documents to a player who is not the owner", len(documents))
+	}
+}
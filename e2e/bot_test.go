@@ -0,0 +1,159 @@
+package e2e
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"tg-sunday-league/monitoring"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func wantText(t *testing.T, got string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("reply %q does not contain %q", got, w)
+		}
+	}
+}
+
+func TestHelpListsCommands(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(private, player, "/help")
+
+	wantText(t, reply.Params["text"], "Bot Commands:", "/new", "/in", "/settings")
+	if reply.Params["chat_id"] != strconv.FormatInt(private.ID, 10) {
+		t.Errorf("reply sent to chat %s, want %d", reply.Params["chat_id"], private.ID)
+	}
+}
+
+func TestCommandsAreGroupOnly(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(private, player, "/new sunday 11am")
+
+	wantText(t, reply.Params["text"], "group chat only")
+}
+
+func TestGameLifecycle(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(group, admin, "/new 2099-01-04 11:00, Kallang, Rovers, 12")
+	wantText(t, reply.Params["text"], "2099-01-04 11:00", "Location: Kallang", "Opponent: Rovers", "Price: 12.00")
+
+	reply = h.send(group, admin, "/in")
+	wantText(t, reply.Params["text"], "Players: 1. Ana")
+
+	reply = h.send(group, player, "/out")
+	wantText(t, reply.Params["text"], "Players: 1. Ana", "Absentees: 1. Bea")
+
+	reply = h.send(group, player, "/paid")
+	wantText(t, reply.Params["text"], "Absentees: 1. Bea ✅")
+
+	reply = h.send(group, admin, "/details")
+	wantText(t, reply.Params["text"], "Opponent: Rovers", "Players: 1. Ana\n")
+
+	game, err := h.games.GetLatestGameByChatID(context.Background(), group.ID)
+	if err != nil || game == nil {
+		t.Fatalf("no game stored: %v", err)
+	}
+	players, _ := h.games.GetGamePlayers(context.Background(), game.Id)
+	for _, p := range players {
+		if p.HasPaid != (p.Name == "Bea") {
+			t.Errorf("%s paid = %v", p.Name, p.HasPaid)
+		}
+	}
+}
+
+func TestOnlyAdminsCreateGames(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(group, player, "/new 2099-01-04 11:00, Kallang")
+
+	wantText(t, reply.Params["text"], "Only admins")
+	if game, _ := h.games.GetLatestGameByChatID(context.Background(), group.ID); game != nil {
+		t.Errorf("game created by non-admin: %+v", game)
+	}
+}
+
+func TestUnreadableKickoffIsExplained(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(group, admin, "/new whenever, Kallang")
+
+	wantText(t, reply.Params["text"], `Could not understand "whenever"`)
+}
+
+func TestSettingsButtons(t *testing.T) {
+	h := newHarness(t)
+	settings := h.send(group, admin, "/settings")
+	wantText(t, settings.Params["text"], "Chat settings:")
+
+	answer, edit := h.press(settings, player, "Language: en")
+	wantText(t, answer.Params["text"], "Only admins")
+	if edit != nil {
+		t.Errorf("non-admin changed settings: %q", edit.Params["text"])
+	}
+
+	answer, edit = h.press(settings, admin, "Language: en")
+	if edit == nil {
+		t.Fatalf("settings not edited, callback answered with %q", answer.Params["text"])
+	}
+	wantText(t, edit.Params["text"], "Configuración del chat:", "(language): es")
+
+	reply := h.send(group, admin, "/details")
+	wantText(t, reply.Params["text"], "No hay")
+}
+
+func TestChatCommandsApplyInOrder(t *testing.T) {
+	h := newHarness(t)
+	h.send(group, admin, "/new 2099-01-04 11:00, Kallang")
+
+	// All taps arrive before the first is handled. Each reply must show
+	// exactly the players who tapped before.
+	const taps = 5
+	sent := len(h.requests("sendMessage"))
+	for i := 1; i <= taps; i++ {
+		h.server.SendText(group, &telebot.User{ID: int64(100 + i), FirstName: "P" + strconv.Itoa(i)}, "/in")
+	}
+	replies, err := h.server.WaitForRequests(sent+taps, replyTimeout, "sendMessage")
+	if err != nil {
+		t.Fatalf("replies to /in: %v", err)
+	}
+
+	for i, reply := range replies[sent:] {
+		n := i + 1
+		text := reply.Params["text"]
+		wantText(t, text, strconv.Itoa(n)+". P"+strconv.Itoa(n))
+		if strings.Contains(text, strconv.Itoa(n+1)+". ") {
+			t.Errorf("reply %d lists players who tapped later: %q", n, text)
+		}
+	}
+}
+
+func TestCommandsAndErrorsAreCounted(t *testing.T) {
+	h := newHarness(t)
+	help := monitoring.CommandsTotal.WithLabelValues("/help")
+	denied := monitoring.ErrorsTotal.WithLabelValues("permission_denied")
+	helpBefore, deniedBefore := testutil.ToFloat64(help), testutil.ToFloat64(denied)
+
+	h.send(private, player, "/help")
+	h.send(group, player, "/cancel")
+
+	// Commands are counted once their handler returns, just after replying.
+	deadline := time.Now().Add(replyTimeout)
+	for testutil.ToFloat64(help) != helpBefore+1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s counted %v times, want %v", "/help", testutil.ToFloat64(help), helpBefore+1)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := testutil.ToFloat64(denied); got != deniedBefore+1 {
+		t.Errorf("permission_denied errors = %v, want %v", got, deniedBefore+1)
+	}
+}
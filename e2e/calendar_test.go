@@ -0,0 +1,90 @@
+package e2e
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tg-sunday-league/bot"
+	"tg-sunday-league/repositories"
+	"tg-sunday-league/services"
+	"tg-sunday-league/web"
+)
+
+// getCalendar fetches the calendar at link, returning its status and body.
+func getCalendar(t *testing.T, link string) (int, string) {
+	t.Helper()
+	response, err := http.Get(link)
+	if err != nil {
+		t.Fatalf("GET calendar: %v", err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	return response.StatusCode, string(body)
+}
+
+// lastLine is the link ending the bot's calendar messages.
+func lastLine(text string) string {
+	return text[strings.LastIndex(text, "\n")+1:]
+}
+
+func TestCalendar(t *testing.T) {
+	tokens := &services.TokenService{TokenRepository: repositories.NewMemoryTokenRepository()}
+	var site *httptest.Server
+	h := newHarnessWithSetup(t, nil, func(b *bot.Bot) {
+		handler := (&web.Server{Games: b.GameService.(*services.GameService), Settings: b.SettingsService, Tokens: tokens}).Handler()
+		site = httptest.NewServer(handler)
+		b.Tokens = tokens
+		b.WebUrl = site.URL
+	})
+	t.Cleanup(site.Close)
+	h.send(group, admin, "/new 2099-01-04 11:00, Kallang, Rovers, 12")
+
+	reply := h.send(group, player, "/calendar")
+	wantText(t, reply.Params["text"], "Subscribe to the games of this chat", site.URL+"/calendar/")
+	link := lastLine(reply.Params["text"])
+	documents, err := h.server.WaitForRequests(1, replyTimeout, "sendDocument")
+	if err != nil {
+		t.Fatalf("no calendar file: %v", err)
+	}
+	file := documents[0].Files["document"]
+	if file.Name != "game-2099-01-04.ics" || !strings.Contains(string(file.Content), "SUMMARY:Football vs Rovers\r\n") {
+		t.Errorf("calendar file %s =\n%s\nwant the game against Rovers", file.Name, file.Content)
+	}
+
+	status, body := getCalendar(t, link)
+	if status != http.StatusOK {
+		t.Fatalf("GET %s = %d, want 200", link, status)
+	}
+	wantText(t, body, "DTSTART:20990104T110000Z", "SEQUENCE:0", "STATUS:CONFIRMED")
+
+	h.send(group, admin, "/cancel")
+	_, body = getCalendar(t, link)
+	wantText(t, body, "SEQUENCE:1", "STATUS:CANCELLED", "SUMMARY:Cancelled: Football vs Rovers")
+
+	reply = h.send(group, player, "/calendar reset")
+	wantText(t, reply.Params["text"], "Only admins")
+	reply = h.send(group, admin, "/calendar reset")
+	wantText(t, reply.Params["text"], "The previous calendar link no longer works")
+	if status, _ := getCalendar(t, link); status != http.StatusNotFound {
+		t.Errorf("GET reset link = %d, want 404", status)
+	}
+	if status, _ := getCalendar(t, lastLine(reply.Params["text"])); status != http.StatusOK {
+		t.Errorf("GET new link = %d, want 200", status)
+	}
+	if documents := h.requests("sendDocument"); len(documents) != 1 {
+		t.Errorf("sent %d calendar files, want none once the game was cancelled", len(documents)-1)
+	}
+}
+
+func TestCalendarWithoutServer(t *testing.T) {
+	h := newHarness(t)
+	h.send(group, admin, "/new 2099-01-04 11:00, Kallang")
+
+	reply := h.send(group, player, "/calendar")
+	wantText(t, reply.Params["text"], "Calendar links are not available")
+	if _, err := h.server.WaitForRequests(1, replyTimeout, "sendDocument"); err != nil {
+		t.Errorf("no calendar file of the next game: %v", err)
+	}
+}
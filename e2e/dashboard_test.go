@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"tg-sunday-league/bot"
+	"tg-sunday-league/repositories"
+	"tg-sunday-league/services"
+	"tg-sunday-league/web"
+)
+
+func TestDashboardLink(t *testing.T) {
+	sessions := &services.SessionService{SessionRepository: repositories.NewMemorySessionRepository()}
+	var site *httptest.Server
+	h := newHarnessWithSetup(t, nil, func(b *bot.Bot) {
+		gameService := b.GameService.(*services.GameService)
+		handler := (&web.Server{Games: gameService, GameService: gameService, Settings: b.SettingsService, Sessions: sessions}).Handler()
+		site = httptest.NewServer(handler)
+		b.Sessions = sessions
+		b.WebUrl = site.URL
+	})
+	t.Cleanup(site.Close)
+	h.send(group, admin, "/new 2099-01-04 11:00, Kallang, Rovers")
+	h.send(group, player, "/in")
+
+	sent := len(h.requests("sendMessage"))
+	reply := h.send(group, player, "/dashboard")
+	if reply.Params["chat_id"] != strconv.FormatInt(player.ID, 10) {
+		t.Fatalf("link sent to chat %s, want the player's private chat", reply.Params["chat_id"])
+	}
+	if reply.Params["disable_web_page_preview"] != "true" {
+		t.Errorf("link sent with a preview, params %v", reply.Params)
+	}
+	wantText(t, reply.Params["text"], "Sign in to the dashboard of Sunday League", site.URL+web.DASHBOARD_PATH+"login?code=")
+	replies, err := h.server.WaitForRequests(sent+2, replyTimeout, "sendMessage")
+	if err != nil {
+		t.Fatalf("no reply in the group: %v", err)
+	}
+	wantText(t, replies[sent+1].Params["text"], "sent to you in a private chat")
+
+	link, err := url.Parse(strings.TrimSpace(reply.Params["text"][strings.LastIndex(reply.Params["text"], "\n"):]))
+	if err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	response, err := client.PostForm(site.URL+web.DASHBOARD_PATH+"login", url.Values{"code": {link.Query().Get("code")}})
+	if err != nil {
+		t.Fatalf("POST login: %v", err)
+	}
+	defer response.Body.Close()
+	page, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("dashboard = %d, want the page:\n%s", response.StatusCode, page)
+	}
+	wantText(t, string(page), "Next game", "vs Rovers", "<td>Bea</td>")
+}
+
+func TestDashboardWithoutServer(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(group, player, "/dashboard")
+	wantText(t, reply.Params["text"], "The dashboard is not available")
+}
@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExportSendsSpreadsheet(t *testing.T) {
+	h := newHarness(t)
+	h.send(group, admin, "/new 2099-01-04 11:00, Kallang, Rovers, 12")
+	h.send(group, admin, "/in")
+	h.send(group, player, "/in")
+	h.send(group, player, "/paid")
+
+	for i, format := range []string{"csv", "xlsx"} {
+		h.server.SendText(group, admin, "/export "+format+" 2099-01-01 2099-01-31")
+		documents, err := h.server.WaitForRequests(i+1, replyTimeout, "sendDocument")
+		if err != nil {
+			t.Fatalf("no export sent: %v", err)
+		}
+		document := documents[i]
+		if document.Params["chat_id"] != strconv.FormatInt(group.ID, 10) || document.Params["caption"] != "Games: 1" {
+			t.Errorf("export sent to %s with caption %q, want the group and a game count", document.Params["chat_id"], document.Params["caption"])
+		}
+		file := document.Files["document"]
+		if file.Name != "games-2099-01-04-2099-01-04."+format {
+			t.Errorf("file name = %q", file.Name)
+		}
+
+		var content string
+		if format == "csv" {
+			content = string(file.Content)
+		} else {
+			f, err := excelize.OpenReader(bytes.NewReader(file.Content))
+			if err != nil {
+				t.Fatalf("OpenReader: %v", err)
+			}
+			rows, _ := f.GetRows("Attendance")
+			for _, row := range rows {
+				content += strings.Join(row, ",") + "\n"
+			}
+			f.Close()
+		}
+		wantText(t, content, ",Ana,10,attending,no", ",Bea,20,attending,yes")
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(group, player, "/export")
+	wantText(t, reply.Params["text"], "Only admins")
+
+	reply = h.send(group, admin, "/export pdf")
+	wantText(t, reply.Params["text"], "/export [csv|xlsx] [from] [to]")
+
+	reply = h.send(group, admin, "/export 2099-02-01 2099-01-01")
+	wantText(t, reply.Params["text"], "must not be after the last")
+
+	reply = h.send(group, admin, "/export")
+	wantText(t, reply.Params["text"], "no games to export")
+}
@@ -0,0 +1,120 @@
+package e2e
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"tg-sunday-league/bot"
+	"tg-sunday-league/models"
+	"tg-sunday-league/telegramtest"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestImportFixturesOpensTheFirst(t *testing.T) {
+	h := newHarness(t)
+	fixtures := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:round-1@league",
+		"DTSTART:20990104T030000Z",
+		"SUMMARY:Sunday FC vs Rovers",
+		"LOCATION:Kallang",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:round-2@league",
+		"DTSTART:20990111T030000Z",
+		"SUMMARY:United vs Sunday FC",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	file := telegramtest.File{Name: "fixtures.ics", Content: []byte(fixtures)}
+
+	reply := h.sendDocument(group, admin, file, "/import")
+	wantText(t, reply.Params["text"], "Fixtures to schedule: 2", "Rovers, Kallang", "United", "/import confirm")
+	if games, _ := h.games.ListGamesByChatID(context.Background(), group.ID); len(games) != 0 {
+		t.Fatalf("games = %+v after checking the file, want none", games)
+	}
+
+	sent := len(h.requests("sendMessage"))
+	reply = h.sendDocument(group, admin, file, "/import confirm")
+	wantText(t, reply.Params["text"], "Fixtures scheduled: 2")
+	replies, err := h.server.WaitForRequests(sent+2, replyTimeout, "sendMessage")
+	if err != nil {
+		t.Fatalf("the opened fixture was not announced: %v", err)
+	}
+	wantText(t, replies[sent+1].Params["text"], "Answers are open for the next fixture", "Opponent: Rovers")
+
+	reply = h.send(group, player, "/in")
+	wantText(t, reply.Params["text"], "Bea")
+	games, _ := h.games.ListGamesByChatID(context.Background(), group.ID)
+	if len(games) != 1 || games[0].Opponent != "Rovers" {
+		t.Fatalf("games = %+v, want only the game against Rovers", games)
+	}
+}
+
+func TestOpenFixturesAnnouncesTheNextGame(t *testing.T) {
+	var b *bot.Bot
+	h := newHarnessWithSetup(t, nil, func(started *bot.Bot) { b = started })
+	fixture := &models.Fixture{Id: uuid.New(), ChatId: group.ID, Uid: "round-2", Date: time.Now().Add(time.Hour), Opponent: "United"}
+	if err := h.games.InsertFixture(context.Background(), fixture); err != nil {
+		t.Fatalf("InsertFixture: %v", err)
+	}
+
+	sent := len(h.requests("sendMessage"))
+	b.OpenFixtures(context.Background())
+	replies, err := h.server.WaitForRequests(sent+1, replyTimeout, "sendMessage")
+	if err != nil {
+		t.Fatalf("the fixture was not announced: %v", err)
+	}
+	wantText(t, replies[sent].Params["text"], "Answers are open for the next fixture", "Opponent: United")
+	if replies[sent].Params["chat_id"] != strconv.FormatInt(group.ID, 10) {
+		t.Errorf("announced in chat %s, want %d", replies[sent].Params["chat_id"], group.ID)
+	}
+
+	// Fixtures open behind the commands of the chat, so once a later command
+	// is answered the fixture was handled.
+	b.OpenFixtures(context.Background())
+	reply := h.send(group, player, "/details")
+	wantText(t, reply.Params["text"], "Opponent: United")
+	if games, _ := h.games.ListGamesByChatID(context.Background(), group.ID); len(games) != 1 {
+		t.Errorf("games = %+v, want the fixture opened once", games)
+	}
+}
+
+func TestImportFixturesReschedulesTheOpenedGame(t *testing.T) {
+	h := newHarness(t)
+	fixtures := func(location string) telegramtest.File {
+		return telegramtest.File{Name: "fixtures.ics", Content: []byte(strings.Join([]string{
+			"BEGIN:VCALENDAR",
+			"BEGIN:VEVENT",
+			"UID:round-1@league",
+			"DTSTART:20990104T030000Z",
+			"SUMMARY:Sunday FC vs Rovers",
+			"LOCATION:" + location,
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"UID:round-2@league",
+			"DTSTART:20990111T030000Z",
+			"SUMMARY:United vs Sunday FC",
+			"END:VEVENT",
+			"END:VCALENDAR",
+		}, "\r\n"))}
+	}
+	sent := len(h.requests("sendMessage"))
+	h.sendDocument(group, admin, fixtures("Kallang"), "/import confirm")
+	if _, err := h.server.WaitForRequests(sent+2, replyTimeout, "sendMessage"); err != nil {
+		t.Fatalf("the opened fixture was not announced: %v", err)
+	}
+
+	reply := h.sendDocument(group, admin, fixtures("Bishan"), "/import")
+	wantText(t, reply.Params["text"], "Upcoming game rescheduled:", "Rovers, Bishan", "/import confirm")
+	reply = h.send(group, player, "/details")
+	wantText(t, reply.Params["text"], "Location: Kallang")
+
+	h.sendDocument(group, admin, fixtures("Bishan"), "/import confirm")
+	reply = h.send(group, player, "/details")
+	wantText(t, reply.Params["text"], "Location: Bishan", "Opponent: Rovers")
+}
@@ -0,0 +1,170 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"tg-sunday-league/bot"
+	"tg-sunday-league/repositories"
+	"tg-sunday-league/services"
+	"tg-sunday-league/telegramtest"
+	"time"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+const replyTimeout = 5 * time.Second
+
+var (
+	group   = &telebot.Chat{ID: -1001, Type: telebot.ChatSuperGroup, Title: "Sunday League"}
+	admin   = &telebot.User{ID: 10, FirstName: "Ana"}
+	player  = &telebot.User{ID: 20, FirstName: "Bea"}
+	private = &telebot.Chat{ID: player.ID, Type: telebot.ChatPrivate}
+)
+
+// harness runs the real bot, with in-memory storage, against a fake Bot API
+// server, and lets tests talk to it like a Telegram user would.
+type harness struct {
+	t        *testing.T
+	server   *telegramtest.Server
+	games    *repositories.MemoryGameRepository
+	webhooks *repositories.MemoryWebhookRepository
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	return newHarnessWithPoller(t, nil)
+}
+
+// newHarnessWithPoller is newHarness with the bot receiving updates from
+// poller instead of long polling the fake server.
+func newHarnessWithPoller(t *testing.T, poller telebot.Poller) *harness {
+	t.Helper()
+	return newHarnessWithSetup(t, poller, func(*bot.Bot) {})
+}
+
+// newHarnessWithSetup is newHarnessWithPoller with setup called on the bot
+// before it starts, to set the fields NewBot leaves empty.
+func newHarnessWithSetup(t *testing.T, poller telebot.Poller, setup func(b *bot.Bot)) *harness {
+	t.Helper()
+	server := telegramtest.NewServer()
+	server.SetAdmins(group.ID, *admin)
+
+	games := repositories.NewMemoryGameRepository()
+	settings := repositories.NewMemorySettingsRepository()
+	webhooks := repositories.NewMemoryWebhookRepository()
+	settingsService := &services.SettingsService{SettingsRepository: settings}
+	gameService := &services.GameService{
+		GameRepository:  games,
+		SettingsService: settingsService,
+		UnitOfWork:      repositories.NewMemoryUnitOfWork(games, settings, webhooks),
+	}
+
+	b, err := bot.NewBot("123:test", server.URL, poller, gameService, settingsService, &bot.MessageFormatter{})
+	if err != nil {
+		server.Close()
+		t.Fatalf("NewBot: %v", err)
+	}
+	b.Webhooks = &services.WebhookService{WebhookRepository: webhooks}
+	setup(b)
+
+	done := make(chan struct{})
+	go func() {
+		if err := b.Start(); err != nil {
+			t.Errorf("Start: %v", err)
+		}
+		close(done)
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), replyTimeout)
+		defer cancel()
+		if err := b.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown: %v", err)
+		}
+		<-done
+		server.Close()
+	})
+
+	return &harness{t: t, server: server, games: games, webhooks: webhooks}
+}
+
+// send posts text from user in chat and returns the message the bot replies
+// with.
+func (h *harness) send(chat *telebot.Chat, from *telebot.User, text string) telegramtest.Request {
+	h.t.Helper()
+	sent := len(h.requests("sendMessage"))
+	h.server.SendText(chat, from, text)
+
+	replies, err := h.server.WaitForRequests(sent+1, replyTimeout, "sendMessage")
+	if err != nil {
+		h.t.Fatalf("no reply to %q: %v", text, err)
+	}
+	return replies[sent]
+}
+
+// sendDocument posts file from user in chat with caption and returns the
+// message the bot replies with.
+func (h *harness) sendDocument(chat *telebot.Chat, from *telebot.User, file telegramtest.File, caption string) telegramtest.Request {
+	h.t.Helper()
+	sent := len(h.requests("sendMessage"))
+	h.server.SendDocument(chat, from, file, caption)
+
+	replies, err := h.server.WaitForRequests(sent+1, replyTimeout, "sendMessage")
+	if err != nil {
+		h.t.Fatalf("no reply to %s sent with %q: %v", file.Name, caption, err)
+	}
+	return replies[sent]
+}
+
+// press presses the button labelled text under the bot's message and returns
+// the bot's answer to the callback query, along with the edit it made, if any.
+func (h *harness) press(message telegramtest.Request, from *telebot.User, text string) (answer telegramtest.Request, edit *telegramtest.Request) {
+	h.t.Helper()
+	data := buttonData(h.t, message, text)
+	answered := len(h.requests("answerCallbackQuery"))
+	edited := len(h.requests("editMessageText"))
+	h.server.PressButton(message.Message, from, data)
+
+	answers, err := h.server.WaitForRequests(answered+1, replyTimeout, "answerCallbackQuery")
+	if err != nil {
+		h.t.Fatalf("no answer to pressing %q: %v", text, err)
+	}
+	// Edits are sent before the callback is answered.
+	if edits := h.requests("editMessageText"); len(edits) > edited {
+		edit = &edits[edited]
+	}
+	return answers[answered], edit
+}
+
+func (h *harness) requests(method string) []telegramtest.Request {
+	var matching []telegramtest.Request
+	for _, r := range h.server.Requests() {
+		if r.Method == method {
+			matching = append(matching, r)
+		}
+	}
+	return matching
+}
+
+// buttonData finds the callback data of the inline button labelled text.
+func buttonData(t *testing.T, message telegramtest.Request, text string) string {
+	t.Helper()
+	var markup struct {
+		InlineKeyboard [][]struct {
+			Text         string `json:"text"`
+			CallbackData string `json:"callback_data"`
+		} `json:"inline_keyboard"`
+	}
+	if err := json.Unmarshal([]byte(message.Params["reply_markup"]), &markup); err != nil {
+		t.Fatalf("message %q has no inline keyboard: %v", message.Params["text"], err)
+	}
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			if button.Text == text {
+				return button.CallbackData
+			}
+		}
+	}
+	t.Fatalf("message %q has no button %q", message.Params["text"], text)
+	return ""
+}
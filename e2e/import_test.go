@@ -0,0 +1,56 @@
+package e2e
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"tg-sunday-league/telegramtest"
+)
+
+func TestImportChecksThenImports(t *testing.T) {
+	h := newHarness(t)
+	history := "Kickoff,Opponent,Score,Player,Telegram ID,Paid\n" +
+		"2023-03-05 11:00,Rovers,3-1,Ana,10,yes\n" +
+		"2023-03-05 11:00,Rovers,3-1,Zed,,no\n"
+
+	reply := h.sendDocument(group, admin, telegramtest.File{Name: "history.csv", Content: []byte(history)}, "/import")
+	wantText(t, reply.Params["text"], "Problems to fix before importing: 1", "Line 3: nobody called Zed has played in this chat")
+
+	file := telegramtest.File{Name: "history.csv", Content: []byte(strings.Replace(history, "Zed,,no", "Zed,30,no", 1))}
+	reply = h.sendDocument(group, admin, file, "/import")
+	wantText(t, reply.Params["text"], "Games to import: 1\nAnswers: 2", "New players: Ana, Zed")
+	if games, _ := h.games.ListGamesByChatID(context.Background(), group.ID); len(games) != 0 {
+		t.Fatalf("games = %+v after checking the file, want none", games)
+	}
+
+	reply = h.sendDocument(group, admin, file, "/import@sunday_league_bot confirm")
+	wantText(t, reply.Params["text"], "Games imported: 1\nAnswers: 2")
+	games, _ := h.games.ListGamesByChatID(context.Background(), group.ID)
+	if len(games) != 1 || games[0].Opponent != "Rovers" || games[0].Score != "3-1" {
+		t.Fatalf("games = %+v, want the game against Rovers", games)
+	}
+	players, _ := h.games.GetGamePlayers(context.Background(), games[0].Id)
+	if len(players) != 2 {
+		t.Errorf("players = %+v, want Ana and Zed", players)
+	}
+
+	reply = h.sendDocument(group, admin, file, "/import confirm")
+	wantText(t, reply.Params["text"], "Line 2: the chat already has a game at this kickoff")
+}
+
+func TestImportErrors(t *testing.T) {
+	h := newHarness(t)
+	file := telegramtest.File{Name: "history.csv", Content: []byte("Kickoff,Notes\n2023-03-05,rainy\n")}
+
+	reply := h.send(group, admin, "/import")
+	wantText(t, reply.Params["text"], "with /import as its caption")
+
+	reply = h.sendDocument(group, player, file, "/import")
+	wantText(t, reply.Params["text"], "Only admins")
+
+	reply = h.sendDocument(group, admin, file, "/import now")
+	wantText(t, reply.Params["text"], "with /import as its caption")
+
+	reply = h.sendDocument(group, admin, file, "/import")
+	wantText(t, reply.Params["text"], `The file has a "Notes" column`)
+}
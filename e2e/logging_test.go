@@ -0,0 +1,81 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logBuffer collects the lines logged by the bot, which logs from the chat
+// queue goroutines.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// find waits for a JSON record with the message msg and the given command.
+func (b *logBuffer) find(t *testing.T, msg, command string) map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(replyTimeout)
+	for {
+		b.mu.Lock()
+		lines := strings.Split(b.buf.String(), "\n")
+		b.mu.Unlock()
+		for _, line := range lines {
+			var record map[string]interface{}
+			if json.Unmarshal([]byte(line), &record) != nil {
+				continue
+			}
+			if record["msg"] == msg && record["command"] == command {
+				return record
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no %q record for %s in:\n%s", msg, command, strings.Join(lines, "\n"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLogsCarryUpdateContext(t *testing.T) {
+	logs := &logBuffer{}
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	h := newHarness(t)
+	h.send(group, admin, "/new 2099-01-04 11:00")
+	h.send(group, player, "/in")
+
+	// The service logs through the logger the bot put in the context.
+	created := logs.find(t, "Game created", "/new")
+	handled := logs.find(t, "Handled command", "/in")
+
+	tests := []struct {
+		record map[string]interface{}
+		key    string
+		want   float64
+	}{
+		{created, "update_id", 1},
+		{created, "chat_id", float64(group.ID)},
+		{created, "user_id", float64(admin.ID)},
+		{handled, "update_id", 2},
+		{handled, "chat_id", float64(group.ID)},
+		{handled, "user_id", float64(player.ID)},
+	}
+	for _, tt := range tests {
+		if got := tt.record[tt.key]; got != tt.want {
+			t.Errorf("%s of %q = %v, want %v", tt.key, tt.record["msg"], got, tt.want)
+		}
+	}
+}
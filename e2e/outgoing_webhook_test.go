@@ -0,0 +1,94 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"tg-sunday-league/hooks"
+	"tg-sunday-league/services"
+	"time"
+)
+
+func TestOutgoingWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var received []services.WebhookPayload
+	var signatures []string
+	receiver := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload services.WebhookPayload
+		json.Unmarshal(body, &payload)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, payload)
+		signatures = append(signatures, r.Header.Get(hooks.HEADER_SIGNATURE)+" "+r.Header.Get(hooks.HEADER_TIMESTAMP)+" "+string(body))
+	}))
+	t.Cleanup(receiver.Close)
+
+	h := newHarness(t)
+	url := receiver.URL + "/hooks/s3cret-path"
+
+	reply := h.send(group, player, "/webhook add "+url)
+	wantText(t, reply.Params["text"], "Only admins")
+
+	sent := len(h.requests("sendMessage"))
+	reply = h.send(group, admin, "/webhook add "+url)
+	if reply.Params["chat_id"] != strconv.FormatInt(admin.ID, 10) {
+		t.Fatalf("secret sent to chat %s, want the admin's private chat", reply.Params["chat_id"])
+	}
+	wantText(t, reply.Params["text"], "Events of the games of Sunday League are now POSTed as JSON to:\n"+url)
+	lines := strings.Split(reply.Params["text"], "\n")
+	secret := lines[len(lines)-1]
+	replies, err := h.server.WaitForRequests(sent+2, replyTimeout, "sendMessage")
+	if err != nil {
+		t.Fatalf("no reply in the group: %v", err)
+	}
+	wantText(t, replies[sent+1].Params["text"], "Webhook added")
+
+	reply = h.send(group, admin, "/webhook")
+	wantText(t, reply.Params["text"], "Webhooks of this group:\n- https://127.0.0.1:")
+	if strings.Contains(reply.Params["text"], "s3cret-path") {
+		t.Errorf("list shows the path of the URL: %q", reply.Params["text"])
+	}
+
+	h.send(group, admin, "/new 2099-01-04 11:00, Kallang, Rovers")
+	h.send(group, player, "/in")
+	dispatcher := &hooks.Dispatcher{Webhooks: h.webhooks, Client: receiver.Client()}
+	if delivered := dispatcher.Deliver(context.Background(), time.Now()); delivered != 2 {
+		t.Fatalf("Deliver = %d, want the game created and Bea joining", delivered)
+	}
+	mu.Lock()
+	if len(received) != 2 || received[0].Type != services.EVENT_GAME_CREATED || received[0].Game.Opponent != "Rovers" ||
+		received[1].Type != services.EVENT_PLAYER_JOINED || received[1].Player == nil || received[1].Player.Name != "Bea" {
+		t.Errorf("received = %+v, want the game created then Bea joining", received)
+	}
+	for _, signed := range signatures {
+		parts := strings.SplitN(signed, " ", 3)
+		timestamp, _ := strconv.ParseInt(parts[1], 10, 64)
+		if parts[0] != hooks.Sign(secret, timestamp, []byte(parts[2])) {
+			t.Errorf("signature %q does not match the secret sent to the admin", parts[0])
+		}
+	}
+	mu.Unlock()
+
+	reply = h.send(group, admin, "/webhook remove "+url)
+	wantText(t, reply.Params["text"], "Webhook removed")
+	h.send(group, player, "/out")
+	if delivered := dispatcher.Deliver(context.Background(), time.Now()); delivered != 0 {
+		t.Errorf("Deliver after removing the webhook = %d, want 0", delivered)
+	}
+}
+
+func TestOutgoingWebhookRefusesPlainHTTP(t *testing.T) {
+	h := newHarness(t)
+
+	reply := h.send(group, admin, "/webhook add http://example.com/hook")
+	wantText(t, reply.Params["text"], `"http://example.com/hook" is not an https URL`)
+	reply = h.send(group, admin, "/webhook delete http://example.com/hook")
+	wantText(t, reply.Params["text"], "How to use: /webhook add")
+}
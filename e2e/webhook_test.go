@@ -0,0 +1,118 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tg-sunday-league/bot"
+	"tg-sunday-league/repositories"
+	"tg-sunday-league/services"
+	"tg-sunday-league/telegramtest"
+	"time"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func TestWebhookMode(t *testing.T) {
+	poller := &bot.WebhookPoller{PublicURL: "https://bot.example.com/telegram", SecretToken: "s3cret"}
+	h := newHarnessWithPoller(t, poller)
+	hook := httptest.NewServer(poller)
+	defer hook.Close()
+
+	registered, err := h.server.WaitForRequests(1, replyTimeout, "setWebhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params := registered[0].Params; params["url"] != poller.PublicURL || params["secret_token"] != "s3cret" {
+		t.Errorf("setWebhook params = %v", params)
+	}
+
+	update, _ := json.Marshal(telebot.Update{ID: 1, Message: &telebot.Message{ID: 1, Sender: player, Chat: private, Text: "/help"}})
+	post := func(secret string) int {
+		req, _ := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(update))
+		if secret != "" {
+			req.Header.Set(bot.SECRET_TOKEN_HEADER, secret)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post update: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, secret := range []string{"", "wrong"} {
+		if status := post(secret); status != http.StatusUnauthorized {
+			t.Errorf("update with secret %q answered %d, want %d", secret, status, http.StatusUnauthorized)
+		}
+	}
+	if status := post("s3cret"); status != http.StatusOK {
+		t.Fatalf("update with secret answered %d, want %d", status, http.StatusOK)
+	}
+
+	replies, err := h.server.WaitForRequests(1, replyTimeout, "sendMessage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantText(t, replies[0].Params["text"], "Bot Commands:")
+	if len(replies) != 1 {
+		t.Errorf("bot answered %d times, rejected updates must not reach it", len(replies))
+	}
+}
+
+// A bot in webhook mode that cannot get updates stops with the reason, so
+// the process exits instead of running on and looking ready.
+func TestWebhookModeFailsToStart(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	tests := []struct {
+		name   string
+		reject string
+		listen string
+		want   string
+	}{
+		{"TelegramRefusesWebhook", "Bad Request: bad webhook: HTTPS url must be provided for webhook", "", "set webhook: telegram unknown: Bad Request: bad webhook: HTTPS url must be provided for webhook"},
+		{"ListenerCannotBind", "", busy.Addr().String(), "webhook listener: listen tcp " + busy.Addr().String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := telegramtest.NewServer()
+			defer server.Close()
+			if tt.reject != "" {
+				server.Reject("setWebhook", tt.reject)
+			}
+			poller := &bot.WebhookPoller{Listen: tt.listen, PublicURL: "https://bot.example.com/telegram", SecretToken: "s3cret"}
+			settings := &services.SettingsService{SettingsRepository: repositories.NewMemorySettingsRepository()}
+			games := &services.GameService{GameRepository: repositories.NewMemoryGameRepository(), SettingsService: settings}
+			b, err := bot.NewBot("123:test", server.URL, poller, games, settings, &bot.MessageFormatter{})
+			if err != nil {
+				t.Fatalf("NewBot: %v", err)
+			}
+
+			started := make(chan error, 1)
+			go func() { started <- b.Start() }()
+			select {
+			case err = <-started:
+			case <-time.After(replyTimeout):
+				b.Shutdown(context.Background())
+				t.Fatal("Start kept running without receiving updates")
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !errors.Is(err, poller.Err()) {
+				t.Errorf("Start = %v, want an error containing %q", err, tt.want)
+			}
+			if err := b.Shutdown(context.Background()); err != nil {
+				t.Errorf("Shutdown after a failed start: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package export writes the games of a chat, with who played and paid, as
+// files for spreadsheets.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File formats Write can produce, named by their extension.
+const (
+	FORMAT_CSV  = "csv"
+	FORMAT_XLSX = "xlsx"
+)
+
+var FORMATS = []string{FORMAT_CSV, FORMAT_XLSX}
+
+// KICKOFF_LAYOUT is how CSV files write kickoffs, in the chat's time zone.
+const KICKOFF_LAYOUT = "2006-01-02 15:04"
+
+// Write writes report to w in format, with times in the chat's time zone.
+func Write(w io.Writer, format string, report *services.GamesReport, settings *models.ChatSettings) error {
+	switch format {
+	case FORMAT_CSV:
+		return writeCSV(w, report, settings)
+	case FORMAT_XLSX:
+		return writeXLSX(w, report, settings)
+	}
+	return fmt.Errorf("unknown export format %q, expected one of %s", format, strings.Join(FORMATS, ", "))
+}
+
+// FileName names the export of report in format after the kickoff days of
+// its first and last games.
+func FileName(report *services.GamesReport, format string, settings *models.ChatSettings) string {
+	first := report.Games[0].Date.In(settings.Timezone).Format(time.DateOnly)
+	last := report.Games[len(report.Games)-1].Date.In(settings.Timezone).Format(time.DateOnly)
+	return fmt.Sprintf("games-%s-%s.%s", first, last, format)
+}
+
+// GameStatus is cancelled, played or upcoming at now.
+func GameStatus(game *models.Game, now time.Time) string {
+	switch {
+	case game.Cancelled:
+		return "cancelled"
+	case game.Date.Before(now):
+		return "played"
+	}
+	return "upcoming"
+}
+
+// playersByGame groups the attendance of report by game, keeping its order.
+func playersByGame(report *services.GamesReport) map[uuid.UUID][]models.User {
+	players := make(map[uuid.UUID][]models.User, len(report.Games))
+	for _, a := range report.Attendance {
+		players[a.GameId] = append(players[a.GameId], a.Player)
+	}
+	return players
+}
+
+// counts returns how many players attended and how many of them paid.
+func counts(players []models.User) (attending int, paid int) {
+	for _, player := range players {
+		if player.Status != string(services.ATTENDING) {
+			continue
+		}
+		attending++
+		if player.HasPaid {
+			paid++
+		}
+	}
+	return attending, paid
+}
+
+func attendance(player models.User) string {
+	return strings.ToLower(player.Status)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func priceHeader(settings *models.ChatSettings) string {
+	if settings.Currency == "" {
+		return "Price"
+	}
+	return "Price (" + settings.Currency + ")"
+}
+
+// writeCSV writes a row per player of each game, and a row with no player
+// for games nobody answered.
+func writeCSV(w io.Writer, report *services.GamesReport, settings *models.ChatSettings) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"Game ID", "Kickoff", "Opponent", "Location", priceHeader(settings), "Score", "Game status", "Player", "Telegram ID", "Attendance", "Paid"})
+
+	players := playersByGame(report)
+	for _, game := range report.Games {
+		gameColumns := []string{
+			game.Id.String(),
+			game.Date.In(settings.Timezone).Format(KICKOFF_LAYOUT),
+			game.Opponent,
+			game.Location,
+			strconv.FormatFloat(game.Price, 'f', 2, 64),
+			game.Score,
+			GameStatus(&game, report.GeneratedAt),
+		}
+		if len(players[game.Id]) == 0 {
+			out.Write(append(gameColumns, "", "", "", ""))
+			continue
+		}
+		for _, player := range players[game.Id] {
+			out.Write(append(gameColumns[:len(gameColumns):len(gameColumns)],
+				player.Name, strconv.FormatInt(player.UserId, 10), attendance(player), yesNo(player.HasPaid)))
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
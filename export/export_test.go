@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+var singapore, _ = time.LoadLocation("Asia/Singapore")
+
+func newReport() (*services.GamesReport, *models.ChatSettings) {
+	played := models.Game{Id: uuid.MustParse("11111111-1111-1111-1111-111111111111"), ChatId: -1001, Price: 12, Date: time.Date(2030, time.March, 10, 3, 0, 0, 0, time.UTC), Location: "Kallang", Opponent: "Rovers", Score: "3-1"}
+	cancelled := models.Game{Id: uuid.MustParse("22222222-2222-2222-2222-222222222222"), ChatId: -1001, Price: 10, Date: time.Date(2030, time.March, 17, 3, 0, 0, 0, time.UTC), Location: "Bishan", Opponent: "United", Cancelled: true}
+	report := &services.GamesReport{
+		ChatId:      -1001,
+		GeneratedAt: time.Date(2030, time.March, 12, 0, 0, 0, 0, time.UTC),
+		Games:       []models.Game{played, cancelled},
+		Attendance: []models.Attendance{
+			{GameId: played.Id, Player: models.User{UserId: 10, Name: "Ana", Status: "ATTENDING", HasPaid: true}},
+			{GameId: played.Id, Player: models.User{UserId: 20, Name: "Bea, Jr", Status: "ATTENDING"}},
+			{GameId: played.Id, Player: models.User{UserId: 30, Name: "Caio", Status: "OUT"}},
+		},
+	}
+	return report, &models.ChatSettings{Currency: "SGD", Timezone: singapore}
+}
+
+func TestCSV(t *testing.T) {
+	report, settings := newReport()
+	var out bytes.Buffer
+	if err := Write(&out, FORMAT_CSV, report, settings); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"Game ID,Kickoff,Opponent,Location,Price (SGD),Score,Game status,Player,Telegram ID,Attendance,Paid",
+		"11111111-1111-1111-1111-111111111111,2030-03-10 11:00,Rovers,Kallang,12.00,3-1,played,Ana,10,attending,yes",
+		`11111111-1111-1111-1111-111111111111,2030-03-10 11:00,Rovers,Kallang,12.00,3-1,played,"Bea, Jr",20,attending,no`,
+		"11111111-1111-1111-1111-111111111111,2030-03-10 11:00,Rovers,Kallang,12.00,3-1,played,Caio,30,out,no",
+		"22222222-2222-2222-2222-222222222222,2030-03-17 11:00,United,Bishan,10.00,,cancelled,,,,",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	report, settings := newReport()
+	var out bytes.Buffer
+	if err := Write(&out, FORMAT_XLSX, report, settings); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&out)
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); len(sheets) != 2 || sheets[0] != SHEET_GAMES || sheets[1] != SHEET_ATTENDANCE {
+		t.Fatalf("sheets = %v, want %s and %s", sheets, SHEET_GAMES, SHEET_ATTENDANCE)
+	}
+
+	games, err := f.GetRows(SHEET_GAMES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGames := [][]string{
+		{"Game ID", "Kickoff", "Opponent", "Location", "Price (SGD)", "Score", "Status", "Attending", "Paid", "Collected"},
+		{"11111111-1111-1111-1111-111111111111", "2030-03-10 11:00", "Rovers", "Kallang", "12.00", "3-1", "played", "2", "1", "12.00"},
+		{"22222222-2222-2222-2222-222222222222", "2030-03-17 11:00", "United", "Bishan", "10.00", "", "cancelled", "0", "0", "0.00"},
+	}
+	for i, row := range wantGames {
+		if i >= len(games) || strings.Join(games[i], "|") != strings.Join(row, "|") {
+			t.Errorf("%s row %d = %v, want %v", SHEET_GAMES, i+1, games, row)
+		}
+	}
+
+	rows, err := f.GetRows(SHEET_ATTENDANCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || strings.Join(rows[2], "|") != "11111111-1111-1111-1111-111111111111|2030-03-10 11:00|Rovers|Bea, Jr|20|attending|no" {
+		t.Errorf("%s rows = %v, want the header and a row per player", SHEET_ATTENDANCE, rows)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	report, settings := newReport()
+	if got := FileName(report, FORMAT_XLSX, settings); got != "games-2030-03-10-2030-03-17.xlsx" {
+		t.Errorf("FileName = %q", got)
+	}
+}
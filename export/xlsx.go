@@ -0,0 +1,102 @@
+package export
+
+import (
+	"io"
+	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheets of the XLSX export.
+const (
+	SHEET_GAMES      = "Games"
+	SHEET_ATTENDANCE = "Attendance"
+)
+
+// writeXLSX writes a sheet with a row per game, with how many attended and
+// paid, and a sheet with a row per player of each game. Kickoffs and prices
+// are written as dates and numbers, so they sort and add up.
+func writeXLSX(w io.Writer, report *services.GamesReport, settings *models.ChatSettings) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	kickoffFormat := "yyyy-mm-dd hh:mm"
+	kickoffStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &kickoffFormat})
+	if err != nil {
+		return err
+	}
+	// Built-in format 2 is 0.00.
+	priceStyle, err := f.NewStyle(&excelize.Style{NumFmt: 2})
+	if err != nil {
+		return err
+	}
+
+	if err := f.SetSheetName("Sheet1", SHEET_GAMES); err != nil {
+		return err
+	}
+	if _, err := f.NewSheet(SHEET_ATTENDANCE); err != nil {
+		return err
+	}
+
+	players := playersByGame(report)
+	games := [][]interface{}{{"Game ID", "Kickoff", "Opponent", "Location", priceHeader(settings), "Score", "Status", "Attending", "Paid", "Collected"}}
+	var rows [][]interface{}
+	rows = append(rows, []interface{}{"Game ID", "Kickoff", "Opponent", "Player", "Telegram ID", "Attendance", "Paid"})
+	for _, game := range report.Games {
+		kickoff := wallClock(game.Date.In(settings.Timezone))
+		attending, paid := counts(players[game.Id])
+		games = append(games, []interface{}{
+			game.Id.String(), kickoff, game.Opponent, game.Location, game.Price, game.Score,
+			GameStatus(&game, report.GeneratedAt), attending, paid, float64(paid) * game.Price,
+		})
+		for _, player := range players[game.Id] {
+			rows = append(rows, []interface{}{
+				game.Id.String(), kickoff, game.Opponent, player.Name, player.UserId, attendance(player), yesNo(player.HasPaid),
+			})
+		}
+	}
+
+	sheets := []struct {
+		name      string
+		rows      [][]interface{}
+		lastCol   string
+		priceCols []string
+	}{
+		{SHEET_GAMES, games, "J", []string{"E", "J"}},
+		{SHEET_ATTENDANCE, rows, "G", nil},
+	}
+	for _, sheet := range sheets {
+		for i, row := range sheet.rows {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			if err := f.SetSheetRow(sheet.name, cell, &row); err != nil {
+				return err
+			}
+		}
+		// Column styles apply to the header too, so it is styled last.
+		f.SetColStyle(sheet.name, "B", kickoffStyle)
+		for _, col := range sheet.priceCols {
+			f.SetColStyle(sheet.name, col, priceStyle)
+		}
+		f.SetCellStyle(sheet.name, "A1", sheet.lastCol+"1", headerStyle)
+		f.SetColWidth(sheet.name, "A", "A", 38)
+		f.SetColWidth(sheet.name, "B", "B", 17)
+		f.SetColWidth(sheet.name, "C", "D", 20)
+		// Keep the header in view while scrolling.
+		f.SetPanes(sheet.name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	}
+	f.SetActiveSheet(0)
+
+	return f.Write(w)
+}
+
+// wallClock returns the time shown by t's clock, as spreadsheets have no
+// time zones.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
@@ -0,0 +1,262 @@
+// Package hooks delivers the game events queued in the webhook outbox to the
+// URLs chats registered, signed with the secret of each webhook.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"tg-sunday-league/models"
+	"tg-sunday-league/monitoring"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+// DISPATCH_INTERVAL is how often the outbox is checked for events due.
+const DISPATCH_INTERVAL = 10 * time.Second
+
+// BATCH_SIZE is how many events are delivered at most per check.
+const BATCH_SIZE = 50
+
+// MAX_ATTEMPTS is how many times an event is sent before it is given up on.
+const MAX_ATTEMPTS = 8
+
+// RETRY_DELAY is the wait before the first retry. It doubles with every
+// attempt, so an event is given up on about two hours after it was queued.
+const RETRY_DELAY = time.Minute
+
+// DELIVERY_TIMEOUT bounds a single attempt, from connecting to the response.
+const DELIVERY_TIMEOUT = 10 * time.Second
+
+// KEEP_FINISHED is how long delivered events and those given up on stay in
+// the outbox, to look into what a webhook was sent.
+const KEEP_FINISHED = 7 * 24 * time.Hour
+
+// MAX_ERROR is the length past which the error of an attempt is cut.
+const MAX_ERROR = 200
+
+// Headers sent with every event. The delivery ID is the event's, the same on
+// every attempt, so receivers can tell a retry from a new event.
+const (
+	HEADER_EVENT     = "X-Sunday-League-Event"
+	HEADER_DELIVERY  = "X-Sunday-League-Delivery"
+	HEADER_TIMESTAMP = "X-Sunday-League-Timestamp"
+	HEADER_SIGNATURE = "X-Sunday-League-Signature"
+)
+
+// Dispatcher POSTs the events due in the outbox to their webhooks, retrying
+// those that fail.
+type Dispatcher struct {
+	Webhooks repositories.IWebhookRepository
+	// Client sends the events, NewClient's outside of tests.
+	Client *http.Client
+
+	wakeOnce sync.Once
+	wake     chan struct{}
+}
+
+// Sign is the signature sent in HEADER_SIGNATURE: the hex HMAC-SHA256 of the
+// timestamp, a dot and the body, keyed with the webhook's secret. Receivers
+// compute it again to check an event came from the bot, and refuse old
+// timestamps to refuse replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewClient returns a client that only connects to public addresses, so
+// webhooks cannot reach the bot's own host or network.
+func NewClient() *http.Client {
+	dialer := &net.Dialer{Timeout: DELIVERY_TIMEOUT, Control: refusePrivate}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		Timeout:   DELIVERY_TIMEOUT,
+		// A redirect would be followed without the signature being checked
+		// against the new URL, so it counts as a failure.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+var errPrivateAddress = errors.New("webhook resolves to a private address")
+
+// refusedPrefixes are the ranges that reach the bot's own networks without
+// being private or local to netip: carrier-grade NAT, "this network", and
+// NAT64, which wraps any IPv4 address, private ones included.
+var refusedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// refusePrivate is checked on the address actually dialled, after DNS, so a
+// name resolving to a private address is refused too.
+func refusePrivate(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() || addr.IsInterfaceLocalMulticast() {
+		return errPrivateAddress
+	}
+	for _, prefix := range refusedPrefixes {
+		if prefix.Contains(addr) {
+			return errPrivateAddress
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) wakeup() chan struct{} {
+	d.wakeOnce.Do(func() { d.wake = make(chan struct{}, 1) })
+	return d.wake
+}
+
+// Wake makes Run check the outbox now rather than at the next interval, as
+// when events were just queued. It never blocks, and wakes Run once however
+// many times it is called in between.
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wakeup() <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers the events due every interval, and whenever woken, until ctx
+// ends.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.Deliver(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wakeup():
+		}
+	}
+}
+
+// Deliver sends the events due at now, and returns how many were delivered.
+// Delivered events and those given up on are dropped after KEEP_FINISHED.
+func (d *Dispatcher) Deliver(ctx context.Context, now time.Time) int {
+	if err := d.Webhooks.DeleteFinishedMessages(ctx, now.Add(-KEEP_FINISHED)); err != nil && ctx.Err() == nil {
+		slog.Warn("Could not delete finished webhook events", "error", err)
+	}
+	messages, err := d.Webhooks.ListDueMessages(ctx, now, BATCH_SIZE)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Could not list webhook events due", "error", err)
+		}
+		return 0
+	}
+
+	delivered := 0
+	for _, message := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+		if d.deliver(ctx, &message, now) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, message *models.OutboxMessage, now time.Time) bool {
+	// The URL may hold a secret of its own, as Discord's do, so only its host
+	// is logged.
+	logger := slog.With("webhook_id", message.WebhookId, "event_id", message.EventId, "event", message.EventType, "host", host(message.Url))
+	attempts := message.Attempts + 1
+	sendErr := d.send(ctx, message, now)
+	if sendErr == nil {
+		monitoring.WebhookDeliveriesTotal.WithLabelValues("delivered").Inc()
+		if err := d.Webhooks.MarkMessageDelivered(ctx, message.Id, now); err != nil {
+			// The event is sent again, which receivers tell by its delivery ID.
+			logger.Error("Could not mark webhook event delivered", "error", err)
+		}
+		logger.Debug("Webhook event delivered", "attempts", attempts)
+		return true
+	}
+
+	lastError := sendErr.Error()
+	if len(lastError) > MAX_ERROR {
+		lastError = lastError[:MAX_ERROR]
+	}
+	if attempts >= MAX_ATTEMPTS {
+		monitoring.WebhookDeliveriesTotal.WithLabelValues("failed").Inc()
+		logger.Warn("Gave up delivering webhook event", "attempts", attempts, "error", sendErr)
+		if err := d.Webhooks.MarkMessageFailed(ctx, message.Id, attempts, lastError, now); err != nil {
+			logger.Error("Could not mark webhook event failed", "error", err)
+		}
+		return false
+	}
+	monitoring.WebhookDeliveriesTotal.WithLabelValues("retried").Inc()
+	next := now.Add(RETRY_DELAY << (attempts - 1))
+	logger.Info("Could not deliver webhook event, will retry", "attempts", attempts, "next_attempt_at", next, "error", sendErr)
+	if err := d.Webhooks.RetryMessage(ctx, message.Id, attempts, next, lastError); err != nil {
+		logger.Error("Could not schedule webhook event retry", "error", err)
+	}
+	return false
+}
+
+func (d *Dispatcher) send(ctx context.Context, message *models.OutboxMessage, now time.Time) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, message.Url, bytes.NewReader(message.Payload))
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+	timestamp := now.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "tg-sunday-league-webhooks")
+	request.Header.Set(HEADER_EVENT, message.EventType)
+	request.Header.Set(HEADER_DELIVERY, message.EventId.String())
+	request.Header.Set(HEADER_TIMESTAMP, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HEADER_SIGNATURE, Sign(message.Secret, timestamp, message.Payload))
+
+	response, err := d.Client.Do(request)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// Its message repeats the URL, which is not to be logged.
+		return urlErr.Err
+	}
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	// The body is read so the connection can be reused, but no more than
+	// what an acknowledgement takes.
+	io.Copy(io.Discard, io.LimitReader(response.Body, 4<<10))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("status %d", response.StatusCode)
+	}
+	return nil
+}
+
+func host(rawUrl string) string {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
@@ -0,0 +1,203 @@
+package hooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var now = time.Date(2030, time.March, 10, 11, 0, 0, 0, time.UTC)
+
+// receiver records the events POSTed to it, answering with status.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+}
+
+// newDispatcher returns a dispatcher with an event queued for a webhook to a
+// receiver answering status.
+func newDispatcher(t *testing.T, status int) (*Dispatcher, *receiver, *repositories.MemoryWebhookRepository, *models.OutboxMessage) {
+	t.Helper()
+	rec := &receiver{status: status}
+	server := httptest.NewTLSServer(rec)
+	t.Cleanup(server.Close)
+
+	webhooks := repositories.NewMemoryWebhookRepository()
+	webhook := &models.Webhook{Id: uuid.New(), ChatId: -1001, Url: server.URL + "/hook", Secret: "s3cret"}
+	if err := webhooks.InsertWebhook(context.Background(), webhook); err != nil {
+		t.Fatalf("InsertWebhook: %v", err)
+	}
+	message := &models.OutboxMessage{Id: uuid.New(), WebhookId: webhook.Id, EventId: uuid.New(), EventType: "game.created", Payload: []byte(`{"type":"game.created"}`), NextAttemptAt: now}
+	if err := webhooks.InsertOutboxMessage(context.Background(), message); err != nil {
+		t.Fatalf("InsertOutboxMessage: %v", err)
+	}
+	return &Dispatcher{Webhooks: webhooks, Client: server.Client()}, rec, webhooks, message
+}
+
+func due(t *testing.T, webhooks repositories.IWebhookRepository, at time.Time) []models.OutboxMessage {
+	t.Helper()
+	messages, err := webhooks.ListDueMessages(context.Background(), at, 10)
+	if err != nil {
+		t.Fatalf("ListDueMessages: %v", err)
+	}
+	return messages
+}
+
+func TestDeliverSignsEvents(t *testing.T) {
+	d, rec, webhooks, message := newDispatcher(t, http.StatusNoContent)
+
+	if delivered := d.Deliver(context.Background(), now); delivered != 1 {
+		t.Fatalf("Deliver = %d, want 1", delivered)
+	}
+	if len(rec.requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(rec.requests))
+	}
+	request, body := rec.requests[0], rec.bodies[0]
+	if request.Method != http.MethodPost || request.URL.Path != "/hook" || string(body) != `{"type":"game.created"}` || request.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request = %s %s %q, want the payload POSTed", request.Method, request.URL.Path, body)
+	}
+	if request.Header.Get(HEADER_EVENT) != "game.created" || request.Header.Get(HEADER_DELIVERY) != message.EventId.String() {
+		t.Errorf("headers = %v, want the event and its ID", request.Header)
+	}
+	timestamp := request.Header.Get(HEADER_TIMESTAMP)
+	if timestamp != strconv.FormatInt(now.Unix(), 10) {
+		t.Errorf("timestamp = %q, want %d", timestamp, now.Unix())
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); request.Header.Get(HEADER_SIGNATURE) != want {
+		t.Errorf("signature = %q, want %q", request.Header.Get(HEADER_SIGNATURE), want)
+	}
+
+	if messages := due(t, webhooks, now.Add(time.Hour)); len(messages) != 0 {
+		t.Errorf("due after delivery = %+v, want none", messages)
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	d, rec, webhooks, _ := newDispatcher(t, http.StatusInternalServerError)
+
+	at := now
+	for attempt := 1; attempt < MAX_ATTEMPTS; attempt++ {
+		if delivered := d.Deliver(context.Background(), at); delivered != 0 {
+			t.Fatalf("Deliver = %d, want the event to fail", delivered)
+		}
+		wait := RETRY_DELAY << (attempt - 1)
+		if messages := due(t, webhooks, at.Add(wait-time.Second)); len(messages) != 0 {
+			t.Fatalf("due %v after attempt %d = %+v, want none before the retry", wait-time.Second, attempt, messages)
+		}
+		at = at.Add(wait)
+		messages := due(t, webhooks, at)
+		if len(messages) != 1 || messages[0].Attempts != attempt || messages[0].LastError != "status 500" {
+			t.Fatalf("due %v after attempt %d = %+v, want it retried", wait, attempt, messages)
+		}
+	}
+
+	d.Deliver(context.Background(), at)
+	if len(rec.requests) != MAX_ATTEMPTS {
+		t.Errorf("receiver got %d requests, want %d", len(rec.requests), MAX_ATTEMPTS)
+	}
+	if messages := due(t, webhooks, at.Add(24*time.Hour)); len(messages) != 0 {
+		t.Errorf("due after the last attempt = %+v, want it given up on", messages)
+	}
+}
+
+func TestRunDeliversWhenWoken(t *testing.T) {
+	d, rec, webhooks, message := newDispatcher(t, http.StatusNoContent)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		d.Run(ctx, time.Hour)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	queued := &models.OutboxMessage{Id: uuid.New(), WebhookId: message.WebhookId, EventId: uuid.New(), EventType: "player.joined", Payload: []byte(`{"type":"player.joined"}`), NextAttemptAt: time.Now()}
+	if err := webhooks.InsertOutboxMessage(context.Background(), queued); err != nil {
+		t.Fatalf("InsertOutboxMessage: %v", err)
+	}
+	d.Wake()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec.mu.Lock()
+		received := len(rec.requests)
+		rec.mu.Unlock()
+		if received == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("receiver got %d requests, want the queued event delivered once woken", received)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNewClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback server")
+	}))
+	defer server.Close()
+
+	_, err := NewClient().Post(server.URL, "application/json", nil)
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("Post to %s = %v, want it refused", server.URL, err)
+	}
+}
+
+func TestRefusePrivate(t *testing.T) {
+	tests := []struct {
+		address string
+		refused bool
+	}{
+		{"127.0.0.1:443", true},
+		{"[::1]:443", true},
+		{"10.1.2.3:443", true},
+		{"192.168.1.10:443", true},
+		{"[fd00::1]:443", true},
+		{"169.254.169.254:80", true},
+		{"[::ffff:10.0.0.1]:443", true},
+		{"100.64.0.1:443", true},
+		{"100.127.255.254:443", true},
+		{"0.1.2.3:443", true},
+		{"[64:ff9b::a00:1]:443", true},
+		{"[64:ff9b::7f00:1]:443", true},
+		{"[64:ff9b:1::a00:1]:443", true},
+		{"93.184.216.34:443", false},
+		{"100.128.0.1:443", false},
+		{"[2606:4700::1111]:443", false},
+	}
+	for _, tt := range tests {
+		err := refusePrivate("tcp", tt.address, nil)
+		if refused := errors.Is(err, errPrivateAddress); refused != tt.refused {
+			t.Errorf("refusePrivate(%s) = %v, want refused %v", tt.address, err, tt.refused)
+		}
+	}
+}
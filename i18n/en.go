@@ -0,0 +1,216 @@
+package i18n
+
+var english = catalog{
+	weekdays: [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	messages: map[string]string{
+		"help.title": "Bot Commands:",
+
+		"command.help":    "Show this help message",
+		"command.new":     "Create a new game with the specified kickoff, location, opponent and price.\nHow to use: /new (Kickoff, Location, Opponent, Price)\ni.e: /new (sunday 11am, Marina Bay Sands, Célavi FC, 15)\nKickoff also accepts \"next sat 9:30\", \"tomorrow 19:00\", \"13/10 8pm\" or \"2024-10-10, 11:00\"",
+		"command.cancel":  "Cancel the upcoming game",
+		"command.in":      "Register yourself for the upcoming game",
+		"command.out":     "Mark yourself as absent for the upcoming game",
+		"command.details": "Show the details of the game",
+		"command.paid":    "Mark you as paid for the game",
+		"command.backup":  "Send the owner of the bot a backup of the database in a private chat",
+		"command.export": "Send the games of a period with who played and paid as a spreadsheet (admins only)\n" +
+			"i.e: /export xlsx 2024-01-01 2024-06-30",
+		"command.import": "Import past games from a CSV file, or the league's fixtures from an iCalendar (.ics) file, sent with /import as its caption, then with /import confirm to write them (admins only)",
+		"command.calendar": "Send the link to subscribe to the games of the chat in a calendar app, and the next game as a calendar file\n" +
+			"Admins can send /calendar reset to replace a link that was shared too widely",
+		"command.api":       "Get a new API token of this group in a private chat, for apps to use its games (admins only)",
+		"command.dashboard": "Get a link in a private chat to sign in to the web dashboard of this group, with its games, payments and leaderboards",
+		"command.webhook": "Send the events of this group's games to other apps, signed (admins only)\n" +
+			"i.e: /webhook add https://example.com/hook, /webhook remove https://example.com/hook, or /webhook to list them",
+		"command.timezone": "Show or set the time zone of the chat (admins only to set)\n" +
+			"i.e: /timezone Asia/Singapore",
+		"command.settings": "Show or change the chat settings (admins only to change)\n" +
+			"i.e: /settings squad_size 14",
+
+		"game.details":   "Game on %s\nLocation: %s\nOpponent: %s%s\nPlayers%s: %s\nAbsentees: %s",
+		"game.price":     "\nPrice: %s",
+		"game.cancelled": "Game on %s has been cancelled.",
+
+		"new.usage":  "Invalid format. Please use:\n/new (Kickoff, Optional[Location], Optional[Opponent], Optional[Price])\ni.e: /new sunday 11am, Kallang, Célavi",
+		"group_only": "This bot is intended to work for group chat only. /help for more info",
+
+		"backup.sent": "The backup was sent to you in a private chat.",
+
+		"export.usage":   "Invalid format. Please use:\n/export [csv|xlsx] [from] [to]\nwith days written as YYYY-MM-DD, i.e: /export xlsx 2024-01-01 2024-06-30",
+		"export.caption": "Games: %d",
+
+		"import.usage":       "Send the CSV file of the games as a document with /import as its caption to check it, then send it again with /import confirm to import it. It needs a kickoff column, and may have opponent, location, price, score, player, telegram id, attendance and paid ones, with a line per player of each game. A league's fixture list can be sent as an iCalendar (.ics) file the same way, to open each fixture for answers in turn.",
+		"import.preview":     "Games to import: %d\nAnswers: %d\nSend the file again with /import confirm as its caption to import them.",
+		"import.done":        "Games imported: %d\nAnswers: %d",
+		"import.new_players": "New players: %s",
+		"import.conflicts":   "Problems to fix before importing: %d",
+		"import.line":        "Line %d: %s",
+		"import.more":        "…and %d more.",
+
+		"fixtures.preview":     "Fixtures to schedule: %d",
+		"fixtures.confirm":     "Send the file again with /import confirm as its caption to schedule them.",
+		"fixtures.done":        "Fixtures scheduled: %d",
+		"fixtures.moved":       "Fixtures changed: %d",
+		"fixtures.removed":     "Fixtures cancelled: %d",
+		"fixtures.rescheduled": "Upcoming game rescheduled:",
+		"fixtures.cancelled":   "Upcoming game cancelled:",
+		"fixtures.past":        "Fixtures left out as already played: %d",
+		"fixtures.undated":     "Events left out without a kickoff time: %d",
+		"fixtures.opened":      "Answers are open for the next fixture.\n\n%s",
+
+		"calendar.name":             "Football games",
+		"calendar.summary":          "Football game",
+		"calendar.summary_opponent": "Football vs %s",
+		"calendar.cancelled":        "Cancelled: %s",
+		"calendar.price":            "Price: %s",
+		"calendar.score":            "Score: %s",
+		"calendar.link":             "Subscribe to the games of this chat in your calendar app with this link. Keep it to the group, anyone with it sees the games:\n%s",
+		"calendar.reset":            "The previous calendar link no longer works. Subscribe again with this one:\n%s",
+		"calendar.next_game":        "Next game",
+
+		"api.token": "API token of %s:\n%s\n\nSend it as a bearer token to %s, described by openapi.yaml there. Anyone with it can manage the group's games, so keep it to yourself. It is not shown again, and sending /api in the group replaces it.",
+		"api.sent":  "A new API token was sent to you in a private chat. The previous one, if any, no longer works.",
+
+		"dashboard.link":           "Sign in to the dashboard of %s with this link. It works once, within 15 minutes:\n%s",
+		"dashboard.sent":           "The link to the dashboard was sent to you in a private chat.",
+		"dashboard.title":          "Football dashboard",
+		"dashboard.login":          "You were sent here from Telegram to see the dashboard of your group.",
+		"dashboard.sign_in":        "Sign in",
+		"dashboard.sign_out":       "Sign out",
+		"dashboard.how_to_sign_in": "Send /dashboard in your group to get a link to sign in.",
+		"dashboard.next_game":      "Next game",
+		"dashboard.cancelled":      "Cancelled",
+		"dashboard.vs":             "vs %s",
+		"dashboard.players":        "Players (%d)",
+		"dashboard.absentees":      "Absentees (%d)",
+		"dashboard.paid":           "Paid",
+		"dashboard.unpaid":         "Not paid",
+		"dashboard.nobody":         "Nobody yet.",
+		"dashboard.no_game":        "No game is scheduled.",
+		"dashboard.history":        "Games played",
+		"dashboard.record":         "%d played: %d won, %d drawn, %d lost",
+		"dashboard.date":           "Date",
+		"dashboard.opponent":       "Opponent",
+		"dashboard.location":       "Location",
+		"dashboard.score":          "Score",
+		"dashboard.no_history":     "No games played yet.",
+		"dashboard.appearances":    "Most games played",
+		"dashboard.unpaid_games":   "Games played without paying",
+		"dashboard.all_paid":       "Everyone paid.",
+
+		"webhook.secret":  "Events of the games of %s are now POSTed as JSON to:\n%s\n\nEach one is signed with an X-Sunday-League-Signature header, sha256= and the HMAC-SHA256 of the X-Sunday-League-Timestamp header, a dot and the body, keyed with this secret:\n%s",
+		"webhook.added":   "Webhook added. Its signing secret was sent to you in a private chat.",
+		"webhook.removed": "Webhook removed. The events still waiting for it were dropped.",
+		"webhook.list":    "Webhooks of this group:\n%s",
+		"webhook.none":    "This group has no webhooks. Add one with /webhook add followed by an https URL.",
+
+		"timezone.current": "Times in this chat are shown in %s (currently %s).",
+
+		"settings.title":                "Chat settings:",
+		"settings.help":                 "Admins can change a setting with the buttons below or with /settings <name> <value>, i.e: /settings currency SGD",
+		"settings.hint":                 "Send /settings %s <value> to change it.",
+		"error.settings_admins_only":    "Only admins of the group can change settings.",
+		"settings.button.squad_down":    "Squad size −",
+		"settings.button.squad_up":      "Squad size +",
+		"settings.button.language":      "Language: %s",
+		"settings.button.game_creators": "Games by: %s",
+
+		"setting.currency":      "Currency",
+		"setting.default_price": "Default price",
+		"setting.squad_size":    "Squad size",
+		"setting.timezone":      "Time zone",
+		"setting.language":      "Language",
+		"setting.reminders":     "Reminders",
+		"setting.game_creators": "Games created by",
+
+		"error.unknown":                 "Something went wrong, please try again.",
+		"error.user_retrieve":           "Could not retrieve user, please try again.",
+		"error.user_create":             "Could not create user, please try again.",
+		"error.latest_game":             "Could not find the latest game, please try again.",
+		"error.game_already_scheduled":  "There is already a game scheduled on %s against %s",
+		"error.kickoff_invalid":         "Could not understand %q. Try something like \"sunday 11am\" or \"2024-10-10 11:00\".",
+		"error.kickoff_missing_time":    "%q was read as %s %s but has no kickoff time. Add one like 11am or 19:30.",
+		"error.kickoff_past":            "%q was read as %s, which is in the past.",
+		"error.invalid_price":           "Invalid price format. Please provide a valid number.",
+		"error.game_create":             "Could not create game, please try again.",
+		"error.game_details":            "Could not retrieve game details, please try again.",
+		"error.game_cancel":             "Could not cancel the game, please try again.",
+		"error.game_players":            "Could not retrieve game players, please try again.",
+		"error.no_game":                 "No existing game.",
+		"error.no_upcoming_game":        "No upcoming game.",
+		"error.player_create":           "Could not create player, please try again.",
+		"error.player_register":         "Could not register player, please try again.",
+		"error.player_retrieve":         "Could not find the player, please try again.",
+		"error.player_game_retrieve":    "Could not find the player for the game, please try again.",
+		"error.player_not_registered":   "%s is not registered for the game.",
+		"error.sender_not_registered":   "You are not registered for the game. Use /in first.",
+		"error.payment_update":          "Could not update player payment, please try again.",
+		"error.settings_retrieve":       "Could not retrieve the chat settings, please try again.",
+		"error.settings_save":           "Could not save the setting, please try again.",
+		"error.invalid_currency":        "Invalid currency %q. Please use a three-letter code like SGD or a symbol like €.",
+		"error.invalid_squad_size":      "Invalid squad size. Please provide a number between 0 and %d, 0 meaning no limit.",
+		"error.unknown_timezone":        "Unknown time zone %q. Please use a name like Asia/Singapore or Europe/Madrid.",
+		"error.unsupported_language":    "Unsupported language %q. Please choose one of %s.",
+		"error.invalid_reminder":        "Invalid reminder %q. Please use values like 1d, 2h or 30m.",
+		"error.too_many_reminders":      "Too many reminders. Please give at most %d.",
+		"error.invalid_game_creators":   "Invalid value %q. Please choose %s or %s.",
+		"error.admins_only":             "Only admins of the group can use this command.",
+		"error.unknown_setting":         "Unknown setting %q.",
+		"error.games_list":              "Could not list the games, please try again.",
+		"error.player_not_found":        "No player %s.",
+		"error.player_merge":            "Could not merge the players, please try again.",
+		"error.merge_same_player":       "A player cannot be merged into itself.",
+		"error.owner_only":              "Only the owner of the bot can use this command.",
+		"error.backup_unavailable":      "Backups are only taken of SQLite databases.",
+		"error.backup":                  "Could not back up the database, please try again.",
+		"error.backup_send":             "Could not send you the backup. Start a private chat with the bot and try again.",
+		"error.export":                  "Could not export the games, please try again.",
+		"error.export_range":            "The first day of the period must not be after the last.",
+		"error.no_games_to_export":      "There are no games to export in that period.",
+		"error.import":                  "Could not import the games, please try again.",
+		"error.import_file":             "The file could not be read as CSV.",
+		"error.import_missing_column":   "The file has no %s column.",
+		"error.import_unknown_column":   "The file has a %q column, which cannot be imported. Remove it and try again.",
+		"error.no_games_to_import":      "The file has no games.",
+		"error.import_kickoff":          "the kickoff %q is not written like 2024-10-06 11:00",
+		"error.import_price":            "the price %q is not a positive number",
+		"error.import_score":            "the score %q is not written like 3-1",
+		"error.import_telegram_id":      "the Telegram ID %q is not a number",
+		"error.import_attendance":       "the attendance %q is neither attending nor out",
+		"error.import_paid":             "paid is %q rather than yes or no",
+		"error.import_no_player":        "the line has a Telegram ID, attendance or payment but no player",
+		"error.import_game_mismatch":    "the game at this kickoff on line %d has another opponent, location, price or score",
+		"error.import_future_game":      "the game has not been played yet",
+		"error.import_game_exists":      "the chat already has a game at this kickoff",
+		"error.import_unknown_player":   "nobody called %s has played in this chat, add their Telegram ID to create them",
+		"error.import_ambiguous_player": "%d players are called %s, give the Telegram ID of the right one",
+		"error.import_duplicate_player": "%s is listed twice for the game",
+		"error.token":                   "Could not get the link of the chat, please try again.",
+		"error.unknown_token":           "This link is not valid anymore.",
+		"error.calendar":                "Could not build the calendar, please try again.",
+		"error.calendar_unavailable":    "Calendar links are not available, the bot has no public address configured.",
+		"error.fixtures":                "Could not schedule the fixtures, please try again.",
+		"error.fixtures_file":           "The file could not be read as an iCalendar file.",
+		"error.no_fixtures":             "The file has no fixtures with a kickoff time.",
+		"error.api_unavailable":         "The API is not available, the bot has no public address configured.",
+		"error.api_send":                "Could not send you the API token. Start a private chat with the bot, then send /api again.",
+		"error.api_body":                "The request body is not JSON with the expected fields.",
+		"error.api_field_missing":       "%s is required.",
+		"error.api_status":              "The status %q is neither in nor out.",
+		"error.api_user_id":             "The Telegram ID %q is not a positive number.",
+		"error.session":                 "Could not sign you in, please try again.",
+		"error.login_expired":           "This link to sign in was already used or has expired.",
+		"error.not_signed_in":           "You are not signed in, or your session has expired.",
+		"error.dashboard":               "Could not load the dashboard, please try again.",
+		"error.dashboard_unavailable":   "The dashboard is not available, the bot has no public address configured.",
+		"error.dashboard_send":          "Could not send you the link to the dashboard. Start a private chat with the bot, then send /dashboard again.",
+		"error.webhooks":                "Could not update the webhooks, please try again.",
+		"error.webhook_url":             "%q is not an https URL.",
+		"error.webhook_exists":          "This URL is a webhook of the group already.",
+		"error.webhook_not_found":       "This URL is not a webhook of the group.",
+		"error.webhook_limit":           "A group can have at most %d webhooks. Remove one first.",
+		"error.webhook_send":            "Could not send you the secret of the webhook, so it was not added. Start a private chat with the bot, then add it again.",
+		"error.webhook_usage":           "How to use: /webhook add followed by an https URL, /webhook remove followed by the URL, or /webhook alone to list them.",
+		"error.webhooks_unavailable":    "Webhooks are not available.",
+	},
+}
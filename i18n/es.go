@@ -0,0 +1,217 @@
+package i18n
+
+var spanish = catalog{
+	weekdays:     [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+	decimalComma: true,
+	messages: map[string]string{
+		"help.title": "Comandos del bot:",
+
+		"command.help":    "Muestra este mensaje de ayuda",
+		"command.new":     "Crea un partido nuevo con la hora, el lugar, el rival y el precio indicados.\nCómo usarlo: /new (Hora, Lugar, Rival, Precio)\nej: /new (sunday 11am, Marina Bay Sands, Célavi FC, 15)\nLa hora también acepta \"next sat 9:30\", \"tomorrow 19:00\", \"13/10 8pm\" o \"2024-10-10, 11:00\"",
+		"command.cancel":  "Cancela el próximo partido",
+		"command.in":      "Apúntate al próximo partido",
+		"command.out":     "Indica que no vas al próximo partido",
+		"command.details": "Muestra los detalles del partido",
+		"command.paid":    "Marca que has pagado el partido",
+		"command.backup":  "Envía al dueño del bot una copia de seguridad de la base de datos por chat privado",
+		"command.export": "Envía los partidos de un periodo con quién jugó y pagó como hoja de cálculo (solo administradores)\n" +
+			"ej: /export xlsx 2024-01-01 2024-06-30",
+		"command.import": "Importa partidos pasados de un archivo CSV, o el calendario de la liga de un archivo iCalendar (.ics), enviado con /import como pie, y después con /import confirm para guardarlos (solo administradores)",
+		"command.calendar": "Envía el enlace para suscribirse a los partidos del chat en una aplicación de calendario, y el próximo partido como archivo de calendario\n" +
+			"Los administradores pueden enviar /calendar reset para cambiar un enlace que se compartió de más",
+		"command.api":       "Recibe en un chat privado un nuevo token de la API de este grupo, para que otras apps usen sus partidos (solo administradores)",
+		"command.dashboard": "Recibe en un chat privado un enlace para entrar al panel web de este grupo, con sus partidos, pagos y clasificaciones",
+		"command.webhook": "Envía los eventos de los partidos de este grupo a otras apps, firmados (solo administradores)\n" +
+			"p. ej.: /webhook add https://example.com/hook, /webhook remove https://example.com/hook, o /webhook para listarlos",
+		"command.timezone": "Muestra o cambia la zona horaria del chat (solo administradores pueden cambiarla)\n" +
+			"ej: /timezone Europe/Madrid",
+		"command.settings": "Muestra o cambia la configuración del chat (solo administradores pueden cambiarla)\n" +
+			"ej: /settings squad_size 14",
+
+		"game.details":   "Partido el %s\nLugar: %s\nRival: %s%s\nJugadores%s: %s\nAusentes: %s",
+		"game.price":     "\nPrecio: %s",
+		"game.cancelled": "El partido del %s ha sido cancelado.",
+
+		"new.usage":  "Formato no válido. Usa:\n/new (Hora, Opcional[Lugar], Opcional[Rival], Opcional[Precio])\nej: /new sunday 11am, Kallang, Célavi",
+		"group_only": "Este bot solo funciona en chats de grupo. /help para más información",
+
+		"backup.sent": "Te he enviado la copia de seguridad por chat privado.",
+
+		"export.usage":   "Formato inválido. Usa:\n/export [csv|xlsx] [desde] [hasta]\ncon los días escritos como AAAA-MM-DD, ej: /export xlsx 2024-01-01 2024-06-30",
+		"export.caption": "Partidos: %d",
+
+		"import.usage":       "Envía el archivo CSV de los partidos como documento con /import como pie para revisarlo, y después envíalo de nuevo con /import confirm para importarlo. Necesita la columna kickoff, y puede tener opponent, location, price, score, player, telegram id, attendance y paid, con una línea por jugador de cada partido. El calendario de una liga puede enviarse igual como archivo iCalendar (.ics), para abrir las respuestas de cada partido por turno.",
+		"import.preview":     "Partidos a importar: %d\nRespuestas: %d\nEnvía el archivo de nuevo con /import confirm como pie para importarlos.",
+		"import.done":        "Partidos importados: %d\nRespuestas: %d",
+		"import.new_players": "Jugadores nuevos: %s",
+		"import.conflicts":   "Problemas a corregir antes de importar: %d",
+		"import.line":        "Línea %d: %s",
+		"import.more":        "…y %d más.",
+
+		"fixtures.preview":     "Partidos a programar: %d",
+		"fixtures.confirm":     "Envía el archivo de nuevo con /import confirm como pie para programarlos.",
+		"fixtures.done":        "Partidos programados: %d",
+		"fixtures.moved":       "Partidos cambiados: %d",
+		"fixtures.removed":     "Partidos cancelados: %d",
+		"fixtures.rescheduled": "Próximo partido reprogramado:",
+		"fixtures.cancelled":   "Próximo partido cancelado:",
+		"fixtures.past":        "Partidos omitidos por ya jugados: %d",
+		"fixtures.undated":     "Eventos omitidos sin hora de inicio: %d",
+		"fixtures.opened":      "Ya se puede responder para el próximo partido.\n\n%s",
+
+		"calendar.name":             "Partidos de fútbol",
+		"calendar.summary":          "Partido de fútbol",
+		"calendar.summary_opponent": "Fútbol contra %s",
+		"calendar.cancelled":        "Cancelado: %s",
+		"calendar.price":            "Precio: %s",
+		"calendar.score":            "Resultado: %s",
+		"calendar.link":             "Suscríbete a los partidos de este chat en tu aplicación de calendario con este enlace. Guárdalo para el grupo, quien lo tenga ve los partidos:\n%s",
+		"calendar.reset":            "El enlace anterior del calendario ya no funciona. Suscríbete de nuevo con este:\n%s",
+		"calendar.next_game":        "Próximo partido",
+
+		"api.token": "Token de la API de %s:\n%s\n\nEnvíalo como bearer token a %s, descrita por openapi.yaml allí. Cualquiera que lo tenga puede gestionar los partidos del grupo, así que guárdalo para ti. No se vuelve a mostrar, y enviar /api en el grupo lo reemplaza.",
+		"api.sent":  "Te envié un nuevo token de la API en un chat privado. El anterior, si había, ya no funciona.",
+
+		"dashboard.link":           "Entra al panel de %s con este enlace. Funciona una vez, durante 15 minutos:\n%s",
+		"dashboard.sent":           "Te envié el enlace al panel en un chat privado.",
+		"dashboard.title":          "Panel de fútbol",
+		"dashboard.login":          "Llegaste aquí desde Telegram para ver el panel de tu grupo.",
+		"dashboard.sign_in":        "Entrar",
+		"dashboard.sign_out":       "Salir",
+		"dashboard.how_to_sign_in": "Envía /dashboard en tu grupo para recibir un enlace para entrar.",
+		"dashboard.next_game":      "Próximo partido",
+		"dashboard.cancelled":      "Cancelado",
+		"dashboard.vs":             "contra %s",
+		"dashboard.players":        "Jugadores (%d)",
+		"dashboard.absentees":      "Ausentes (%d)",
+		"dashboard.paid":           "Pagado",
+		"dashboard.unpaid":         "Sin pagar",
+		"dashboard.nobody":         "Nadie todavía.",
+		"dashboard.no_game":        "No hay ningún partido programado.",
+		"dashboard.history":        "Partidos jugados",
+		"dashboard.record":         "%d jugados: %d ganados, %d empatados, %d perdidos",
+		"dashboard.date":           "Fecha",
+		"dashboard.opponent":       "Rival",
+		"dashboard.location":       "Lugar",
+		"dashboard.score":          "Resultado",
+		"dashboard.no_history":     "Todavía no se ha jugado ningún partido.",
+		"dashboard.appearances":    "Más partidos jugados",
+		"dashboard.unpaid_games":   "Partidos jugados sin pagar",
+		"dashboard.all_paid":       "Todos han pagado.",
+
+		"webhook.secret":  "Los eventos de los partidos de %s se envían ahora como JSON por POST a:\n%s\n\nCada uno va firmado con una cabecera X-Sunday-League-Signature, sha256= y el HMAC-SHA256 de la cabecera X-Sunday-League-Timestamp, un punto y el cuerpo, con este secreto como clave:\n%s",
+		"webhook.added":   "Webhook añadido. Te envié su secreto de firma en un chat privado.",
+		"webhook.removed": "Webhook eliminado. Se descartaron los eventos que aún esperaban para él.",
+		"webhook.list":    "Webhooks de este grupo:\n%s",
+		"webhook.none":    "Este grupo no tiene webhooks. Añade uno con /webhook add seguido de una URL https.",
+
+		"timezone.current": "Las horas de este chat se muestran en %s (ahora son las %s).",
+
+		"settings.title":                "Configuración del chat:",
+		"settings.help":                 "Los administradores pueden cambiar un ajuste con los botones de abajo o con /settings <nombre> <valor>, ej: /settings currency EUR",
+		"settings.hint":                 "Envía /settings %s <valor> para cambiarlo.",
+		"error.settings_admins_only":    "Solo los administradores del grupo pueden cambiar la configuración.",
+		"settings.button.squad_down":    "Plantilla −",
+		"settings.button.squad_up":      "Plantilla +",
+		"settings.button.language":      "Idioma: %s",
+		"settings.button.game_creators": "Partidos por: %s",
+
+		"setting.currency":      "Moneda",
+		"setting.default_price": "Precio por defecto",
+		"setting.squad_size":    "Tamaño de la plantilla",
+		"setting.timezone":      "Zona horaria",
+		"setting.language":      "Idioma",
+		"setting.reminders":     "Recordatorios",
+		"setting.game_creators": "Partidos creados por",
+
+		"error.unknown":                 "Algo salió mal, inténtalo de nuevo.",
+		"error.user_retrieve":           "No se pudo obtener el usuario, inténtalo de nuevo.",
+		"error.user_create":             "No se pudo crear el usuario, inténtalo de nuevo.",
+		"error.latest_game":             "No se pudo encontrar el último partido, inténtalo de nuevo.",
+		"error.game_already_scheduled":  "Ya hay un partido programado el %s contra %s",
+		"error.kickoff_invalid":         "No se entendió %q. Prueba algo como \"sunday 11am\" o \"2024-10-10 11:00\".",
+		"error.kickoff_missing_time":    "%q se leyó como %s %s pero no tiene hora. Añade una como 11am o 19:30.",
+		"error.kickoff_past":            "%q se leyó como %s, que ya ha pasado.",
+		"error.invalid_price":           "Formato de precio no válido. Indica un número válido.",
+		"error.game_create":             "No se pudo crear el partido, inténtalo de nuevo.",
+		"error.game_details":            "No se pudieron obtener los detalles del partido, inténtalo de nuevo.",
+		"error.game_cancel":             "No se pudo cancelar el partido, inténtalo de nuevo.",
+		"error.game_players":            "No se pudieron obtener los jugadores del partido, inténtalo de nuevo.",
+		"error.no_game":                 "No hay ningún partido.",
+		"error.no_upcoming_game":        "No hay ningún próximo partido.",
+		"error.player_create":           "No se pudo crear el jugador, inténtalo de nuevo.",
+		"error.player_register":         "No se pudo apuntar al jugador, inténtalo de nuevo.",
+		"error.player_retrieve":         "No se pudo encontrar al jugador, inténtalo de nuevo.",
+		"error.player_game_retrieve":    "No se pudo encontrar al jugador en el partido, inténtalo de nuevo.",
+		"error.player_not_registered":   "%s no está apuntado al partido.",
+		"error.sender_not_registered":   "No estás apuntado al partido. Usa /in primero.",
+		"error.payment_update":          "No se pudo registrar el pago, inténtalo de nuevo.",
+		"error.settings_retrieve":       "No se pudo obtener la configuración del chat, inténtalo de nuevo.",
+		"error.settings_save":           "No se pudo guardar el ajuste, inténtalo de nuevo.",
+		"error.invalid_currency":        "Moneda no válida %q. Usa un código de tres letras como EUR o un símbolo como €.",
+		"error.invalid_squad_size":      "Tamaño de plantilla no válido. Indica un número entre 0 y %d; 0 significa sin límite.",
+		"error.unknown_timezone":        "Zona horaria desconocida %q. Usa un nombre como Europe/Madrid o America/Mexico_City.",
+		"error.unsupported_language":    "Idioma no disponible %q. Elige uno de %s.",
+		"error.invalid_reminder":        "Recordatorio no válido %q. Usa valores como 1d, 2h o 30m.",
+		"error.too_many_reminders":      "Demasiados recordatorios. Indica como máximo %d.",
+		"error.invalid_game_creators":   "Valor no válido %q. Elige %s o %s.",
+		"error.admins_only":             "Solo los administradores del grupo pueden usar este comando.",
+		"error.unknown_setting":         "Ajuste desconocido %q.",
+		"error.games_list":              "No se pudieron listar los partidos, inténtalo de nuevo.",
+		"error.player_not_found":        "No existe el jugador %s.",
+		"error.player_merge":            "No se pudieron fusionar los jugadores, inténtalo de nuevo.",
+		"error.merge_same_player":       "Un jugador no se puede fusionar consigo mismo.",
+		"error.owner_only":              "Solo el dueño del bot puede usar este comando.",
+		"error.backup_unavailable":      "Solo se hacen copias de seguridad de bases de datos SQLite.",
+		"error.backup":                  "No se pudo hacer la copia de seguridad, inténtalo de nuevo.",
+		"error.backup_send":             "No se pudo enviarte la copia de seguridad. Abre un chat privado con el bot e inténtalo de nuevo.",
+		"error.export":                  "No se pudieron exportar los partidos, inténtalo de nuevo.",
+		"error.export_range":            "El primer día del periodo no puede ser posterior al último.",
+		"error.no_games_to_export":      "No hay partidos que exportar en ese periodo.",
+		"error.import":                  "No se pudieron importar los partidos, inténtalo de nuevo.",
+		"error.import_file":             "No se pudo leer el archivo como CSV.",
+		"error.import_missing_column":   "El archivo no tiene la columna %s.",
+		"error.import_unknown_column":   "El archivo tiene una columna %q que no se puede importar. Quítala e inténtalo de nuevo.",
+		"error.no_games_to_import":      "El archivo no tiene partidos.",
+		"error.import_kickoff":          "el inicio %q no está escrito como 2024-10-06 11:00",
+		"error.import_price":            "el precio %q no es un número positivo",
+		"error.import_score":            "el resultado %q no está escrito como 3-1",
+		"error.import_telegram_id":      "el ID de Telegram %q no es un número",
+		"error.import_attendance":       "la asistencia %q no es attending ni out",
+		"error.import_paid":             "paid es %q en vez de yes o no",
+		"error.import_no_player":        "la línea tiene ID de Telegram, asistencia o pago pero ningún jugador",
+		"error.import_game_mismatch":    "el partido a esta hora en la línea %d tiene otro rival, lugar, precio o resultado",
+		"error.import_future_game":      "el partido aún no se ha jugado",
+		"error.import_game_exists":      "el chat ya tiene un partido a esta hora",
+		"error.import_unknown_player":   "nadie llamado %s ha jugado en este chat, añade su ID de Telegram para crearlo",
+		"error.import_ambiguous_player": "hay %d jugadores llamados %s, da el ID de Telegram del correcto",
+		"error.import_duplicate_player": "%s aparece dos veces en el partido",
+		"error.token":                   "No se pudo obtener el enlace del chat, inténtalo de nuevo.",
+		"error.unknown_token":           "Este enlace ya no es válido.",
+		"error.calendar":                "No se pudo generar el calendario, inténtalo de nuevo.",
+		"error.calendar_unavailable":    "Los enlaces de calendario no están disponibles, el bot no tiene una dirección pública configurada.",
+		"error.fixtures":                "No se pudieron programar los partidos, inténtalo de nuevo.",
+		"error.fixtures_file":           "El archivo no se pudo leer como iCalendar.",
+		"error.no_fixtures":             "El archivo no tiene partidos con hora de inicio.",
+		"error.api_unavailable":         "La API no está disponible, el bot no tiene una dirección pública configurada.",
+		"error.api_send":                "No pude enviarte el token de la API. Inicia un chat privado con el bot y envía /api de nuevo.",
+		"error.api_body":                "El cuerpo de la petición no es JSON con los campos esperados.",
+		"error.api_field_missing":       "%s es obligatorio.",
+		"error.api_status":              "El estado %q no es in ni out.",
+		"error.api_user_id":             "El ID de Telegram %q no es un número positivo.",
+		"error.session":                 "No se pudo iniciar tu sesión, inténtalo de nuevo.",
+		"error.login_expired":           "Este enlace para entrar ya se usó o ha caducado.",
+		"error.not_signed_in":           "No has entrado, o tu sesión ha caducado.",
+		"error.dashboard":               "No se pudo cargar el panel, inténtalo de nuevo.",
+		"error.dashboard_unavailable":   "El panel no está disponible, el bot no tiene una dirección pública configurada.",
+		"error.dashboard_send":          "No se pudo enviarte el enlace al panel. Abre un chat privado con el bot y envía /dashboard de nuevo.",
+		"error.webhooks":                "No se pudieron actualizar los webhooks, inténtalo de nuevo.",
+		"error.webhook_url":             "%q no es una URL https.",
+		"error.webhook_exists":          "Esta URL ya es un webhook del grupo.",
+		"error.webhook_not_found":       "Esta URL no es un webhook del grupo.",
+		"error.webhook_limit":           "Un grupo puede tener como máximo %d webhooks. Elimina uno primero.",
+		"error.webhook_send":            "No se pudo enviarte el secreto del webhook, así que no se añadió. Abre un chat privado con el bot y añádelo de nuevo.",
+		"error.webhook_usage":           "Cómo usarlo: /webhook add seguido de una URL https, /webhook remove seguido de la URL, o /webhook solo para listarlos.",
+		"error.webhooks_unavailable":    "Los webhooks no están disponibles.",
+	},
+}
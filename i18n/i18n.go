@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultLanguage = "en"
+
+type catalog struct {
+	messages map[string]string
+	weekdays [7]string
+	// decimalComma is set for languages writing 12,50 rather than 12.50.
+	decimalComma bool
+}
+
+var catalogs = map[string]*catalog{
+	"en": &english,
+	"es": &spanish,
+	"pt": &portuguese,
+}
+
+// Languages returns the codes of every language with a catalog, sorted.
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Localizer renders catalog messages in one language and time zone.
+type Localizer struct {
+	Language string
+	Location *time.Location
+	catalog  *catalog
+}
+
+// For returns a Localizer for language, falling back to English for unknown
+// languages and to UTC when loc is nil.
+func For(language string, loc *time.Location) *Localizer {
+	c, ok := catalogs[language]
+	if !ok {
+		language = DefaultLanguage
+		c = catalogs[DefaultLanguage]
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Localizer{Language: language, Location: loc, catalog: c}
+}
+
+// T renders the message stored under key. Messages missing from the
+// localizer's language fall back to English, and unknown keys are returned
+// as they are. Times, weekdays and prices in args are localized first.
+func (l *Localizer) T(key string, args ...interface{}) string {
+	format, ok := l.catalog.messages[key]
+	if !ok {
+		format, ok = catalogs[DefaultLanguage].messages[key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+
+	localized := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			localized[i] = l.DateTime(v)
+		case time.Weekday:
+			localized[i] = l.Weekday(v)
+		default:
+			localized[i] = v
+		}
+	}
+	return fmt.Sprintf(format, localized...)
+}
+
+// DateTime formats t in the localizer's time zone.
+func (l *Localizer) DateTime(t time.Time) string {
+	t = t.In(l.Location)
+	return l.Weekday(t.Weekday()) + " " + t.Format("2006-01-02 15:04")
+}
+
+func (l *Localizer) Weekday(d time.Weekday) string {
+	return l.catalog.weekdays[d]
+}
+
+// Amount formats a price with two decimals and the currency, if any.
+func (l *Localizer) Amount(amount float64, currency string) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+	if l.catalog.decimalComma {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	if currency == "" {
+		return s
+	}
+	return s + " " + currency
+}
@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// verb matches one fmt directive, including %% so it can be skipped.
+var verb = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*(\d+|\*)?(\.(\d+|\*))?[a-zA-Z%]`)
+
+func countVerbs(format string) int {
+	count := 0
+	for _, match := range verb.FindAllString(format, -1) {
+		if match != "%%" {
+			count++
+		}
+	}
+	return count
+}
+
+func TestLanguages(t *testing.T) {
+	if got, want := Languages(), []string{"en", "es", "pt"}; !slices.Equal(got, want) {
+		t.Errorf("Languages() = %v, want %v", got, want)
+	}
+}
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	english := catalogs[DefaultLanguage]
+	for _, language := range Languages() {
+		if language == DefaultLanguage {
+			continue
+		}
+		c := catalogs[language]
+		for key, format := range english.messages {
+			translated, ok := c.messages[key]
+			if !ok {
+				t.Errorf("%s: missing %q", language, key)
+				continue
+			}
+			if got, want := countVerbs(translated), countVerbs(format); got != want {
+				t.Errorf("%s: %q has %d verbs, want %d like English", language, key, got, want)
+			}
+		}
+		for key := range c.messages {
+			if _, ok := english.messages[key]; !ok {
+				t.Errorf("%s: %q is not in English", language, key)
+			}
+		}
+		for d, name := range c.weekdays {
+			if name == "" {
+				t.Errorf("%s: no name for weekday %d", language, d)
+			}
+		}
+	}
+}
+
+// errorCodes reads the values of the ErrorCode constants from the services
+// package, which imports this one and so cannot be imported by its tests.
+func errorCodes(t *testing.T) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "../services/errors.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		if typ, ok := spec.Type.(*ast.Ident); !ok || typ.Name != "ErrorCode" {
+			return false
+		}
+		for _, value := range spec.Values {
+			lit, ok := value.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Fatalf("ErrorCode constant %v is not a string literal", spec.Names)
+			}
+			code, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			codes = append(codes, code)
+		}
+		return false
+	})
+	if len(codes) == 0 {
+		t.Fatal("no ErrorCode constants found in services/errors.go")
+	}
+	return codes
+}
+
+func TestEveryErrorCodeHasAMessage(t *testing.T) {
+	for _, code := range errorCodes(t) {
+		for _, language := range Languages() {
+			if _, ok := catalogs[language].messages[code]; !ok {
+				t.Errorf("%s: no message for error code %q", language, code)
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package i18n
+
+var portuguese = catalog{
+	weekdays:     [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+	decimalComma: true,
+	messages: map[string]string{
+		"help.title": "Comandos do bot:",
+
+		"command.help":    "Mostra esta mensagem de ajuda",
+		"command.new":     "Cria um novo jogo com o horário, local, adversário e preço indicados.\nComo usar: /new (Horário, Local, Adversário, Preço)\nex: /new (sunday 11am, Marina Bay Sands, Célavi FC, 15)\nO horário também aceita \"next sat 9:30\", \"tomorrow 19:00\", \"13/10 8pm\" ou \"2024-10-10, 11:00\"",
+		"command.cancel":  "Cancela o próximo jogo",
+		"command.in":      "Confirma a tua presença no próximo jogo",
+		"command.out":     "Indica que não vais ao próximo jogo",
+		"command.details": "Mostra os detalhes do jogo",
+		"command.paid":    "Marca que pagaste o jogo",
+		"command.backup":  "Envia ao dono do bot uma cópia de segurança da base de dados por chat privado",
+		"command.export": "Envia os jogos de um período com quem jogou e pagou como folha de cálculo (só administradores)\n" +
+			"ex: /export xlsx 2024-01-01 2024-06-30",
+		"command.import": "Importa jogos passados de um ficheiro CSV, ou o calendário da liga de um ficheiro iCalendar (.ics), enviado com /import como legenda, e depois com /import confirm para os gravar (só administradores)",
+		"command.calendar": "Envia o link para subscrever os jogos do chat numa aplicação de calendário, e o próximo jogo como ficheiro de calendário\n" +
+			"Os administradores podem enviar /calendar reset para trocar um link partilhado demais",
+		"command.api":       "Recebe num chat privado um novo token da API deste grupo, para outras apps usarem os seus jogos (só administradores)",
+		"command.dashboard": "Recebe num chat privado um link para entrar no painel web deste grupo, com os seus jogos, pagamentos e classificações",
+		"command.webhook": "Envia os eventos dos jogos deste grupo para outras apps, assinados (só administradores)\n" +
+			"p. ex.: /webhook add https://example.com/hook, /webhook remove https://example.com/hook, ou /webhook para os listar",
+		"command.timezone": "Mostra ou altera o fuso horário do chat (só administradores podem alterar)\n" +
+			"ex: /timezone America/Sao_Paulo",
+		"command.settings": "Mostra ou altera as configurações do chat (só administradores podem alterar)\n" +
+			"ex: /settings squad_size 14",
+
+		"game.details":   "Jogo em %s\nLocal: %s\nAdversário: %s%s\nJogadores%s: %s\nAusentes: %s",
+		"game.price":     "\nPreço: %s",
+		"game.cancelled": "O jogo de %s foi cancelado.",
+
+		"new.usage":  "Formato inválido. Usa:\n/new (Horário, Opcional[Local], Opcional[Adversário], Opcional[Preço])\nex: /new sunday 11am, Kallang, Célavi",
+		"group_only": "Este bot só funciona em chats de grupo. /help para mais informações",
+
+		"backup.sent": "A cópia de segurança foi-te enviada por chat privado.",
+
+		"export.usage":   "Formato inválido. Use:\n/export [csv|xlsx] [de] [até]\ncom os dias escritos como AAAA-MM-DD, ex: /export xlsx 2024-01-01 2024-06-30",
+		"export.caption": "Jogos: %d",
+
+		"import.usage":       "Envie o ficheiro CSV dos jogos como documento com /import como legenda para o verificar, e depois envie-o de novo com /import confirm para o importar. Precisa da coluna kickoff, e pode ter opponent, location, price, score, player, telegram id, attendance e paid, com uma linha por jogador de cada jogo. O calendário de uma liga pode ser enviado da mesma forma como ficheiro iCalendar (.ics), para abrir as respostas de cada jogo à vez.",
+		"import.preview":     "Jogos a importar: %d\nRespostas: %d\nEnvie o ficheiro de novo com /import confirm como legenda para os importar.",
+		"import.done":        "Jogos importados: %d\nRespostas: %d",
+		"import.new_players": "Jogadores novos: %s",
+		"import.conflicts":   "Problemas a corrigir antes de importar: %d",
+		"import.line":        "Linha %d: %s",
+		"import.more":        "…e mais %d.",
+
+		"fixtures.preview":     "Jogos a agendar: %d",
+		"fixtures.confirm":     "Envie o ficheiro de novo com /import confirm como legenda para os agendar.",
+		"fixtures.done":        "Jogos agendados: %d",
+		"fixtures.moved":       "Jogos alterados: %d",
+		"fixtures.removed":     "Jogos cancelados: %d",
+		"fixtures.rescheduled": "Próximo jogo reagendado:",
+		"fixtures.cancelled":   "Próximo jogo cancelado:",
+		"fixtures.past":        "Jogos deixados de fora por já jogados: %d",
+		"fixtures.undated":     "Eventos deixados de fora sem hora de início: %d",
+		"fixtures.opened":      "Já pode responder para o próximo jogo.\n\n%s",
+
+		"calendar.name":             "Jogos de futebol",
+		"calendar.summary":          "Jogo de futebol",
+		"calendar.summary_opponent": "Futebol contra %s",
+		"calendar.cancelled":        "Cancelado: %s",
+		"calendar.price":            "Preço: %s",
+		"calendar.score":            "Resultado: %s",
+		"calendar.link":             "Subscreva os jogos deste chat na sua aplicação de calendário com este link. Guarde-o para o grupo, quem o tiver vê os jogos:\n%s",
+		"calendar.reset":            "O link anterior do calendário já não funciona. Subscreva de novo com este:\n%s",
+		"calendar.next_game":        "Próximo jogo",
+
+		"api.token": "Token da API de %s:\n%s\n\nEnvie-o como bearer token para %s, descrita pelo openapi.yaml aí. Qualquer pessoa com ele pode gerir os jogos do grupo, por isso guarde-o para si. Não volta a ser mostrado, e enviar /api no grupo substitui-o.",
+		"api.sent":  "Foi-lhe enviado um novo token da API num chat privado. O anterior, se existia, já não funciona.",
+
+		"dashboard.link":           "Entra no painel de %s com este link. Funciona uma vez, durante 15 minutos:\n%s",
+		"dashboard.sent":           "Enviei-te o link para o painel num chat privado.",
+		"dashboard.title":          "Painel de futebol",
+		"dashboard.login":          "Vieste do Telegram para ver o painel do teu grupo.",
+		"dashboard.sign_in":        "Entrar",
+		"dashboard.sign_out":       "Sair",
+		"dashboard.how_to_sign_in": "Envia /dashboard no teu grupo para receberes um link para entrar.",
+		"dashboard.next_game":      "Próximo jogo",
+		"dashboard.cancelled":      "Cancelado",
+		"dashboard.vs":             "contra %s",
+		"dashboard.players":        "Jogadores (%d)",
+		"dashboard.absentees":      "Ausentes (%d)",
+		"dashboard.paid":           "Pago",
+		"dashboard.unpaid":         "Por pagar",
+		"dashboard.nobody":         "Ninguém ainda.",
+		"dashboard.no_game":        "Não há nenhum jogo marcado.",
+		"dashboard.history":        "Jogos disputados",
+		"dashboard.record":         "%d disputados: %d vitórias, %d empates, %d derrotas",
+		"dashboard.date":           "Data",
+		"dashboard.opponent":       "Adversário",
+		"dashboard.location":       "Local",
+		"dashboard.score":          "Resultado",
+		"dashboard.no_history":     "Ainda não se disputou nenhum jogo.",
+		"dashboard.appearances":    "Mais jogos disputados",
+		"dashboard.unpaid_games":   "Jogos disputados sem pagar",
+		"dashboard.all_paid":       "Todos pagaram.",
+
+		"webhook.secret":  "Os eventos dos jogos de %s são agora enviados como JSON por POST para:\n%s\n\nCada um é assinado com um cabeçalho X-Sunday-League-Signature, sha256= e o HMAC-SHA256 do cabeçalho X-Sunday-League-Timestamp, um ponto e o corpo, com este segredo como chave:\n%s",
+		"webhook.added":   "Webhook adicionado. Enviei-te o segredo de assinatura num chat privado.",
+		"webhook.removed": "Webhook removido. Os eventos que ainda esperavam por ele foram descartados.",
+		"webhook.list":    "Webhooks deste grupo:\n%s",
+		"webhook.none":    "Este grupo não tem webhooks. Adiciona um com /webhook add seguido de um URL https.",
+
+		"timezone.current": "Os horários deste chat são mostrados em %s (agora são %s).",
+
+		"settings.title":                "Configurações do chat:",
+		"settings.help":                 "Os administradores podem alterar uma configuração com os botões abaixo ou com /settings <nome> <valor>, ex: /settings currency BRL",
+		"settings.hint":                 "Envia /settings %s <valor> para alterar.",
+		"error.settings_admins_only":    "Só os administradores do grupo podem alterar as configurações.",
+		"settings.button.squad_down":    "Plantel −",
+		"settings.button.squad_up":      "Plantel +",
+		"settings.button.language":      "Idioma: %s",
+		"settings.button.game_creators": "Jogos por: %s",
+
+		"setting.currency":      "Moeda",
+		"setting.default_price": "Preço padrão",
+		"setting.squad_size":    "Tamanho do plantel",
+		"setting.timezone":      "Fuso horário",
+		"setting.language":      "Idioma",
+		"setting.reminders":     "Lembretes",
+		"setting.game_creators": "Jogos criados por",
+
+		"error.unknown":                 "Algo correu mal, tenta novamente.",
+		"error.user_retrieve":           "Não foi possível obter o utilizador, tenta novamente.",
+		"error.user_create":             "Não foi possível criar o utilizador, tenta novamente.",
+		"error.latest_game":             "Não foi possível encontrar o último jogo, tenta novamente.",
+		"error.game_already_scheduled":  "Já existe um jogo marcado para %s contra %s",
+		"error.kickoff_invalid":         "Não foi possível perceber %q. Tenta algo como \"sunday 11am\" ou \"2024-10-10 11:00\".",
+		"error.kickoff_missing_time":    "%q foi lido como %s %s mas não tem horário. Adiciona um como 11am ou 19:30.",
+		"error.kickoff_past":            "%q foi lido como %s, que já passou.",
+		"error.invalid_price":           "Formato de preço inválido. Indica um número válido.",
+		"error.game_create":             "Não foi possível criar o jogo, tenta novamente.",
+		"error.game_details":            "Não foi possível obter os detalhes do jogo, tenta novamente.",
+		"error.game_cancel":             "Não foi possível cancelar o jogo, tenta novamente.",
+		"error.game_players":            "Não foi possível obter os jogadores do jogo, tenta novamente.",
+		"error.no_game":                 "Não existe nenhum jogo.",
+		"error.no_upcoming_game":        "Não há nenhum próximo jogo.",
+		"error.player_create":           "Não foi possível criar o jogador, tenta novamente.",
+		"error.player_register":         "Não foi possível inscrever o jogador, tenta novamente.",
+		"error.player_retrieve":         "Não foi possível encontrar o jogador, tenta novamente.",
+		"error.player_game_retrieve":    "Não foi possível encontrar o jogador no jogo, tenta novamente.",
+		"error.player_not_registered":   "%s não está inscrito no jogo.",
+		"error.sender_not_registered":   "Não estás inscrito no jogo. Usa /in primeiro.",
+		"error.payment_update":          "Não foi possível registar o pagamento, tenta novamente.",
+		"error.settings_retrieve":       "Não foi possível obter as configurações do chat, tenta novamente.",
+		"error.settings_save":           "Não foi possível guardar a configuração, tenta novamente.",
+		"error.invalid_currency":        "Moeda inválida %q. Usa um código de três letras como BRL ou um símbolo como €.",
+		"error.invalid_squad_size":      "Tamanho de plantel inválido. Indica um número entre 0 e %d; 0 significa sem limite.",
+		"error.unknown_timezone":        "Fuso horário desconhecido %q. Usa um nome como America/Sao_Paulo ou Europe/Lisbon.",
+		"error.unsupported_language":    "Idioma não suportado %q. Escolhe um de %s.",
+		"error.invalid_reminder":        "Lembrete inválido %q. Usa valores como 1d, 2h ou 30m.",
+		"error.too_many_reminders":      "Demasiados lembretes. Indica no máximo %d.",
+		"error.invalid_game_creators":   "Valor inválido %q. Escolhe %s ou %s.",
+		"error.admins_only":             "Só os administradores do grupo podem usar este comando.",
+		"error.unknown_setting":         "Configuração desconhecida %q.",
+		"error.games_list":              "Não foi possível listar os jogos, tente novamente.",
+		"error.player_not_found":        "Não existe o jogador %s.",
+		"error.player_merge":            "Não foi possível juntar os jogadores, tente novamente.",
+		"error.merge_same_player":       "Um jogador não pode ser juntado a si mesmo.",
+		"error.owner_only":              "Só o dono do bot pode usar este comando.",
+		"error.backup_unavailable":      "Só são feitas cópias de segurança de bases de dados SQLite.",
+		"error.backup":                  "Não foi possível fazer a cópia de segurança, tente novamente.",
+		"error.backup_send":             "Não foi possível enviar-te a cópia de segurança. Abre um chat privado com o bot e tenta novamente.",
+		"error.export":                  "Não foi possível exportar os jogos, tente novamente.",
+		"error.export_range":            "O primeiro dia do período não pode ser depois do último.",
+		"error.no_games_to_export":      "Não há jogos para exportar nesse período.",
+		"error.import":                  "Não foi possível importar os jogos, tente novamente.",
+		"error.import_file":             "Não foi possível ler o ficheiro como CSV.",
+		"error.import_missing_column":   "O ficheiro não tem a coluna %s.",
+		"error.import_unknown_column":   "O ficheiro tem uma coluna %q que não pode ser importada. Remova-a e tente novamente.",
+		"error.no_games_to_import":      "O ficheiro não tem jogos.",
+		"error.import_kickoff":          "o início %q não está escrito como 2024-10-06 11:00",
+		"error.import_price":            "o preço %q não é um número positivo",
+		"error.import_score":            "o resultado %q não está escrito como 3-1",
+		"error.import_telegram_id":      "o ID do Telegram %q não é um número",
+		"error.import_attendance":       "a presença %q não é attending nem out",
+		"error.import_paid":             "paid é %q em vez de yes ou no",
+		"error.import_no_player":        "a linha tem ID do Telegram, presença ou pagamento mas nenhum jogador",
+		"error.import_game_mismatch":    "o jogo a esta hora na linha %d tem outro adversário, local, preço ou resultado",
+		"error.import_future_game":      "o jogo ainda não foi jogado",
+		"error.import_game_exists":      "o chat já tem um jogo a esta hora",
+		"error.import_unknown_player":   "ninguém chamado %s jogou neste chat, adicione o ID do Telegram para o criar",
+		"error.import_ambiguous_player": "há %d jogadores chamados %s, indique o ID do Telegram do certo",
+		"error.import_duplicate_player": "%s aparece duas vezes no jogo",
+		"error.token":                   "Não foi possível obter o link do chat, tente novamente.",
+		"error.unknown_token":           "Este link já não é válido.",
+		"error.calendar":                "Não foi possível gerar o calendário, tente novamente.",
+		"error.calendar_unavailable":    "Os links de calendário não estão disponíveis, o bot não tem um endereço público configurado.",
+		"error.fixtures":                "Não foi possível agendar os jogos, tente novamente.",
+		"error.fixtures_file":           "O ficheiro não pôde ser lido como iCalendar.",
+		"error.no_fixtures":             "O ficheiro não tem jogos com hora de início.",
+		"error.api_unavailable":         "A API não está disponível, o bot não tem um endereço público configurado.",
+		"error.api_send":                "Não foi possível enviar-lhe o token da API. Inicie um chat privado com o bot e envie /api de novo.",
+		"error.api_body":                "O corpo do pedido não é JSON com os campos esperados.",
+		"error.api_field_missing":       "%s é obrigatório.",
+		"error.api_status":              "O estado %q não é in nem out.",
+		"error.api_user_id":             "O ID do Telegram %q não é um número positivo.",
+		"error.session":                 "Não foi possível iniciar a tua sessão, tenta novamente.",
+		"error.login_expired":           "Este link para entrar já foi usado ou expirou.",
+		"error.not_signed_in":           "Não tens sessão iniciada, ou a tua sessão expirou.",
+		"error.dashboard":               "Não foi possível carregar o painel, tenta novamente.",
+		"error.dashboard_unavailable":   "O painel não está disponível, o bot não tem um endereço público configurado.",
+		"error.dashboard_send":          "Não foi possível enviar-te o link para o painel. Abre um chat privado com o bot e envia /dashboard novamente.",
+		"error.webhooks":                "Não foi possível atualizar os webhooks, tenta novamente.",
+		"error.webhook_url":             "%q não é um URL https.",
+		"error.webhook_exists":          "Este URL já é um webhook do grupo.",
+		"error.webhook_not_found":       "Este URL não é um webhook do grupo.",
+		"error.webhook_limit":           "Um grupo pode ter no máximo %d webhooks. Remove um primeiro.",
+		"error.webhook_send":            "Não foi possível enviar-te o segredo do webhook, por isso não foi adicionado. Abre um chat privado com o bot e adiciona-o novamente.",
+		"error.webhook_usage":           "Como usar: /webhook add seguido de um URL https, /webhook remove seguido do URL, ou só /webhook para os listar.",
+		"error.webhooks_unavailable":    "Os webhooks não estão disponíveis.",
+	},
+}
@@ -0,0 +1,275 @@
+// Package importer reads games kept before the bot, such as in a
+// spreadsheet, for GameService.ImportGames.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"tg-sunday-league/services"
+	"time"
+)
+
+// Columns of an import file, matched regardless of case, and of a suffix in
+// brackets such as the currency in "Price (SGD)". Only COLUMN_KICKOFF is
+// required.
+const (
+	COLUMN_KICKOFF     = "kickoff"
+	COLUMN_OPPONENT    = "opponent"
+	COLUMN_LOCATION    = "location"
+	COLUMN_PRICE       = "price"
+	COLUMN_SCORE       = "score"
+	COLUMN_PLAYER      = "player"
+	COLUMN_TELEGRAM_ID = "telegram id"
+	COLUMN_ATTENDANCE  = "attendance"
+	COLUMN_PAID        = "paid"
+)
+
+var COLUMNS = []string{COLUMN_KICKOFF, COLUMN_OPPONENT, COLUMN_LOCATION, COLUMN_PRICE, COLUMN_SCORE, COLUMN_PLAYER, COLUMN_TELEGRAM_ID, COLUMN_ATTENDANCE, COLUMN_PAID}
+
+// aliases are other names of the columns. The columns of an export that
+// cannot be imported are ignored, so exports import as they are.
+var aliases = map[string]string{
+	"date":        COLUMN_KICKOFF,
+	"telegram_id": COLUMN_TELEGRAM_ID,
+	"game id":     "",
+	"game status": "",
+}
+
+// KICKOFF_LAYOUTS are the ways kickoffs may be written, with numeric dates
+// day first as /new reads them. Without a time, games kick off at midnight.
+var KICKOFF_LAYOUTS = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2/1/2006 15:04",
+	"2/1/2006",
+}
+
+var scorePattern = regexp.MustCompile(`^(\d+)\s*[-:x]\s*(\d+)$`)
+
+// ReadCSV reads a CSV file with a header line and a line per player of each
+// game, the lines of a game sharing its kickoff, or a single line without a
+// player for a game nobody answered. Kickoffs are read in loc. Files saved by
+// spreadsheets with semicolons between fields are read too.
+func ReadCSV(r io.Reader, loc *time.Location) (*services.GamesImport, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, services.Invalid("file", services.ERR_IMPORT_FILE, err)
+	}
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if header, _, _ := bytes.Cut(content, []byte("\n")); !bytes.Contains(header, []byte(",")) && bytes.Contains(header, []byte(";")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, services.Invalid("file", services.ERR_NO_GAMES_TO_IMPORT, nil)
+		}
+		return nil, services.Invalid("file", services.ERR_IMPORT_FILE, err)
+	}
+	columns, err := readHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &services.GamesImport{}
+	games := make(map[int64]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, services.Invalid("file", services.ERR_IMPORT_FILE, err)
+		}
+		line, _ := reader.FieldPos(0)
+		readLine(in, games, columns.row(record), line, loc)
+	}
+	return in, nil
+}
+
+// columnIndex maps the columns of a file to their positions.
+type columnIndex map[string]int
+
+func readHeader(header []string) (columnIndex, error) {
+	columns := make(columnIndex)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if before, _, found := strings.Cut(name, "("); found {
+			name = strings.TrimSpace(before)
+		}
+		if name == "" {
+			continue
+		}
+		if alias, ok := aliases[name]; ok {
+			if alias == "" {
+				continue
+			}
+			name = alias
+		}
+		known := false
+		for _, column := range COLUMNS {
+			known = known || column == name
+		}
+		if !known {
+			return nil, services.Invalid("file", services.ERR_IMPORT_UNKNOWN_COLUMN, nil, strings.TrimSpace(header[i]))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns[COLUMN_KICKOFF]; !ok {
+		return nil, services.Invalid("file", services.ERR_IMPORT_MISSING_COLUMN, nil, COLUMN_KICKOFF)
+	}
+	return columns, nil
+}
+
+// row returns the trimmed cells of record by column, empty where record is
+// short.
+func (columns columnIndex) row(record []string) map[string]string {
+	row := make(map[string]string, len(columns))
+	for name, i := range columns {
+		if i < len(record) {
+			row[name] = strings.TrimSpace(record[i])
+		}
+	}
+	return row
+}
+
+// readLine adds the player of row to its game in in, starting the game if it
+// is the first line at its kickoff. games has the index in in.Games of each
+// kickoff read so far.
+func readLine(in *services.GamesImport, games map[int64]int, row map[string]string, line int, loc *time.Location) {
+	empty := true
+	for _, cell := range row {
+		empty = empty && cell == ""
+	}
+	if empty {
+		return
+	}
+	conflict := func(code services.ErrorCode, args ...interface{}) {
+		in.Conflicts = append(in.Conflicts, services.ImportConflict{Line: line, Code: code, Args: args})
+	}
+
+	kickoff, ok := parseKickoff(row[COLUMN_KICKOFF], loc)
+	if !ok {
+		conflict(services.ERR_IMPORT_KICKOFF, row[COLUMN_KICKOFF])
+		return
+	}
+	game := services.ImportedGame{
+		Line:     line,
+		Date:     kickoff,
+		Opponent: row[COLUMN_OPPONENT],
+		Location: row[COLUMN_LOCATION],
+	}
+	if price := row[COLUMN_PRICE]; price != "" {
+		var err error
+		game.Price, err = strconv.ParseFloat(price, 64)
+		if err != nil || game.Price < 0 {
+			conflict(services.ERR_IMPORT_PRICE, price)
+		}
+	}
+	if score := row[COLUMN_SCORE]; score != "" {
+		match := scorePattern.FindStringSubmatch(score)
+		if match == nil {
+			conflict(services.ERR_IMPORT_SCORE, score)
+		} else {
+			game.Score = match[1] + "-" + match[2]
+		}
+	}
+
+	i, seen := games[kickoff.Unix()]
+	if !seen {
+		i = len(in.Games)
+		games[kickoff.Unix()] = i
+		in.Games = append(in.Games, game)
+	} else if !mergeGame(&in.Games[i], game) {
+		conflict(services.ERR_IMPORT_GAME_MISMATCH, in.Games[i].Line)
+	}
+
+	player, ok := readPlayer(row, line, conflict)
+	if ok {
+		in.Games[i].Players = append(in.Games[i].Players, player)
+	}
+}
+
+// mergeGame fills the details of game left out on its first line from a
+// later line of it, and reports whether the two agree.
+func mergeGame(game *services.ImportedGame, other services.ImportedGame) bool {
+	agree := true
+	for _, field := range []struct{ into, from *string }{
+		{&game.Opponent, &other.Opponent},
+		{&game.Location, &other.Location},
+		{&game.Score, &other.Score},
+	} {
+		switch {
+		case *field.from == "":
+		case *field.into == "":
+			*field.into = *field.from
+		case !strings.EqualFold(*field.into, *field.from):
+			agree = false
+		}
+	}
+	switch {
+	case other.Price == 0:
+	case game.Price == 0:
+		game.Price = other.Price
+	case game.Price != other.Price:
+		agree = false
+	}
+	return agree
+}
+
+func readPlayer(row map[string]string, line int, conflict func(code services.ErrorCode, args ...interface{})) (services.ImportedPlayer, bool) {
+	player := services.ImportedPlayer{Line: line, Name: row[COLUMN_PLAYER], Status: services.ATTENDING}
+	if player.Name == "" {
+		if row[COLUMN_TELEGRAM_ID] != "" || row[COLUMN_ATTENDANCE] != "" || row[COLUMN_PAID] != "" {
+			conflict(services.ERR_IMPORT_NO_PLAYER)
+		}
+		return player, false
+	}
+
+	ok := true
+	if id := row[COLUMN_TELEGRAM_ID]; id != "" {
+		var err error
+		player.UserId, err = strconv.ParseInt(id, 10, 64)
+		if err != nil || player.UserId <= 0 {
+			conflict(services.ERR_IMPORT_TELEGRAM_ID, id)
+			ok = false
+		}
+	}
+	switch attendance := strings.ToLower(row[COLUMN_ATTENDANCE]); attendance {
+	case "", "attending", "in", "yes":
+	case "out", "no":
+		player.Status = services.OUT
+	default:
+		conflict(services.ERR_IMPORT_ATTENDANCE, row[COLUMN_ATTENDANCE])
+		ok = false
+	}
+	switch paid := strings.ToLower(row[COLUMN_PAID]); paid {
+	case "", "no", "n", "false", "0":
+	case "yes", "y", "true", "1":
+		player.HasPaid = true
+	default:
+		conflict(services.ERR_IMPORT_PAID, row[COLUMN_PAID])
+		ok = false
+	}
+	return player, ok
+}
+
+func parseKickoff(value string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range KICKOFF_LAYOUTS {
+		if kickoff, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return kickoff, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"tg-sunday-league/services"
+	"time"
+)
+
+var singapore, _ = time.LoadLocation("Asia/Singapore")
+
+func TestReadCSV(t *testing.T) {
+	file := "\ufeffKickoff,Opponent,Location,Price (SGD),Score,Player,Telegram ID,Attendance,Paid\n" +
+		"2023-03-05 11:00,Rovers,Kallang,12,3 - 1,Ana,,attending,yes\n" +
+		"2023-03-05 11:00,,,,,Bea,20,out,no\n" +
+		"\n" +
+		"12/3/2023,United,Bishan,10,,,,,\n" +
+		"2023-03-05 11:00,Rovers,Kallang,12,3-1,Caio,,,Y\n"
+	in, err := ReadCSV(strings.NewReader(file), singapore)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	if len(in.Conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", in.Conflicts)
+	}
+	if len(in.Games) != 2 {
+		t.Fatalf("games = %+v, want 2", in.Games)
+	}
+
+	first := in.Games[0]
+	if first.Line != 2 || !first.Date.Equal(time.Date(2023, time.March, 5, 3, 0, 0, 0, time.UTC)) || first.Opponent != "Rovers" || first.Price != 12 || first.Score != "3-1" {
+		t.Errorf("first game = %+v", first)
+	}
+	want := []services.ImportedPlayer{
+		{Line: 2, Name: "Ana", Status: services.ATTENDING, HasPaid: true},
+		{Line: 3, Name: "Bea", UserId: 20, Status: services.OUT},
+		{Line: 6, Name: "Caio", Status: services.ATTENDING, HasPaid: true},
+	}
+	if len(first.Players) != len(want) {
+		t.Fatalf("players = %+v, want %+v", first.Players, want)
+	}
+	for i, p := range first.Players {
+		if p != want[i] {
+			t.Errorf("player %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+
+	second := in.Games[1]
+	if second.Line != 5 || !second.Date.Equal(time.Date(2023, time.March, 11, 16, 0, 0, 0, time.UTC)) || second.Location != "Bishan" || len(second.Players) != 0 {
+		t.Errorf("second game = %+v, want one on 12 March with no players", second)
+	}
+}
+
+func TestReadCSVConflicts(t *testing.T) {
+	file := "kickoff;price;score;player;telegram id;attendance;paid\n" +
+		"yesterday;;;Ana;;;\n" +
+		"2023-03-05;ten;;Ana;;;\n" +
+		"2023-03-05;12;three;Bea;x;maybe;perhaps\n" +
+		"2023-03-12;;;;5;;\n"
+	in, err := ReadCSV(strings.NewReader(file), singapore)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	want := []struct {
+		line int
+		code services.ErrorCode
+	}{
+		{2, services.ERR_IMPORT_KICKOFF},
+		{3, services.ERR_IMPORT_PRICE},
+		{4, services.ERR_IMPORT_SCORE},
+		{4, services.ERR_IMPORT_TELEGRAM_ID},
+		{4, services.ERR_IMPORT_ATTENDANCE},
+		{4, services.ERR_IMPORT_PAID},
+		{5, services.ERR_IMPORT_NO_PLAYER},
+	}
+	if len(in.Conflicts) != len(want) {
+		t.Fatalf("conflicts = %+v, want %+v", in.Conflicts, want)
+	}
+	for i, c := range in.Conflicts {
+		if c.Line != want[i].line || c.Code != want[i].code {
+			t.Errorf("conflict %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+}
+
+func TestReadCSVRejects(t *testing.T) {
+	tests := []struct {
+		name, file string
+		wantCode   services.ErrorCode
+	}{
+		{"empty", "", services.ERR_NO_GAMES_TO_IMPORT},
+		{"no kickoff", "opponent,player\nRovers,Ana\n", services.ERR_IMPORT_MISSING_COLUMN},
+		{"unknown column", "kickoff,notes\n2023-03-05,rainy\n", services.ERR_IMPORT_UNKNOWN_COLUMN},
+		{"not CSV", "kickoff,player\n2023-03-05,\"Ana\n", services.ERR_IMPORT_FILE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCSV(strings.NewReader(tt.file), singapore)
+			if serviceErr := services.AsError(err); serviceErr == nil || serviceErr.Code != tt.wantCode {
+				t.Errorf("ReadCSV error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
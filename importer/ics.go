@@ -0,0 +1,244 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"tg-sunday-league/services"
+	"time"
+)
+
+// ICS_EXTENSION ends the names of iCalendar files, which are read as
+// fixture lists rather than games.
+const ICS_EXTENSION = ".ics"
+
+// ICS_MEDIA_TYPE is the media type of iCalendar files.
+const ICS_MEDIA_TYPE = "text/calendar"
+
+// IsICS tells whether a file named name and sent as mediaType is an
+// iCalendar file.
+func IsICS(name string, mediaType string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ICS_EXTENSION) || strings.HasPrefix(strings.ToLower(mediaType), ICS_MEDIA_TYPE)
+}
+
+// sidesPattern splits the summaries of fixtures, such as "Rovers vs United",
+// into the two teams.
+var sidesPattern = regexp.MustCompile(`(?i)\s+(?:vs\.?|v\.?|versus|x|-|–|@)\s+`)
+
+// awayPattern matches summaries naming only the opponent, such as "vs United".
+var awayPattern = regexp.MustCompile(`(?i)^(?:vs\.?|v\.?|versus|@|against)\s+`)
+
+var errNotCalendar = errors.New("not an iCalendar file")
+
+// ReadICS reads the events of an iCalendar (RFC 5545) file as fixtures.
+// Kickoffs without a time zone are read in loc, and events of a whole day,
+// without a kickoff time, are left out. The opponent is taken from the
+// summary: the team named in the summaries of all events is the chat's, and
+// the other one its opponent. Recurrence rules are not expanded.
+func ReadICS(r io.Reader, loc *time.Location) (*services.FixturesImport, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, services.Invalid("file", services.ERR_FIXTURES_FILE, err)
+	}
+	lines := unfold(bytes.TrimPrefix(content, []byte("\ufeff")))
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, services.Invalid("file", services.ERR_FIXTURES_FILE, errNotCalendar)
+	}
+
+	in := &services.FixturesImport{}
+	var summaries []string
+	var event *services.ImportedFixture
+	var summary string
+	undated := false
+	// Components nested in events, such as alarms, are skipped.
+	nested := 0
+	for number, line := range lines {
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			return nil, services.Invalid("file", services.ERR_FIXTURES_FILE, fmt.Errorf("line %d: %q is not a property", number+1, line))
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && event == nil:
+			event = &services.ImportedFixture{}
+			summary, undated, nested = "", false, 0
+		case event == nil:
+		case name == "BEGIN":
+			nested++
+		case name == "END" && nested > 0:
+			nested--
+		case nested > 0:
+		case name == "END":
+			switch {
+			case undated || event.Date.IsZero():
+				in.Undated++
+			default:
+				if event.Uid == "" {
+					event.Uid = event.Date.UTC().Format(time.RFC3339) + " " + summary
+				}
+				in.Fixtures = append(in.Fixtures, *event)
+				summaries = append(summaries, summary)
+			}
+			event = nil
+		case name == "UID":
+			event.Uid = value
+		case name == "DTSTART":
+			date, hasTime, err := readDateTime(params, value, loc)
+			if err != nil {
+				return nil, services.Invalid("file", services.ERR_FIXTURES_FILE, fmt.Errorf("line %d: %w", number+1, err))
+			}
+			event.Date, undated = date, !hasTime
+		case name == "SUMMARY":
+			summary = strings.TrimSpace(unescape(value))
+		case name == "LOCATION":
+			event.Location = strings.TrimSpace(unescape(value))
+		case name == "STATUS":
+			event.Cancelled = strings.EqualFold(value, "CANCELLED")
+		}
+	}
+	if event != nil {
+		return nil, services.Invalid("file", services.ERR_FIXTURES_FILE, errors.New("event not ended"))
+	}
+
+	// Later events of the same UID are later versions of it.
+	uids := make(map[string]int, len(in.Fixtures))
+	fixtures := in.Fixtures[:0]
+	team := ownTeam(summaries)
+	for i, fixture := range in.Fixtures {
+		fixture.Opponent = opponent(summaries[i], team)
+		if j, ok := uids[fixture.Uid]; ok {
+			fixtures[j] = fixture
+			continue
+		}
+		uids[fixture.Uid] = len(fixtures)
+		fixtures = append(fixtures, fixture)
+	}
+	in.Fixtures = fixtures
+	return in, nil
+}
+
+// unfold joins the lines folded by a leading space or tab to the line before
+// them, leaving out blank lines.
+func unfold(content []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseLine splits a content line into its upper cased name, its parameters
+// with upper cased names, and its value.
+func parseLine(line string) (string, map[string]string, string, bool) {
+	// Parameter values may hold colons and semicolons inside quotes.
+	colon, quoted := -1, false
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return "", nil, "", false
+	}
+	fields := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(fields)-1)
+	for _, param := range fields[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return strings.ToUpper(fields[0]), params, line[colon+1:], true
+}
+
+// readDateTime reads a DATE-TIME value, in UTC when it ends in Z, in its TZID
+// or else in loc, or a DATE value, which has no time.
+func readDateTime(params map[string]string, value string, loc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		date, err := time.ParseInLocation("20060102", value, loc)
+		return date, false, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		date, err := time.Parse("20060102T150405Z", value)
+		return date, true, err
+	}
+	if tzid, ok := params["TZID"]; ok {
+		// Zones outside the IANA database, as some apps name them, fall back
+		// to the chat's.
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+	date, err := time.ParseInLocation("20060102T150405", value, loc)
+	return date, true, err
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescape(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+// ownTeam finds the team named on either side of the summaries of all
+// events, when there are at least two of them.
+func ownTeam(summaries []string) string {
+	counts := map[string]int{}
+	events := 0
+	for _, summary := range summaries {
+		home, away, ok := sides(summary)
+		if !ok {
+			continue
+		}
+		events++
+		counts[strings.ToLower(home)]++
+		if !strings.EqualFold(home, away) {
+			counts[strings.ToLower(away)]++
+		}
+	}
+	if events < 2 {
+		return ""
+	}
+	team := ""
+	for name, count := range counts {
+		if count == events {
+			if team != "" {
+				return ""
+			}
+			team = name
+		}
+	}
+	return team
+}
+
+func sides(summary string) (string, string, bool) {
+	parts := sidesPattern.Split(summary, -1)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
+
+func opponent(summary string, team string) string {
+	if loc := awayPattern.FindStringIndex(summary); loc != nil {
+		return strings.TrimSpace(summary[loc[1]:])
+	}
+	if home, away, ok := sides(summary); ok && team != "" {
+		switch {
+		case strings.EqualFold(home, team):
+			return away
+		case strings.EqualFold(away, team):
+			return home
+		}
+	}
+	return summary
+}
@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"tg-sunday-league/services"
+	"time"
+)
+
+func TestReadICS(t *testing.T) {
+	file := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:round-2@league",
+		"DTSTART;TZID=Asia/Singapore:20990111T110000",
+		"SUMMARY:United vs Sunday FC",
+		"LOCATION:Bishan\\, Pitch 2",
+		"BEGIN:VALARM",
+		"SUMMARY:Reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:round-1@league",
+		"DTSTART:20990104T030000Z",
+		"SUMMARY:Sunday FC v Rovers",
+		"LOCATION:Kal",
+		" lang",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cup@league",
+		"DTSTART:20990118T110000",
+		"SUMMARY:vs Athletic",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:training@league",
+		"DTSTART;VALUE=DATE:20990125",
+		"SUMMARY:Training",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	in, err := ReadICS(strings.NewReader(file), singapore)
+	if err != nil {
+		t.Fatalf("ReadICS: %v", err)
+	}
+	want := []services.ImportedFixture{
+		{Uid: "round-2@league", Date: time.Date(2099, time.January, 11, 3, 0, 0, 0, time.UTC), Location: "Bishan, Pitch 2", Opponent: "United"},
+		{Uid: "round-1@league", Date: time.Date(2099, time.January, 4, 3, 0, 0, 0, time.UTC), Location: "Kallang", Opponent: "Rovers"},
+		{Uid: "cup@league", Date: time.Date(2099, time.January, 18, 3, 0, 0, 0, time.UTC), Opponent: "Athletic", Cancelled: true},
+	}
+	if len(in.Fixtures) != len(want) {
+		t.Fatalf("fixtures = %+v, want %+v", in.Fixtures, want)
+	}
+	for i, fixture := range in.Fixtures {
+		if fixture.Uid != want[i].Uid || !fixture.Date.Equal(want[i].Date) || fixture.Location != want[i].Location || fixture.Opponent != want[i].Opponent || fixture.Cancelled != want[i].Cancelled {
+			t.Errorf("fixture %d = %+v, want %+v", i, fixture, want[i])
+		}
+	}
+	if in.Undated != 1 {
+		t.Errorf("undated = %d, want 1", in.Undated)
+	}
+}
+
+func TestReadICSKeepsLastVersion(t *testing.T) {
+	file := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\nDTSTART:20990104T030000Z\nSUMMARY:Rovers\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:1\nDTSTART:20990111T030000Z\nSUMMARY:United\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:1\nDTSTART:20990112T030000Z\nSUMMARY:United\nEND:VEVENT\n" +
+		"END:VCALENDAR\n"
+	in, err := ReadICS(strings.NewReader(file), singapore)
+	if err != nil {
+		t.Fatalf("ReadICS: %v", err)
+	}
+	if len(in.Fixtures) != 2 {
+		t.Fatalf("fixtures = %+v, want 2", in.Fixtures)
+	}
+	if in.Fixtures[0].Uid == "" || in.Fixtures[0].Opponent != "Rovers" {
+		t.Errorf("fixture without UID = %+v", in.Fixtures[0])
+	}
+	if in.Fixtures[1].Date.Day() != 12 {
+		t.Errorf("fixture 1 = %+v, want the last version", in.Fixtures[1])
+	}
+}
+
+func TestReadICSInvalid(t *testing.T) {
+	for name, file := range map[string]string{
+		"not a calendar": "Kickoff,Opponent\n2099-01-04,Rovers\n",
+		"bad date":       "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\nEND:VCALENDAR\n",
+		"unended event":  "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20990104T030000Z\n",
+	} {
+		_, err := ReadICS(strings.NewReader(file), singapore)
+		if serviceErr := services.AsError(err); serviceErr == nil || serviceErr.Code != services.ERR_FIXTURES_FILE {
+			t.Errorf("%s: err = %v, want %s", name, err, services.ERR_FIXTURES_FILE)
+		}
+	}
+}
+
+func TestOpponent(t *testing.T) {
+	team := ownTeam([]string{"Sunday FC - Rovers", "United @ Sunday FC", "Training"})
+	if team != "sunday fc" {
+		t.Fatalf("ownTeam = %q, want sunday fc", team)
+	}
+	for summary, want := range map[string]string{
+		"Sunday FC - Rovers": "Rovers",
+		"United @ Sunday FC": "United",
+		"Athletic x Albion":  "Athletic x Albion",
+		"Versus Ajax":        "Ajax",
+		"Training":           "Training",
+	} {
+		if got := opponent(summary, team); got != want {
+			t.Errorf("opponent(%q) = %q, want %q", summary, got, want)
+		}
+	}
+	if team := ownTeam([]string{"Sunday FC vs Rovers"}); team != "" {
+		t.Errorf("ownTeam of one event = %q, want none", team)
+	}
+}
+
+func TestIsICS(t *testing.T) {
+	if !IsICS("Fixtures.ICS", "") || !IsICS("fixtures", "text/calendar; charset=utf-8") || IsICS("games.csv", "text/csv") {
+		t.Error("IsICS misread a file")
+	}
+}
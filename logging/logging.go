@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// LEVELS are the names Setup accepts, from the most to the least verbose.
+var LEVELS = []string{"debug", "info", "warn", "error"}
+
+// Setup makes a JSON logger writing to w at level the default one. The
+// standard log package writes through it too.
+func Setup(w io.Writer, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lvl})))
+	return nil
+}
+
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("log level must be one of %s, not %q", strings.Join(LEVELS, ", "), level)
+}
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, so every layer handling
+// the same update logs with the same attributes.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of ctx, or the default logger if it has
+// none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		level   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"", slog.LevelInfo, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.level)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, error %v", tt.level, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestContextLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, nil)).With("chat_id", int64(-1001))
+	ctx := NewContext(context.Background(), logger)
+
+	FromContext(ctx).Info("handled", "command", "/in")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("log line %q: %v", out.String(), err)
+	}
+	if record["chat_id"] != float64(-1001) || record["command"] != "/in" || record["msg"] != "handled" {
+		t.Errorf("log line = %v, want chat_id, command and msg", record)
+	}
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext without a logger is not the default logger")
+	}
+}
@@ -1,44 +1,19 @@
 package main
 
 import (
-	"log"
-	"tg-sunday-league/bot"
-	"tg-sunday-league/config"
-	"tg-sunday-league/db"
-	"tg-sunday-league/repositories"
-	"tg-sunday-league/services"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"tg-sunday-league/cli"
+	_ "time/tzdata" // Time zone names must resolve even without system zoneinfo
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("Could not load config: %v", err)
-	}
-
-	// Connect to the SQLite database
-	dbInstance, dbErr := db.Connect(cfg.SqlliteDbPath)
-	if dbErr != nil {
-		log.Fatalf("Could not connect to database: %v", dbErr)
-	}
-	defer dbInstance.Close() // Ensure the DB connection closes when main exits
-
-	// Setup database tables
-	if err := db.SetupDatabase(dbInstance); err != nil {
-		log.Fatalf("Could not setup database: %v", err)
-	}
-
-	// Initialize GameRepository
-	gameRepo := &repositories.GameRepository{Db: dbInstance}
-	gameService := &services.GameService{GameRepository: gameRepo}
-	messageFormatter := &bot.MessageFormatter{}
-
-	// Start the bot with service dependency
-	b, err := bot.NewBot(cfg.BotToken, gameService, messageFormatter)
-	if err != nil {
-		log.Fatalf("Could not create bot: %v", err)
-	}
-
-	log.Println("Bot is running...")
-	b.TelegramBot.Start()
+	// Commands stop on SIGINT or SIGTERM, the bot after shutting down
+	// gracefully.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
 }
@@ -15,6 +15,54 @@ type Game struct {
 	Opponent  string    // Opponent for the game
 	Players   []User
 	CreatedBy uuid.UUID
+	Cancelled bool   // Whether the game was cancelled
+	Score     string // Final score as goals for and against, such as 3-1, empty if not recorded
+	Sequence  int    // Revision of the game, bumped whenever it changes so calendars update
+}
+
+// Fixture is a game the chat's league scheduled, waiting to be opened as a
+// Game once the chat has no upcoming one.
+type Fixture struct {
+	Id       uuid.UUID
+	ChatId   int64
+	Uid      string    // UID of the calendar event it was imported from
+	Date     time.Time // Kickoff of the fixture
+	Location string
+	Opponent string
+	GameId   uuid.UUID // Game opened from it, uuid.Nil while it waits
+}
+
+// Session signs a Telegram user in to the web dashboard of a chat, or is the
+// one-time login link that does, told apart by Kind.
+type Session struct {
+	TokenHash string // SHA-256 of the token, in hex
+	Kind      string
+	ChatId    int64
+	UserId    int64 // Telegram ID of the user signed in
+	ExpiresAt time.Time
+}
+
+// Webhook is a URL a chat registered to be sent the events of its games.
+type Webhook struct {
+	Id     uuid.UUID
+	ChatId int64
+	Url    string
+	Secret string // Key the payloads sent to Url are signed with
+}
+
+// OutboxMessage is an event waiting in the outbox to be delivered to a
+// webhook.
+type OutboxMessage struct {
+	Id            uuid.UUID
+	WebhookId     uuid.UUID
+	Url           string // Url and Secret of the webhook, as listed for delivery
+	Secret        string
+	EventId       uuid.UUID
+	EventType     string
+	Payload       []byte // JSON body to POST
+	Attempts      int    // Deliveries tried so far
+	NextAttemptAt time.Time
+	LastError     string // Why the last attempt failed, if it did
 }
 
 type User struct {
@@ -24,3 +72,20 @@ type User struct {
 	Status  string    // Status of the player (Attending, Not Attending, Paid)
 	HasPaid bool      // Whether the player has paid
 }
+
+// Attendance is a player's status in a game, as listed by exports.
+type Attendance struct {
+	GameId uuid.UUID
+	Player User // Player with their status and payment in the game
+}
+
+type ChatSettings struct {
+	ChatId          int64
+	Currency        string          // Currency shown next to prices
+	DefaultPrice    float64         // Price used when /new does not give one
+	SquadSize       int             // Number of players needed, 0 if not limited
+	Timezone        *time.Location  // Time zone kickoff times are read and shown in
+	Language        string          // Language code of the bot messages
+	ReminderOffsets []time.Duration // How long before kickoff reminders are sent
+	GameCreators    string          // Who may create games (admins, everyone)
+}
@@ -0,0 +1,49 @@
+package monitoring
+
+import (
+	"context"
+	"strconv"
+	"tg-sunday-league/logging"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// COLLECT_TIMEOUT bounds the queries run while serving a scrape.
+const COLLECT_TIMEOUT = 5 * time.Second
+
+var activeGamesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(NAMESPACE, "", "active_games"),
+	"Upcoming games that have not been cancelled, by chat.",
+	[]string{"chat_id"}, nil,
+)
+
+// activeGamesCollector counts the active games of every chat on each scrape.
+type activeGamesCollector struct {
+	count func(ctx context.Context) (map[int64]int, error)
+}
+
+// NewActiveGamesCollector exports the active games per chat, as counted by
+// count, such as a repository's CountUpcomingGamesByChat.
+func NewActiveGamesCollector(count func(ctx context.Context) (map[int64]int, error)) prometheus.Collector {
+	return &activeGamesCollector{count: count}
+}
+
+func (c *activeGamesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeGamesDesc
+}
+
+func (c *activeGamesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), COLLECT_TIMEOUT)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		// Leaving the metric out keeps the rest of the scrape working.
+		logging.FromContext(ctx).Error("Could not count active games", "error", err)
+		return
+	}
+	for chatID, count := range counts {
+		ch <- prometheus.MustNewConstMetric(activeGamesDesc, prometheus.GaugeValue, float64(count), strconv.FormatInt(chatID, 10))
+	}
+}
@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NAMESPACE prefixes the name of every metric the bot exports.
+const NAMESPACE = "sunday_league"
+
+var (
+	CommandsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "commands_total",
+		Help:      "Commands and button presses handled, by command.",
+	}, []string{"command"})
+
+	CommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "command_duration_seconds",
+		Help:      "Time spent handling a command, not counting the time it was queued, by command.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	ErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "errors_total",
+		Help:      "Errors reported to chats, by kind.",
+	}, []string{"kind"})
+
+	DbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time spent in game repository queries, by repository method.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"method"})
+
+	GameEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "game_events_total",
+		Help:      "Changes made to games, by event type.",
+	}, []string{"event"})
+
+	WebhookDeliveriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "webhook_deliveries_total",
+		Help:      "Attempts to deliver events to webhooks, by result: delivered, retried or failed.",
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(CommandsTotal, CommandDuration, ErrorsTotal, DbQueryDuration, GameEventsTotal, WebhookDeliveriesTotal)
+}
+
+// ObserveCommand counts command and records how long it took since started.
+func ObserveCommand(command string, started time.Time) {
+	CommandsTotal.WithLabelValues(command).Inc()
+	CommandDuration.WithLabelValues(command).Observe(time.Since(started).Seconds())
+}
+
+// TimeQuery starts timing the repository method and returns the function that
+// records it, meant to be deferred:
+//
+//	defer monitoring.TimeQuery("InsertGame")()
+func TimeQuery(method string) func() {
+	started := time.Now()
+	return func() {
+		DbQueryDuration.WithLabelValues(method).Observe(time.Since(started).Seconds())
+	}
+}
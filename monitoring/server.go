@@ -0,0 +1,87 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// CHECK_TIMEOUT bounds each health check.
+const CHECK_TIMEOUT = 5 * time.Second
+
+// Server answers health checks and Prometheus scrapes:
+//
+//	/healthz  runs every check in Checks, 503 if any fails
+//	/readyz   200 once SetReady(true) was called and until SetReady(false)
+//	/metrics  the metrics of Gatherer, the default registry if nil
+type Server struct {
+	Checks   map[string]func(ctx context.Context) error
+	Gatherer prometheus.Gatherer
+
+	ready atomic.Bool
+}
+
+// SetReady marks the bot as taking updates, or no longer taking them while it
+// shuts down.
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+func (s *Server) Handler() http.Handler {
+	gatherer := s.Gatherer
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	return mux
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(s.Checks))
+	for name := range s.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	response := healthResponse{Status: "ok", Checks: make(map[string]string, len(names))}
+	status := http.StatusOK
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(r.Context(), CHECK_TIMEOUT)
+		err := s.Checks[name](ctx)
+		cancel()
+		if err != nil {
+			response.Status = "failing"
+			response.Checks[name] = err.Error()
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		response.Checks[name] = "ok"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ready\n"))
+}
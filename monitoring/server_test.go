@@ -0,0 +1,121 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func get(t *testing.T, handler http.Handler, path string) (int, string) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := io.ReadAll(recorder.Body)
+	return recorder.Code, string(body)
+}
+
+func TestHealthz(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name       string
+		checks     map[string]func(ctx context.Context) error
+		wantStatus int
+		want       healthResponse
+	}{
+		{
+			name:       "all checks pass",
+			checks:     map[string]func(ctx context.Context) error{"database": ok, "telegram": ok},
+			wantStatus: http.StatusOK,
+			want:       healthResponse{Status: "ok", Checks: map[string]string{"database": "ok", "telegram": "ok"}},
+		},
+		{
+			name:       "a check fails",
+			checks:     map[string]func(ctx context.Context) error{"database": ok, "telegram": down},
+			wantStatus: http.StatusServiceUnavailable,
+			want:       healthResponse{Status: "failing", Checks: map[string]string{"database": "ok", "telegram": "connection refused"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{Checks: tt.checks, Gatherer: prometheus.NewRegistry()}
+
+			status, body := get(t, s.Handler(), "/healthz")
+
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+			var got healthResponse
+			if err := json.Unmarshal([]byte(body), &got); err != nil {
+				t.Fatalf("body %q: %v", body, err)
+			}
+			if got.Status != tt.want.Status || len(got.Checks) != len(tt.want.Checks) {
+				t.Fatalf("body = %+v, want %+v", got, tt.want)
+			}
+			for name, result := range tt.want.Checks {
+				if got.Checks[name] != result {
+					t.Errorf("check %s = %q, want %q", name, got.Checks[name], result)
+				}
+			}
+		})
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	s := &Server{Gatherer: prometheus.NewRegistry()}
+	handler := s.Handler()
+
+	if status, _ := get(t, handler, "/readyz"); status != http.StatusServiceUnavailable {
+		t.Errorf("status before SetReady = %d, want 503", status)
+	}
+	s.SetReady(true)
+	if status, _ := get(t, handler, "/readyz"); status != http.StatusOK {
+		t.Errorf("status when ready = %d, want 200", status)
+	}
+	s.SetReady(false)
+	if status, _ := get(t, handler, "/readyz"); status != http.StatusServiceUnavailable {
+		t.Errorf("status when shutting down = %d, want 503", status)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(CommandsTotal, NewActiveGamesCollector(func(ctx context.Context) (map[int64]int, error) {
+		return map[int64]int{-1001: 2}, nil
+	}))
+	ObserveCommand("/metrics_test", time.Now())
+	s := &Server{Gatherer: registry}
+
+	status, body := get(t, s.Handler(), "/metrics")
+
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	for _, want := range []string{
+		`sunday_league_commands_total{command="/metrics_test"} 1`,
+		`sunday_league_active_games{chat_id="-1001"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestActiveGamesCollectorSkipsFailedCount(t *testing.T) {
+	collector := NewActiveGamesCollector(func(ctx context.Context) (map[int64]int, error) {
+		return nil, errors.New("database is down")
+	})
+	if n := testutil.CollectAndCount(collector); n != 0 {
+		t.Errorf("collected %d metrics, want none", n)
+	}
+}
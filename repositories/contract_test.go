@@ -0,0 +1,606 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The contract every storage backend has to honour. Each backend test calls
+// these with a constructor returning repositories over an empty, migrated
+// database.
+
+type repositoryFactory func(t *testing.T) (repositories.IGameRepository, repositories.ISettingsRepository)
+
+func testRepositoryContract(t *testing.T, newRepositories repositoryFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, games repositories.IGameRepository, settings repositories.ISettingsRepository)
+	}{
+		{"LatestGameOfEmptyChatIsNil", testLatestGameOfEmptyChat},
+		{"LatestGameIsLatestKickoff", testLatestGameIsLatestKickoff},
+		{"GamesAreScopedToChat", testGamesAreScopedToChat},
+		{"CancelledGameIsNotLatest", testCancelledGameIsNotLatest},
+		{"CancelBumpsSequence", testCancelBumpsSequence},
+		{"RescheduleGame", testRescheduleGame},
+		{"Users", testUsers},
+		{"DuplicateUserConflicts", testDuplicateUserConflicts},
+		{"GamePlayers", testGamePlayers},
+		{"DuplicateGamePlayerConflicts", testDuplicateGamePlayerConflicts},
+		{"ChatSettings", testChatSettings},
+		{"CancelledContextFails", testCancelledContextFails},
+		{"CountUpcomingGamesByChat", testCountUpcomingGamesByChat},
+		{"ListGamesByChatID", testListGamesByChatID},
+		{"GetGameById", testGetGameById},
+		{"GetUserByUUID", testGetUserByUUID},
+		{"MergeUsers", testMergeUsers},
+		{"ListGamesBetween", testListGamesBetween},
+		{"ListAttendanceBetween", testListAttendanceBetween},
+		{"GameScore", testGameScore},
+		{"ListChatPlayers", testListChatPlayers},
+		{"Fixtures", testFixtures},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			games, settings := newRepositories(t)
+			tt.run(t, games, settings)
+		})
+	}
+}
+
+var kickoff = time.Date(2030, time.March, 10, 11, 0, 0, 0, time.UTC)
+
+func newGame(chatID int64, date time.Time) *models.Game {
+	return &models.Game{
+		Id:        uuid.New(),
+		ChatId:    chatID,
+		Price:     12.5,
+		Date:      date,
+		Location:  "Park",
+		Opponent:  "Rovers",
+		CreatedBy: uuid.New(),
+	}
+}
+
+func newUser(userID int64, name string) *models.User {
+	return &models.User{Id: uuid.New(), UserId: userID, Name: name, Status: "attending"}
+}
+
+func mustInsertGame(t *testing.T, repo repositories.IGameRepository, game *models.Game) {
+	t.Helper()
+	if _, err := repo.InsertGame(context.Background(), game); err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+}
+
+func mustInsertUser(t *testing.T, repo repositories.IGameRepository, user *models.User) {
+	t.Helper()
+	if _, err := repo.InsertUser(context.Background(), user); err != nil {
+		t.Fatalf("InsertUser: %v", err)
+	}
+}
+
+func testLatestGameOfEmptyChat(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	game, err := repo.GetLatestGameByChatID(context.Background(), 1)
+	if err != nil || game != nil {
+		t.Fatalf("GetLatestGameByChatID = %v, %v; want nil, nil", game, err)
+	}
+}
+
+func testLatestGameIsLatestKickoff(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	later := newGame(1, kickoff.Add(7*24*time.Hour))
+	// Kickoffs in other zones are stored as the same instant.
+	earlier := newGame(1, kickoff.In(time.FixedZone("UTC+8", 8*60*60)))
+	mustInsertGame(t, repo, later)
+	mustInsertGame(t, repo, earlier)
+
+	got, err := repo.GetLatestGameByChatID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetLatestGameByChatID: %v", err)
+	}
+	if got == nil {
+		t.Fatal("GetLatestGameByChatID = nil, want a game")
+	}
+	if got.Id != later.Id || got.ChatId != later.ChatId || got.Price != later.Price ||
+		got.Location != later.Location || got.Opponent != later.Opponent || got.CreatedBy != later.CreatedBy {
+		t.Errorf("GetLatestGameByChatID = %+v, want %+v", got, later)
+	}
+	if !got.Date.Equal(later.Date) {
+		t.Errorf("Date = %v, want %v", got.Date, later.Date)
+	}
+}
+
+func testGamesAreScopedToChat(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	mustInsertGame(t, repo, newGame(1, kickoff))
+
+	got, err := repo.GetLatestGameByChatID(context.Background(), 2)
+	if err != nil || got != nil {
+		t.Fatalf("GetLatestGameByChatID(2) = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func testCancelledGameIsNotLatest(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	game := newGame(1, kickoff)
+	mustInsertGame(t, repo, game)
+	if _, err := repo.CancelGame(context.Background(), game); err != nil {
+		t.Fatalf("CancelGame: %v", err)
+	}
+
+	got, err := repo.GetLatestGameByChatID(context.Background(), 1)
+	if err != nil || got != nil {
+		t.Fatalf("GetLatestGameByChatID = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func testCancelBumpsSequence(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	ctx := context.Background()
+	game := newGame(1, kickoff)
+	mustInsertGame(t, repo, game)
+	if got, err := repo.GetLatestGameByChatID(ctx, 1); err != nil || got.Sequence != 0 {
+		t.Fatalf("GetLatestGameByChatID = %+v, %v; want sequence 0", got, err)
+	}
+	cancelled, err := repo.CancelGame(ctx, game)
+	if err != nil {
+		t.Fatalf("CancelGame: %v", err)
+	}
+	if !cancelled.Cancelled || cancelled.Sequence != 1 || game.Cancelled {
+		t.Errorf("CancelGame = %+v, want the game cancelled at sequence 1, leaving its argument as it was", cancelled)
+	}
+
+	got, err := repo.GetGameById(ctx, game.Id)
+	if err != nil || !got.Cancelled || got.Sequence != 1 {
+		t.Errorf("GetGameById = %+v, %v; want cancelled at sequence 1", got, err)
+	}
+	games, err := repo.ListGamesByChatID(ctx, 1)
+	if err != nil || len(games) != 1 || games[0].Sequence != 1 {
+		t.Errorf("ListGamesByChatID = %+v, %v; want the game at sequence 1", games, err)
+	}
+}
+
+func testRescheduleGame(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	ctx := context.Background()
+	game := newGame(1, kickoff)
+	mustInsertGame(t, repo, game)
+
+	moved := *game
+	moved.Date, moved.Location, moved.Opponent = kickoff.Add(2*time.Hour), "Stadium", "United"
+	rescheduled, err := repo.RescheduleGame(ctx, &moved)
+	if err != nil {
+		t.Fatalf("RescheduleGame: %v", err)
+	}
+	if rescheduled.Sequence != 1 || moved.Sequence != 0 {
+		t.Errorf("RescheduleGame = %+v, want the game at sequence 1, leaving its argument as it was", rescheduled)
+	}
+
+	got, err := repo.GetGameById(ctx, game.Id)
+	if err != nil || !got.Date.Equal(moved.Date) || got.Location != "Stadium" || got.Opponent != "United" || got.Price != game.Price || got.Sequence != 1 || got.Cancelled {
+		t.Errorf("GetGameById = %+v, %v; want it moved at sequence 1, its price kept", got, err)
+	}
+}
+
+func testUsers(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	user := newUser(42, "Ana")
+	mustInsertUser(t, repo, user)
+
+	got, err := repo.GetUserByUserID(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("GetUserByUserID: %v", err)
+	}
+	if got == nil || got.Id != user.Id || got.UserId != 42 || got.Name != "Ana" {
+		t.Errorf("GetUserByUserID = %+v, want %+v", got, user)
+	}
+
+	telegramID := int64(42)
+	got, err = repo.GetUserById(context.Background(), &telegramID)
+	if err != nil || got == nil || got.Id != user.Id {
+		t.Errorf("GetUserById = %+v, %v; want %+v", got, err, user)
+	}
+
+	got, err = repo.GetUserByUserID(context.Background(), 43)
+	if err != nil || got != nil {
+		t.Errorf("GetUserByUserID(unknown) = %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func testDuplicateUserConflicts(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	user := newUser(42, "Ana")
+	mustInsertUser(t, repo, user)
+
+	_, err := repo.InsertUser(context.Background(), user)
+	if !errors.Is(err, repositories.ErrConflict) {
+		t.Fatalf("second InsertUser = %v, want ErrConflict", err)
+	}
+}
+
+func testGamePlayers(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	game := newGame(1, kickoff)
+	mustInsertGame(t, repo, game)
+	ana, bea := newUser(1, "Ana"), newUser(2, "Bea")
+	mustInsertUser(t, repo, ana)
+	mustInsertUser(t, repo, bea)
+
+	if _, err := repo.InsertGamePlayer(context.Background(), game, ana); err != nil {
+		t.Fatalf("InsertGamePlayer: %v", err)
+	}
+
+	id, err := repo.GetPlayerForGame(context.Background(), ana.Id, game.Id)
+	if err != nil || id == nil || *id != ana.Id {
+		t.Errorf("GetPlayerForGame(ana) = %v, %v; want %v", id, err, ana.Id)
+	}
+	id, err = repo.GetPlayerForGame(context.Background(), bea.Id, game.Id)
+	if err != nil || id != nil {
+		t.Errorf("GetPlayerForGame(bea) = %v, %v; want nil, nil", id, err)
+	}
+
+	if err := repo.UpdatePlayerGameStatus(context.Background(), game.Id, ana.Id, "out"); err != nil {
+		t.Fatalf("UpdatePlayerGameStatus: %v", err)
+	}
+	if err := repo.UpdatePlayerPayment(context.Background(), game.Id, ana.Id); err != nil {
+		t.Fatalf("UpdatePlayerPayment: %v", err)
+	}
+
+	players, err := repo.GetGamePlayers(context.Background(), game.Id)
+	if err != nil {
+		t.Fatalf("GetGamePlayers: %v", err)
+	}
+	if len(players) != 1 {
+		t.Fatalf("GetGamePlayers returned %d players, want 1", len(players))
+	}
+	got := players[0]
+	if got.Id != ana.Id || got.UserId != 1 || got.Name != "Ana" || got.Status != "out" || !got.HasPaid {
+		t.Errorf("GetGamePlayers = %+v, want Ana out and paid", got)
+	}
+}
+
+func testDuplicateGamePlayerConflicts(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	game := newGame(1, kickoff)
+	mustInsertGame(t, repo, game)
+	user := newUser(1, "Ana")
+	mustInsertUser(t, repo, user)
+	if _, err := repo.InsertGamePlayer(context.Background(), game, user); err != nil {
+		t.Fatalf("InsertGamePlayer: %v", err)
+	}
+
+	_, err := repo.InsertGamePlayer(context.Background(), game, user)
+	if !errors.Is(err, repositories.ErrConflict) {
+		t.Fatalf("second InsertGamePlayer = %v, want ErrConflict", err)
+	}
+}
+
+func testChatSettings(t *testing.T, _ repositories.IGameRepository, repo repositories.ISettingsRepository) {
+	got, err := repo.GetChatSettings(context.Background(), 1)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("GetChatSettings of new chat = %v, %v; want empty", got, err)
+	}
+
+	for _, value := range []string{"EUR", "SGD"} {
+		if err := repo.UpsertChatSetting(context.Background(), 1, "currency", value); err != nil {
+			t.Fatalf("UpsertChatSetting: %v", err)
+		}
+	}
+	if err := repo.UpsertChatSetting(context.Background(), 2, "currency", "BRL"); err != nil {
+		t.Fatalf("UpsertChatSetting: %v", err)
+	}
+
+	got, err = repo.GetChatSettings(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetChatSettings: %v", err)
+	}
+	if len(got) != 1 || got["currency"] != "SGD" {
+		t.Errorf("GetChatSettings = %v, want currency SGD only", got)
+	}
+}
+
+func testCancelledContextFails(t *testing.T, games repositories.IGameRepository, settings repositories.ISettingsRepository) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := games.InsertGame(ctx, newGame(1, kickoff)); !errors.Is(err, context.Canceled) {
+		t.Errorf("InsertGame = %v, want context.Canceled", err)
+	}
+	if _, err := games.GetLatestGameByChatID(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetLatestGameByChatID = %v, want context.Canceled", err)
+	}
+	if err := settings.UpsertChatSetting(ctx, 1, "currency", "EUR"); !errors.Is(err, context.Canceled) {
+		t.Errorf("UpsertChatSetting = %v, want context.Canceled", err)
+	}
+
+	got, err := games.GetLatestGameByChatID(context.Background(), 1)
+	if err != nil || got != nil {
+		t.Fatalf("GetLatestGameByChatID after cancelled insert = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func testCountUpcomingGamesByChat(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	mustInsertGame(t, repo, newGame(1, kickoff))
+	mustInsertGame(t, repo, newGame(1, kickoff.Add(7*24*time.Hour)))
+	mustInsertGame(t, repo, newGame(1, kickoff.Add(-time.Hour)))
+	mustInsertGame(t, repo, newGame(2, kickoff.In(time.FixedZone("UTC+8", 8*60*60))))
+	cancelled := newGame(3, kickoff)
+	mustInsertGame(t, repo, cancelled)
+	if _, err := repo.CancelGame(context.Background(), cancelled); err != nil {
+		t.Fatalf("CancelGame: %v", err)
+	}
+
+	got, err := repo.CountUpcomingGamesByChat(context.Background(), kickoff)
+	if err != nil {
+		t.Fatalf("CountUpcomingGamesByChat: %v", err)
+	}
+	if len(got) != 2 || got[1] != 2 || got[2] != 1 {
+		t.Errorf("CountUpcomingGamesByChat = %v, want map[1:2 2:1]", got)
+	}
+}
+
+func testListGamesByChatID(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	later, earlier, cancelled := newGame(1, kickoff.Add(time.Hour)), newGame(1, kickoff), newGame(1, kickoff.Add(2*time.Hour))
+	mustInsertGame(t, repo, later)
+	mustInsertGame(t, repo, earlier)
+	mustInsertGame(t, repo, cancelled)
+	mustInsertGame(t, repo, newGame(2, kickoff))
+	if _, err := repo.CancelGame(context.Background(), cancelled); err != nil {
+		t.Fatalf("CancelGame: %v", err)
+	}
+
+	games, err := repo.ListGamesByChatID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ListGamesByChatID: %v", err)
+	}
+	want := []*models.Game{earlier, later, cancelled}
+	if len(games) != len(want) {
+		t.Fatalf("ListGamesByChatID returned %d games, want %d", len(games), len(want))
+	}
+	for i, game := range games {
+		if game.Id != want[i].Id || !game.Date.Equal(want[i].Date) || game.Cancelled != (want[i] == cancelled) {
+			t.Errorf("game %d = %v at %v, cancelled %v; want %v at %v", i, game.Id, game.Date, game.Cancelled, want[i].Id, want[i].Date)
+		}
+	}
+}
+
+func testGetGameById(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	game := newGame(1, kickoff)
+	mustInsertGame(t, repo, game)
+	if _, err := repo.CancelGame(context.Background(), game); err != nil {
+		t.Fatalf("CancelGame: %v", err)
+	}
+
+	got, err := repo.GetGameById(context.Background(), game.Id)
+	if err != nil || got == nil {
+		t.Fatalf("GetGameById = %v, %v; want the game", got, err)
+	}
+	if got.ChatId != 1 || got.Opponent != "Rovers" || got.CreatedBy != game.CreatedBy || !got.Cancelled {
+		t.Errorf("GetGameById = %+v, want the cancelled game against Rovers", got)
+	}
+
+	got, err = repo.GetGameById(context.Background(), uuid.New())
+	if err != nil || got != nil {
+		t.Errorf("GetGameById(unknown) = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func testGetUserByUUID(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	ana := newUser(1, "Ana")
+	mustInsertUser(t, repo, ana)
+
+	got, err := repo.GetUserByUUID(context.Background(), ana.Id)
+	if err != nil || got == nil || got.UserId != 1 || got.Name != "Ana" {
+		t.Errorf("GetUserByUUID = %+v, %v; want Ana", got, err)
+	}
+	got, err = repo.GetUserByUUID(context.Background(), uuid.New())
+	if err != nil || got != nil {
+		t.Errorf("GetUserByUUID(unknown) = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func testMergeUsers(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	ctx := context.Background()
+	ana, duplicate := newUser(1, "Ana"), newUser(1, "Ana B")
+	mustInsertUser(t, repo, ana)
+	mustInsertUser(t, repo, duplicate)
+
+	// The duplicate played one game alone and one with Ana, paying for it.
+	alone, both := newGame(1, kickoff), newGame(1, kickoff.Add(time.Hour))
+	alone.CreatedBy = duplicate.Id
+	mustInsertGame(t, repo, alone)
+	mustInsertGame(t, repo, both)
+	for _, registration := range []struct {
+		game *models.Game
+		user *models.User
+	}{{alone, duplicate}, {both, ana}, {both, duplicate}} {
+		if _, err := repo.InsertGamePlayer(ctx, registration.game, registration.user); err != nil {
+			t.Fatalf("InsertGamePlayer: %v", err)
+		}
+	}
+	if err := repo.UpdatePlayerPayment(ctx, both.Id, duplicate.Id); err != nil {
+		t.Fatalf("UpdatePlayerPayment: %v", err)
+	}
+
+	if err := repo.MergeUsers(ctx, duplicate.Id, ana.Id); err != nil {
+		t.Fatalf("MergeUsers: %v", err)
+	}
+
+	if user, err := repo.GetUserByUUID(ctx, duplicate.Id); err != nil || user != nil {
+		t.Errorf("merged user still exists: %+v, %v", user, err)
+	}
+	for _, game := range []*models.Game{alone, both} {
+		players, err := repo.GetGamePlayers(ctx, game.Id)
+		if err != nil {
+			t.Fatalf("GetGamePlayers: %v", err)
+		}
+		if len(players) != 1 || players[0].Id != ana.Id {
+			t.Errorf("players of game at %v = %+v, want only Ana", game.Date, players)
+		}
+		if game == both && len(players) == 1 && !players[0].HasPaid {
+			t.Errorf("Ana has not paid for the game the duplicate paid for")
+		}
+	}
+	if game, err := repo.GetGameById(ctx, alone.Id); err != nil || game.CreatedBy != ana.Id {
+		t.Errorf("game created by %v, %v; want %v", game.CreatedBy, err, ana.Id)
+	}
+}
+
+func testListGamesBetween(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	before, first, cancelled, atEnd := newGame(1, kickoff.Add(-time.Minute)), newGame(1, kickoff), newGame(1, kickoff.Add(24*time.Hour)), newGame(1, kickoff.Add(48*time.Hour))
+	for _, game := range []*models.Game{atEnd, cancelled, first, before, newGame(2, kickoff)} {
+		mustInsertGame(t, repo, game)
+	}
+	if _, err := repo.CancelGame(context.Background(), cancelled); err != nil {
+		t.Fatalf("CancelGame: %v", err)
+	}
+
+	// from is inclusive and to exclusive.
+	games, err := repo.ListGamesBetween(context.Background(), 1, kickoff, kickoff.Add(48*time.Hour))
+	if err != nil {
+		t.Fatalf("ListGamesBetween: %v", err)
+	}
+	if len(games) != 2 || games[0].Id != first.Id || games[1].Id != cancelled.Id || !games[1].Cancelled {
+		t.Errorf("ListGamesBetween = %+v, want the first game and the cancelled one", games)
+	}
+}
+
+func testListAttendanceBetween(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	ctx := context.Background()
+	first, second, outside := newGame(1, kickoff), newGame(1, kickoff.Add(time.Hour)), newGame(1, kickoff.Add(-time.Hour))
+	for _, game := range []*models.Game{second, first, outside} {
+		mustInsertGame(t, repo, game)
+	}
+	ana, bea := newUser(1, "Ana"), newUser(2, "Bea")
+	mustInsertUser(t, repo, ana)
+	mustInsertUser(t, repo, bea)
+	for _, entry := range []struct {
+		game   *models.Game
+		player *models.User
+	}{{second, ana}, {first, bea}, {first, ana}, {outside, ana}} {
+		if _, err := repo.InsertGamePlayer(ctx, entry.game, entry.player); err != nil {
+			t.Fatalf("InsertGamePlayer: %v", err)
+		}
+	}
+	if err := repo.UpdatePlayerPayment(ctx, first.Id, bea.Id); err != nil {
+		t.Fatalf("UpdatePlayerPayment: %v", err)
+	}
+
+	attendance, err := repo.ListAttendanceBetween(ctx, 1, kickoff, kickoff.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("ListAttendanceBetween: %v", err)
+	}
+	want := []struct {
+		game *models.Game
+		name string
+		paid bool
+	}{{first, "Ana", false}, {first, "Bea", true}, {second, "Ana", false}}
+	if len(attendance) != len(want) {
+		t.Fatalf("ListAttendanceBetween returned %d rows, want %d: %+v", len(attendance), len(want), attendance)
+	}
+	for i, a := range attendance {
+		if a.GameId != want[i].game.Id || a.Player.Name != want[i].name || a.Player.HasPaid != want[i].paid || a.Player.Status != "attending" {
+			t.Errorf("row %d = %+v, want %s in game %v, paid %v", i, a, want[i].name, want[i].game.Id, want[i].paid)
+		}
+	}
+}
+
+func testGameScore(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	ctx := context.Background()
+	scored, unscored := newGame(1, kickoff), newGame(1, kickoff.Add(time.Hour))
+	scored.Score = "3-1"
+	mustInsertGame(t, repo, scored)
+	mustInsertGame(t, repo, unscored)
+
+	if game, err := repo.GetGameById(ctx, scored.Id); err != nil || game.Score != "3-1" {
+		t.Errorf("GetGameById = %+v, %v; want the score 3-1", game, err)
+	}
+	games, err := repo.ListGamesByChatID(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListGamesByChatID: %v", err)
+	}
+	if len(games) != 2 || games[0].Score != "3-1" || games[1].Score != "" {
+		t.Errorf("ListGamesByChatID = %+v, want the score only on the first game", games)
+	}
+}
+
+func testListChatPlayers(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	ctx := context.Background()
+	first, second, elsewhere := newGame(1, kickoff), newGame(1, kickoff.Add(time.Hour)), newGame(2, kickoff)
+	for _, game := range []*models.Game{first, second, elsewhere} {
+		mustInsertGame(t, repo, game)
+	}
+	ana, bea, caio, idle := newUser(1, "Ana"), newUser(2, "Bea"), newUser(3, "Caio"), newUser(4, "Dani")
+	for _, user := range []*models.User{bea, ana, caio, idle} {
+		mustInsertUser(t, repo, user)
+	}
+	for _, entry := range []struct {
+		game   *models.Game
+		player *models.User
+	}{{first, bea}, {second, bea}, {first, ana}, {elsewhere, caio}} {
+		if _, err := repo.InsertGamePlayer(ctx, entry.game, entry.player); err != nil {
+			t.Fatalf("InsertGamePlayer: %v", err)
+		}
+	}
+
+	players, err := repo.ListChatPlayers(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListChatPlayers: %v", err)
+	}
+	if len(players) != 2 || players[0].Id != ana.Id || players[1].Id != bea.Id {
+		t.Errorf("ListChatPlayers = %+v, want Ana and Bea once each", players)
+	}
+}
+
+func testFixtures(t *testing.T, repo repositories.IGameRepository, _ repositories.ISettingsRepository) {
+	ctx := context.Background()
+	later := &models.Fixture{Id: uuid.New(), ChatId: 1, Uid: "later@league", Date: kickoff.Add(7 * 24 * time.Hour), Location: "Park", Opponent: "United"}
+	first := &models.Fixture{Id: uuid.New(), ChatId: 1, Uid: "first@league", Date: kickoff, Opponent: "Rovers"}
+	other := &models.Fixture{Id: uuid.New(), ChatId: 2, Uid: "first@league", Date: kickoff}
+	for _, fixture := range []*models.Fixture{later, first, other} {
+		if err := repo.InsertFixture(ctx, fixture); err != nil {
+			t.Fatalf("InsertFixture(%s): %v", fixture.Uid, err)
+		}
+	}
+	again := *first
+	again.Id = uuid.New()
+	if err := repo.InsertFixture(ctx, &again); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("InsertFixture of a known UID = %v, want ErrConflict", err)
+	}
+
+	fixtures, err := repo.ListFixtures(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListFixtures: %v", err)
+	}
+	if len(fixtures) != 2 || fixtures[0].Id != first.Id || fixtures[1].Id != later.Id ||
+		fixtures[1].Location != "Park" || fixtures[1].Opponent != "United" || !fixtures[0].Date.Equal(kickoff) || fixtures[0].GameId != uuid.Nil {
+		t.Fatalf("ListFixtures = %+v, want first then later, neither opened", fixtures)
+	}
+
+	if chats, err := repo.ListFixtureChats(ctx, kickoff.Add(-time.Hour)); err != nil || len(chats) != 2 || chats[0] != 1 || chats[1] != 2 {
+		t.Errorf("ListFixtureChats before kickoff = %v, %v; want chats 1 and 2", chats, err)
+	}
+	game := newGame(1, first.Date)
+	mustInsertGame(t, repo, game)
+	if err := repo.SetFixtureGame(ctx, first.Id, game.Id); err != nil {
+		t.Fatalf("SetFixtureGame: %v", err)
+	}
+	if err := repo.DeleteFixture(ctx, other.Id); err != nil {
+		t.Fatalf("DeleteFixture: %v", err)
+	}
+	later.Date, later.Location = later.Date.Add(time.Hour), "Stadium"
+	if err := repo.UpdateFixture(ctx, later); err != nil {
+		t.Fatalf("UpdateFixture: %v", err)
+	}
+
+	if chats, err := repo.ListFixtureChats(ctx, kickoff.Add(-time.Hour)); err != nil || len(chats) != 1 || chats[0] != 1 {
+		t.Errorf("ListFixtureChats = %v, %v; want chat 1 only", chats, err)
+	}
+	if chats, err := repo.ListFixtureChats(ctx, later.Date); err != nil || len(chats) != 0 {
+		t.Errorf("ListFixtureChats after the last kickoff = %v, %v; want none", chats, err)
+	}
+	fixtures, err = repo.ListFixtures(ctx, 1)
+	if err != nil || len(fixtures) != 2 || fixtures[0].GameId != game.Id || fixtures[1].Location != "Stadium" || !fixtures[1].Date.Equal(later.Date) {
+		t.Errorf("ListFixtures = %+v, %v; want the first opened and the later moved", fixtures, err)
+	}
+	if fixtures, err := repo.ListFixtures(ctx, 2); err != nil || len(fixtures) != 0 {
+		t.Errorf("ListFixtures(2) = %+v, %v; want none", fixtures, err)
+	}
+}
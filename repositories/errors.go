@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrConflict is wrapped by errors from writes rejected by a primary key or
+// unique constraint, such as registering a player to the same game twice.
+var ErrConflict = errors.New("conflicting row already exists")
+
+// pqUniqueViolation is the SQLSTATE Postgres reports for duplicate keys.
+const pqUniqueViolation = "23505"
+
+// wrapError tags constraint violations with ErrConflict so callers can tell
+// them apart from other database failures.
+func wrapError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintPrimaryKey, sqlite3.ErrConstraintUnique:
+			return fmt.Errorf("%w: %v", ErrConflict, err)
+		}
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+	return err
+}
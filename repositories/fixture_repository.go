@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/monitoring"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InsertFixture stores a fixture waiting to be opened as a game.
+func (r *GameRepository) InsertFixture(ctx context.Context, fixture *models.Fixture) error {
+	defer monitoring.TimeQuery("InsertFixture")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO fixtures (id, chat_id, uid, date, location, opponent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		fixture.Id, fixture.ChatId, fixture.Uid, fixture.Date.UTC(), fixture.Location, fixture.Opponent, time.Now().UTC())
+	if err != nil {
+		return wrapError(err)
+	}
+	logging.FromContext(ctx).Debug("Fixture inserted", "fixture_id", fixture.Id)
+	return nil
+}
+
+// UpdateFixture changes the kickoff, location and opponent of the fixture.
+func (r *GameRepository) UpdateFixture(ctx context.Context, fixture *models.Fixture) error {
+	defer monitoring.TimeQuery("UpdateFixture")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE fixtures
+		SET date = ?,
+			location = ?,
+			opponent = ?
+		WHERE id = ?`, fixture.Date.UTC(), fixture.Location, fixture.Opponent, fixture.Id)
+	return err
+}
+
+func (r *GameRepository) DeleteFixture(ctx context.Context, fixtureId uuid.UUID) error {
+	defer monitoring.TimeQuery("DeleteFixture")()
+	_, err := r.Db.ExecContext(ctx, `DELETE FROM fixtures WHERE id = ?`, fixtureId)
+	return err
+}
+
+// ListFixtures returns every fixture of the chat, opened ones included, by
+// kickoff.
+func (r *GameRepository) ListFixtures(ctx context.Context, chatID int64) ([]models.Fixture, error) {
+	defer monitoring.TimeQuery("ListFixtures")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT
+			id,
+			chat_id,
+			uid,
+			date,
+			location,
+			opponent,
+			game_id
+		FROM fixtures
+		WHERE chat_id = ?
+		ORDER BY date, uid`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fixtures []models.Fixture
+	for rows.Next() {
+		var fixture models.Fixture
+		err := rows.Scan(&fixture.Id, &fixture.ChatId, &fixture.Uid, &fixture.Date, &fixture.Location, &fixture.Opponent, &fixture.GameId)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	return fixtures, rows.Err()
+}
+
+// ListFixtureChats returns the chats with fixtures waiting to be opened that
+// kick off after after.
+func (r *GameRepository) ListFixtureChats(ctx context.Context, after time.Time) ([]int64, error) {
+	defer monitoring.TimeQuery("ListFixtureChats")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT DISTINCT chat_id
+		FROM fixtures
+		WHERE game_id IS NULL
+		AND date > ?
+		ORDER BY chat_id`, after.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chats []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, err
+		}
+		chats = append(chats, chatID)
+	}
+
+	return chats, rows.Err()
+}
+
+// SetFixtureGame records the game opened from the fixture.
+func (r *GameRepository) SetFixtureGame(ctx context.Context, fixtureId uuid.UUID, gameId uuid.UUID) error {
+	defer monitoring.TimeQuery("SetFixtureGame")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE fixtures
+		SET game_id = ?
+		WHERE id = ?`, gameId, fixtureId)
+	return err
+}
@@ -1,41 +1,54 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"tg-sunday-league/logging"
 	"tg-sunday-league/models"
+	"tg-sunday-league/monitoring"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type IGameRepository interface {
-	InsertGame(game *models.Game) (*models.Game, error)
-	CancelGame(game *models.Game) (*models.Game, error)
-	GetLatestGameByChatID(chatID int64) (*models.Game, error)
-	InsertUser(user *models.User) (int64, error)
-	InsertGamePlayer(game *models.Game, player *models.User) (string, error)
-	GetUserById(playerId *int64) (*models.User, error)
-	GetUserByUserID(userId int64) (*models.User, error)
-	GetPlayerForGame(playerId uuid.UUID, gameId uuid.UUID) (*uuid.UUID, error)
-	GetGamePlayers(gameId uuid.UUID) ([]models.User, error)
-	UpdatePlayerPayment(gameId uuid.UUID, playerId uuid.UUID) error
-	UpdatePlayerGameStatus(gameId uuid.UUID, playerId uuid.UUID, status string) error
+	InsertGame(ctx context.Context, game *models.Game) (*models.Game, error)
+	CancelGame(ctx context.Context, game *models.Game) (*models.Game, error)
+	RescheduleGame(ctx context.Context, game *models.Game) (*models.Game, error)
+	GetLatestGameByChatID(ctx context.Context, chatID int64) (*models.Game, error)
+	InsertUser(ctx context.Context, user *models.User) (int64, error)
+	InsertGamePlayer(ctx context.Context, game *models.Game, player *models.User) (string, error)
+	GetUserById(ctx context.Context, playerId *int64) (*models.User, error)
+	GetUserByUserID(ctx context.Context, userId int64) (*models.User, error)
+	GetPlayerForGame(ctx context.Context, playerId uuid.UUID, gameId uuid.UUID) (*uuid.UUID, error)
+	GetGamePlayers(ctx context.Context, gameId uuid.UUID) ([]models.User, error)
+	UpdatePlayerPayment(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID) error
+	UpdatePlayerGameStatus(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID, status string) error
+	CountUpcomingGamesByChat(ctx context.Context, now time.Time) (map[int64]int, error)
+	ListGamesByChatID(ctx context.Context, chatID int64) ([]models.Game, error)
+	GetGameById(ctx context.Context, gameId uuid.UUID) (*models.Game, error)
+	GetUserByUUID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	MergeUsers(ctx context.Context, fromId uuid.UUID, intoId uuid.UUID) error
+	ListGamesBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Game, error)
+	ListAttendanceBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Attendance, error)
+	ListChatPlayers(ctx context.Context, chatID int64) ([]models.User, error)
+	InsertFixture(ctx context.Context, fixture *models.Fixture) error
+	UpdateFixture(ctx context.Context, fixture *models.Fixture) error
+	DeleteFixture(ctx context.Context, fixtureId uuid.UUID) error
+	ListFixtures(ctx context.Context, chatID int64) ([]models.Fixture, error)
+	ListFixtureChats(ctx context.Context, after time.Time) ([]int64, error)
+	SetFixtureGame(ctx context.Context, fixtureId uuid.UUID, gameId uuid.UUID) error
 }
 
 type GameRepository struct {
-	Db *sql.DB
+	Db DBTX
 }
 
 // InsertGame inserts a new game into the database
-func (r *GameRepository) InsertGame(game *models.Game) (*models.Game, error) {
-	tx, err := r.Db.Begin()
-	if err != nil {
-		return nil, err
-	}
-
+func (r *GameRepository) InsertGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	defer monitoring.TimeQuery("InsertGame")()
 	// Prepare the SQL statement
-	stmt, err := tx.Prepare(`
+	stmt, err := r.Db.PrepareContext(ctx, `
 		INSERT INTO games (
 			id, 
 			chat_id,
@@ -45,66 +58,76 @@ func (r *GameRepository) InsertGame(game *models.Game) (*models.Game, error) {
 			price, 
 			created_at, 
 			created_by, 
-			is_active
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			is_active,
+			score
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 	defer stmt.Close()
 
 	// Execute the SQL statement
-	_, err = stmt.Exec(&game.Id, &game.ChatId, &game.Opponent,
-		&game.Location, &game.Date, &game.Price, time.Now(), &game.CreatedBy, true)
+	// Kickoff is stored in UTC so games from chats in different time zones
+	// compare and sort as absolute instants.
+	_, err = stmt.ExecContext(ctx, &game.Id, &game.ChatId, &game.Opponent,
+		&game.Location, game.Date.UTC(), &game.Price, time.Now().UTC(), &game.CreatedBy, true, nullIfEmpty(game.Score))
 	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	log.Println("Game created and committed successfully.")
+	logging.FromContext(ctx).Debug("Game inserted", "game_id", game.Id)
 	return game, nil
 }
 
-func (r *GameRepository) CancelGame(game *models.Game) (*models.Game, error) {
-	tx, err := r.Db.Begin()
-	if err != nil {
-		return nil, err
-	}
-
-	stmt, err := tx.Prepare(
+func (r *GameRepository) CancelGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	defer monitoring.TimeQuery("CancelGame")()
+	stmt, err := r.Db.PrepareContext(ctx,
 		`UPDATE games
-		SET is_active = 0
+		SET is_active = 0,
+			sequence = sequence + 1
 		WHERE id = ?`)
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
 	defer stmt.Close()
-	_, err = stmt.Exec(game.Id)
+	_, err = stmt.ExecContext(ctx, game.Id)
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		tx.Rollback()
+	logging.FromContext(ctx).Debug("Game cancelled", "game_id", game.Id)
+	cancelled := *game
+	cancelled.Cancelled = true
+	cancelled.Sequence++
+	return &cancelled, nil
+}
+
+// RescheduleGame saves the kickoff, location and opponent of game, and
+// returns it with its sequence bumped.
+func (r *GameRepository) RescheduleGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	defer monitoring.TimeQuery("RescheduleGame")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE games
+		SET date = ?,
+			location = ?,
+			opponent = ?,
+			sequence = sequence + 1
+		WHERE id = ?`, game.Date.UTC(), game.Location, game.Opponent, game.Id)
+	if err != nil {
 		return nil, err
 	}
 
-	log.Println("Game cancelled and committed successfully.")
-	return game, nil
+	logging.FromContext(ctx).Debug("Game rescheduled", "game_id", game.Id)
+	rescheduled := *game
+	rescheduled.Sequence++
+	return &rescheduled, nil
 }
 
-func (r *GameRepository) GetLatestGameByChatID(chatID int64) (*models.Game, error) {
-	stmt, err := r.Db.Prepare(
+func (r *GameRepository) GetLatestGameByChatID(ctx context.Context, chatID int64) (*models.Game, error) {
+	defer monitoring.TimeQuery("GetLatestGameByChatID")()
+	stmt, err := r.Db.PrepareContext(ctx,
 		`SELECT 
 			id, 
 			chat_id, 
@@ -112,7 +135,9 @@ func (r *GameRepository) GetLatestGameByChatID(chatID int64) (*models.Game, erro
 			location, 
 			price,
 			date,
-			created_by
+			created_by,
+			COALESCE(score, ''),
+			sequence
 			FROM games 
 		WHERE chat_id = ? 
 		AND is_active = 1 
@@ -123,10 +148,10 @@ func (r *GameRepository) GetLatestGameByChatID(chatID int64) (*models.Game, erro
 	}
 
 	defer stmt.Close()
-	row := stmt.QueryRow(chatID)
+	row := stmt.QueryRowContext(ctx, chatID)
 
 	game := &models.Game{}
-	err = row.Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy)
+	err = row.Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy, &game.Score, &game.Sequence)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -137,48 +162,33 @@ func (r *GameRepository) GetLatestGameByChatID(chatID int64) (*models.Game, erro
 	return game, nil
 }
 
-func (r *GameRepository) InsertUser(user *models.User) (int64, error) {
+func (r *GameRepository) InsertUser(ctx context.Context, user *models.User) (int64, error) {
+	defer monitoring.TimeQuery("InsertUser")()
 
-	tx, err := r.Db.Begin()
-	if err != nil {
-		return 0, err
-	}
-	stmt, err := tx.Prepare(
+	stmt, err := r.Db.PrepareContext(ctx,
 		`INSERT INTO users (
 			id, user_id, name)
 		VALUES (?, ?, ?)`)
 	if err != nil {
-		tx.Rollback()
 		return 0, err
 	}
 	defer stmt.Close()
 
-	result, err := stmt.Exec(&user.Id, &user.UserId, &user.Name)
+	result, err := stmt.ExecContext(ctx, &user.Id, &user.UserId, &user.Name)
 	if err != nil {
-		tx.Rollback()
-		return 0, err
+		return 0, wrapError(err)
 	}
 
 	playerID, err := result.LastInsertId()
 	if err != nil {
-		tx.Rollback()
-		return 0, err
-	}
-
-	if err := tx.Commit(); err != nil {
-		tx.Rollback()
 		return 0, err
 	}
 	return playerID, nil
 }
 
-func (r *GameRepository) InsertGamePlayer(game *models.Game, player *models.User) (string, error) {
-	tx, err := r.Db.Begin()
-	if err != nil {
-		return "", err
-	}
-
-	stmt, err := tx.Prepare(
+func (r *GameRepository) InsertGamePlayer(ctx context.Context, game *models.Game, player *models.User) (string, error) {
+	defer monitoring.TimeQuery("InsertGamePlayer")()
+	stmt, err := r.Db.PrepareContext(ctx,
 		`INSERT INTO game_players (
 				game_id, 
 				user_id,
@@ -188,50 +198,27 @@ func (r *GameRepository) InsertGamePlayer(game *models.Game, player *models.User
 				(?, ?, ?, ?)
 		`)
 	if err != nil {
-		tx.Rollback()
 		return "", err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(&game.Id, &player.Id, &player.Status, false)
+	_, err = stmt.ExecContext(ctx, &game.Id, &player.Id, &player.Status, false)
 	if err != nil {
-		tx.Rollback()
-		return "", err
-	}
-
-	if err := tx.Commit(); err != nil {
-		tx.Rollback()
-		return "", err
+		return "", wrapError(err)
 	}
 
-	log.Println("User registered to game and committed successfully.")
+	logging.FromContext(ctx).Debug("Player registered to game", "game_id", game.Id, "player_id", player.Id)
 	return player.Name, nil
 }
 
-func (r *GameRepository) GetUserById(playerId *int64) (*models.User, error) {
-	stmt, err := r.Db.Prepare(
-		`SELECT id 
-		FROM players 
-		WHERE player_id = ?`)
-	if err != nil {
-		return nil, err
-	}
-	defer stmt.Close()
-
-	row := stmt.QueryRow(playerId)
-
-	var user models.User
-	err = row.Scan(&user.Id, &user.UserId, &user.Name)
-	if err != nil {
-		return nil, err
-	}
-
-	return &user, nil
-
+// GetUserById looks the user up by Telegram ID, like GetUserByUserID.
+func (r *GameRepository) GetUserById(ctx context.Context, playerId *int64) (*models.User, error) {
+	return r.GetUserByUserID(ctx, *playerId)
 }
 
-func (r *GameRepository) GetUserByUserID(userId int64) (*models.User, error) {
-	stmt, err := r.Db.Prepare(
+func (r *GameRepository) GetUserByUserID(ctx context.Context, userId int64) (*models.User, error) {
+	defer monitoring.TimeQuery("GetUserByUserID")()
+	stmt, err := r.Db.PrepareContext(ctx,
 		`SELECT 
 			id,
 			user_id,
@@ -243,7 +230,7 @@ func (r *GameRepository) GetUserByUserID(userId int64) (*models.User, error) {
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(userId)
+	row := stmt.QueryRowContext(ctx, userId)
 
 	var user models.User
 	err = row.Scan(&user.Id, &user.UserId, &user.Name)
@@ -257,20 +244,21 @@ func (r *GameRepository) GetUserByUserID(userId int64) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *GameRepository) GetPlayerForGame(playerId uuid.UUID, gameId uuid.UUID) (*uuid.UUID, error) {
-	stmt, err := r.Db.Prepare("SELECT user_id FROM game_players WHERE user_id = ? AND game_id = ?")
+func (r *GameRepository) GetPlayerForGame(ctx context.Context, playerId uuid.UUID, gameId uuid.UUID) (*uuid.UUID, error) {
+	defer monitoring.TimeQuery("GetPlayerForGame")()
+	stmt, err := r.Db.PrepareContext(ctx, "SELECT user_id FROM game_players WHERE user_id = ? AND game_id = ?")
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
 	var id uuid.UUID
-	row := stmt.QueryRow(playerId.String(), gameId.String())
+	row := stmt.QueryRowContext(ctx, playerId.String(), gameId.String())
 	err = row.Scan(&id)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("No player found for game_id %s and player_id %s", gameId.String(), playerId.String())
+			logging.FromContext(ctx).Debug("No player found for game", "game_id", gameId, "player_id", playerId)
 			return nil, nil
 		}
 		return nil, err
@@ -279,8 +267,9 @@ func (r *GameRepository) GetPlayerForGame(playerId uuid.UUID, gameId uuid.UUID)
 	return &id, nil
 }
 
-func (r *GameRepository) GetGamePlayers(gameId uuid.UUID) ([]models.User, error) {
-	stmt, err := r.Db.Prepare(
+func (r *GameRepository) GetGamePlayers(ctx context.Context, gameId uuid.UUID) ([]models.User, error) {
+	defer monitoring.TimeQuery("GetGamePlayers")()
+	stmt, err := r.Db.PrepareContext(ctx,
 		`SELECT 
 			u.id, 
 			u.user_id, 
@@ -296,7 +285,7 @@ func (r *GameRepository) GetGamePlayers(gameId uuid.UUID) ([]models.User, error)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(gameId.String())
+	rows, err := stmt.QueryContext(ctx, gameId.String())
 	if err != nil {
 		return nil, err
 	}
@@ -315,8 +304,9 @@ func (r *GameRepository) GetGamePlayers(gameId uuid.UUID) ([]models.User, error)
 	return players, nil
 }
 
-func (r *GameRepository) UpdatePlayerPayment(gameId uuid.UUID, playerId uuid.UUID) error {
-	stmt, err := r.Db.Prepare(
+func (r *GameRepository) UpdatePlayerPayment(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID) error {
+	defer monitoring.TimeQuery("UpdatePlayerPayment")()
+	stmt, err := r.Db.PrepareContext(ctx,
 		`UPDATE game_players 
 		SET has_paid = 1 
 		WHERE game_id = ? 
@@ -326,7 +316,7 @@ func (r *GameRepository) UpdatePlayerPayment(gameId uuid.UUID, playerId uuid.UUI
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(gameId.String(), playerId.String())
+	_, err = stmt.ExecContext(ctx, gameId.String(), playerId.String())
 	if err != nil {
 		return err
 	}
@@ -335,8 +325,9 @@ func (r *GameRepository) UpdatePlayerPayment(gameId uuid.UUID, playerId uuid.UUI
 
 }
 
-func (r *GameRepository) UpdatePlayerGameStatus(gameId uuid.UUID, playerId uuid.UUID, status string) error {
-	stmt, err := r.Db.Prepare(
+func (r *GameRepository) UpdatePlayerGameStatus(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID, status string) error {
+	defer monitoring.TimeQuery("UpdatePlayerGameStatus")()
+	stmt, err := r.Db.PrepareContext(ctx,
 		`UPDATE game_players 
 		SET status = ? 
 		WHERE game_id = ? 
@@ -346,10 +337,319 @@ func (r *GameRepository) UpdatePlayerGameStatus(gameId uuid.UUID, playerId uuid.
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(status, gameId.String(), playerId.String())
+	_, err = stmt.ExecContext(ctx, status, gameId.String(), playerId.String())
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// CountUpcomingGamesByChat counts the active games kicking off at or after now
+// in each chat. Chats without any are left out.
+func (r *GameRepository) CountUpcomingGamesByChat(ctx context.Context, now time.Time) (map[int64]int, error) {
+	defer monitoring.TimeQuery("CountUpcomingGamesByChat")()
+	stmt, err := r.Db.PrepareContext(ctx,
+		`SELECT
+			chat_id,
+			COUNT(*)
+		FROM games
+		WHERE is_active = 1
+		AND date >= ?
+		GROUP BY chat_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var chatID int64
+		var count int
+		if err := rows.Scan(&chatID, &count); err != nil {
+			return nil, err
+		}
+		counts[chatID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// ListGamesByChatID returns every game of the chat, cancelled ones included,
+// by kickoff.
+func (r *GameRepository) ListGamesByChatID(ctx context.Context, chatID int64) ([]models.Game, error) {
+	defer monitoring.TimeQuery("ListGamesByChatID")()
+	stmt, err := r.Db.PrepareContext(ctx,
+		`SELECT
+			id,
+			chat_id,
+			opponent,
+			location,
+			price,
+			date,
+			created_by,
+			is_active,
+			COALESCE(score, ''),
+			sequence
+		FROM games
+		WHERE chat_id = ?
+		ORDER BY date, created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []models.Game
+	for rows.Next() {
+		var game models.Game
+		var isActive bool
+		err := rows.Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy, &isActive, &game.Score, &game.Sequence)
+		if err != nil {
+			return nil, err
+		}
+		game.Cancelled = !isActive
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+// GetGameById returns the game, even if cancelled, or nil if there is none.
+func (r *GameRepository) GetGameById(ctx context.Context, gameId uuid.UUID) (*models.Game, error) {
+	defer monitoring.TimeQuery("GetGameById")()
+	stmt, err := r.Db.PrepareContext(ctx,
+		`SELECT
+			id,
+			chat_id,
+			opponent,
+			location,
+			price,
+			date,
+			created_by,
+			is_active,
+			COALESCE(score, ''),
+			sequence
+		FROM games
+		WHERE id = ?`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	game := &models.Game{}
+	var isActive bool
+	err = stmt.QueryRowContext(ctx, gameId.String()).Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy, &isActive, &game.Score, &game.Sequence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	game.Cancelled = !isActive
+
+	return game, nil
+}
+
+// GetUserByUUID looks the user up by the ID of its row rather than by
+// Telegram ID.
+func (r *GameRepository) GetUserByUUID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer monitoring.TimeQuery("GetUserByUUID")()
+	stmt, err := r.Db.PrepareContext(ctx,
+		`SELECT
+			id,
+			user_id,
+			name
+		FROM users
+		WHERE id = ?`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var user models.User
+	err = stmt.QueryRowContext(ctx, id.String()).Scan(&user.Id, &user.UserId, &user.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// MergeUsers moves the games of user fromId to user intoId and deletes
+// fromId. In games both played, intoId keeps its status and counts as paid if
+// either had paid. It should run in a unit of work, as it takes several
+// statements.
+func (r *GameRepository) MergeUsers(ctx context.Context, fromId uuid.UUID, intoId uuid.UUID) error {
+	defer monitoring.TimeQuery("MergeUsers")()
+	statements := []string{
+		`UPDATE game_players
+		SET has_paid = 1
+		WHERE user_id = ?2
+		AND game_id IN (SELECT game_id FROM game_players WHERE user_id = ?1 AND has_paid = 1)`,
+		`DELETE FROM game_players
+		WHERE user_id = ?1
+		AND game_id IN (SELECT game_id FROM game_players WHERE user_id = ?2)`,
+		`UPDATE game_players SET user_id = ?2 WHERE user_id = ?1`,
+		`UPDATE games SET created_by = ?2 WHERE created_by = ?1`,
+		`DELETE FROM users WHERE id = ?1`,
+	}
+	for _, statement := range statements {
+		if _, err := r.Db.ExecContext(ctx, statement, fromId.String(), intoId.String()); err != nil {
+			return err
+		}
+	}
+
+	logging.FromContext(ctx).Debug("Users merged", "from", fromId, "into", intoId)
+	return nil
+}
+
+// ListGamesBetween returns the games of the chat kicking off from from until
+// before to, cancelled ones included, by kickoff.
+func (r *GameRepository) ListGamesBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Game, error) {
+	defer monitoring.TimeQuery("ListGamesBetween")()
+	stmt, err := r.Db.PrepareContext(ctx,
+		`SELECT
+			id,
+			chat_id,
+			opponent,
+			location,
+			price,
+			date,
+			created_by,
+			is_active,
+			COALESCE(score, ''),
+			sequence
+		FROM games
+		WHERE chat_id = ?
+		AND date >= ?
+		AND date < ?
+		ORDER BY date, created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, chatID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []models.Game
+	for rows.Next() {
+		var game models.Game
+		var isActive bool
+		err := rows.Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy, &isActive, &game.Score, &game.Sequence)
+		if err != nil {
+			return nil, err
+		}
+		game.Cancelled = !isActive
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+// ListAttendanceBetween returns the players of the games ListGamesBetween
+// returns, by game and then by name.
+func (r *GameRepository) ListAttendanceBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Attendance, error) {
+	defer monitoring.TimeQuery("ListAttendanceBetween")()
+	stmt, err := r.Db.PrepareContext(ctx,
+		`SELECT
+			g.id,
+			u.id,
+			u.user_id,
+			u.name,
+			gp.status,
+			gp.has_paid
+		FROM games g
+		JOIN game_players gp
+		ON gp.game_id = g.id
+		JOIN users u
+		ON u.id = gp.user_id
+		WHERE g.chat_id = ?
+		AND g.date >= ?
+		AND g.date < ?
+		ORDER BY g.date, g.created_at, u.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, chatID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attendance []models.Attendance
+	for rows.Next() {
+		var a models.Attendance
+		err := rows.Scan(&a.GameId, &a.Player.Id, &a.Player.UserId, &a.Player.Name, &a.Player.Status, &a.Player.HasPaid)
+		if err != nil {
+			return nil, err
+		}
+		attendance = append(attendance, a)
+	}
+
+	return attendance, rows.Err()
+}
+
+// ListChatPlayers returns the users who answered for any game of the chat,
+// by name.
+func (r *GameRepository) ListChatPlayers(ctx context.Context, chatID int64) ([]models.User, error) {
+	defer monitoring.TimeQuery("ListChatPlayers")()
+	stmt, err := r.Db.PrepareContext(ctx,
+		`SELECT DISTINCT
+			u.id,
+			u.user_id,
+			u.name
+		FROM users u
+		JOIN game_players gp
+		ON gp.user_id = u.id
+		JOIN games g
+		ON g.id = gp.game_id
+		WHERE g.chat_id = ?
+		ORDER BY u.name, u.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.Id, &user.UserId, &user.Name); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// nullIfEmpty stores an empty optional column as NULL.
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *MemoryGameRepository) InsertFixture(ctx context.Context, fixture *models.Fixture) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range r.fixtures {
+		if f.Id == fixture.Id || (f.ChatId == fixture.ChatId && f.Uid == fixture.Uid) {
+			return fmt.Errorf("%w: fixture %s of chat %d", ErrConflict, fixture.Uid, fixture.ChatId)
+		}
+	}
+	stored := *fixture
+	stored.Date = fixture.Date.UTC()
+	stored.GameId = uuid.Nil
+	r.fixtures = append(r.fixtures, stored)
+	return nil
+}
+
+func (r *MemoryGameRepository) UpdateFixture(ctx context.Context, fixture *models.Fixture) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.fixtures {
+		if r.fixtures[i].Id == fixture.Id {
+			r.fixtures[i].Date = fixture.Date.UTC()
+			r.fixtures[i].Location = fixture.Location
+			r.fixtures[i].Opponent = fixture.Opponent
+		}
+	}
+	return nil
+}
+
+func (r *MemoryGameRepository) DeleteFixture(ctx context.Context, fixtureId uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.fixtures[:0]
+	for _, f := range r.fixtures {
+		if f.Id != fixtureId {
+			kept = append(kept, f)
+		}
+	}
+	r.fixtures = kept
+	return nil
+}
+
+func (r *MemoryGameRepository) ListFixtures(ctx context.Context, chatID int64) ([]models.Fixture, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var fixtures []models.Fixture
+	for _, f := range r.fixtures {
+		if f.ChatId == chatID {
+			fixtures = append(fixtures, f)
+		}
+	}
+	sort.Slice(fixtures, func(i, j int) bool {
+		if !fixtures[i].Date.Equal(fixtures[j].Date) {
+			return fixtures[i].Date.Before(fixtures[j].Date)
+		}
+		return fixtures[i].Uid < fixtures[j].Uid
+	})
+	return fixtures, nil
+}
+
+func (r *MemoryGameRepository) ListFixtureChats(ctx context.Context, after time.Time) ([]int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[int64]bool)
+	var chats []int64
+	for _, f := range r.fixtures {
+		if f.GameId == uuid.Nil && f.Date.After(after) && !seen[f.ChatId] {
+			seen[f.ChatId] = true
+			chats = append(chats, f.ChatId)
+		}
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i] < chats[j] })
+	return chats, nil
+}
+
+func (r *MemoryGameRepository) SetFixtureGame(ctx context.Context, fixtureId uuid.UUID, gameId uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.fixtures {
+		if r.fixtures[i].Id == fixtureId {
+			r.fixtures[i].GameId = gameId
+		}
+	}
+	return nil
+}
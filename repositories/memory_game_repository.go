@@ -0,0 +1,433 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryGameRepository keeps everything in memory with the semantics of the
+// SQLite GameRepository, for tests and demos. It is safe for concurrent use.
+type MemoryGameRepository struct {
+	mu          sync.Mutex
+	games       []memoryGame
+	users       []models.User
+	gamePlayers []memoryGamePlayer
+	fixtures    []models.Fixture
+}
+
+type memoryGame struct {
+	game     models.Game
+	isActive bool
+}
+
+type memoryGamePlayer struct {
+	gameId  uuid.UUID
+	userId  uuid.UUID
+	status  string
+	hasPaid bool
+}
+
+func NewMemoryGameRepository() *MemoryGameRepository {
+	return &MemoryGameRepository{}
+}
+
+func (r *MemoryGameRepository) InsertGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, g := range r.games {
+		if g.game.Id == game.Id {
+			return nil, fmt.Errorf("%w: game %s", ErrConflict, game.Id)
+		}
+	}
+	stored := *game
+	stored.Date = game.Date.UTC()
+	stored.Players = nil
+	r.games = append(r.games, memoryGame{game: stored, isActive: true})
+	return game, nil
+}
+
+func (r *MemoryGameRepository) CancelGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cancelled := *game
+	for i := range r.games {
+		if r.games[i].game.Id == game.Id {
+			r.games[i].isActive = false
+			r.games[i].game.Sequence++
+			cancelled.Sequence = r.games[i].game.Sequence
+		}
+	}
+	cancelled.Cancelled = true
+	return &cancelled, nil
+}
+
+func (r *MemoryGameRepository) RescheduleGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rescheduled := *game
+	for i := range r.games {
+		if r.games[i].game.Id == game.Id {
+			stored := &r.games[i].game
+			stored.Date, stored.Location, stored.Opponent = game.Date, game.Location, game.Opponent
+			stored.Sequence++
+			rescheduled.Sequence = stored.Sequence
+		}
+	}
+	return &rescheduled, nil
+}
+
+// GetLatestGameByChatID returns the active game of the chat with the latest
+// kickoff, the first one inserted on ties.
+func (r *MemoryGameRepository) GetLatestGameByChatID(ctx context.Context, chatID int64) (*models.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var latest *models.Game
+	for i := range r.games {
+		g := &r.games[i]
+		if g.game.ChatId != chatID || !g.isActive {
+			continue
+		}
+		if latest == nil || g.game.Date.After(latest.Date) {
+			latest = &g.game
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	game := *latest
+	return &game, nil
+}
+
+// InsertUser returns the position of the user in insertion order, like the
+// row IDs SQLite hands out.
+func (r *MemoryGameRepository) InsertUser(ctx context.Context, user *models.User) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.Id == user.Id {
+			return 0, fmt.Errorf("%w: user %s", ErrConflict, user.Id)
+		}
+	}
+	r.users = append(r.users, models.User{Id: user.Id, UserId: user.UserId, Name: user.Name})
+	return int64(len(r.users)), nil
+}
+
+func (r *MemoryGameRepository) InsertGamePlayer(ctx context.Context, game *models.Game, player *models.User) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, gp := range r.gamePlayers {
+		if gp.gameId == game.Id && gp.userId == player.Id {
+			return "", fmt.Errorf("%w: player %s of game %s", ErrConflict, player.Id, game.Id)
+		}
+	}
+	r.gamePlayers = append(r.gamePlayers, memoryGamePlayer{gameId: game.Id, userId: player.Id, status: player.Status})
+	return player.Name, nil
+}
+
+// GetUserById looks the user up by Telegram ID, like GetUserByUserID.
+func (r *MemoryGameRepository) GetUserById(ctx context.Context, playerId *int64) (*models.User, error) {
+	return r.GetUserByUserID(ctx, *playerId)
+}
+
+func (r *MemoryGameRepository) GetUserByUserID(ctx context.Context, userId int64) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.UserId == userId {
+			user := u
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryGameRepository) GetPlayerForGame(ctx context.Context, playerId uuid.UUID, gameId uuid.UUID) (*uuid.UUID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, gp := range r.gamePlayers {
+		if gp.gameId == gameId && gp.userId == playerId {
+			id := gp.userId
+			return &id, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetGamePlayers returns the players in the order they joined. Like the SQL
+// join, registrations of unknown users are left out.
+func (r *MemoryGameRepository) GetGamePlayers(ctx context.Context, gameId uuid.UUID) ([]models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var players []models.User
+	for _, gp := range r.gamePlayers {
+		if gp.gameId != gameId {
+			continue
+		}
+		for _, u := range r.users {
+			if u.Id == gp.userId {
+				player := u
+				player.Status = gp.status
+				player.HasPaid = gp.hasPaid
+				players = append(players, player)
+				break
+			}
+		}
+	}
+	return players, nil
+}
+
+func (r *MemoryGameRepository) UpdatePlayerPayment(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.gamePlayers {
+		if r.gamePlayers[i].gameId == gameId && r.gamePlayers[i].userId == playerId {
+			r.gamePlayers[i].hasPaid = true
+		}
+	}
+	return nil
+}
+
+func (r *MemoryGameRepository) UpdatePlayerGameStatus(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID, status string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.gamePlayers {
+		if r.gamePlayers[i].gameId == gameId && r.gamePlayers[i].userId == playerId {
+			r.gamePlayers[i].status = status
+		}
+	}
+	return nil
+}
+
+func (r *MemoryGameRepository) CountUpcomingGamesByChat(ctx context.Context, now time.Time) (map[int64]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[int64]int)
+	for _, g := range r.games {
+		if g.isActive && !g.game.Date.Before(now) {
+			counts[g.game.ChatId]++
+		}
+	}
+	return counts, nil
+}
+
+// ListGamesByChatID returns the games of the chat by kickoff, in insertion
+// order on ties.
+func (r *MemoryGameRepository) ListGamesByChatID(ctx context.Context, chatID int64) ([]models.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var games []models.Game
+	for _, g := range r.games {
+		if g.game.ChatId == chatID {
+			game := g.game
+			game.Cancelled = !g.isActive
+			games = append(games, game)
+		}
+	}
+	sort.SliceStable(games, func(i, j int) bool { return games[i].Date.Before(games[j].Date) })
+	return games, nil
+}
+
+func (r *MemoryGameRepository) GetGameById(ctx context.Context, gameId uuid.UUID) (*models.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, g := range r.games {
+		if g.game.Id == gameId {
+			game := g.game
+			game.Cancelled = !g.isActive
+			return &game, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryGameRepository) GetUserByUUID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.Id == id {
+			user := u
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryGameRepository) MergeUsers(ctx context.Context, fromId uuid.UUID, intoId uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fromPaid := make(map[uuid.UUID]bool)
+	intoGames := make(map[uuid.UUID]bool)
+	for _, gp := range r.gamePlayers {
+		switch gp.userId {
+		case fromId:
+			fromPaid[gp.gameId] = gp.hasPaid
+		case intoId:
+			intoGames[gp.gameId] = true
+		}
+	}
+	var gamePlayers []memoryGamePlayer
+	for _, gp := range r.gamePlayers {
+		switch gp.userId {
+		case fromId:
+			if intoGames[gp.gameId] {
+				continue
+			}
+			gp.userId = intoId
+		case intoId:
+			gp.hasPaid = gp.hasPaid || fromPaid[gp.gameId]
+		}
+		gamePlayers = append(gamePlayers, gp)
+	}
+	r.gamePlayers = gamePlayers
+
+	for i := range r.games {
+		if r.games[i].game.CreatedBy == fromId {
+			r.games[i].game.CreatedBy = intoId
+		}
+	}
+	var users []models.User
+	for _, u := range r.users {
+		if u.Id != fromId {
+			users = append(users, u)
+		}
+	}
+	r.users = users
+	return nil
+}
+
+func (r *MemoryGameRepository) ListGamesBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Game, error) {
+	games, err := r.ListGamesByChatID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	var between []models.Game
+	for _, game := range games {
+		if !game.Date.Before(from) && game.Date.Before(to) {
+			between = append(between, game)
+		}
+	}
+	return between, nil
+}
+
+func (r *MemoryGameRepository) ListAttendanceBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Attendance, error) {
+	games, err := r.ListGamesBetween(ctx, chatID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var attendance []models.Attendance
+	for _, game := range games {
+		players, err := r.GetGamePlayers(ctx, game.Id)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(players, func(i, j int) bool { return players[i].Name < players[j].Name })
+		for _, player := range players {
+			attendance = append(attendance, models.Attendance{GameId: game.Id, Player: player})
+		}
+	}
+	return attendance, nil
+}
+
+func (r *MemoryGameRepository) ListChatPlayers(ctx context.Context, chatID int64) ([]models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inChat := make(map[uuid.UUID]bool)
+	for _, g := range r.games {
+		if g.game.ChatId == chatID {
+			inChat[g.game.Id] = true
+		}
+	}
+	played := make(map[uuid.UUID]bool)
+	for _, gp := range r.gamePlayers {
+		if inChat[gp.gameId] {
+			played[gp.userId] = true
+		}
+	}
+	var users []models.User
+	for _, u := range r.users {
+		if played[u.Id] {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Name != users[j].Name {
+			return users[i].Name < users[j].Name
+		}
+		return users[i].Id.String() < users[j].Id.String()
+	})
+	return users, nil
+}
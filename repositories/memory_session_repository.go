@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"tg-sunday-league/models"
+	"time"
+)
+
+// MemorySessionRepository is the in-memory ISessionRepository. It is safe
+// for concurrent use.
+type MemorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]models.Session
+}
+
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{sessions: make(map[string]models.Session)}
+}
+
+func (r *MemorySessionRepository) InsertSession(ctx context.Context, session *models.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sessions[session.TokenHash]; ok {
+		return fmt.Errorf("%w: session of chat %d", ErrConflict, session.ChatId)
+	}
+	stored := *session
+	stored.ExpiresAt = session.ExpiresAt.UTC()
+	r.sessions[session.TokenHash] = stored
+	return nil
+}
+
+func (r *MemorySessionRepository) GetSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (r *MemorySessionRepository) DeleteSession(ctx context.Context, tokenHash string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.sessions[tokenHash]
+	delete(r.sessions, tokenHash)
+	return ok, nil
+}
+
+func (r *MemorySessionRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, session := range r.sessions {
+		if !session.ExpiresAt.After(now) {
+			delete(r.sessions, hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+)
+
+// MemorySettingsRepository is the in-memory ISettingsRepository used next to
+// MemoryGameRepository. It is safe for concurrent use.
+type MemorySettingsRepository struct {
+	mu       sync.Mutex
+	settings map[int64]map[string]string
+}
+
+func NewMemorySettingsRepository() *MemorySettingsRepository {
+	return &MemorySettingsRepository{settings: make(map[int64]map[string]string)}
+}
+
+func (r *MemorySettingsRepository) GetChatSettings(ctx context.Context, chatID int64) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings := make(map[string]string, len(r.settings[chatID]))
+	for key, value := range r.settings[chatID] {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+func (r *MemorySettingsRepository) UpsertChatSetting(ctx context.Context, chatID int64, key string, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.settings[chatID] == nil {
+		r.settings[chatID] = make(map[string]string)
+	}
+	r.settings[chatID][key] = value
+	return nil
+}
@@ -0,0 +1,70 @@
+package repositories_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) (repositories.IGameRepository, repositories.ISettingsRepository) {
+		return repositories.NewMemoryGameRepository(), repositories.NewMemorySettingsRepository()
+	})
+}
+
+func TestMemoryTokenRepository(t *testing.T) {
+	testTokenRepositoryContract(t, func(t *testing.T) repositories.ITokenRepository {
+		return repositories.NewMemoryTokenRepository()
+	})
+}
+
+func TestMemorySessionRepository(t *testing.T) {
+	testSessionRepositoryContract(t, func(t *testing.T) repositories.ISessionRepository {
+		return repositories.NewMemorySessionRepository()
+	})
+}
+
+func TestMemoryWebhookRepository(t *testing.T) {
+	testWebhookRepositoryContract(t, func(t *testing.T) repositories.IWebhookRepository {
+		return repositories.NewMemoryWebhookRepository()
+	})
+}
+
+func TestMemoryUnitOfWork(t *testing.T) {
+	testUnitOfWorkContract(t, func(t *testing.T) repositories.IUnitOfWork {
+		return repositories.NewMemoryUnitOfWork(repositories.NewMemoryGameRepository(), repositories.NewMemorySettingsRepository(), repositories.NewMemoryWebhookRepository())
+	})
+}
+
+func TestMemoryGameRepositoryConcurrentRegistration(t *testing.T) {
+	repo := repositories.NewMemoryGameRepository()
+	game := newGame(1, kickoff)
+	mustInsertGame(t, repo, game)
+
+	const players = 50
+	var wg sync.WaitGroup
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := &models.User{Id: uuid.New(), UserId: int64(i), Name: "player", Status: "ATTENDING"}
+			if _, err := repo.InsertUser(context.Background(), user); err != nil {
+				t.Errorf("InsertUser: %v", err)
+				return
+			}
+			if _, err := repo.InsertGamePlayer(context.Background(), game, user); err != nil {
+				t.Errorf("InsertGamePlayer: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got, err := repo.GetGamePlayers(context.Background(), game.Id)
+	if err != nil || len(got) != players {
+		t.Fatalf("GetGamePlayers returned %d players, %v; want %d", len(got), err, players)
+	}
+}
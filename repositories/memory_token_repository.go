@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryTokenRepository is the in-memory ITokenRepository. It is safe for
+// concurrent use.
+type MemoryTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[memoryTokenKey]string
+}
+
+type memoryTokenKey struct {
+	chatID  int64
+	purpose string
+}
+
+func NewMemoryTokenRepository() *MemoryTokenRepository {
+	return &MemoryTokenRepository{tokens: make(map[memoryTokenKey]string)}
+}
+
+func (r *MemoryTokenRepository) InsertChatToken(ctx context.Context, chatID int64, purpose string, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryTokenKey{chatID, purpose}
+	if _, ok := r.tokens[key]; ok {
+		return nil
+	}
+	if err := r.checkUnique(key, token); err != nil {
+		return err
+	}
+	r.tokens[key] = token
+	return nil
+}
+
+func (r *MemoryTokenRepository) ReplaceChatToken(ctx context.Context, chatID int64, purpose string, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryTokenKey{chatID, purpose}
+	if err := r.checkUnique(key, token); err != nil {
+		return err
+	}
+	r.tokens[key] = token
+	return nil
+}
+
+// checkUnique fails like the unique index on tokens if token is taken by
+// another key.
+func (r *MemoryTokenRepository) checkUnique(key memoryTokenKey, token string) error {
+	for other, taken := range r.tokens {
+		if taken == token && other != key {
+			return fmt.Errorf("%w: token of chat %d", ErrConflict, other.chatID)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryTokenRepository) GetChatToken(ctx context.Context, chatID int64, purpose string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tokens[memoryTokenKey{chatID, purpose}], nil
+}
+
+func (r *MemoryTokenRepository) GetChatByToken(ctx context.Context, purpose string, token string) (int64, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, taken := range r.tokens {
+		if taken == token && key.purpose == purpose {
+			return key.chatID, true, nil
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"tg-sunday-league/models"
+)
+
+// MemoryUnitOfWork runs units of work on the in-memory repositories one at a
+// time, restoring their contents when one fails.
+type MemoryUnitOfWork struct {
+	mu       sync.Mutex
+	games    *MemoryGameRepository
+	settings *MemorySettingsRepository
+	webhooks *MemoryWebhookRepository
+}
+
+func NewMemoryUnitOfWork(games *MemoryGameRepository, settings *MemorySettingsRepository, webhooks *MemoryWebhookRepository) *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{games: games, settings: settings, webhooks: webhooks}
+}
+
+func (u *MemoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	games := u.games.snapshot()
+	settings := u.settings.snapshot()
+	webhooks := u.webhooks.snapshot()
+	if err := fn(ctx, Repositories{Games: u.games, Settings: u.settings, Webhooks: u.webhooks}); err != nil {
+		u.games.restore(games)
+		u.settings.restore(settings)
+		u.webhooks.restore(webhooks)
+		return err
+	}
+	return nil
+}
+
+type memoryGameSnapshot struct {
+	games       []memoryGame
+	users       []models.User
+	gamePlayers []memoryGamePlayer
+	fixtures    []models.Fixture
+}
+
+func (r *MemoryGameRepository) snapshot() memoryGameSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return memoryGameSnapshot{
+		games:       append([]memoryGame(nil), r.games...),
+		users:       append([]models.User(nil), r.users...),
+		gamePlayers: append([]memoryGamePlayer(nil), r.gamePlayers...),
+		fixtures:    append([]models.Fixture(nil), r.fixtures...),
+	}
+}
+
+func (r *MemoryGameRepository) restore(s memoryGameSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.games, r.users, r.gamePlayers, r.fixtures = s.games, s.users, s.gamePlayers, s.fixtures
+}
+
+func (r *MemorySettingsRepository) snapshot() map[int64]map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	settings := make(map[int64]map[string]string, len(r.settings))
+	for chatID, values := range r.settings {
+		copied := make(map[string]string, len(values))
+		for key, value := range values {
+			copied[key] = value
+		}
+		settings[chatID] = copied
+	}
+	return settings
+}
+
+func (r *MemorySettingsRepository) restore(settings map[int64]map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings = settings
+}
+
+type memoryWebhookSnapshot struct {
+	webhooks []models.Webhook
+	outbox   []memoryOutboxMessage
+}
+
+func (r *MemoryWebhookRepository) snapshot() memoryWebhookSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return memoryWebhookSnapshot{
+		webhooks: append([]models.Webhook(nil), r.webhooks...),
+		outbox:   append([]memoryOutboxMessage(nil), r.outbox...),
+	}
+}
+
+func (r *MemoryWebhookRepository) restore(s memoryWebhookSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks, r.outbox = s.webhooks, s.outbox
+}
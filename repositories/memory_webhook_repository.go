@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryWebhookRepository is the in-memory IWebhookRepository. It is safe
+// for concurrent use.
+type MemoryWebhookRepository struct {
+	mu       sync.Mutex
+	webhooks []models.Webhook
+	outbox   []memoryOutboxMessage
+}
+
+type memoryOutboxMessage struct {
+	models.OutboxMessage
+	createdAt   time.Time
+	deliveredAt time.Time
+	failedAt    time.Time
+}
+
+func NewMemoryWebhookRepository() *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{}
+}
+
+func (r *MemoryWebhookRepository) InsertWebhook(ctx context.Context, webhook *models.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.webhooks {
+		if existing.Id == webhook.Id || (existing.ChatId == webhook.ChatId && existing.Url == webhook.Url) {
+			return fmt.Errorf("%w: webhook of chat %d to %s", ErrConflict, webhook.ChatId, webhook.Url)
+		}
+	}
+	r.webhooks = append(r.webhooks, *webhook)
+	return nil
+}
+
+func (r *MemoryWebhookRepository) ListWebhooks(ctx context.Context, chatID int64) ([]models.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var webhooks []models.Webhook
+	for _, webhook := range r.webhooks {
+		if webhook.ChatId == chatID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].Url < webhooks[j].Url })
+	return webhooks, nil
+}
+
+func (r *MemoryWebhookRepository) DeleteWebhook(ctx context.Context, chatID int64, url string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, webhook := range r.webhooks {
+		if webhook.ChatId != chatID || webhook.Url != url {
+			continue
+		}
+		r.webhooks = append(r.webhooks[:i], r.webhooks[i+1:]...)
+		kept := r.outbox[:0]
+		for _, message := range r.outbox {
+			if message.WebhookId != webhook.Id {
+				kept = append(kept, message)
+			}
+		}
+		r.outbox = kept
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *MemoryWebhookRepository) InsertOutboxMessage(ctx context.Context, message *models.OutboxMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := false
+	for _, webhook := range r.webhooks {
+		found = found || webhook.Id == message.WebhookId
+	}
+	if !found {
+		return fmt.Errorf("webhook %s does not exist", message.WebhookId)
+	}
+	for _, existing := range r.outbox {
+		if existing.Id == message.Id {
+			return fmt.Errorf("%w: outbox message %s", ErrConflict, message.Id)
+		}
+	}
+	stored := memoryOutboxMessage{OutboxMessage: *message, createdAt: time.Now()}
+	stored.Url, stored.Secret, stored.Attempts, stored.LastError = "", "", 0, ""
+	stored.Payload = append([]byte(nil), message.Payload...)
+	stored.NextAttemptAt = message.NextAttemptAt.UTC()
+	r.outbox = append(r.outbox, stored)
+	return nil
+}
+
+func (r *MemoryWebhookRepository) ListDueMessages(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []memoryOutboxMessage
+	for _, message := range r.outbox {
+		if message.deliveredAt.IsZero() && message.failedAt.IsZero() && !message.NextAttemptAt.After(now) {
+			due = append(due, message)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].createdAt.Before(due[j].createdAt)
+	})
+	var messages []models.OutboxMessage
+	for _, message := range due {
+		if len(messages) == limit {
+			break
+		}
+		for _, webhook := range r.webhooks {
+			if webhook.Id == message.WebhookId {
+				message.Url, message.Secret = webhook.Url, webhook.Secret
+			}
+		}
+		message.Payload = append([]byte(nil), message.Payload...)
+		messages = append(messages, message.OutboxMessage)
+	}
+	return messages, nil
+}
+
+func (r *MemoryWebhookRepository) MarkMessageDelivered(ctx context.Context, messageId uuid.UUID, at time.Time) error {
+	return r.update(ctx, messageId, func(message *memoryOutboxMessage) {
+		message.deliveredAt = at
+		message.Attempts++
+		message.LastError = ""
+	})
+}
+
+func (r *MemoryWebhookRepository) RetryMessage(ctx context.Context, messageId uuid.UUID, attempts int, next time.Time, lastError string) error {
+	return r.update(ctx, messageId, func(message *memoryOutboxMessage) {
+		message.Attempts, message.NextAttemptAt, message.LastError = attempts, next.UTC(), lastError
+	})
+}
+
+func (r *MemoryWebhookRepository) MarkMessageFailed(ctx context.Context, messageId uuid.UUID, attempts int, lastError string, at time.Time) error {
+	return r.update(ctx, messageId, func(message *memoryOutboxMessage) {
+		message.Attempts, message.LastError, message.failedAt = attempts, lastError, at
+	})
+}
+
+func (r *MemoryWebhookRepository) DeleteFinishedMessages(ctx context.Context, before time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.outbox[:0]
+	for _, message := range r.outbox {
+		finished := (!message.deliveredAt.IsZero() && message.deliveredAt.Before(before)) ||
+			(!message.failedAt.IsZero() && message.failedAt.Before(before))
+		if !finished {
+			kept = append(kept, message)
+		}
+	}
+	r.outbox = kept
+	return nil
+}
+
+func (r *MemoryWebhookRepository) update(ctx context.Context, messageId uuid.UUID, change func(message *memoryOutboxMessage)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.outbox {
+		if r.outbox[i].Id == messageId {
+			change(&r.outbox[i])
+		}
+	}
+	return nil
+}
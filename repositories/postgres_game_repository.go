@@ -0,0 +1,493 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/monitoring"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostgresGameRepository is the IGameRepository for Postgres, where IDs are
+// native UUID columns, flags are BOOLEAN and kickoffs TIMESTAMPTZ.
+type PostgresGameRepository struct {
+	Db DBTX
+}
+
+func (r *PostgresGameRepository) InsertGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	defer monitoring.TimeQuery("InsertGame")()
+	stmt, err := r.Db.PrepareContext(ctx, `
+		INSERT INTO games (
+			id,
+			chat_id,
+			opponent,
+			location,
+			date,
+			price,
+			created_at,
+			created_by,
+			is_active,
+			score
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, TRUE, $9)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, game.Id, game.ChatId, game.Opponent,
+		game.Location, game.Date.UTC(), game.Price, time.Now().UTC(), game.CreatedBy, nullIfEmpty(game.Score))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	logging.FromContext(ctx).Debug("Game inserted", "game_id", game.Id)
+	return game, nil
+}
+
+func (r *PostgresGameRepository) CancelGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	defer monitoring.TimeQuery("CancelGame")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE games
+		SET is_active = FALSE,
+			sequence = sequence + 1
+		WHERE id = $1`, game.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("Game cancelled", "game_id", game.Id)
+	cancelled := *game
+	cancelled.Cancelled = true
+	cancelled.Sequence++
+	return &cancelled, nil
+}
+
+func (r *PostgresGameRepository) RescheduleGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	defer monitoring.TimeQuery("RescheduleGame")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE games
+		SET date = $1,
+			location = $2,
+			opponent = $3,
+			sequence = sequence + 1
+		WHERE id = $4`, game.Date.UTC(), game.Location, game.Opponent, game.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("Game rescheduled", "game_id", game.Id)
+	rescheduled := *game
+	rescheduled.Sequence++
+	return &rescheduled, nil
+}
+
+func (r *PostgresGameRepository) GetLatestGameByChatID(ctx context.Context, chatID int64) (*models.Game, error) {
+	defer monitoring.TimeQuery("GetLatestGameByChatID")()
+	row := r.Db.QueryRowContext(ctx,
+		`SELECT
+			id,
+			chat_id,
+			opponent,
+			location,
+			price,
+			date,
+			created_by,
+			COALESCE(score, ''),
+			sequence
+		FROM games
+		WHERE chat_id = $1
+		AND is_active
+		ORDER BY date DESC LIMIT 1`, chatID)
+
+	game := &models.Game{}
+	err := row.Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy, &game.Score, &game.Sequence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	game.Date = game.Date.UTC()
+	return game, nil
+}
+
+// InsertUser returns the number of rows inserted, as Postgres tables have no
+// implicit row IDs to report.
+func (r *PostgresGameRepository) InsertUser(ctx context.Context, user *models.User) (int64, error) {
+	defer monitoring.TimeQuery("InsertUser")()
+	result, err := r.Db.ExecContext(ctx,
+		`INSERT INTO users (
+			id, user_id, name)
+		VALUES ($1, $2, $3)`, user.Id, user.UserId, user.Name)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *PostgresGameRepository) InsertGamePlayer(ctx context.Context, game *models.Game, player *models.User) (string, error) {
+	defer monitoring.TimeQuery("InsertGamePlayer")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO game_players (
+			game_id,
+			user_id,
+			status,
+			has_paid
+		) VALUES ($1, $2, $3, FALSE)`, game.Id, player.Id, player.Status)
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	logging.FromContext(ctx).Debug("Player registered to game", "game_id", game.Id, "player_id", player.Id)
+	return player.Name, nil
+}
+
+// GetUserById looks the user up by Telegram ID, like GetUserByUserID.
+func (r *PostgresGameRepository) GetUserById(ctx context.Context, playerId *int64) (*models.User, error) {
+	return r.GetUserByUserID(ctx, *playerId)
+}
+
+func (r *PostgresGameRepository) GetUserByUserID(ctx context.Context, userId int64) (*models.User, error) {
+	defer monitoring.TimeQuery("GetUserByUserID")()
+	row := r.Db.QueryRowContext(ctx,
+		`SELECT
+			id,
+			user_id,
+			name
+		FROM users
+		WHERE user_id = $1`, userId)
+
+	var user models.User
+	err := row.Scan(&user.Id, &user.UserId, &user.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *PostgresGameRepository) GetPlayerForGame(ctx context.Context, playerId uuid.UUID, gameId uuid.UUID) (*uuid.UUID, error) {
+	defer monitoring.TimeQuery("GetPlayerForGame")()
+	var id uuid.UUID
+	row := r.Db.QueryRowContext(ctx, "SELECT user_id FROM game_players WHERE user_id = $1 AND game_id = $2", playerId, gameId)
+	err := row.Scan(&id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logging.FromContext(ctx).Debug("No player found for game", "game_id", gameId, "player_id", playerId)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+func (r *PostgresGameRepository) GetGamePlayers(ctx context.Context, gameId uuid.UUID) ([]models.User, error) {
+	defer monitoring.TimeQuery("GetGamePlayers")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT
+			u.id,
+			u.user_id,
+			u.name,
+			gp.status,
+			gp.has_paid
+		FROM users u
+		JOIN game_players gp
+		ON u.id = gp.user_id
+		WHERE gp.game_id = $1`, gameId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var players []models.User
+	for rows.Next() {
+		var player models.User
+		err := rows.Scan(&player.Id, &player.UserId, &player.Name, &player.Status, &player.HasPaid)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, player)
+	}
+
+	return players, rows.Err()
+}
+
+func (r *PostgresGameRepository) UpdatePlayerPayment(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID) error {
+	defer monitoring.TimeQuery("UpdatePlayerPayment")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE game_players
+		SET has_paid = TRUE
+		WHERE game_id = $1
+		AND user_id = $2`, gameId, playerId)
+	return err
+}
+
+func (r *PostgresGameRepository) UpdatePlayerGameStatus(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID, status string) error {
+	defer monitoring.TimeQuery("UpdatePlayerGameStatus")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE game_players
+		SET status = $1
+		WHERE game_id = $2
+		AND user_id = $3`, status, gameId, playerId)
+	return err
+}
+
+func (r *PostgresGameRepository) CountUpcomingGamesByChat(ctx context.Context, now time.Time) (map[int64]int, error) {
+	defer monitoring.TimeQuery("CountUpcomingGamesByChat")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT
+			chat_id,
+			COUNT(*)
+		FROM games
+		WHERE is_active
+		AND date >= $1
+		GROUP BY chat_id`, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var chatID int64
+		var count int
+		if err := rows.Scan(&chatID, &count); err != nil {
+			return nil, err
+		}
+		counts[chatID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *PostgresGameRepository) ListGamesByChatID(ctx context.Context, chatID int64) ([]models.Game, error) {
+	defer monitoring.TimeQuery("ListGamesByChatID")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT
+			id,
+			chat_id,
+			opponent,
+			location,
+			price,
+			date,
+			created_by,
+			is_active,
+			COALESCE(score, ''),
+			sequence
+		FROM games
+		WHERE chat_id = $1
+		ORDER BY date, created_at`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []models.Game
+	for rows.Next() {
+		var game models.Game
+		var isActive bool
+		err := rows.Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy, &isActive, &game.Score, &game.Sequence)
+		if err != nil {
+			return nil, err
+		}
+		game.Cancelled = !isActive
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+func (r *PostgresGameRepository) GetGameById(ctx context.Context, gameId uuid.UUID) (*models.Game, error) {
+	defer monitoring.TimeQuery("GetGameById")()
+	row := r.Db.QueryRowContext(ctx,
+		`SELECT
+			id,
+			chat_id,
+			opponent,
+			location,
+			price,
+			date,
+			created_by,
+			is_active,
+			COALESCE(score, ''),
+			sequence
+		FROM games
+		WHERE id = $1`, gameId)
+
+	game := &models.Game{}
+	var isActive bool
+	err := row.Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy, &isActive, &game.Score, &game.Sequence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	game.Cancelled = !isActive
+
+	return game, nil
+}
+
+func (r *PostgresGameRepository) GetUserByUUID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer monitoring.TimeQuery("GetUserByUUID")()
+	row := r.Db.QueryRowContext(ctx,
+		`SELECT
+			id,
+			user_id,
+			name
+		FROM users
+		WHERE id = $1`, id)
+
+	var user models.User
+	if err := row.Scan(&user.Id, &user.UserId, &user.Name); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *PostgresGameRepository) MergeUsers(ctx context.Context, fromId uuid.UUID, intoId uuid.UUID) error {
+	defer monitoring.TimeQuery("MergeUsers")()
+	statements := []string{
+		`UPDATE game_players
+		SET has_paid = TRUE
+		WHERE user_id = $2
+		AND game_id IN (SELECT game_id FROM game_players WHERE user_id = $1 AND has_paid)`,
+		`DELETE FROM game_players
+		WHERE user_id = $1
+		AND game_id IN (SELECT game_id FROM game_players WHERE user_id = $2)`,
+		`UPDATE game_players SET user_id = $2 WHERE user_id = $1`,
+		`UPDATE games SET created_by = $2 WHERE created_by = $1`,
+		`DELETE FROM users WHERE id = $1`,
+	}
+	for _, statement := range statements {
+		if _, err := r.Db.ExecContext(ctx, statement, fromId, intoId); err != nil {
+			return err
+		}
+	}
+
+	logging.FromContext(ctx).Debug("Users merged", "from", fromId, "into", intoId)
+	return nil
+}
+
+func (r *PostgresGameRepository) ListGamesBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Game, error) {
+	defer monitoring.TimeQuery("ListGamesBetween")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT
+			id,
+			chat_id,
+			opponent,
+			location,
+			price,
+			date,
+			created_by,
+			is_active,
+			COALESCE(score, ''),
+			sequence
+		FROM games
+		WHERE chat_id = $1
+		AND date >= $2
+		AND date < $3
+		ORDER BY date, created_at`, chatID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []models.Game
+	for rows.Next() {
+		var game models.Game
+		var isActive bool
+		err := rows.Scan(&game.Id, &game.ChatId, &game.Opponent, &game.Location, &game.Price, &game.Date, &game.CreatedBy, &isActive, &game.Score, &game.Sequence)
+		if err != nil {
+			return nil, err
+		}
+		game.Date = game.Date.UTC()
+		game.Cancelled = !isActive
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+func (r *PostgresGameRepository) ListAttendanceBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Attendance, error) {
+	defer monitoring.TimeQuery("ListAttendanceBetween")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT
+			g.id,
+			u.id,
+			u.user_id,
+			u.name,
+			gp.status,
+			gp.has_paid
+		FROM games g
+		JOIN game_players gp
+		ON gp.game_id = g.id
+		JOIN users u
+		ON u.id = gp.user_id
+		WHERE g.chat_id = $1
+		AND g.date >= $2
+		AND g.date < $3
+		ORDER BY g.date, g.created_at, u.name`, chatID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attendance []models.Attendance
+	for rows.Next() {
+		var a models.Attendance
+		err := rows.Scan(&a.GameId, &a.Player.Id, &a.Player.UserId, &a.Player.Name, &a.Player.Status, &a.Player.HasPaid)
+		if err != nil {
+			return nil, err
+		}
+		attendance = append(attendance, a)
+	}
+
+	return attendance, rows.Err()
+}
+
+func (r *PostgresGameRepository) ListChatPlayers(ctx context.Context, chatID int64) ([]models.User, error) {
+	defer monitoring.TimeQuery("ListChatPlayers")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT DISTINCT
+			u.id,
+			u.user_id,
+			u.name
+		FROM users u
+		JOIN game_players gp
+		ON gp.user_id = u.id
+		JOIN games g
+		ON g.id = gp.game_id
+		WHERE g.chat_id = $1
+		ORDER BY u.name, u.id`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.Id, &user.UserId, &user.Name); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
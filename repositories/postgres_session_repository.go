@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"tg-sunday-league/models"
+	"tg-sunday-league/monitoring"
+	"time"
+)
+
+// PostgresSessionRepository is the ISessionRepository for Postgres.
+type PostgresSessionRepository struct {
+	Db DBTX
+}
+
+func (r *PostgresSessionRepository) InsertSession(ctx context.Context, session *models.Session) error {
+	defer monitoring.TimeQuery("InsertSession")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO web_sessions (token_hash, kind, chat_id, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)`,
+		session.TokenHash, session.Kind, session.ChatId, session.UserId, session.ExpiresAt.UTC())
+	return wrapError(err)
+}
+
+func (r *PostgresSessionRepository) GetSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	defer monitoring.TimeQuery("GetSession")()
+	session := &models.Session{}
+	err := r.Db.QueryRowContext(ctx,
+		`SELECT token_hash, kind, chat_id, user_id, expires_at
+		FROM web_sessions
+		WHERE token_hash = $1`, tokenHash).Scan(&session.TokenHash, &session.Kind, &session.ChatId, &session.UserId, &session.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *PostgresSessionRepository) DeleteSession(ctx context.Context, tokenHash string) (bool, error) {
+	defer monitoring.TimeQuery("DeleteSession")()
+	result, err := r.Db.ExecContext(ctx,
+		`DELETE FROM web_sessions
+		WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (r *PostgresSessionRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
+	defer monitoring.TimeQuery("DeleteExpiredSessions")()
+	_, err := r.Db.ExecContext(ctx,
+		`DELETE FROM web_sessions
+		WHERE expires_at <= $1`, now.UTC())
+	return err
+}
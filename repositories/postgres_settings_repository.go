@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+)
+
+// PostgresSettingsRepository is the ISettingsRepository for Postgres.
+type PostgresSettingsRepository struct {
+	Db DBTX
+}
+
+func (r *PostgresSettingsRepository) GetChatSettings(ctx context.Context, chatID int64) (map[string]string, error) {
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT
+			key,
+			value
+		FROM chat_settings
+		WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		err := rows.Scan(&key, &value)
+		if err != nil {
+			return nil, err
+		}
+		settings[key] = value
+	}
+
+	return settings, rows.Err()
+}
+
+func (r *PostgresSettingsRepository) UpsertChatSetting(ctx context.Context, chatID int64, key string, value string) error {
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO chat_settings (chat_id, key, value, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (chat_id, key) DO UPDATE
+		SET value = excluded.value,
+			updated_at = excluded.updated_at`, chatID, key, value)
+	return err
+}
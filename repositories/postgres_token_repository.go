@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"tg-sunday-league/monitoring"
+)
+
+// PostgresTokenRepository is the ITokenRepository for Postgres.
+type PostgresTokenRepository struct {
+	Db DBTX
+}
+
+func (r *PostgresTokenRepository) InsertChatToken(ctx context.Context, chatID int64, purpose string, token string) error {
+	defer monitoring.TimeQuery("InsertChatToken")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO chat_tokens (chat_id, purpose, token, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (chat_id, purpose) DO NOTHING`, chatID, purpose, token)
+	return wrapError(err)
+}
+
+func (r *PostgresTokenRepository) ReplaceChatToken(ctx context.Context, chatID int64, purpose string, token string) error {
+	defer monitoring.TimeQuery("ReplaceChatToken")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO chat_tokens (chat_id, purpose, token, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (chat_id, purpose) DO UPDATE
+		SET token = excluded.token,
+			created_at = excluded.created_at`, chatID, purpose, token)
+	return wrapError(err)
+}
+
+func (r *PostgresTokenRepository) GetChatToken(ctx context.Context, chatID int64, purpose string) (string, error) {
+	defer monitoring.TimeQuery("GetChatToken")()
+	var token string
+	err := r.Db.QueryRowContext(ctx,
+		`SELECT token
+		FROM chat_tokens
+		WHERE chat_id = $1
+		AND purpose = $2`, chatID, purpose).Scan(&token)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return token, err
+}
+
+func (r *PostgresTokenRepository) GetChatByToken(ctx context.Context, purpose string, token string) (int64, bool, error) {
+	defer monitoring.TimeQuery("GetChatByToken")()
+	var chatID int64
+	err := r.Db.QueryRowContext(ctx,
+		`SELECT chat_id
+		FROM chat_tokens
+		WHERE token = $1
+		AND purpose = $2`, token, purpose).Scan(&chatID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return chatID, true, nil
+}
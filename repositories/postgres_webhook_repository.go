@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"tg-sunday-league/models"
+	"tg-sunday-league/monitoring"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostgresWebhookRepository is the IWebhookRepository for Postgres.
+type PostgresWebhookRepository struct {
+	Db DBTX
+}
+
+func (r *PostgresWebhookRepository) InsertWebhook(ctx context.Context, webhook *models.Webhook) error {
+	defer monitoring.TimeQuery("InsertWebhook")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO webhooks (id, chat_id, url, secret, created_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)`,
+		webhook.Id, webhook.ChatId, webhook.Url, webhook.Secret)
+	return wrapError(err)
+}
+
+func (r *PostgresWebhookRepository) ListWebhooks(ctx context.Context, chatID int64) ([]models.Webhook, error) {
+	defer monitoring.TimeQuery("ListWebhooks")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT id, chat_id, url, secret
+		FROM webhooks
+		WHERE chat_id = $1
+		ORDER BY url`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		var webhook models.Webhook
+		if err := rows.Scan(&webhook.Id, &webhook.ChatId, &webhook.Url, &webhook.Secret); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) DeleteWebhook(ctx context.Context, chatID int64, url string) (bool, error) {
+	defer monitoring.TimeQuery("DeleteWebhook")()
+	_, err := r.Db.ExecContext(ctx,
+		`DELETE FROM webhook_outbox
+		WHERE webhook_id IN (SELECT id FROM webhooks WHERE chat_id = $1 AND url = $2)`, chatID, url)
+	if err != nil {
+		return false, err
+	}
+	result, err := r.Db.ExecContext(ctx,
+		`DELETE FROM webhooks
+		WHERE chat_id = $1 AND url = $2`, chatID, url)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (r *PostgresWebhookRepository) InsertOutboxMessage(ctx context.Context, message *models.OutboxMessage) error {
+	defer monitoring.TimeQuery("InsertOutboxMessage")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO webhook_outbox (id, webhook_id, event_id, event_type, payload, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, CURRENT_TIMESTAMP)`,
+		message.Id, message.WebhookId, message.EventId, message.EventType, string(message.Payload), message.NextAttemptAt.UTC())
+	return wrapError(err)
+}
+
+func (r *PostgresWebhookRepository) ListDueMessages(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error) {
+	defer monitoring.TimeQuery("ListDueMessages")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT o.id, o.webhook_id, w.url, w.secret, o.event_id, o.event_type, o.payload, o.attempts, o.next_attempt_at, o.last_error
+		FROM webhook_outbox o
+		JOIN webhooks w ON w.id = o.webhook_id
+		WHERE o.delivered_at IS NULL AND o.failed_at IS NULL AND o.next_attempt_at <= $1
+		ORDER BY o.next_attempt_at, o.created_at
+		LIMIT $2`, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxMessages(rows)
+}
+
+func (r *PostgresWebhookRepository) MarkMessageDelivered(ctx context.Context, messageId uuid.UUID, at time.Time) error {
+	defer monitoring.TimeQuery("MarkMessageDelivered")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET delivered_at = $1, attempts = attempts + 1, last_error = ''
+		WHERE id = $2`, at.UTC(), messageId)
+	return err
+}
+
+func (r *PostgresWebhookRepository) RetryMessage(ctx context.Context, messageId uuid.UUID, attempts int, next time.Time, lastError string) error {
+	defer monitoring.TimeQuery("RetryMessage")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET attempts = $1, next_attempt_at = $2, last_error = $3
+		WHERE id = $4`, attempts, next.UTC(), lastError, messageId)
+	return err
+}
+
+func (r *PostgresWebhookRepository) MarkMessageFailed(ctx context.Context, messageId uuid.UUID, attempts int, lastError string, at time.Time) error {
+	defer monitoring.TimeQuery("MarkMessageFailed")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET attempts = $1, last_error = $2, failed_at = $3
+		WHERE id = $4`, attempts, lastError, at.UTC(), messageId)
+	return err
+}
+
+func (r *PostgresWebhookRepository) DeleteFinishedMessages(ctx context.Context, before time.Time) error {
+	defer monitoring.TimeQuery("DeleteFinishedMessages")()
+	_, err := r.Db.ExecContext(ctx,
+		`DELETE FROM webhook_outbox
+		WHERE delivered_at < $1 OR failed_at < $2`, before.UTC(), before.UTC())
+	return err
+}
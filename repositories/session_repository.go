@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"tg-sunday-league/models"
+	"tg-sunday-league/monitoring"
+	"time"
+)
+
+type ISessionRepository interface {
+	InsertSession(ctx context.Context, session *models.Session) error
+	// GetSession returns the session whose token hashes to tokenHash, nil if
+	// there is none.
+	GetSession(ctx context.Context, tokenHash string) (*models.Session, error)
+	// DeleteSession deletes the session whose token hashes to tokenHash,
+	// reporting whether there was one.
+	DeleteSession(ctx context.Context, tokenHash string) (bool, error)
+	// DeleteExpiredSessions deletes the sessions expired at now.
+	DeleteExpiredSessions(ctx context.Context, now time.Time) error
+}
+
+type SessionRepository struct {
+	Db DBTX
+}
+
+func (r *SessionRepository) InsertSession(ctx context.Context, session *models.Session) error {
+	defer monitoring.TimeQuery("InsertSession")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO web_sessions (token_hash, kind, chat_id, user_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		session.TokenHash, session.Kind, session.ChatId, session.UserId, session.ExpiresAt.UTC())
+	return wrapError(err)
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	defer monitoring.TimeQuery("GetSession")()
+	session := &models.Session{}
+	err := r.Db.QueryRowContext(ctx,
+		`SELECT token_hash, kind, chat_id, user_id, expires_at
+		FROM web_sessions
+		WHERE token_hash = ?`, tokenHash).Scan(&session.TokenHash, &session.Kind, &session.ChatId, &session.UserId, &session.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *SessionRepository) DeleteSession(ctx context.Context, tokenHash string) (bool, error) {
+	defer monitoring.TimeQuery("DeleteSession")()
+	result, err := r.Db.ExecContext(ctx,
+		`DELETE FROM web_sessions
+		WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (r *SessionRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
+	defer monitoring.TimeQuery("DeleteExpiredSessions")()
+	_, err := r.Db.ExecContext(ctx,
+		`DELETE FROM web_sessions
+		WHERE expires_at <= ?`, now.UTC())
+	return err
+}
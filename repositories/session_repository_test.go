@@ -0,0 +1,96 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+// The contract every session repository has to honour. Each backend test
+// calls it with a constructor returning a repository over an empty, migrated
+// database.
+
+type sessionRepositoryFactory func(t *testing.T) repositories.ISessionRepository
+
+func testSessionRepositoryContract(t *testing.T, newRepository sessionRepositoryFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, sessions repositories.ISessionRepository)
+	}{
+		{"InsertThenGet", testInsertThenGetSession},
+		{"DeleteOnce", testDeleteSessionOnce},
+		{"DeleteExpired", testDeleteExpiredSessions},
+		{"TakenHashConflicts", testTakenSessionHashConflicts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepository(t))
+		})
+	}
+}
+
+func newSession(hash string, expiresAt time.Time) *models.Session {
+	return &models.Session{TokenHash: hash, Kind: "web", ChatId: -1001, UserId: 20, ExpiresAt: expiresAt}
+}
+
+func testInsertThenGetSession(t *testing.T, sessions repositories.ISessionRepository) {
+	ctx := context.Background()
+	if got, err := sessions.GetSession(ctx, "unknown"); err != nil || got != nil {
+		t.Fatalf("GetSession(unknown) = %+v, %v; want none", got, err)
+	}
+	session := newSession("hash", kickoff)
+	if err := sessions.InsertSession(ctx, session); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	got, err := sessions.GetSession(ctx, "hash")
+	if err != nil || got == nil || got.Kind != "web" || got.ChatId != -1001 || got.UserId != 20 || !got.ExpiresAt.Equal(kickoff) {
+		t.Errorf("GetSession = %+v, %v; want %+v", got, err, session)
+	}
+}
+
+func testDeleteSessionOnce(t *testing.T, sessions repositories.ISessionRepository) {
+	ctx := context.Background()
+	if err := sessions.InsertSession(ctx, newSession("hash", kickoff)); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	if deleted, err := sessions.DeleteSession(ctx, "hash"); err != nil || !deleted {
+		t.Errorf("first DeleteSession = %v, %v; want deleted", deleted, err)
+	}
+	if deleted, err := sessions.DeleteSession(ctx, "hash"); err != nil || deleted {
+		t.Errorf("second DeleteSession = %v, %v; want nothing deleted", deleted, err)
+	}
+	if got, err := sessions.GetSession(ctx, "hash"); err != nil || got != nil {
+		t.Errorf("GetSession after delete = %+v, %v; want none", got, err)
+	}
+}
+
+func testDeleteExpiredSessions(t *testing.T, sessions repositories.ISessionRepository) {
+	ctx := context.Background()
+	for _, session := range []*models.Session{newSession("expired", kickoff.Add(-time.Minute)), newSession("now", kickoff), newSession("live", kickoff.Add(time.Minute))} {
+		if err := sessions.InsertSession(ctx, session); err != nil {
+			t.Fatalf("InsertSession(%s): %v", session.TokenHash, err)
+		}
+	}
+	if err := sessions.DeleteExpiredSessions(ctx, kickoff); err != nil {
+		t.Fatalf("DeleteExpiredSessions: %v", err)
+	}
+	for hash, want := range map[string]bool{"expired": false, "now": false, "live": true} {
+		if got, err := sessions.GetSession(ctx, hash); err != nil || (got != nil) != want {
+			t.Errorf("GetSession(%s) = %+v, %v; want kept %v", hash, got, err, want)
+		}
+	}
+}
+
+func testTakenSessionHashConflicts(t *testing.T, sessions repositories.ISessionRepository) {
+	ctx := context.Background()
+	if err := sessions.InsertSession(ctx, newSession("hash", kickoff)); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	if err := sessions.InsertSession(ctx, newSession("hash", kickoff)); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("InsertSession of a taken hash = %v, want ErrConflict", err)
+	}
+}
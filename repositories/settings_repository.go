@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+)
+
+type ISettingsRepository interface {
+	GetChatSettings(ctx context.Context, chatID int64) (map[string]string, error)
+	UpsertChatSetting(ctx context.Context, chatID int64, key string, value string) error
+}
+
+type SettingsRepository struct {
+	Db DBTX
+}
+
+// GetChatSettings returns the raw values stored for the chat keyed by setting
+// name. Settings that were never changed are absent from the map.
+func (r *SettingsRepository) GetChatSettings(ctx context.Context, chatID int64) (map[string]string, error) {
+	stmt, err := r.Db.PrepareContext(ctx,
+		`SELECT
+			key,
+			value
+		FROM chat_settings
+		WHERE chat_id = ?`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		err := rows.Scan(&key, &value)
+		if err != nil {
+			return nil, err
+		}
+		settings[key] = value
+	}
+
+	return settings, rows.Err()
+}
+
+func (r *SettingsRepository) UpsertChatSetting(ctx context.Context, chatID int64, key string, value string) error {
+	stmt, err := r.Db.PrepareContext(ctx,
+		`INSERT INTO chat_settings (chat_id, key, value, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (chat_id, key) DO UPDATE
+		SET value = excluded.value,
+			updated_at = excluded.updated_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, chatID, key, value)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package repositories_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"tg-sunday-league/db"
+	"tg-sunday-league/repositories"
+)
+
+func TestSQLiteRepositories(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) (repositories.IGameRepository, repositories.ISettingsRepository) {
+		conn := newSQLiteDatabase(t)
+		return &repositories.GameRepository{Db: conn}, &repositories.SettingsRepository{Db: conn}
+	})
+}
+
+func TestSQLiteTokenRepository(t *testing.T) {
+	testTokenRepositoryContract(t, func(t *testing.T) repositories.ITokenRepository {
+		return &repositories.TokenRepository{Db: newSQLiteDatabase(t)}
+	})
+}
+
+func TestSQLiteSessionRepository(t *testing.T) {
+	testSessionRepositoryContract(t, func(t *testing.T) repositories.ISessionRepository {
+		return &repositories.SessionRepository{Db: newSQLiteDatabase(t)}
+	})
+}
+
+func TestSQLiteWebhookRepository(t *testing.T) {
+	testWebhookRepositoryContract(t, func(t *testing.T) repositories.IWebhookRepository {
+		return &repositories.WebhookRepository{Db: newSQLiteDatabase(t)}
+	})
+}
+
+func TestSQLiteUnitOfWork(t *testing.T) {
+	testUnitOfWorkContract(t, func(t *testing.T) repositories.IUnitOfWork {
+		return repositories.NewSqliteUnitOfWork(newSQLiteDatabase(t))
+	})
+}
+
+// newSQLiteDatabase returns a migrated database in a temporary file.
+func newSQLiteDatabase(t *testing.T) *sql.DB {
+	conn, err := db.Connect(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := db.SetupDatabase(conn, db.DRIVER_SQLITE, false); err != nil {
+		t.Fatalf("SetupDatabase: %v", err)
+	}
+	return conn
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"tg-sunday-league/monitoring"
+)
+
+type ITokenRepository interface {
+	// InsertChatToken stores token for the chat unless it has one for
+	// purpose already.
+	InsertChatToken(ctx context.Context, chatID int64, purpose string, token string) error
+	// ReplaceChatToken stores token for the chat in place of the one it has
+	// for purpose, if any.
+	ReplaceChatToken(ctx context.Context, chatID int64, purpose string, token string) error
+	// GetChatToken returns the chat's token for purpose, empty if it has none.
+	GetChatToken(ctx context.Context, chatID int64, purpose string) (string, error)
+	// GetChatByToken returns the chat token was given to for purpose, and
+	// false if there is none.
+	GetChatByToken(ctx context.Context, purpose string, token string) (int64, bool, error)
+}
+
+type TokenRepository struct {
+	Db DBTX
+}
+
+func (r *TokenRepository) InsertChatToken(ctx context.Context, chatID int64, purpose string, token string) error {
+	defer monitoring.TimeQuery("InsertChatToken")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO chat_tokens (chat_id, purpose, token, created_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (chat_id, purpose) DO NOTHING`, chatID, purpose, token)
+	return wrapError(err)
+}
+
+func (r *TokenRepository) ReplaceChatToken(ctx context.Context, chatID int64, purpose string, token string) error {
+	defer monitoring.TimeQuery("ReplaceChatToken")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO chat_tokens (chat_id, purpose, token, created_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (chat_id, purpose) DO UPDATE
+		SET token = excluded.token,
+			created_at = excluded.created_at`, chatID, purpose, token)
+	return wrapError(err)
+}
+
+func (r *TokenRepository) GetChatToken(ctx context.Context, chatID int64, purpose string) (string, error) {
+	defer monitoring.TimeQuery("GetChatToken")()
+	var token string
+	err := r.Db.QueryRowContext(ctx,
+		`SELECT token
+		FROM chat_tokens
+		WHERE chat_id = ?
+		AND purpose = ?`, chatID, purpose).Scan(&token)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return token, err
+}
+
+func (r *TokenRepository) GetChatByToken(ctx context.Context, purpose string, token string) (int64, bool, error) {
+	defer monitoring.TimeQuery("GetChatByToken")()
+	var chatID int64
+	err := r.Db.QueryRowContext(ctx,
+		`SELECT chat_id
+		FROM chat_tokens
+		WHERE token = ?
+		AND purpose = ?`, token, purpose).Scan(&chatID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return chatID, true, nil
+}
@@ -0,0 +1,98 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tg-sunday-league/repositories"
+)
+
+// The contract every token repository has to honour. Each backend test calls
+// it with a constructor returning a repository over an empty, migrated
+// database.
+
+type tokenRepositoryFactory func(t *testing.T) repositories.ITokenRepository
+
+func testTokenRepositoryContract(t *testing.T, newRepository tokenRepositoryFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, tokens repositories.ITokenRepository)
+	}{
+		{"InsertKeepsFirstToken", testInsertKeepsFirstToken},
+		{"ReplaceChangesToken", testReplaceChangesToken},
+		{"TokensArePerPurpose", testTokensArePerPurpose},
+		{"TakenTokenConflicts", testTakenTokenConflicts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepository(t))
+		})
+	}
+}
+
+func testInsertKeepsFirstToken(t *testing.T, tokens repositories.ITokenRepository) {
+	ctx := context.Background()
+	if token, err := tokens.GetChatToken(ctx, 1, "calendar"); err != nil || token != "" {
+		t.Fatalf("GetChatToken of new chat = %q, %v; want none", token, err)
+	}
+	for _, token := range []string{"first", "second"} {
+		if err := tokens.InsertChatToken(ctx, 1, "calendar", token); err != nil {
+			t.Fatalf("InsertChatToken(%s): %v", token, err)
+		}
+	}
+
+	if token, err := tokens.GetChatToken(ctx, 1, "calendar"); err != nil || token != "first" {
+		t.Errorf("GetChatToken = %q, %v; want first", token, err)
+	}
+	if chat, ok, err := tokens.GetChatByToken(ctx, "calendar", "first"); err != nil || !ok || chat != 1 {
+		t.Errorf("GetChatByToken(first) = %d, %v, %v; want chat 1", chat, ok, err)
+	}
+	if _, ok, err := tokens.GetChatByToken(ctx, "calendar", "second"); err != nil || ok {
+		t.Errorf("GetChatByToken(second) = %v, %v; want no chat", ok, err)
+	}
+}
+
+func testReplaceChangesToken(t *testing.T, tokens repositories.ITokenRepository) {
+	ctx := context.Background()
+	if err := tokens.InsertChatToken(ctx, 1, "calendar", "old"); err != nil {
+		t.Fatalf("InsertChatToken: %v", err)
+	}
+	if err := tokens.ReplaceChatToken(ctx, 1, "calendar", "new"); err != nil {
+		t.Fatalf("ReplaceChatToken: %v", err)
+	}
+
+	if token, err := tokens.GetChatToken(ctx, 1, "calendar"); err != nil || token != "new" {
+		t.Errorf("GetChatToken = %q, %v; want new", token, err)
+	}
+	if _, ok, err := tokens.GetChatByToken(ctx, "calendar", "old"); err != nil || ok {
+		t.Errorf("GetChatByToken(old) = %v, %v; want the old token revoked", ok, err)
+	}
+}
+
+func testTokensArePerPurpose(t *testing.T, tokens repositories.ITokenRepository) {
+	ctx := context.Background()
+	if err := tokens.InsertChatToken(ctx, 1, "calendar", "secret"); err != nil {
+		t.Fatalf("InsertChatToken: %v", err)
+	}
+
+	if token, err := tokens.GetChatToken(ctx, 1, "api"); err != nil || token != "" {
+		t.Errorf("GetChatToken(api) = %q, %v; want none", token, err)
+	}
+	if _, ok, err := tokens.GetChatByToken(ctx, "api", "secret"); err != nil || ok {
+		t.Errorf("GetChatByToken(api) = %v, %v; want no chat", ok, err)
+	}
+}
+
+func testTakenTokenConflicts(t *testing.T, tokens repositories.ITokenRepository) {
+	ctx := context.Background()
+	if err := tokens.InsertChatToken(ctx, 1, "calendar", "secret"); err != nil {
+		t.Fatalf("InsertChatToken: %v", err)
+	}
+
+	if err := tokens.InsertChatToken(ctx, 2, "calendar", "secret"); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("InsertChatToken for another chat = %v, want ErrConflict", err)
+	}
+	if err := tokens.ReplaceChatToken(ctx, 2, "calendar", "secret"); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("ReplaceChatToken for another chat = %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"tg-sunday-league/logging"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// DBTX is what the SQL repositories run their queries on: the connection pool,
+// or the transaction of a unit of work.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Repositories are the repositories a unit of work hands to its function,
+// all bound to the same transaction.
+type Repositories struct {
+	Games    IGameRepository
+	Settings ISettingsRepository
+	Webhooks IWebhookRepository
+}
+
+// IUnitOfWork runs fn atomically: everything fn writes through repos is kept
+// if it returns nil and discarded otherwise. fn may be run again when the
+// storage was too busy, so it must not have side effects besides repos.
+type IUnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
+
+// UOW_ATTEMPTS is how many times a unit of work is tried before the busy
+// error is returned.
+const UOW_ATTEMPTS = 5
+
+// UOW_RETRY_DELAY is the wait before the first retry. It doubles with every
+// attempt.
+const UOW_RETRY_DELAY = 10 * time.Millisecond
+
+// SqlUnitOfWork runs units of work in database transactions.
+type SqlUnitOfWork struct {
+	Db              *sql.DB
+	TxOptions       *sql.TxOptions
+	NewRepositories func(tx DBTX) Repositories
+}
+
+func NewSqliteUnitOfWork(db *sql.DB) *SqlUnitOfWork {
+	return &SqlUnitOfWork{
+		Db: db,
+		NewRepositories: func(tx DBTX) Repositories {
+			return Repositories{Games: &GameRepository{Db: tx}, Settings: &SettingsRepository{Db: tx}, Webhooks: &WebhookRepository{Db: tx}}
+		},
+	}
+}
+
+// NewPostgresUnitOfWork runs units of work in serializable transactions, so
+// two of them racing on the same rows make one fail and be retried, as they
+// do on SQLite.
+func NewPostgresUnitOfWork(db *sql.DB) *SqlUnitOfWork {
+	return &SqlUnitOfWork{
+		Db:        db,
+		TxOptions: &sql.TxOptions{Isolation: sql.LevelSerializable},
+		NewRepositories: func(tx DBTX) Repositories {
+			return Repositories{Games: &PostgresGameRepository{Db: tx}, Settings: &PostgresSettingsRepository{Db: tx}, Webhooks: &PostgresWebhookRepository{Db: tx}}
+		},
+	}
+}
+
+func (u *SqlUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	delay := UOW_RETRY_DELAY
+	for attempt := 1; ; attempt++ {
+		err := u.run(ctx, fn)
+		if err == nil || !isBusy(err) || attempt == UOW_ATTEMPTS {
+			return err
+		}
+		logging.FromContext(ctx).Warn("Database busy, retrying transaction", "attempt", attempt, "error", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+func (u *SqlUnitOfWork) run(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	tx, err := u.Db.BeginTx(ctx, u.TxOptions)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(ctx, u.NewRepositories(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// pqSerializationFailure and pqDeadlockDetected are the SQLSTATEs Postgres
+// reports when a transaction lost a race and should be retried.
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+// isBusy reports whether err means the transaction collided with another one
+// and may succeed if run again.
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+	}
+	return false
+}
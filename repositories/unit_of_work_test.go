@@ -0,0 +1,207 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+// The contract every unit of work has to honour. Each backend test calls it
+// with a constructor returning a unit of work over an empty, migrated
+// database.
+
+type unitOfWorkFactory func(t *testing.T) repositories.IUnitOfWork
+
+func testUnitOfWorkContract(t *testing.T, newUnitOfWork unitOfWorkFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, uow repositories.IUnitOfWork)
+	}{
+		{"CommitsOnSuccess", testUnitOfWorkCommits},
+		{"RollsBackOnError", testUnitOfWorkRollsBack},
+		{"RollsBackOutboxMessages", testUnitOfWorkRollsBackOutbox},
+		{"ConcurrentRegistrationsDoNotDuplicate", testUnitOfWorkConcurrentRegistrations},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newUnitOfWork(t))
+		})
+	}
+}
+
+var errAbort = errors.New("abort")
+
+func testUnitOfWorkCommits(t *testing.T, uow repositories.IUnitOfWork) {
+	user := newUser(42, "Ana")
+	err := uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		if _, err := repos.Games.InsertUser(ctx, user); err != nil {
+			return err
+		}
+		return repos.Settings.UpsertChatSetting(ctx, 1, "currency", "EUR")
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	err = uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		got, err := repos.Games.GetUserByUserID(ctx, 42)
+		if err != nil || got == nil || got.Id != user.Id {
+			t.Errorf("GetUserByUserID = %+v, %v; want %+v", got, err, user)
+		}
+		settings, err := repos.Settings.GetChatSettings(ctx, 1)
+		if err != nil || settings["currency"] != "EUR" {
+			t.Errorf("GetChatSettings = %v, %v; want currency EUR", settings, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+}
+
+func testUnitOfWorkRollsBack(t *testing.T, uow repositories.IUnitOfWork) {
+	err := uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		if _, err := repos.Games.InsertUser(ctx, newUser(42, "Ana")); err != nil {
+			return err
+		}
+		if err := repos.Settings.UpsertChatSetting(ctx, 1, "currency", "EUR"); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Do = %v, want errAbort", err)
+	}
+
+	err = uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		if got, err := repos.Games.GetUserByUserID(ctx, 42); err != nil || got != nil {
+			t.Errorf("GetUserByUserID = %+v, %v; want nil, nil", got, err)
+		}
+		if settings, err := repos.Settings.GetChatSettings(ctx, 1); err != nil || len(settings) != 0 {
+			t.Errorf("GetChatSettings = %v, %v; want empty", settings, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+}
+
+// testUnitOfWorkRollsBackOutbox queues a message with a game, as an event is
+// queued with the change it tells of, and aborts: neither must be kept.
+func testUnitOfWorkRollsBackOutbox(t *testing.T, uow repositories.IUnitOfWork) {
+	var webhook *models.Webhook
+	err := uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		webhook = insertWebhook(t, repos.Webhooks, 1, "https://example.com/hook")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	game := newGame(1, kickoff)
+	err = uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		if _, err := repos.Games.InsertGame(ctx, game); err != nil {
+			return err
+		}
+		insertMessage(t, repos.Webhooks, webhook, kickoff)
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Do = %v, want errAbort", err)
+	}
+
+	err = uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		if got, err := repos.Games.GetGameById(ctx, game.Id); err != nil || got != nil {
+			t.Errorf("GetGameById = %+v, %v; want nil, nil", got, err)
+		}
+		if due := dueMessages(t, repos.Webhooks, kickoff); len(due) != 0 {
+			t.Errorf("due messages = %+v, want none", due)
+		}
+		if webhooks, err := repos.Webhooks.ListWebhooks(ctx, 1); err != nil || len(webhooks) != 1 {
+			t.Errorf("ListWebhooks = %+v, %v; want the webhook committed before", webhooks, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+}
+
+// testUnitOfWorkConcurrentRegistrations registers the same new player from
+// several goroutines, as when /in is tapped twice, each looking the player up
+// before inserting. Exactly one user and one registration must come out.
+func testUnitOfWorkConcurrentRegistrations(t *testing.T, uow repositories.IUnitOfWork) {
+	game := newGame(1, kickoff)
+	err := uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		_, err := repos.Games.InsertGame(ctx, game)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+
+	// Every tap waits after its lookup until all have looked the player up,
+	// so all of them find none. Backends running units of work one at a
+	// time never get there and go on after a short wait.
+	const taps = 5
+	var looked sync.WaitGroup
+	looked.Add(taps)
+	allLooked := make(chan struct{})
+	go func() {
+		looked.Wait()
+		close(allLooked)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < taps; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var once sync.Once
+			err := uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+				user, err := repos.Games.GetUserByUserID(ctx, 42)
+				if err != nil {
+					return err
+				}
+				once.Do(looked.Done)
+				select {
+				case <-allLooked:
+				case <-time.After(50 * time.Millisecond):
+				}
+				if user == nil {
+					user = newUser(42, "Ana")
+					if _, err := repos.Games.InsertUser(ctx, user); err != nil {
+						return err
+					}
+				}
+				registered, err := repos.Games.GetPlayerForGame(ctx, user.Id, game.Id)
+				if err != nil || registered != nil {
+					return err
+				}
+				user.Status = "ATTENDING"
+				_, err = repos.Games.InsertGamePlayer(ctx, game, user)
+				return err
+			})
+			if err != nil {
+				t.Errorf("Do: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	err = uow.Do(context.Background(), func(ctx context.Context, repos repositories.Repositories) error {
+		players, err := repos.Games.GetGamePlayers(ctx, game.Id)
+		if err != nil || len(players) != 1 {
+			t.Errorf("GetGamePlayers = %+v, %v; want one player", players, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"tg-sunday-league/models"
+	"tg-sunday-league/monitoring"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IWebhookRepository interface {
+	InsertWebhook(ctx context.Context, webhook *models.Webhook) error
+	ListWebhooks(ctx context.Context, chatID int64) ([]models.Webhook, error)
+	// DeleteWebhook deletes the chat's webhook to url with the messages
+	// waiting for it, reporting whether there was one.
+	DeleteWebhook(ctx context.Context, chatID int64, url string) (bool, error)
+	InsertOutboxMessage(ctx context.Context, message *models.OutboxMessage) error
+	// ListDueMessages lists up to limit messages due at now, the longest
+	// waiting first.
+	ListDueMessages(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error)
+	MarkMessageDelivered(ctx context.Context, messageId uuid.UUID, at time.Time) error
+	// RetryMessage records a failed attempt, leaving the message due again
+	// at next.
+	RetryMessage(ctx context.Context, messageId uuid.UUID, attempts int, next time.Time, lastError string) error
+	// MarkMessageFailed records the last failed attempt of a message given up
+	// on.
+	MarkMessageFailed(ctx context.Context, messageId uuid.UUID, attempts int, lastError string, at time.Time) error
+	// DeleteFinishedMessages deletes the messages delivered or given up on
+	// before before.
+	DeleteFinishedMessages(ctx context.Context, before time.Time) error
+}
+
+type WebhookRepository struct {
+	Db DBTX
+}
+
+func (r *WebhookRepository) InsertWebhook(ctx context.Context, webhook *models.Webhook) error {
+	defer monitoring.TimeQuery("InsertWebhook")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO webhooks (id, chat_id, url, secret, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		webhook.Id.String(), webhook.ChatId, webhook.Url, webhook.Secret)
+	return wrapError(err)
+}
+
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, chatID int64) ([]models.Webhook, error) {
+	defer monitoring.TimeQuery("ListWebhooks")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT id, chat_id, url, secret
+		FROM webhooks
+		WHERE chat_id = ?
+		ORDER BY url`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		var webhook models.Webhook
+		if err := rows.Scan(&webhook.Id, &webhook.ChatId, &webhook.Url, &webhook.Secret); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, chatID int64, url string) (bool, error) {
+	defer monitoring.TimeQuery("DeleteWebhook")()
+	_, err := r.Db.ExecContext(ctx,
+		`DELETE FROM webhook_outbox
+		WHERE webhook_id IN (SELECT id FROM webhooks WHERE chat_id = ? AND url = ?)`, chatID, url)
+	if err != nil {
+		return false, err
+	}
+	result, err := r.Db.ExecContext(ctx,
+		`DELETE FROM webhooks
+		WHERE chat_id = ? AND url = ?`, chatID, url)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (r *WebhookRepository) InsertOutboxMessage(ctx context.Context, message *models.OutboxMessage) error {
+	defer monitoring.TimeQuery("InsertOutboxMessage")()
+	_, err := r.Db.ExecContext(ctx,
+		`INSERT INTO webhook_outbox (id, webhook_id, event_id, event_type, payload, attempts, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, 0, ?, CURRENT_TIMESTAMP)`,
+		message.Id.String(), message.WebhookId.String(), message.EventId.String(), message.EventType, string(message.Payload), message.NextAttemptAt.UTC())
+	return wrapError(err)
+}
+
+func (r *WebhookRepository) ListDueMessages(ctx context.Context, now time.Time, limit int) ([]models.OutboxMessage, error) {
+	defer monitoring.TimeQuery("ListDueMessages")()
+	rows, err := r.Db.QueryContext(ctx,
+		`SELECT o.id, o.webhook_id, w.url, w.secret, o.event_id, o.event_type, o.payload, o.attempts, o.next_attempt_at, o.last_error
+		FROM webhook_outbox o
+		JOIN webhooks w ON w.id = o.webhook_id
+		WHERE o.delivered_at IS NULL AND o.failed_at IS NULL AND o.next_attempt_at <= ?
+		ORDER BY o.next_attempt_at, o.created_at
+		LIMIT ?`, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxMessages(rows)
+}
+
+func (r *WebhookRepository) MarkMessageDelivered(ctx context.Context, messageId uuid.UUID, at time.Time) error {
+	defer monitoring.TimeQuery("MarkMessageDelivered")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET delivered_at = ?, attempts = attempts + 1, last_error = ''
+		WHERE id = ?`, at.UTC(), messageId.String())
+	return err
+}
+
+func (r *WebhookRepository) RetryMessage(ctx context.Context, messageId uuid.UUID, attempts int, next time.Time, lastError string) error {
+	defer monitoring.TimeQuery("RetryMessage")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET attempts = ?, next_attempt_at = ?, last_error = ?
+		WHERE id = ?`, attempts, next.UTC(), lastError, messageId.String())
+	return err
+}
+
+func (r *WebhookRepository) MarkMessageFailed(ctx context.Context, messageId uuid.UUID, attempts int, lastError string, at time.Time) error {
+	defer monitoring.TimeQuery("MarkMessageFailed")()
+	_, err := r.Db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET attempts = ?, last_error = ?, failed_at = ?
+		WHERE id = ?`, attempts, lastError, at.UTC(), messageId.String())
+	return err
+}
+
+func (r *WebhookRepository) DeleteFinishedMessages(ctx context.Context, before time.Time) error {
+	defer monitoring.TimeQuery("DeleteFinishedMessages")()
+	_, err := r.Db.ExecContext(ctx,
+		`DELETE FROM webhook_outbox
+		WHERE delivered_at < ? OR failed_at < ?`, before.UTC(), before.UTC())
+	return err
+}
+
+// scanOutboxMessages reads the rows of ListDueMessages, whose columns are
+// the same on every backend.
+func scanOutboxMessages(rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	for rows.Next() {
+		var message models.OutboxMessage
+		err := rows.Scan(&message.Id, &message.WebhookId, &message.Url, &message.Secret, &message.EventId, &message.EventType,
+			&message.Payload, &message.Attempts, &message.NextAttemptAt, &message.LastError)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,181 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The contract every webhook repository has to honour. Each backend test
+// calls it with a constructor returning a repository over an empty, migrated
+// database.
+
+type webhookRepositoryFactory func(t *testing.T) repositories.IWebhookRepository
+
+func testWebhookRepositoryContract(t *testing.T, newRepository webhookRepositoryFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, webhooks repositories.IWebhookRepository)
+	}{
+		{"InsertThenList", testInsertThenListWebhooks},
+		{"TakenUrlConflicts", testTakenWebhookUrlConflicts},
+		{"DeleteTakesMessages", testDeleteWebhookTakesMessages},
+		{"DueMessages", testDueMessages},
+		{"RetryAndFail", testRetryAndFailMessages},
+		{"DeleteFinished", testDeleteFinishedMessages},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepository(t))
+		})
+	}
+}
+
+func insertWebhook(t *testing.T, webhooks repositories.IWebhookRepository, chatID int64, url string) *models.Webhook {
+	t.Helper()
+	webhook := &models.Webhook{Id: uuid.New(), ChatId: chatID, Url: url, Secret: "secret of " + url}
+	if err := webhooks.InsertWebhook(context.Background(), webhook); err != nil {
+		t.Fatalf("InsertWebhook(%s): %v", url, err)
+	}
+	return webhook
+}
+
+func insertMessage(t *testing.T, webhooks repositories.IWebhookRepository, webhook *models.Webhook, due time.Time) *models.OutboxMessage {
+	t.Helper()
+	message := &models.OutboxMessage{Id: uuid.New(), WebhookId: webhook.Id, EventId: uuid.New(), EventType: "game.created", Payload: []byte(`{"type":"game.created"}`), NextAttemptAt: due}
+	if err := webhooks.InsertOutboxMessage(context.Background(), message); err != nil {
+		t.Fatalf("InsertOutboxMessage: %v", err)
+	}
+	return message
+}
+
+func dueMessages(t *testing.T, webhooks repositories.IWebhookRepository, now time.Time) []models.OutboxMessage {
+	t.Helper()
+	messages, err := webhooks.ListDueMessages(context.Background(), now, 10)
+	if err != nil {
+		t.Fatalf("ListDueMessages: %v", err)
+	}
+	return messages
+}
+
+func testInsertThenListWebhooks(t *testing.T, webhooks repositories.IWebhookRepository) {
+	second := insertWebhook(t, webhooks, -1001, "https://b.example.com/hook")
+	insertWebhook(t, webhooks, -1001, "https://a.example.com/hook")
+	insertWebhook(t, webhooks, -1002, "https://c.example.com/hook")
+
+	got, err := webhooks.ListWebhooks(context.Background(), -1001)
+	if err != nil || len(got) != 2 || got[0].Url != "https://a.example.com/hook" || got[1] != *second {
+		t.Errorf("ListWebhooks = %+v, %v; want the chat's two, by URL", got, err)
+	}
+}
+
+func testTakenWebhookUrlConflicts(t *testing.T, webhooks repositories.IWebhookRepository) {
+	insertWebhook(t, webhooks, -1001, "https://example.com/hook")
+	again := &models.Webhook{Id: uuid.New(), ChatId: -1001, Url: "https://example.com/hook", Secret: "other"}
+	if err := webhooks.InsertWebhook(context.Background(), again); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("InsertWebhook of a taken URL = %v, want ErrConflict", err)
+	}
+	insertWebhook(t, webhooks, -1002, "https://example.com/hook")
+}
+
+func testDeleteWebhookTakesMessages(t *testing.T, webhooks repositories.IWebhookRepository) {
+	ctx := context.Background()
+	webhook := insertWebhook(t, webhooks, -1001, "https://example.com/hook")
+	other := insertWebhook(t, webhooks, -1001, "https://other.example.com/hook")
+	insertMessage(t, webhooks, webhook, kickoff)
+	kept := insertMessage(t, webhooks, other, kickoff)
+
+	if deleted, err := webhooks.DeleteWebhook(ctx, -1002, webhook.Url); err != nil || deleted {
+		t.Errorf("DeleteWebhook of another chat = %v, %v; want nothing deleted", deleted, err)
+	}
+	if deleted, err := webhooks.DeleteWebhook(ctx, -1001, webhook.Url); err != nil || !deleted {
+		t.Fatalf("DeleteWebhook = %v, %v; want deleted", deleted, err)
+	}
+	if got := dueMessages(t, webhooks, kickoff); len(got) != 1 || got[0].Id != kept.Id {
+		t.Errorf("due messages = %+v, want only the other webhook's", got)
+	}
+	if got, _ := webhooks.ListWebhooks(ctx, -1001); len(got) != 1 || got[0].Id != other.Id {
+		t.Errorf("ListWebhooks = %+v, want only the other webhook", got)
+	}
+}
+
+func testDueMessages(t *testing.T, webhooks repositories.IWebhookRepository) {
+	webhook := insertWebhook(t, webhooks, -1001, "https://example.com/hook")
+	later := insertMessage(t, webhooks, webhook, kickoff)
+	earlier := insertMessage(t, webhooks, webhook, kickoff.Add(-time.Minute))
+	insertMessage(t, webhooks, webhook, kickoff.Add(time.Minute))
+
+	got := dueMessages(t, webhooks, kickoff)
+	if len(got) != 2 || got[0].Id != earlier.Id || got[1].Id != later.Id {
+		t.Fatalf("due messages = %+v, want the two due, longest waiting first", got)
+	}
+	message := got[0]
+	if message.Url != webhook.Url || message.Secret != webhook.Secret || message.EventId != earlier.EventId ||
+		message.EventType != "game.created" || string(message.Payload) != `{"type":"game.created"}` || message.Attempts != 0 {
+		t.Errorf("due message = %+v, want it with its webhook's URL and secret", message)
+	}
+
+	if messages, err := webhooks.ListDueMessages(context.Background(), kickoff, 1); err != nil || len(messages) != 1 {
+		t.Errorf("ListDueMessages(limit 1) = %d messages, %v; want 1", len(messages), err)
+	}
+	if err := webhooks.MarkMessageDelivered(context.Background(), earlier.Id, kickoff); err != nil {
+		t.Fatalf("MarkMessageDelivered: %v", err)
+	}
+	if got := dueMessages(t, webhooks, kickoff); len(got) != 1 || got[0].Id != later.Id {
+		t.Errorf("due messages after a delivery = %+v, want the other one", got)
+	}
+}
+
+func testRetryAndFailMessages(t *testing.T, webhooks repositories.IWebhookRepository) {
+	ctx := context.Background()
+	webhook := insertWebhook(t, webhooks, -1001, "https://example.com/hook")
+	message := insertMessage(t, webhooks, webhook, kickoff)
+
+	if err := webhooks.RetryMessage(ctx, message.Id, 1, kickoff.Add(time.Minute), "status 500"); err != nil {
+		t.Fatalf("RetryMessage: %v", err)
+	}
+	if got := dueMessages(t, webhooks, kickoff); len(got) != 0 {
+		t.Errorf("due messages before the retry = %+v, want none", got)
+	}
+	got := dueMessages(t, webhooks, kickoff.Add(time.Minute))
+	if len(got) != 1 || got[0].Attempts != 1 || got[0].LastError != "status 500" || !got[0].NextAttemptAt.Equal(kickoff.Add(time.Minute)) {
+		t.Fatalf("due messages at the retry = %+v, want it after 1 attempt", got)
+	}
+
+	if err := webhooks.MarkMessageFailed(ctx, message.Id, 2, "status 500", kickoff.Add(time.Minute)); err != nil {
+		t.Fatalf("MarkMessageFailed: %v", err)
+	}
+	if got := dueMessages(t, webhooks, kickoff.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("due messages after giving up = %+v, want none", got)
+	}
+}
+
+func testDeleteFinishedMessages(t *testing.T, webhooks repositories.IWebhookRepository) {
+	ctx := context.Background()
+	webhook := insertWebhook(t, webhooks, -1001, "https://example.com/hook")
+	delivered := insertMessage(t, webhooks, webhook, kickoff)
+	failed := insertMessage(t, webhooks, webhook, kickoff)
+	recent := insertMessage(t, webhooks, webhook, kickoff)
+	pending := insertMessage(t, webhooks, webhook, kickoff)
+	if err := webhooks.MarkMessageDelivered(ctx, delivered.Id, kickoff); err != nil {
+		t.Fatalf("MarkMessageDelivered: %v", err)
+	}
+	if err := webhooks.MarkMessageFailed(ctx, failed.Id, 8, "timeout", kickoff); err != nil {
+		t.Fatalf("MarkMessageFailed: %v", err)
+	}
+	if err := webhooks.MarkMessageDelivered(ctx, recent.Id, kickoff.Add(time.Hour)); err != nil {
+		t.Fatalf("MarkMessageDelivered: %v", err)
+	}
+
+	if err := webhooks.DeleteFinishedMessages(ctx, kickoff.Add(time.Minute)); err != nil {
+		t.Fatalf("DeleteFinishedMessages: %v", err)
+	}
+	if got := dueMessages(t, webhooks, kickoff); len(got) != 1 || got[0].Id != pending.Id {
+		t.Errorf("due messages = %+v, want the pending one", got)
+	}
+}
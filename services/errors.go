@@ -0,0 +1,182 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorKind groups errors by what went wrong, independently of the operation
+// that failed.
+type ErrorKind string
+
+const (
+	KIND_NOT_FOUND         ErrorKind = "not_found"
+	KIND_CONFLICT          ErrorKind = "conflict"
+	KIND_VALIDATION        ErrorKind = "validation"
+	KIND_PERMISSION_DENIED ErrorKind = "permission_denied"
+	KIND_INTERNAL          ErrorKind = "internal"
+)
+
+// ErrorCode identifies an error the user should be told about. It doubles as
+// the key of the message in the bot's catalog.
+type ErrorCode string
+
+const (
+	ERR_USER_RETRIEVE           ErrorCode = "error.user_retrieve"
+	ERR_USER_CREATE             ErrorCode = "error.user_create"
+	ERR_LATEST_GAME             ErrorCode = "error.latest_game"
+	ERR_GAME_ALREADY_SCHEDULED  ErrorCode = "error.game_already_scheduled"
+	ERR_KICKOFF_INVALID         ErrorCode = "error.kickoff_invalid"
+	ERR_KICKOFF_MISSING_TIME    ErrorCode = "error.kickoff_missing_time"
+	ERR_KICKOFF_PAST            ErrorCode = "error.kickoff_past"
+	ERR_INVALID_PRICE           ErrorCode = "error.invalid_price"
+	ERR_GAME_CREATE             ErrorCode = "error.game_create"
+	ERR_GAME_DETAILS            ErrorCode = "error.game_details"
+	ERR_GAME_CANCEL             ErrorCode = "error.game_cancel"
+	ERR_GAME_PLAYERS            ErrorCode = "error.game_players"
+	ERR_NO_GAME                 ErrorCode = "error.no_game"
+	ERR_NO_UPCOMING_GAME        ErrorCode = "error.no_upcoming_game"
+	ERR_PLAYER_CREATE           ErrorCode = "error.player_create"
+	ERR_PLAYER_REGISTER         ErrorCode = "error.player_register"
+	ERR_PLAYER_RETRIEVE         ErrorCode = "error.player_retrieve"
+	ERR_PLAYER_GAME_RETRIEVE    ErrorCode = "error.player_game_retrieve"
+	ERR_PLAYER_NOT_REGISTERED   ErrorCode = "error.player_not_registered"
+	ERR_SENDER_NOT_REGISTERED   ErrorCode = "error.sender_not_registered"
+	ERR_PAYMENT_UPDATE          ErrorCode = "error.payment_update"
+	ERR_SETTINGS_RETRIEVE       ErrorCode = "error.settings_retrieve"
+	ERR_SETTINGS_SAVE           ErrorCode = "error.settings_save"
+	ERR_INVALID_CURRENCY        ErrorCode = "error.invalid_currency"
+	ERR_INVALID_SQUAD_SIZE      ErrorCode = "error.invalid_squad_size"
+	ERR_UNKNOWN_TIMEZONE        ErrorCode = "error.unknown_timezone"
+	ERR_UNSUPPORTED_LANGUAGE    ErrorCode = "error.unsupported_language"
+	ERR_INVALID_REMINDER        ErrorCode = "error.invalid_reminder"
+	ERR_TOO_MANY_REMINDERS      ErrorCode = "error.too_many_reminders"
+	ERR_INVALID_GAME_CREATORS   ErrorCode = "error.invalid_game_creators"
+	ERR_UNKNOWN_SETTING         ErrorCode = "error.unknown_setting"
+	ERR_ADMINS_ONLY             ErrorCode = "error.admins_only"
+	ERR_SETTINGS_ADMINS_ONLY    ErrorCode = "error.settings_admins_only"
+	ERR_GAMES_LIST              ErrorCode = "error.games_list"
+	ERR_PLAYER_NOT_FOUND        ErrorCode = "error.player_not_found"
+	ERR_PLAYER_MERGE            ErrorCode = "error.player_merge"
+	ERR_MERGE_SAME_PLAYER       ErrorCode = "error.merge_same_player"
+	ERR_OWNER_ONLY              ErrorCode = "error.owner_only"
+	ERR_BACKUP_UNAVAILABLE      ErrorCode = "error.backup_unavailable"
+	ERR_BACKUP                  ErrorCode = "error.backup"
+	ERR_BACKUP_SEND             ErrorCode = "error.backup_send"
+	ERR_EXPORT                  ErrorCode = "error.export"
+	ERR_EXPORT_RANGE            ErrorCode = "error.export_range"
+	ERR_NO_GAMES_TO_EXPORT      ErrorCode = "error.no_games_to_export"
+	ERR_IMPORT                  ErrorCode = "error.import"
+	ERR_IMPORT_FILE             ErrorCode = "error.import_file"
+	ERR_IMPORT_MISSING_COLUMN   ErrorCode = "error.import_missing_column"
+	ERR_IMPORT_UNKNOWN_COLUMN   ErrorCode = "error.import_unknown_column"
+	ERR_NO_GAMES_TO_IMPORT      ErrorCode = "error.no_games_to_import"
+	ERR_IMPORT_KICKOFF          ErrorCode = "error.import_kickoff"
+	ERR_IMPORT_PRICE            ErrorCode = "error.import_price"
+	ERR_IMPORT_SCORE            ErrorCode = "error.import_score"
+	ERR_IMPORT_TELEGRAM_ID      ErrorCode = "error.import_telegram_id"
+	ERR_IMPORT_ATTENDANCE       ErrorCode = "error.import_attendance"
+	ERR_IMPORT_PAID             ErrorCode = "error.import_paid"
+	ERR_IMPORT_NO_PLAYER        ErrorCode = "error.import_no_player"
+	ERR_IMPORT_GAME_MISMATCH    ErrorCode = "error.import_game_mismatch"
+	ERR_IMPORT_FUTURE_GAME      ErrorCode = "error.import_future_game"
+	ERR_IMPORT_GAME_EXISTS      ErrorCode = "error.import_game_exists"
+	ERR_IMPORT_UNKNOWN_PLAYER   ErrorCode = "error.import_unknown_player"
+	ERR_IMPORT_AMBIGUOUS_PLAYER ErrorCode = "error.import_ambiguous_player"
+	ERR_IMPORT_DUPLICATE_PLAYER ErrorCode = "error.import_duplicate_player"
+	ERR_TOKEN                   ErrorCode = "error.token"
+	ERR_UNKNOWN_TOKEN           ErrorCode = "error.unknown_token"
+	ERR_CALENDAR                ErrorCode = "error.calendar"
+	ERR_CALENDAR_UNAVAILABLE    ErrorCode = "error.calendar_unavailable"
+	ERR_FIXTURES                ErrorCode = "error.fixtures"
+	ERR_FIXTURES_FILE           ErrorCode = "error.fixtures_file"
+	ERR_NO_FIXTURES             ErrorCode = "error.no_fixtures"
+	ERR_API_UNAVAILABLE         ErrorCode = "error.api_unavailable"
+	ERR_API_SEND                ErrorCode = "error.api_send"
+	ERR_API_BODY                ErrorCode = "error.api_body"
+	ERR_API_FIELD_MISSING       ErrorCode = "error.api_field_missing"
+	ERR_API_STATUS              ErrorCode = "error.api_status"
+	ERR_API_USER_ID             ErrorCode = "error.api_user_id"
+	ERR_SESSION                 ErrorCode = "error.session"
+	ERR_LOGIN_EXPIRED           ErrorCode = "error.login_expired"
+	ERR_NOT_SIGNED_IN           ErrorCode = "error.not_signed_in"
+	ERR_DASHBOARD               ErrorCode = "error.dashboard"
+	ERR_DASHBOARD_UNAVAILABLE   ErrorCode = "error.dashboard_unavailable"
+	ERR_DASHBOARD_SEND          ErrorCode = "error.dashboard_send"
+	ERR_WEBHOOKS                ErrorCode = "error.webhooks"
+	ERR_WEBHOOK_URL             ErrorCode = "error.webhook_url"
+	ERR_WEBHOOK_EXISTS          ErrorCode = "error.webhook_exists"
+	ERR_WEBHOOK_NOT_FOUND       ErrorCode = "error.webhook_not_found"
+	ERR_WEBHOOK_LIMIT           ErrorCode = "error.webhook_limit"
+	ERR_WEBHOOK_SEND            ErrorCode = "error.webhook_send"
+	ERR_WEBHOOK_USAGE           ErrorCode = "error.webhook_usage"
+	ERR_WEBHOOKS_UNAVAILABLE    ErrorCode = "error.webhooks_unavailable"
+)
+
+// Error is returned by the services instead of user-facing text. The bot
+// renders Code with Args in the chat's language. Field names the invalid input
+// of validation errors and Err keeps the cause, if any, for logging.
+type Error struct {
+	Kind  ErrorKind
+	Code  ErrorCode
+	Field string
+	Args  []interface{}
+	Err   error
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Kind) + ": " + string(e.Code)
+	if e.Field != "" {
+		msg += " (" + e.Field + ")"
+	}
+	if len(e.Args) > 0 {
+		msg += fmt.Sprint(e.Args)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(code ErrorCode, args ...interface{}) error {
+	return &Error{Kind: KIND_NOT_FOUND, Code: code, Args: args}
+}
+
+func Conflict(code ErrorCode, args ...interface{}) error {
+	return &Error{Kind: KIND_CONFLICT, Code: code, Args: args}
+}
+
+// Invalid reports bad input for field. err is the parse error behind it, if
+// any.
+func Invalid(field string, code ErrorCode, err error, args ...interface{}) error {
+	return &Error{Kind: KIND_VALIDATION, Code: code, Field: field, Args: args, Err: err}
+}
+
+func PermissionDenied(code ErrorCode, args ...interface{}) error {
+	return &Error{Kind: KIND_PERMISSION_DENIED, Code: code, Args: args}
+}
+
+// Internal wraps a failure the user can do nothing about but retry, such as a
+// repository error.
+func Internal(code ErrorCode, err error) error {
+	return &Error{Kind: KIND_INTERNAL, Code: code, Err: err}
+}
+
+// AsError returns the first service Error in err's chain, or nil.
+func AsError(err error) *Error {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr
+	}
+	return nil
+}
+
+// IsKind reports whether err is a service Error of the given kind.
+func IsKind(err error, kind ErrorKind) bool {
+	serviceErr := AsError(err)
+	return serviceErr != nil && serviceErr.Kind == kind
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names a change to a chat's games. EVENT_GAME_EDITED is sent when
+// a fixture list moves the upcoming game to another kickoff, place or
+// opponent.
+type EventType string
+
+const (
+	EVENT_GAME_CREATED     EventType = "game.created"
+	EVENT_GAME_EDITED      EventType = "game.edited"
+	EVENT_GAME_CANCELLED   EventType = "game.cancelled"
+	EVENT_PLAYER_JOINED    EventType = "player.joined"
+	EVENT_PLAYER_LEFT      EventType = "player.left"
+	EVENT_PAYMENT_RECORDED EventType = "payment.recorded"
+)
+
+// Event is a change GameService made to a game. It is queued for webhooks
+// in the unit of work saving the change and published once that committed.
+type Event struct {
+	Id         uuid.UUID
+	Type       EventType
+	ChatId     int64
+	OccurredAt time.Time
+	Game       models.Game  // Game as the change left it
+	Player     *models.User // Player who answered or paid, nil for changes to the game itself
+}
+
+func newEvent(eventType EventType, game *models.Game, player *models.User) Event {
+	return Event{Id: uuid.New(), Type: eventType, ChatId: game.ChatId, OccurredAt: time.Now(), Game: *game, Player: player}
+}
+
+type IEventPublisher interface {
+	Publish(ctx context.Context, events ...Event)
+}
+
+// EventHandler handles an event published on an EventBus. Its error is
+// logged, as the change it follows is saved already, so handlers that must
+// not lose events write them in the unit of work instead, as webhooks do.
+type EventHandler func(ctx context.Context, event Event) error
+
+// EventBus hands the events published on it to every handler subscribed, in
+// the order they subscribed, before Publish returns.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers []EventHandler
+}
+
+func (b *EventBus) Subscribe(handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *EventBus) Publish(ctx context.Context, events ...Event) {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	for _, event := range events {
+		for _, handler := range handlers {
+			if err := handler(ctx, event); err != nil {
+				logging.FromContext(ctx).Error("Could not handle event", "event", event.Type, "event_id", event.Id, "error", err)
+			}
+		}
+	}
+}
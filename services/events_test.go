@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordEvents subscribes a handler to a new bus of s, returning the events
+// it is handed.
+func recordEvents(s *GameService) *[]Event {
+	var events []Event
+	bus := &EventBus{}
+	bus.Subscribe(func(ctx context.Context, event Event) error {
+		events = append(events, event)
+		return nil
+	})
+	s.Events = bus
+	return &events
+}
+
+func TestGameServicePublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	s := newFixture().service()
+	events := recordEvents(s)
+	chat, user, name := chatID, int64(20), "Bea"
+
+	game, _, _, err := s.CreateNewGame(ctx, chatID, 10, "Ana", []string{"2099-01-04 11:00", "Kallang", "Rovers", "12"})
+	if err != nil {
+		t.Fatalf("CreateNewGame: %v", err)
+	}
+	if _, _, _, err := s.RegisterPlayer(ctx, &chat, &user, &name, ATTENDING); err != nil {
+		t.Fatalf("RegisterPlayer: %v", err)
+	}
+	if _, _, _, err := s.RepayGame(ctx, &chat, &user); err != nil {
+		t.Fatalf("RepayGame: %v", err)
+	}
+	if _, _, _, err := s.RegisterPlayer(ctx, &chat, &user, &name, OUT); err != nil {
+		t.Fatalf("RegisterPlayer: %v", err)
+	}
+	if _, err := s.CancelGame(ctx, chatID); err != nil {
+		t.Fatalf("CancelGame: %v", err)
+	}
+
+	want := []EventType{EVENT_GAME_CREATED, EVENT_PLAYER_JOINED, EVENT_PAYMENT_RECORDED, EVENT_PLAYER_LEFT, EVENT_GAME_CANCELLED}
+	if len(*events) != len(want) {
+		t.Fatalf("events = %+v, want %v", *events, want)
+	}
+	for i, event := range *events {
+		if event.Type != want[i] || event.ChatId != chatID || event.Game.Id != game.Id {
+			t.Errorf("event %d = %+v, want %s of game %s", i, event, want[i], game.Id)
+		}
+	}
+	if player := (*events)[1].Player; player == nil || player.Name != "Bea" || player.Status != string(ATTENDING) || player.HasPaid {
+		t.Errorf("joined player = %+v, want Bea attending", player)
+	}
+	if player := (*events)[2].Player; player == nil || player.UserId != 20 || !player.HasPaid {
+		t.Errorf("paying player = %+v, want Bea paid", player)
+	}
+	if player := (*events)[3].Player; player == nil || player.Status != string(OUT) {
+		t.Errorf("leaving player = %+v, want Bea out", player)
+	}
+	if cancelled := (*events)[4]; !cancelled.Game.Cancelled || cancelled.Player != nil {
+		t.Errorf("cancel event = %+v, want the cancelled game", cancelled)
+	}
+}
+
+func TestImportFixturesPublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	s := newFixture().service()
+	events := recordEvents(s)
+	next := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Hour)
+	later := ImportedFixture{Uid: "2", Date: next.Add(7 * 24 * time.Hour), Opponent: "United"}
+
+	imports := []*FixturesImport{
+		{Fixtures: []ImportedFixture{{Uid: "1", Date: next, Location: "Kallang", Opponent: "Rovers"}, later}},
+		{Fixtures: []ImportedFixture{{Uid: "1", Date: next.Add(time.Hour), Location: "Bishan", Opponent: "Rovers"}, later}},
+		{Fixtures: []ImportedFixture{{Uid: "1", Date: next.Add(time.Hour), Cancelled: true}, later}},
+	}
+	for _, in := range imports {
+		if _, err := s.ImportFixtures(ctx, chatID, in, false); err != nil {
+			t.Fatalf("ImportFixtures: %v", err)
+		}
+	}
+
+	want := []EventType{EVENT_GAME_CREATED, EVENT_GAME_EDITED, EVENT_GAME_CANCELLED, EVENT_GAME_CREATED}
+	if len(*events) != len(want) {
+		t.Fatalf("events = %+v, want %v", *events, want)
+	}
+	for i, event := range *events {
+		if event.Type != want[i] {
+			t.Errorf("event %d = %s, want %s", i, event.Type, want[i])
+		}
+	}
+	edited := (*events)[1]
+	if edited.Game.Id != (*events)[0].Game.Id || !edited.Game.Date.Equal(next.Add(time.Hour)) || edited.Game.Location != "Bishan" || edited.Game.Sequence != 1 {
+		t.Errorf("edited event = %+v, want the first game moved to Bishan", edited)
+	}
+	if opened := (*events)[3]; opened.Game.Opponent != "United" {
+		t.Errorf("last event = %+v, want the next fixture opened", opened)
+	}
+}
+
+func TestGameServicePublishesNothingOnFailure(t *testing.T) {
+	f := newFixture()
+	failing("InsertGame")(t, f)
+	s := f.service()
+	events := recordEvents(s)
+	chat, user, name := chatID, int64(20), "Bea"
+
+	s.CreateNewGame(context.Background(), chatID, 10, "Ana", []string{"2099-01-04 11:00"})
+	s.RegisterPlayer(context.Background(), &chat, &user, &name, ATTENDING)
+	s.CancelGame(context.Background(), chatID)
+	if len(*events) != 0 {
+		t.Errorf("events = %+v, want none", *events)
+	}
+}
+
+func TestEventBusKeepsGoingAfterAnError(t *testing.T) {
+	bus := &EventBus{}
+	var handled []string
+	bus.Subscribe(func(ctx context.Context, event Event) error {
+		handled = append(handled, "first")
+		return errors.New("outbox is down")
+	})
+	bus.Subscribe(func(ctx context.Context, event Event) error {
+		handled = append(handled, "second")
+		return nil
+	})
+
+	bus.Publish(context.Background(), Event{Type: EVENT_GAME_CREATED}, Event{Type: EVENT_GAME_CANCELLED})
+	if len(handled) != 4 || handled[0] != "first" || handled[1] != "second" {
+		t.Errorf("handled = %v, want both handlers to see both events", handled)
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+
+	"github.com/google/uuid"
+)
+
+// IGameAdminService inspects and repairs the games of any chat, for the
+// admin commands run outside of Telegram.
+type IGameAdminService interface {
+	ListGames(ctx context.Context, chatId int64) ([]models.Game, error)
+	GetGame(ctx context.Context, gameId uuid.UUID) (*models.Game, *[]models.User, *[]models.User, error)
+	MergePlayers(ctx context.Context, fromId uuid.UUID, intoId uuid.UUID) error
+}
+
+// ListGames returns every game of the chat by kickoff, cancelled ones
+// included.
+func (g *GameService) ListGames(ctx context.Context, chatId int64) ([]models.Game, error) {
+	var list []models.Game
+	err := g.atomically(ctx, func(ctx context.Context, games repositories.IGameRepository) error {
+		var err error
+		list, err = games.ListGamesByChatID(ctx, chatId)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not list games", "error", err)
+			return Internal(ERR_GAMES_LIST, fmt.Errorf("list games of chat %d: %w", chatId, err))
+		}
+		return nil
+	})
+	return list, err
+}
+
+// GetGame returns any game, cancelled or past, with its players and
+// absentees.
+func (g *GameService) GetGame(ctx context.Context, gameId uuid.UUID) (*models.Game, *[]models.User, *[]models.User, error) {
+	var game *models.Game
+	var players *[]models.User
+	var absentees *[]models.User
+	err := g.atomically(ctx, func(ctx context.Context, games repositories.IGameRepository) error {
+		var err error
+		game, err = games.GetGameById(ctx, gameId)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not retrieve game details", "error", err)
+			return Internal(ERR_GAME_DETAILS, fmt.Errorf("get game %s: %w", gameId, err))
+		}
+		if game == nil {
+			return NotFound(ERR_NO_GAME)
+		}
+
+		allPlayers, err := games.GetGamePlayers(ctx, game.Id)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not retrieve game players", "error", err)
+			return Internal(ERR_GAME_PLAYERS, fmt.Errorf("get players of game %s: %w", game.Id, err))
+		}
+		players, absentees = splitPlayers(allPlayers)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return game, players, absentees, nil
+}
+
+// MergePlayers folds the player fromId into intoId, such as a second account
+// of the same person, and deletes fromId. Games both played keep the status
+// of intoId, paid if either had paid.
+func (g *GameService) MergePlayers(ctx context.Context, fromId uuid.UUID, intoId uuid.UUID) error {
+	if fromId == intoId {
+		return Invalid("from", ERR_MERGE_SAME_PLAYER, nil)
+	}
+	return g.atomically(ctx, func(ctx context.Context, games repositories.IGameRepository) error {
+		for _, id := range []uuid.UUID{fromId, intoId} {
+			user, err := games.GetUserByUUID(ctx, id)
+			if err != nil {
+				logging.FromContext(ctx).Error("Could not retrieve player", "error", err)
+				return Internal(ERR_PLAYER_RETRIEVE, fmt.Errorf("get user %s: %w", id, err))
+			}
+			if user == nil {
+				return NotFound(ERR_PLAYER_NOT_FOUND, id.String())
+			}
+		}
+
+		if err := games.MergeUsers(ctx, fromId, intoId); err != nil {
+			logging.FromContext(ctx).Error("Could not merge players", "error", err)
+			return Internal(ERR_PLAYER_MERGE, fmt.Errorf("merge user %s into %s: %w", fromId, intoId, err))
+		}
+		logging.FromContext(ctx).Info("Players merged", "from", fromId, "into", intoId)
+		return nil
+	})
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *failingRepository) ListGamesByChatID(ctx context.Context, chatID int64) ([]models.Game, error) {
+	if r.method == "ListGamesByChatID" {
+		return nil, errDatabase
+	}
+	return r.IGameRepository.ListGamesByChatID(ctx, chatID)
+}
+
+func (r *failingRepository) GetGameById(ctx context.Context, gameId uuid.UUID) (*models.Game, error) {
+	if r.method == "GetGameById" {
+		return nil, errDatabase
+	}
+	return r.IGameRepository.GetGameById(ctx, gameId)
+}
+
+func (r *failingRepository) MergeUsers(ctx context.Context, fromId uuid.UUID, intoId uuid.UUID) error {
+	if r.method == "MergeUsers" {
+		return errDatabase
+	}
+	return r.IGameRepository.MergeUsers(ctx, fromId, intoId)
+}
+
+func TestListGames(t *testing.T) {
+	var list []models.Game
+	listGames := func(s *GameService) (result, error) {
+		var err error
+		list, err = s.ListGames(context.Background(), chatID)
+		return result{}, err
+	}
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name: "lists past and cancelled games",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(-7*24*time.Hour))
+				cancelled := f.addGame(t, time.Now().Add(24*time.Hour))
+				if _, err := f.games.CancelGame(context.Background(), cancelled); err != nil {
+					t.Fatalf("CancelGame: %v", err)
+				}
+			},
+			call: listGames,
+			check: func(t *testing.T, f *fixture, _ result) {
+				if len(list) != 2 || list[0].Cancelled || !list[1].Cancelled {
+					t.Errorf("ListGames = %+v, want the past game then the cancelled one", list)
+				}
+			},
+		},
+		{
+			name:     "fails when games cannot be read",
+			setup:    failing("ListGamesByChatID"),
+			call:     listGames,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_GAMES_LIST,
+		},
+	})
+}
+
+func TestGetGame(t *testing.T) {
+	var gameId uuid.UUID
+	getGame := func(s *GameService) (result, error) {
+		game, players, absentees, err := s.GetGame(context.Background(), gameId)
+		return result{game, players, absentees}, err
+	}
+	withPastGame := func(t *testing.T, f *fixture) {
+		game := f.addGame(t, time.Now().Add(-7*24*time.Hour))
+		f.addPlayer(t, game, 2, "Bea", ATTENDING)
+		f.addPlayer(t, game, 3, "Cy", OUT)
+		gameId = game.Id
+	}
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name:  "shows a past game",
+			setup: withPastGame,
+			call:  getGame,
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game == nil || got.game.Id != gameId {
+					t.Errorf("game = %+v, want %v", got.game, gameId)
+				}
+				wantNames(t, "players", got.players, "Bea")
+				wantNames(t, "absentees", got.absentees, "Cy")
+			},
+		},
+		{
+			name:     "reports missing game",
+			setup:    func(t *testing.T, f *fixture) { gameId = uuid.New() },
+			call:     getGame,
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_NO_GAME,
+		},
+		{
+			name: "fails when game cannot be read",
+			setup: func(t *testing.T, f *fixture) {
+				withPastGame(t, f)
+				failing("GetGameById")(t, f)
+			},
+			call:     getGame,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_GAME_DETAILS,
+		},
+	})
+}
+
+func TestMergePlayers(t *testing.T) {
+	var from, into uuid.UUID
+	var game *models.Game
+	mergePlayers := func(s *GameService) (result, error) {
+		return result{}, s.MergePlayers(context.Background(), from, into)
+	}
+	withDuplicate := func(t *testing.T, f *fixture) {
+		game = f.addGame(t, time.Now().Add(24*time.Hour))
+		from = f.addPlayer(t, game, 2, "Bea (old)", ATTENDING).Id
+		into = f.addUser(t, 2, "Bea").Id
+	}
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name:  "moves the games to the other player",
+			setup: withDuplicate,
+			call:  mergePlayers,
+			check: func(t *testing.T, f *fixture, _ result) {
+				players, _ := f.games.GetGamePlayers(context.Background(), game.Id)
+				wantNames(t, "players", &players, "Bea")
+				if user, _ := f.games.GetUserByUUID(context.Background(), from); user != nil {
+					t.Errorf("merged player %+v still exists", user)
+				}
+			},
+		},
+		{
+			name: "rejects merging a player into itself",
+			setup: func(t *testing.T, f *fixture) {
+				withDuplicate(t, f)
+				into = from
+			},
+			call:     mergePlayers,
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_MERGE_SAME_PLAYER,
+		},
+		{
+			name: "reports unknown player",
+			setup: func(t *testing.T, f *fixture) {
+				withDuplicate(t, f)
+				into = uuid.New()
+			},
+			call:     mergePlayers,
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_PLAYER_NOT_FOUND,
+		},
+		{
+			name: "fails when players cannot be merged",
+			setup: func(t *testing.T, f *fixture) {
+				withDuplicate(t, f)
+				failing("MergeUsers")(t, f)
+			},
+			call:     mergePlayers,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_PLAYER_MERGE,
+		},
+	})
+}
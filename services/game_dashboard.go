@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DASHBOARD_HISTORY is how many past games the dashboard lists.
+const DASHBOARD_HISTORY = 10
+
+// Dashboard is the state of a chat's games, as its web dashboard shows it.
+// Only games played count, leaving out cancelled ones and those still to
+// come.
+type Dashboard struct {
+	// Game is the chat's latest game with its players and absentees, nil if
+	// it has none.
+	Game      *models.Game
+	Players   []models.User
+	Absentees []models.User
+	History   []models.Game // Games played, latest first, up to DASHBOARD_HISTORY
+	Played    int
+	Won       int // Results of the games with a score
+	Drawn     int
+	Lost      int
+	// Appearances ranks players by the games they played, Unpaid by the
+	// games they played without paying.
+	Appearances []Standing
+	Unpaid      []Standing
+}
+
+// Standing is a player's count on a leaderboard.
+type Standing struct {
+	Player models.User
+	Games  int
+}
+
+// Dashboard gathers the chat's latest game, the games it played and its
+// leaderboards as of now.
+func (g *GameService) Dashboard(ctx context.Context, chatId int64, now time.Time) (*Dashboard, error) {
+	dashboard := &Dashboard{}
+	err := g.atomically(ctx, func(ctx context.Context, games repositories.IGameRepository) error {
+		game, players, absentees, err := loadGameDetails(ctx, games, chatId)
+		if err != nil && !IsKind(err, KIND_NOT_FOUND) {
+			return err
+		}
+		if game != nil {
+			dashboard.Game, dashboard.Players, dashboard.Absentees = game, *players, *absentees
+		}
+
+		list, err := games.ListGamesBetween(ctx, chatId, time.Time{}, now)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not list games for the dashboard", "error", err)
+			return Internal(ERR_DASHBOARD, fmt.Errorf("list games of chat %d: %w", chatId, err))
+		}
+		attendance, err := games.ListAttendanceBetween(ctx, chatId, time.Time{}, now)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not list attendance for the dashboard", "error", err)
+			return Internal(ERR_DASHBOARD, fmt.Errorf("list attendance of chat %d: %w", chatId, err))
+		}
+		dashboard.tally(list, attendance)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+func (d *Dashboard) tally(games []models.Game, attendance []models.Attendance) {
+	played := make(map[uuid.UUID]models.Game)
+	for i := len(games) - 1; i >= 0; i-- {
+		game := games[i]
+		if game.Cancelled {
+			continue
+		}
+		played[game.Id] = game
+		d.Played++
+		if len(d.History) < DASHBOARD_HISTORY {
+			d.History = append(d.History, game)
+		}
+		var goalsFor, goalsAgainst int
+		if _, err := fmt.Sscanf(game.Score, "%d-%d", &goalsFor, &goalsAgainst); err != nil {
+			continue
+		}
+		switch {
+		case goalsFor > goalsAgainst:
+			d.Won++
+		case goalsFor < goalsAgainst:
+			d.Lost++
+		default:
+			d.Drawn++
+		}
+	}
+
+	appearances := make(map[uuid.UUID]*Standing)
+	unpaid := make(map[uuid.UUID]*Standing)
+	for _, a := range attendance {
+		game, ok := played[a.GameId]
+		if !ok || a.Player.Status != string(ATTENDING) {
+			continue
+		}
+		count(appearances, a.Player)
+		if game.Price > 0 && !a.Player.HasPaid {
+			count(unpaid, a.Player)
+		}
+	}
+	d.Appearances, d.Unpaid = ranked(appearances), ranked(unpaid)
+}
+
+func count(standings map[uuid.UUID]*Standing, player models.User) {
+	standing, ok := standings[player.Id]
+	if !ok {
+		standing = &Standing{Player: models.User{Id: player.Id, UserId: player.UserId, Name: player.Name}}
+		standings[player.Id] = standing
+	}
+	standing.Games++
+}
+
+// ranked lists standings by games, most first, then by name.
+func ranked(standings map[uuid.UUID]*Standing) []Standing {
+	list := make([]Standing, 0, len(standings))
+	for _, standing := range standings {
+		list = append(list, *standing)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Games != list[j].Games {
+			return list[i].Games > list[j].Games
+		}
+		return list[i].Player.Name < list[j].Player.Name
+	})
+	return list
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDashboard(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	now := time.Now()
+	var dashboard *Dashboard
+	getDashboard := func(s *GameService) (result, error) {
+		var err error
+		dashboard, err = s.Dashboard(context.Background(), chatID, now)
+		if err != nil {
+			return result{}, err
+		}
+		return result{dashboard.Game, &dashboard.Players, &dashboard.Absentees}, nil
+	}
+	addGame := func(t *testing.T, f *fixture, date time.Time, score string, cancelled bool) *models.Game {
+		t.Helper()
+		game := &models.Game{Id: uuid.New(), ChatId: chatID, Date: date, Price: 10, Score: score}
+		if _, err := f.games.InsertGame(context.Background(), game); err != nil {
+			t.Fatalf("InsertGame: %v", err)
+		}
+		if cancelled {
+			if _, err := f.games.CancelGame(context.Background(), game); err != nil {
+				t.Fatalf("CancelGame: %v", err)
+			}
+		}
+		return game
+	}
+	join := func(t *testing.T, f *fixture, game *models.Game, user *models.User, status PlayerStatus, paid bool) {
+		t.Helper()
+		player := *user
+		player.Status = string(status)
+		if _, err := f.games.InsertGamePlayer(context.Background(), game, &player); err != nil {
+			t.Fatalf("InsertGamePlayer: %v", err)
+		}
+		if paid {
+			if err := f.games.UpdatePlayerPayment(context.Background(), game.Id, user.Id); err != nil {
+				t.Fatalf("UpdatePlayerPayment: %v", err)
+			}
+		}
+	}
+	withSeason := func(t *testing.T, f *fixture) {
+		bea, cy := f.addUser(t, 2, "Bea"), f.addUser(t, 3, "Cy")
+		won := addGame(t, f, now.Add(-3*week), "3-1", false)
+		join(t, f, won, bea, ATTENDING, true)
+		join(t, f, won, cy, ATTENDING, false)
+		addGame(t, f, now.Add(-2*week), "", true)
+		lost := addGame(t, f, now.Add(-week), "0-2", false)
+		join(t, f, lost, bea, ATTENDING, false)
+		join(t, f, lost, cy, OUT, false)
+		next := addGame(t, f, now.Add(week), "", false)
+		join(t, f, next, cy, ATTENDING, false)
+	}
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name:  "shows the next game, history and leaderboards",
+			setup: withSeason,
+			call:  getDashboard,
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game == nil || !got.game.Date.Equal(now.Add(week)) {
+					t.Errorf("game = %+v, want the next one", got.game)
+				}
+				wantNames(t, "players", got.players, "Cy")
+				if len(dashboard.History) != 2 || dashboard.History[0].Score != "0-2" || dashboard.History[1].Score != "3-1" {
+					t.Errorf("history = %+v, want the games played, latest first", dashboard.History)
+				}
+				if dashboard.Played != 2 || dashboard.Won != 1 || dashboard.Drawn != 0 || dashboard.Lost != 1 {
+					t.Errorf("record = %d played, %d-%d-%d, want 2 played, 1-0-1", dashboard.Played, dashboard.Won, dashboard.Drawn, dashboard.Lost)
+				}
+				wantStandings(t, "appearances", dashboard.Appearances, "Bea", 2, "Cy", 1)
+				wantStandings(t, "unpaid", dashboard.Unpaid, "Bea", 1, "Cy", 1)
+			},
+		},
+		{
+			name: "shows an empty dashboard for a chat without games",
+			call: getDashboard,
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game != nil || dashboard.Played != 0 || len(dashboard.History) != 0 || len(dashboard.Appearances) != 0 {
+					t.Errorf("dashboard = %+v, want it empty", dashboard)
+				}
+			},
+		},
+		{
+			name:  "counts scoreless games as played only",
+			setup: func(t *testing.T, f *fixture) { addGame(t, f, now.Add(-week), "", false) },
+			call:  getDashboard,
+			check: func(t *testing.T, f *fixture, got result) {
+				if dashboard.Played != 1 || dashboard.Won+dashboard.Drawn+dashboard.Lost != 0 {
+					t.Errorf("record = %d played, %d-%d-%d, want 1 played without result", dashboard.Played, dashboard.Won, dashboard.Drawn, dashboard.Lost)
+				}
+			},
+		},
+		{
+			name: "fails when attendance cannot be read",
+			setup: func(t *testing.T, f *fixture) {
+				withSeason(t, f)
+				failing("ListAttendanceBetween")(t, f)
+			},
+			call:     getDashboard,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_DASHBOARD,
+		},
+		{
+			name: "fails when the next game cannot be read",
+			setup: func(t *testing.T, f *fixture) {
+				withSeason(t, f)
+				failing("GetLatestGameByChatID")(t, f)
+			},
+			call:     getDashboard,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_GAME_DETAILS,
+		},
+	})
+}
+
+// wantStandings checks standings against pairs of names and games.
+func wantStandings(t *testing.T, what string, standings []Standing, want ...any) {
+	t.Helper()
+	if len(standings)*2 != len(want) {
+		t.Errorf("%s = %+v, want %v", what, standings, want)
+		return
+	}
+	for i, standing := range standings {
+		if standing.Player.Name != want[2*i] || standing.Games != want[2*i+1] {
+			t.Errorf("%s = %+v, want %v", what, standings, want)
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+// GamesReport is what exports are made of: the games of a chat kicking off
+// from From until before To, and who played them and paid.
+type GamesReport struct {
+	ChatId      int64
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+	Games       []models.Game
+	Attendance  []models.Attendance
+}
+
+// OPEN_END stands for a range with no end. It is far enough away not to
+// matter, and every backend can store it.
+var OPEN_END = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// ExportGames reports on the chat's games kicking off from from until before
+// to, cancelled ones included. A zero from or to leaves the range open on
+// that side.
+func (g *GameService) ExportGames(ctx context.Context, chatId int64, from time.Time, to time.Time) (*GamesReport, error) {
+	if to.IsZero() {
+		to = OPEN_END
+	}
+	if !from.Before(to) {
+		return nil, Invalid("to", ERR_EXPORT_RANGE, nil)
+	}
+
+	report := &GamesReport{ChatId: chatId, From: from, To: to, GeneratedAt: time.Now()}
+	err := g.atomically(ctx, func(ctx context.Context, games repositories.IGameRepository) error {
+		var err error
+		report.Games, err = games.ListGamesBetween(ctx, chatId, from, to)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not list games to export", "error", err)
+			return Internal(ERR_EXPORT, fmt.Errorf("list games of chat %d: %w", chatId, err))
+		}
+		report.Attendance, err = games.ListAttendanceBetween(ctx, chatId, from, to)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not list attendance to export", "error", err)
+			return Internal(ERR_EXPORT, fmt.Errorf("list attendance of chat %d: %w", chatId, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Games) == 0 {
+		return nil, NotFound(ERR_NO_GAMES_TO_EXPORT)
+	}
+	return report, nil
+}
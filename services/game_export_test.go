@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"tg-sunday-league/models"
+	"time"
+)
+
+func (r *failingRepository) ListAttendanceBetween(ctx context.Context, chatID int64, from time.Time, to time.Time) ([]models.Attendance, error) {
+	if r.method == "ListAttendanceBetween" {
+		return nil, errDatabase
+	}
+	return r.IGameRepository.ListAttendanceBetween(ctx, chatID, from, to)
+}
+
+func TestExportGames(t *testing.T) {
+	start := time.Now().Add(-30 * 24 * time.Hour).Truncate(time.Hour)
+	var report *GamesReport
+	exportBetween := func(from, to time.Time) func(s *GameService) (result, error) {
+		return func(s *GameService) (result, error) {
+			var err error
+			report, err = s.ExportGames(context.Background(), chatID, from, to)
+			return result{}, err
+		}
+	}
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name: "reports the games of the period with their players",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, start.Add(-time.Hour))
+				game := f.addGame(t, start)
+				f.addPlayer(t, game, 1, "Ana", ATTENDING)
+				f.addPlayer(t, game, 2, "Bea", OUT)
+				f.addGame(t, start.Add(7*24*time.Hour))
+				f.addGame(t, start.Add(14*24*time.Hour))
+			},
+			call: exportBetween(start, start.Add(14*24*time.Hour)),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if len(report.Games) != 2 || !report.Games[0].Date.Equal(start) {
+					t.Errorf("games = %+v, want the two from the start of the period", report.Games)
+				}
+				if len(report.Attendance) != 2 || report.Attendance[0].Player.Name != "Ana" || report.Attendance[1].Player.Status != string(OUT) {
+					t.Errorf("attendance = %+v, want Ana attending and Bea out", report.Attendance)
+				}
+			},
+		},
+		{
+			name: "leaves a zero end open",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, start)
+				f.addGame(t, time.Now().Add(365*24*time.Hour))
+			},
+			call: exportBetween(time.Time{}, time.Time{}),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if len(report.Games) != 2 || !report.To.Equal(OPEN_END) {
+					t.Errorf("report = %+v, want both games until OPEN_END", report)
+				}
+			},
+		},
+		{
+			name:     "rejects a period ending before it starts",
+			call:     exportBetween(start, start.Add(-time.Hour)),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_EXPORT_RANGE,
+		},
+		{
+			name:     "fails when there is nothing to export",
+			setup:    func(t *testing.T, f *fixture) { f.addGame(t, start.Add(-time.Hour)) },
+			call:     exportBetween(start, time.Time{}),
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_NO_GAMES_TO_EXPORT,
+		},
+		{
+			name:     "fails when attendance cannot be read",
+			setup:    failing("ListAttendanceBetween"),
+			call:     exportBetween(start, time.Time{}),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_EXPORT,
+		},
+	})
+}
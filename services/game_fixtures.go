@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportedFixture is an upcoming game read from a league's fixture list. Uid
+// identifies it across versions of the list, so sending an updated list
+// moves the fixtures it changed rather than adding them twice.
+type ImportedFixture struct {
+	Uid       string
+	Date      time.Time
+	Location  string
+	Opponent  string
+	Cancelled bool // Cancelled by the league
+}
+
+// FixturesImport is a fixture list as read. Undated counts its events
+// without a kickoff time, which are left out.
+type FixturesImport struct {
+	Fixtures []ImportedFixture
+	Undated  int
+}
+
+// FixturesReport tells what ImportFixtures scheduled, or would have on a dry
+// run.
+type FixturesReport struct {
+	DryRun    bool
+	Added     []models.Fixture
+	Moved     []models.Fixture // Waiting fixtures whose kickoff, location or opponent changed
+	Removed   []models.Fixture // Waiting fixtures the league cancelled
+	Unchanged int
+	Past      int // New fixtures left out as they kicked off already
+	Undated   int
+	// Rescheduled and Cancelled hold the fixture opened as the upcoming game
+	// when the league changed or cancelled it, which changes its game too.
+	Rescheduled []models.Fixture
+	Cancelled   []models.Fixture
+	// Opened is the game opened from the first fixture when the chat had no
+	// upcoming game, nil otherwise.
+	Opened *models.Game
+}
+
+// ImportFixtures schedules the fixtures of the chat's league. They are opened
+// as games one at a time, the first one right away if the chat has no
+// upcoming game and each of the others once the game before it kicked off,
+// so players always answer for the next game. On a dry run nothing is
+// written.
+func (g *GameService) ImportFixtures(ctx context.Context, chatId int64, in *FixturesImport, dryRun bool) (*FixturesReport, error) {
+	if len(in.Fixtures) == 0 {
+		return nil, Invalid("file", ERR_NO_FIXTURES, nil)
+	}
+	settings, err := g.SettingsService.GetSettings(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *FixturesReport
+	err = g.changeGames(ctx, func(ctx context.Context, games repositories.IGameRepository) ([]Event, error) {
+		now := time.Now()
+		existing, err := games.ListFixtures(ctx, chatId)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not list fixtures", "error", err)
+			return nil, Internal(ERR_FIXTURES, fmt.Errorf("list fixtures of chat %d: %w", chatId, err))
+		}
+		upcoming, err := games.GetLatestGameByChatID(ctx, chatId)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not find the latest game", "error", err)
+			return nil, Internal(ERR_LATEST_GAME, fmt.Errorf("get latest game of chat %d: %w", chatId, err))
+		}
+		if upcoming != nil && !upcoming.Date.After(now) {
+			upcoming = nil
+		}
+		report = planFixtures(chatId, existing, upcoming, in, now)
+		report.DryRun = dryRun
+		if dryRun {
+			return nil, nil
+		}
+
+		events, err := writeFixtures(ctx, games, upcoming, report)
+		if err != nil {
+			return nil, err
+		}
+		report.Opened, err = openNextFixture(ctx, games, chatId, settings, now)
+		if err != nil {
+			return nil, err
+		}
+		if report.Opened != nil {
+			events = append(events, newEvent(EVENT_GAME_CREATED, report.Opened, nil))
+		}
+		return events, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		logging.FromContext(ctx).Info("Fixtures imported", "added", len(report.Added), "moved", len(report.Moved), "removed", len(report.Removed),
+			"rescheduled", len(report.Rescheduled), "cancelled", len(report.Cancelled))
+	}
+	return report, nil
+}
+
+// planFixtures compares the imported fixtures with those the chat has.
+// Fixtures already opened as games only change while their game is the
+// chat's upcoming one: once it kicked off or was cancelled in the chat, it is
+// left as it is.
+func planFixtures(chatId int64, existing []models.Fixture, upcoming *models.Game, in *FixturesImport, now time.Time) *FixturesReport {
+	report := &FixturesReport{Undated: in.Undated}
+	known := make(map[string]models.Fixture, len(existing))
+	for _, fixture := range existing {
+		known[fixture.Uid] = fixture
+	}
+
+	imported := append([]ImportedFixture(nil), in.Fixtures...)
+	sort.SliceStable(imported, func(i, j int) bool { return imported[i].Date.Before(imported[j].Date) })
+	for _, f := range imported {
+		fixture, ok := known[f.Uid]
+		opened := ok && fixture.GameId != uuid.Nil
+		switch {
+		case opened && (upcoming == nil || fixture.GameId != upcoming.Id):
+			report.Unchanged++
+		case f.Cancelled && opened:
+			report.Cancelled = append(report.Cancelled, fixture)
+		case f.Cancelled:
+			if ok {
+				report.Removed = append(report.Removed, fixture)
+			}
+		case ok && fixture.Date.Equal(f.Date) && fixture.Location == f.Location && fixture.Opponent == f.Opponent:
+			report.Unchanged++
+		case ok:
+			fixture.Date, fixture.Location, fixture.Opponent = f.Date, f.Location, f.Opponent
+			if opened {
+				report.Rescheduled = append(report.Rescheduled, fixture)
+			} else {
+				report.Moved = append(report.Moved, fixture)
+			}
+		case !f.Date.After(now):
+			report.Past++
+		default:
+			report.Added = append(report.Added, models.Fixture{
+				Id:       uuid.New(),
+				ChatId:   chatId,
+				Uid:      f.Uid,
+				Date:     f.Date,
+				Location: f.Location,
+				Opponent: f.Opponent,
+			})
+		}
+	}
+	return report
+}
+
+// writeFixtures saves the changes of report, moving or cancelling the
+// upcoming game along with its fixture, and returns the events of the changes
+// to games.
+func writeFixtures(ctx context.Context, games repositories.IGameRepository, upcoming *models.Game, report *FixturesReport) ([]Event, error) {
+	for _, fixture := range report.Added {
+		if err := games.InsertFixture(ctx, &fixture); err != nil {
+			logging.FromContext(ctx).Error("Could not create fixture", "error", err)
+			return nil, Internal(ERR_FIXTURES, fmt.Errorf("insert fixture %s: %w", fixture.Uid, err))
+		}
+	}
+	for _, fixture := range slices.Concat(report.Moved, report.Rescheduled) {
+		if err := games.UpdateFixture(ctx, &fixture); err != nil {
+			logging.FromContext(ctx).Error("Could not update fixture", "error", err)
+			return nil, Internal(ERR_FIXTURES, fmt.Errorf("update fixture %s: %w", fixture.Uid, err))
+		}
+	}
+	for _, fixture := range report.Removed {
+		if err := games.DeleteFixture(ctx, fixture.Id); err != nil {
+			logging.FromContext(ctx).Error("Could not delete fixture", "error", err)
+			return nil, Internal(ERR_FIXTURES, fmt.Errorf("delete fixture %s: %w", fixture.Uid, err))
+		}
+	}
+
+	var events []Event
+	for _, fixture := range report.Rescheduled {
+		moved := *upcoming
+		moved.Date, moved.Location, moved.Opponent = fixture.Date, fixture.Location, fixture.Opponent
+		rescheduled, err := games.RescheduleGame(ctx, &moved)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not reschedule the game of a fixture", "error", err)
+			return nil, Internal(ERR_FIXTURES, fmt.Errorf("reschedule game of fixture %s: %w", fixture.Uid, err))
+		}
+		logging.FromContext(ctx).Info("Game rescheduled by the league", "game_id", rescheduled.Id, "kickoff", rescheduled.Date)
+		events = append(events, newEvent(EVENT_GAME_EDITED, rescheduled, nil))
+	}
+	for _, fixture := range report.Cancelled {
+		// The fixture stays linked to its game, so it is not opened again.
+		cancelled, err := games.CancelGame(ctx, upcoming)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not cancel the game of a fixture", "error", err)
+			return nil, Internal(ERR_GAME_CANCEL, fmt.Errorf("cancel game of fixture %s: %w", fixture.Uid, err))
+		}
+		logging.FromContext(ctx).Info("Game cancelled by the league", "game_id", cancelled.Id)
+		events = append(events, newEvent(EVENT_GAME_CANCELLED, cancelled, nil))
+	}
+	return events, nil
+}
+
+// OpenNextFixture opens the chat's next fixture as a game, with its players
+// and absentees, unless the chat has an upcoming game already. The game is
+// nil when nothing was opened.
+func (g *GameService) OpenNextFixture(ctx context.Context, chatId int64) (*models.Game, *[]models.User, *[]models.User, error) {
+	settings, err := g.SettingsService.GetSettings(ctx, chatId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var game *models.Game
+	var players *[]models.User
+	var absentees *[]models.User
+	err = g.changeGames(ctx, func(ctx context.Context, games repositories.IGameRepository) ([]Event, error) {
+		opened, err := openNextFixture(ctx, games, chatId, settings, time.Now())
+		if err != nil || opened == nil {
+			game = nil
+			return nil, err
+		}
+		game, players, absentees, err = loadGameDetails(ctx, games, chatId)
+		if err != nil {
+			return nil, err
+		}
+		return []Event{newEvent(EVENT_GAME_CREATED, game, nil)}, nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return game, players, absentees, nil
+}
+
+// ChatsWithFixtures returns the chats with fixtures still to be opened.
+func (g *GameService) ChatsWithFixtures(ctx context.Context) ([]int64, error) {
+	var chats []int64
+	err := g.atomically(ctx, func(ctx context.Context, games repositories.IGameRepository) error {
+		var err error
+		chats, err = games.ListFixtureChats(ctx, time.Now())
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not list chats with fixtures", "error", err)
+			return Internal(ERR_FIXTURES, fmt.Errorf("list chats with fixtures: %w", err))
+		}
+		return nil
+	})
+	return chats, err
+}
+
+// openNextFixture creates the game of the earliest fixture still to come if
+// the chat has no upcoming game, at the chat's default price. Fixtures whose
+// kickoff passed while another game was upcoming are never opened.
+func openNextFixture(ctx context.Context, games repositories.IGameRepository, chatId int64, settings *models.ChatSettings, now time.Time) (*models.Game, error) {
+	latest, err := games.GetLatestGameByChatID(ctx, chatId)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not find the latest game", "error", err)
+		return nil, Internal(ERR_LATEST_GAME, fmt.Errorf("get latest game of chat %d: %w", chatId, err))
+	}
+	if latest != nil && latest.Date.After(now) {
+		return nil, nil
+	}
+
+	fixtures, err := games.ListFixtures(ctx, chatId)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not list fixtures", "error", err)
+		return nil, Internal(ERR_FIXTURES, fmt.Errorf("list fixtures of chat %d: %w", chatId, err))
+	}
+	for _, fixture := range fixtures {
+		if fixture.GameId != uuid.Nil || !fixture.Date.After(now) {
+			continue
+		}
+		game := &models.Game{
+			Id:       uuid.New(),
+			ChatId:   chatId,
+			Price:    settings.DefaultPrice,
+			Date:     fixture.Date,
+			Location: fixture.Location,
+			Opponent: fixture.Opponent,
+		}
+		if _, err := games.InsertGame(ctx, game); err != nil {
+			logging.FromContext(ctx).Error("Could not create game from fixture", "error", err)
+			return nil, Internal(ERR_GAME_CREATE, fmt.Errorf("insert game of fixture %s: %w", fixture.Uid, err))
+		}
+		if err := games.SetFixtureGame(ctx, fixture.Id, game.Id); err != nil {
+			logging.FromContext(ctx).Error("Could not link fixture to its game", "error", err)
+			return nil, Internal(ERR_FIXTURES, fmt.Errorf("open fixture %s: %w", fixture.Uid, err))
+		}
+		logging.FromContext(ctx).Info("Fixture opened", "game_id", game.Id, "kickoff", game.Date)
+		return game, nil
+	}
+	return nil, nil
+}
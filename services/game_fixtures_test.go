@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"tg-sunday-league/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestImportFixtures(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	next := time.Now().Add(week).Truncate(time.Hour)
+	var report *FixturesReport
+	importFixtures := func(in *FixturesImport, dryRun bool) func(s *GameService) (result, error) {
+		return func(s *GameService) (result, error) {
+			var err error
+			report, err = s.ImportFixtures(context.Background(), chatID, in, dryRun)
+			if err != nil {
+				return result{}, err
+			}
+			return result{game: report.Opened}, nil
+		}
+	}
+	season := &FixturesImport{Fixtures: []ImportedFixture{
+		{Uid: "2", Date: next.Add(week), Location: "Bishan", Opponent: "United"},
+		{Uid: "1", Date: next, Location: "Kallang", Opponent: "Rovers"},
+		{Uid: "0", Date: next.Add(-2 * week), Opponent: "Athletic"},
+	}, Undated: 1}
+	addFixture := func(t *testing.T, f *fixture, uid string, date time.Time, gameId uuid.UUID) {
+		t.Helper()
+		fixture := &models.Fixture{Id: uuid.New(), ChatId: chatID, Uid: uid, Date: date, Location: "Kallang", Opponent: "Rovers"}
+		if err := f.games.InsertFixture(context.Background(), fixture); err != nil {
+			t.Fatalf("InsertFixture: %v", err)
+		}
+		if gameId != uuid.Nil {
+			if err := f.games.SetFixtureGame(context.Background(), fixture.Id, gameId); err != nil {
+				t.Fatalf("SetFixtureGame: %v", err)
+			}
+		}
+	}
+	listFixtures := func(t *testing.T, f *fixture) []models.Fixture {
+		t.Helper()
+		fixtures, err := f.games.ListFixtures(context.Background(), chatID)
+		if err != nil {
+			t.Fatalf("ListFixtures: %v", err)
+		}
+		return fixtures
+	}
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name:  "schedules the fixtures and opens the first",
+			setup: func(t *testing.T, f *fixture) { f.setSetting(t, SETTING_DEFAULT_PRICE, "12") },
+			call:  importFixtures(season, false),
+			check: func(t *testing.T, f *fixture, got result) {
+				if len(report.Added) != 2 || report.Past != 1 || report.Undated != 1 {
+					t.Errorf("report = %+v, want 2 added and 1 past", report)
+				}
+				if got.game == nil || !got.game.Date.Equal(next) || got.game.Opponent != "Rovers" || got.game.Location != "Kallang" || got.game.Price != 12 {
+					t.Fatalf("opened = %+v, want the game against Rovers at the default price", got.game)
+				}
+				fixtures := listFixtures(t, f)
+				if len(fixtures) != 2 || fixtures[0].GameId != got.game.Id || fixtures[1].GameId != uuid.Nil {
+					t.Errorf("fixtures = %+v, want the first opened", fixtures)
+				}
+			},
+		},
+		{
+			name: "writes nothing on a dry run",
+			call: importFixtures(season, true),
+			check: func(t *testing.T, f *fixture, got result) {
+				if !report.DryRun || len(report.Added) != 2 || got.game != nil {
+					t.Errorf("report = %+v, want 2 fixtures to add", report)
+				}
+				if fixtures := listFixtures(t, f); len(fixtures) != 0 {
+					t.Errorf("fixtures = %+v, want none", fixtures)
+				}
+			},
+		},
+		{
+			name: "waits for the upcoming game",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(time.Hour))
+			},
+			call: importFixtures(season, false),
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game != nil {
+					t.Errorf("opened = %+v, want none while a game is upcoming", got.game)
+				}
+				if games, _ := f.games.ListGamesByChatID(context.Background(), chatID); len(games) != 1 {
+					t.Errorf("games = %+v, want only the upcoming one", games)
+				}
+			},
+		},
+		{
+			name: "moves and removes the fixtures the league changed",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(time.Hour))
+				addFixture(t, f, "1", next, uuid.Nil)
+				addFixture(t, f, "2", next.Add(week), uuid.Nil)
+				addFixture(t, f, "3", next.Add(2*week), uuid.Nil)
+			},
+			call: importFixtures(&FixturesImport{Fixtures: []ImportedFixture{
+				{Uid: "1", Date: next, Location: "Kallang", Opponent: "Rovers"},
+				{Uid: "2", Date: next.Add(week + time.Hour), Location: "Kallang", Opponent: "Rovers"},
+				{Uid: "3", Date: next.Add(2 * week), Cancelled: true},
+			}}, false),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if report.Unchanged != 1 || len(report.Moved) != 1 || len(report.Removed) != 1 || len(report.Added) != 0 {
+					t.Errorf("report = %+v, want 1 unchanged, 1 moved and 1 removed", report)
+				}
+				fixtures := listFixtures(t, f)
+				if len(fixtures) != 2 || !fixtures[1].Date.Equal(next.Add(week+time.Hour)) {
+					t.Errorf("fixtures = %+v, want the second moved and the third removed", fixtures)
+				}
+			},
+		},
+		{
+			name: "reschedules the upcoming game the league moved",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, next)
+				addFixture(t, f, "1", next, game.Id)
+			},
+			call: importFixtures(&FixturesImport{Fixtures: []ImportedFixture{
+				{Uid: "1", Date: next.Add(time.Hour), Location: "Bishan", Opponent: "Rovers"},
+			}}, false),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if len(report.Rescheduled) != 1 || report.Unchanged != 0 || len(report.Moved) != 0 {
+					t.Errorf("report = %+v, want the opened fixture rescheduled", report)
+				}
+				fixtures := listFixtures(t, f)
+				game, _ := f.games.GetGameById(context.Background(), fixtures[0].GameId)
+				if game == nil || !game.Date.Equal(next.Add(time.Hour)) || game.Location != "Bishan" || game.Cancelled || game.Sequence != 1 {
+					t.Errorf("game = %+v, want it moved to Bishan an hour later", game)
+				}
+				if !fixtures[0].Date.Equal(next.Add(time.Hour)) || fixtures[0].Location != "Bishan" {
+					t.Errorf("fixtures = %+v, want the fixture moved with its game", fixtures)
+				}
+			},
+		},
+		{
+			name: "lists the upcoming game the league moved on a dry run",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, next)
+				addFixture(t, f, "1", next, game.Id)
+			},
+			call: importFixtures(&FixturesImport{Fixtures: []ImportedFixture{
+				{Uid: "1", Date: next.Add(time.Hour), Location: "Kallang", Opponent: "Rovers"},
+			}}, true),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if len(report.Rescheduled) != 1 || !report.Rescheduled[0].Date.Equal(next.Add(time.Hour)) {
+					t.Errorf("report = %+v, want the opened fixture to reschedule", report)
+				}
+				if game, _ := f.games.GetLatestGameByChatID(context.Background(), chatID); game == nil || !game.Date.Equal(next) {
+					t.Errorf("game = %+v, want it left as it was", game)
+				}
+			},
+		},
+		{
+			name: "cancels the upcoming game the league cancelled and opens the next",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, next)
+				addFixture(t, f, "1", next, game.Id)
+				addFixture(t, f, "2", next.Add(week), uuid.Nil)
+			},
+			call: importFixtures(&FixturesImport{Fixtures: []ImportedFixture{
+				{Uid: "1", Date: next, Cancelled: true},
+				{Uid: "2", Date: next.Add(week), Location: "Kallang", Opponent: "Rovers"},
+			}}, false),
+			check: func(t *testing.T, f *fixture, got result) {
+				if len(report.Cancelled) != 1 || report.Unchanged != 1 || len(report.Removed) != 0 {
+					t.Errorf("report = %+v, want the opened fixture cancelled", report)
+				}
+				fixtures := listFixtures(t, f)
+				cancelled, _ := f.games.GetGameById(context.Background(), fixtures[0].GameId)
+				if cancelled == nil || !cancelled.Cancelled {
+					t.Errorf("game = %+v, want it cancelled", cancelled)
+				}
+				if got.game == nil || !got.game.Date.Equal(next.Add(week)) || fixtures[1].GameId != got.game.Id {
+					t.Errorf("opened = %+v, want the next fixture opened in its place", got.game)
+				}
+			},
+		},
+		{
+			name: "leaves fixtures whose game kicked off as they are",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, time.Now().Add(-time.Hour))
+				addFixture(t, f, "1", game.Date, game.Id)
+			},
+			call: importFixtures(&FixturesImport{Fixtures: []ImportedFixture{
+				{Uid: "1", Date: next.Add(time.Hour), Cancelled: true},
+			}}, false),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if report.Unchanged != 1 || len(report.Cancelled) != 0 || len(report.Rescheduled) != 0 {
+					t.Errorf("report = %+v, want the opened fixture unchanged", report)
+				}
+				if game, _ := f.games.GetLatestGameByChatID(context.Background(), chatID); game == nil {
+					t.Errorf("latest game = nil, want the game played left as it was")
+				}
+			},
+		},
+		{
+			name:     "rejects a file without fixtures",
+			call:     importFixtures(&FixturesImport{Undated: 2}, false),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_NO_FIXTURES,
+		},
+		{
+			name:     "rolls back when the fixture cannot be opened",
+			setup:    failing("SetFixtureGame"),
+			call:     importFixtures(season, false),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_FIXTURES,
+			check: func(t *testing.T, f *fixture, _ result) {
+				if fixtures := listFixtures(t, f); len(fixtures) != 0 {
+					t.Errorf("fixtures = %+v, want the import rolled back", fixtures)
+				}
+				if games, _ := f.games.ListGamesByChatID(context.Background(), chatID); len(games) != 0 {
+					t.Errorf("games = %+v, want none", games)
+				}
+			},
+		},
+	})
+}
+
+func TestOpenNextFixture(t *testing.T) {
+	openNext := func(s *GameService) (result, error) {
+		game, players, absentees, err := s.OpenNextFixture(context.Background(), chatID)
+		return result{game, players, absentees}, err
+	}
+	addFixture := func(t *testing.T, f *fixture, date time.Time) {
+		t.Helper()
+		fixture := &models.Fixture{Id: uuid.New(), ChatId: chatID, Uid: date.String(), Date: date, Opponent: "United"}
+		if err := f.games.InsertFixture(context.Background(), fixture); err != nil {
+			t.Fatalf("InsertFixture: %v", err)
+		}
+	}
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name: "opens the next fixture once the game before kicked off",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(-time.Hour))
+				addFixture(t, f, time.Now().Add(-time.Minute))
+				addFixture(t, f, time.Now().Add(time.Hour))
+			},
+			call: openNext,
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game == nil || got.game.Opponent != "United" || !got.game.Date.After(time.Now()) {
+					t.Fatalf("opened = %+v, want the fixture still to come", got.game)
+				}
+				if got.players == nil || len(*got.players) != 0 {
+					t.Errorf("players = %v, want none yet", got.players)
+				}
+			},
+		},
+		{
+			name: "opens nothing while a game is upcoming",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(time.Hour))
+				addFixture(t, f, time.Now().Add(2*time.Hour))
+			},
+			call: openNext,
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game != nil {
+					t.Errorf("opened = %+v, want none", got.game)
+				}
+			},
+		},
+		{
+			name: "opens nothing without fixtures",
+			call: openNext,
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game != nil {
+					t.Errorf("opened = %+v, want none", got.game)
+				}
+			},
+		},
+	})
+}
+
+func TestChatsWithFixtures(t *testing.T) {
+	f := newFixture()
+	fixture := &models.Fixture{Id: uuid.New(), ChatId: chatID, Uid: "1", Date: time.Now().Add(time.Hour)}
+	if err := f.games.InsertFixture(context.Background(), fixture); err != nil {
+		t.Fatalf("InsertFixture: %v", err)
+	}
+	chats, err := f.service().ChatsWithFixtures(context.Background())
+	if err != nil || len(chats) != 1 || chats[0] != chatID {
+		t.Errorf("ChatsWithFixtures = %v, %v, want [%d]", chats, err, chatID)
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportedGame is a past game read from an import file, with its players as
+// the file names them. Line is the line of the file it starts on.
+type ImportedGame struct {
+	Line     int
+	Date     time.Time
+	Opponent string
+	Location string
+	Price    float64
+	Score    string
+	Players  []ImportedPlayer
+}
+
+// ImportedPlayer is a player of an ImportedGame. Players are matched by
+// UserId, their Telegram ID, when the file gives it, and by Name otherwise.
+type ImportedPlayer struct {
+	Line    int
+	Name    string
+	UserId  int64
+	Status  PlayerStatus
+	HasPaid bool
+}
+
+// ImportConflict is why a line of an import file cannot be imported, as a
+// message code with its arguments, like an Error.
+type ImportConflict struct {
+	Line int
+	Code ErrorCode
+	Args []interface{}
+}
+
+// GamesImport is an import file as read, with the conflicts found reading it.
+type GamesImport struct {
+	Games     []ImportedGame
+	Conflicts []ImportConflict
+}
+
+// ImportReport tells what ImportGames wrote, or would have written on a dry
+// run. Nothing is written while there are Conflicts.
+type ImportReport struct {
+	DryRun     bool
+	Games      int
+	Answers    int      // Answers of players recorded with the games
+	NewPlayers []string // Names of the players created from their Telegram ID
+	Conflicts  []ImportConflict
+}
+
+// Imported reports whether the games were written.
+func (r *ImportReport) Imported() bool {
+	return !r.DryRun && len(r.Conflicts) == 0
+}
+
+// importPlan is what an import writes once nothing conflicts.
+type importPlan struct {
+	users     []models.User
+	games     []models.Game
+	conflicts []ImportConflict
+}
+
+func (p *importPlan) conflict(line int, code ErrorCode, args ...interface{}) {
+	p.conflicts = append(p.conflicts, ImportConflict{Line: line, Code: code, Args: args})
+}
+
+// ImportGames records past games of the chat kept before the bot, all in one
+// transaction. Players are matched to those who answered for the chat's
+// games, by name unless the file gives their Telegram ID, and are only
+// created from a Telegram ID. Games not played yet or kicking off with an
+// active game of the chat conflict. On a dry run, or while anything
+// conflicts, nothing is written and the report lists the conflicts.
+func (g *GameService) ImportGames(ctx context.Context, chatId int64, in *GamesImport, dryRun bool) (*ImportReport, error) {
+	if len(in.Games) == 0 && len(in.Conflicts) == 0 {
+		return nil, Invalid("file", ERR_NO_GAMES_TO_IMPORT, nil)
+	}
+
+	report := &ImportReport{DryRun: dryRun}
+	err := g.atomically(ctx, func(ctx context.Context, games repositories.IGameRepository) error {
+		plan, err := planImport(ctx, games, chatId, in, time.Now())
+		if err != nil {
+			return err
+		}
+		report.Games = len(plan.games)
+		for _, game := range plan.games {
+			report.Answers += len(game.Players)
+		}
+		for _, user := range plan.users {
+			report.NewPlayers = append(report.NewPlayers, user.Name)
+		}
+		report.Conflicts = plan.conflicts
+		if !report.Imported() {
+			return nil
+		}
+		return writeImport(ctx, games, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if report.Imported() {
+		logging.FromContext(ctx).Info("Games imported", "games", report.Games, "answers", report.Answers, "new_players", len(report.NewPlayers))
+	}
+	return report, nil
+}
+
+func planImport(ctx context.Context, games repositories.IGameRepository, chatId int64, in *GamesImport, now time.Time) (*importPlan, error) {
+	plan := &importPlan{conflicts: append([]ImportConflict(nil), in.Conflicts...)}
+
+	existing, err := games.ListGamesByChatID(ctx, chatId)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not list games to import into", "error", err)
+		return nil, Internal(ERR_IMPORT, fmt.Errorf("list games of chat %d: %w", chatId, err))
+	}
+	kickoffs := make(map[int64]bool)
+	for _, game := range existing {
+		if !game.Cancelled {
+			kickoffs[game.Date.Unix()] = true
+		}
+	}
+
+	known, err := games.ListChatPlayers(ctx, chatId)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not list players to import", "error", err)
+		return nil, Internal(ERR_IMPORT, fmt.Errorf("list players of chat %d: %w", chatId, err))
+	}
+	players := newPlayerIndex(known)
+
+	for _, imported := range in.Games {
+		switch {
+		case !imported.Date.Before(now):
+			plan.conflict(imported.Line, ERR_IMPORT_FUTURE_GAME)
+		case kickoffs[imported.Date.Unix()]:
+			plan.conflict(imported.Line, ERR_IMPORT_GAME_EXISTS)
+		}
+		kickoffs[imported.Date.Unix()] = true
+		if imported.Price < 0 {
+			plan.conflict(imported.Line, ERR_IMPORT_PRICE, fmt.Sprint(imported.Price))
+		}
+
+		game := models.Game{
+			Id:       uuid.New(),
+			ChatId:   chatId,
+			Price:    imported.Price,
+			Date:     imported.Date,
+			Location: imported.Location,
+			Opponent: imported.Opponent,
+			Score:    imported.Score,
+		}
+		answered := make(map[uuid.UUID]bool)
+		for _, p := range imported.Players {
+			user, err := players.resolve(ctx, games, plan, p)
+			if err != nil {
+				return nil, err
+			}
+			if user == nil {
+				continue
+			}
+			if answered[user.Id] {
+				plan.conflict(p.Line, ERR_IMPORT_DUPLICATE_PLAYER, p.Name)
+				continue
+			}
+			answered[user.Id] = true
+
+			player := *user
+			player.Status = string(p.Status)
+			if p.Status == "" {
+				player.Status = string(ATTENDING)
+			}
+			player.HasPaid = p.HasPaid
+			game.Players = append(game.Players, player)
+		}
+		plan.games = append(plan.games, game)
+	}
+
+	sort.SliceStable(plan.conflicts, func(i, j int) bool { return plan.conflicts[i].Line < plan.conflicts[j].Line })
+	return plan, nil
+}
+
+// playerIndex finds the players of an import among those of the chat and
+// those the import creates.
+type playerIndex struct {
+	byName   map[string][]models.User
+	byUserId map[int64]*models.User
+}
+
+func newPlayerIndex(users []models.User) *playerIndex {
+	index := &playerIndex{byName: make(map[string][]models.User), byUserId: make(map[int64]*models.User)}
+	for _, user := range users {
+		index.add(user)
+	}
+	return index
+}
+
+func (index *playerIndex) add(user models.User) {
+	key := nameKey(user.Name)
+	for _, other := range index.byName[key] {
+		if other.Id == user.Id {
+			return
+		}
+	}
+	index.byName[key] = append(index.byName[key], user)
+}
+
+// resolve returns the user p stands for, or nil after recording why there is
+// none in plan.
+func (index *playerIndex) resolve(ctx context.Context, games repositories.IGameRepository, plan *importPlan, p ImportedPlayer) (*models.User, error) {
+	if p.UserId == 0 {
+		matches := index.byName[nameKey(p.Name)]
+		switch len(matches) {
+		case 0:
+			plan.conflict(p.Line, ERR_IMPORT_UNKNOWN_PLAYER, p.Name)
+			return nil, nil
+		case 1:
+			return &matches[0], nil
+		}
+		plan.conflict(p.Line, ERR_IMPORT_AMBIGUOUS_PLAYER, len(matches), p.Name)
+		return nil, nil
+	}
+
+	if user, ok := index.byUserId[p.UserId]; ok {
+		return user, nil
+	}
+	user, err := games.GetUserByUserID(ctx, p.UserId)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not retrieve player to import", "error", err)
+		return nil, Internal(ERR_IMPORT, fmt.Errorf("get user %d: %w", p.UserId, err))
+	}
+	if user == nil {
+		user = &models.User{Id: uuid.New(), UserId: p.UserId, Name: p.Name}
+		plan.users = append(plan.users, *user)
+	}
+	index.byUserId[p.UserId] = user
+	index.add(*user)
+	return user, nil
+}
+
+// nameKey compares names regardless of case and spacing, as spreadsheets
+// kept by hand vary in both.
+func nameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func writeImport(ctx context.Context, games repositories.IGameRepository, plan *importPlan) error {
+	for _, user := range plan.users {
+		if _, err := games.InsertUser(ctx, &user); err != nil {
+			logging.FromContext(ctx).Error("Could not create imported player", "error", err)
+			return Internal(ERR_IMPORT, fmt.Errorf("insert user %d: %w", user.UserId, err))
+		}
+	}
+	for _, game := range plan.games {
+		if _, err := games.InsertGame(ctx, &game); err != nil {
+			logging.FromContext(ctx).Error("Could not create imported game", "error", err)
+			return Internal(ERR_IMPORT, fmt.Errorf("insert game at %v: %w", game.Date, err))
+		}
+		for _, player := range game.Players {
+			if _, err := games.InsertGamePlayer(ctx, &game, &player); err != nil {
+				logging.FromContext(ctx).Error("Could not record imported answer", "error", err)
+				return Internal(ERR_IMPORT, fmt.Errorf("insert player %s of game %s: %w", player.Id, game.Id, err))
+			}
+			if !player.HasPaid {
+				continue
+			}
+			if err := games.UpdatePlayerPayment(ctx, game.Id, player.Id); err != nil {
+				logging.FromContext(ctx).Error("Could not record imported payment", "error", err)
+				return Internal(ERR_IMPORT, fmt.Errorf("pay player %s of game %s: %w", player.Id, game.Id, err))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestImportGames(t *testing.T) {
+	season := time.Now().Add(-365 * 24 * time.Hour).Truncate(time.Hour)
+	week := 7 * 24 * time.Hour
+	var report *ImportReport
+	importGames := func(in *GamesImport, dryRun bool) func(s *GameService) (result, error) {
+		return func(s *GameService) (result, error) {
+			var err error
+			report, err = s.ImportGames(context.Background(), chatID, in, dryRun)
+			return result{}, err
+		}
+	}
+	// The chat already played at season with Ana and Bea.
+	withPlayers := func(t *testing.T, f *fixture) {
+		game := f.addGame(t, season)
+		f.addPlayer(t, game, 1, "Ana", ATTENDING)
+		f.addPlayer(t, game, 2, "Bea", ATTENDING)
+	}
+	history := &GamesImport{Games: []ImportedGame{
+		{Line: 2, Date: season.Add(week), Opponent: "Rovers", Price: 12, Score: "3-1", Players: []ImportedPlayer{
+			{Line: 2, Name: "Ana", Status: ATTENDING, HasPaid: true},
+			{Line: 3, Name: " bea ", Status: OUT},
+			{Line: 4, Name: "Caio", UserId: 3, Status: ATTENDING},
+		}},
+		{Line: 5, Date: season.Add(2 * week), Opponent: "United", Players: []ImportedPlayer{
+			{Line: 5, Name: "caio", Status: ATTENDING},
+		}},
+	}}
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name:  "imports the games with their players",
+			setup: withPlayers,
+			call:  importGames(history, false),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if !report.Imported() || report.Games != 2 || report.Answers != 4 || len(report.NewPlayers) != 1 || report.NewPlayers[0] != "Caio" {
+					t.Errorf("report = %+v, want 2 games with 4 answers and Caio created", report)
+				}
+				games, _ := f.games.ListGamesByChatID(context.Background(), chatID)
+				if len(games) != 3 || games[1].Score != "3-1" || games[1].Opponent != "Rovers" {
+					t.Fatalf("games = %+v, want the two imported after the first", games)
+				}
+				players, _ := f.games.GetGamePlayers(context.Background(), games[1].Id)
+				paid := make(map[string]bool)
+				status := make(map[string]string)
+				for _, p := range players {
+					paid[p.Name], status[p.Name] = p.HasPaid, p.Status
+				}
+				if len(players) != 3 || !paid["Ana"] || paid["Bea"] || status["Bea"] != string(OUT) || status["Caio"] != string(ATTENDING) {
+					t.Errorf("players = %+v, want Ana paid, Bea out and Caio attending", players)
+				}
+				if caio, _ := f.games.GetUserByUserID(context.Background(), 3); caio == nil {
+					t.Error("Caio was not created")
+				}
+			},
+		},
+		{
+			name:  "writes nothing on a dry run",
+			setup: withPlayers,
+			call:  importGames(history, true),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if report.Imported() || report.Games != 2 || len(report.Conflicts) != 0 {
+					t.Errorf("report = %+v, want 2 games to import", report)
+				}
+				if games, _ := f.games.ListGamesByChatID(context.Background(), chatID); len(games) != 1 {
+					t.Errorf("games = %+v, want only the first", games)
+				}
+				wantNoUser(t, f, 3)
+			},
+		},
+		{
+			name:  "reports conflicts by line and writes nothing",
+			setup: withPlayers,
+			call: importGames(&GamesImport{
+				Games: []ImportedGame{
+					{Line: 2, Date: season, Players: []ImportedPlayer{{Line: 2, Name: "Zed"}}},
+					{Line: 4, Date: season.Add(week), Players: []ImportedPlayer{{Line: 4, Name: "Ana"}, {Line: 5, Name: "ANA"}}},
+					{Line: 6, Date: time.Now().Add(week)},
+				},
+				Conflicts: []ImportConflict{{Line: 3, Code: ERR_IMPORT_PAID, Args: []interface{}{"maybe"}}},
+			}, false),
+			check: func(t *testing.T, f *fixture, _ result) {
+				want := []struct {
+					line int
+					code ErrorCode
+				}{
+					{2, ERR_IMPORT_GAME_EXISTS},
+					{2, ERR_IMPORT_UNKNOWN_PLAYER},
+					{3, ERR_IMPORT_PAID},
+					{5, ERR_IMPORT_DUPLICATE_PLAYER},
+					{6, ERR_IMPORT_FUTURE_GAME},
+				}
+				if report.Imported() || len(report.Conflicts) != len(want) {
+					t.Fatalf("conflicts = %+v, want %+v", report.Conflicts, want)
+				}
+				for i, c := range report.Conflicts {
+					if c.Line != want[i].line || c.Code != want[i].code {
+						t.Errorf("conflict %d = %+v, want %+v", i, c, want[i])
+					}
+				}
+				if games, _ := f.games.ListGamesByChatID(context.Background(), chatID); len(games) != 1 {
+					t.Errorf("games = %+v, want only the first", games)
+				}
+			},
+		},
+		{
+			name: "asks for a Telegram ID when a name is ambiguous",
+			setup: func(t *testing.T, f *fixture) {
+				withPlayers(t, f)
+				game := f.addGame(t, season.Add(-week))
+				f.addPlayer(t, game, 4, "Ana", ATTENDING)
+			},
+			call: importGames(&GamesImport{Games: []ImportedGame{
+				{Line: 2, Date: season.Add(week), Players: []ImportedPlayer{{Line: 2, Name: "Ana"}}},
+				{Line: 3, Date: season.Add(2 * week), Players: []ImportedPlayer{{Line: 3, Name: "Ana", UserId: 4}}},
+			}}, true),
+			check: func(t *testing.T, f *fixture, _ result) {
+				if len(report.Conflicts) != 1 || report.Conflicts[0].Code != ERR_IMPORT_AMBIGUOUS_PLAYER || report.Conflicts[0].Line != 2 {
+					t.Errorf("conflicts = %+v, want only line 2 to be ambiguous", report.Conflicts)
+				}
+			},
+		},
+		{
+			name:     "rejects a file without games",
+			call:     importGames(&GamesImport{}, false),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_NO_GAMES_TO_IMPORT,
+		},
+		{
+			name: "rolls back when a payment cannot be recorded",
+			setup: func(t *testing.T, f *fixture) {
+				withPlayers(t, f)
+				failing("UpdatePlayerPayment")(t, f)
+			},
+			call:     importGames(history, false),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_IMPORT,
+			check: func(t *testing.T, f *fixture, _ result) {
+				if games, _ := f.games.ListGamesByChatID(context.Background(), chatID); len(games) != 1 {
+					t.Errorf("games = %+v, want the import rolled back", games)
+				}
+				wantNoUser(t, f, 3)
+			},
+		},
+	})
+}
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"strconv"
-	"strings"
+	"tg-sunday-league/dateparse"
+	"tg-sunday-league/logging"
 	"tg-sunday-league/models"
 	"tg-sunday-league/repositories"
 	"time"
@@ -20,231 +22,416 @@ const (
 )
 
 type IGameService interface {
-	CreateNewGame(chatId int64, userId int64, userName string, gameData []string) (*models.Game, *[]models.User, *[]models.User, error)
-	CancelGame(chatId int64) (*models.Game, error)
-	RegisterPlayer(chatId *int64, userId *int64, userName *string, status PlayerStatus) (*models.Game, *[]models.User, *[]models.User, error)
-	GetGameDetails(chatId int64) (*models.Game, *[]models.User, *[]models.User, error)
-	RepayGame(chatId *int64, userId *int64) (*models.Game, *[]models.User, *[]models.User, error)
+	CreateNewGame(ctx context.Context, chatId int64, userId int64, userName string, gameData []string) (*models.Game, *[]models.User, *[]models.User, error)
+	CancelGame(ctx context.Context, chatId int64) (*models.Game, error)
+	RegisterPlayer(ctx context.Context, chatId *int64, userId *int64, userName *string, status PlayerStatus) (*models.Game, *[]models.User, *[]models.User, error)
+	GetGameDetails(ctx context.Context, chatId int64) (*models.Game, *[]models.User, *[]models.User, error)
+	RepayGame(ctx context.Context, chatId *int64, userId *int64) (*models.Game, *[]models.User, *[]models.User, error)
+	ExportGames(ctx context.Context, chatId int64, from time.Time, to time.Time) (*GamesReport, error)
+	ImportGames(ctx context.Context, chatId int64, in *GamesImport, dryRun bool) (*ImportReport, error)
+	ImportFixtures(ctx context.Context, chatId int64, in *FixturesImport, dryRun bool) (*FixturesReport, error)
+	OpenNextFixture(ctx context.Context, chatId int64) (*models.Game, *[]models.User, *[]models.User, error)
+	ChatsWithFixtures(ctx context.Context) ([]int64, error)
+	Dashboard(ctx context.Context, chatId int64, now time.Time) (*Dashboard, error)
 }
 
 type GameService struct {
-	GameRepository repositories.IGameRepository
+	GameRepository  repositories.IGameRepository
+	SettingsService ISettingsService
+	// UnitOfWork makes each operation atomic. Without one the operations run
+	// straight on GameRepository, one statement at a time.
+	UnitOfWork repositories.IUnitOfWork
+	// WebhookRepository is where the changes to games are queued for the
+	// chats' webhooks when there is no UnitOfWork, nil to queue none.
+	WebhookRepository repositories.IWebhookRepository
+	// Events is told of the changes to games once they are saved, nil for
+	// no one.
+	Events IEventPublisher
 }
 
-func (g *GameService) CreateNewGame(chatId int64, userId int64, userName string, gameData []string) (*models.Game, *[]models.User, *[]models.User, error) {
+// atomically runs fn with the game repository of a unit of work, so its
+// reads and writes all happen in one transaction.
+func (g *GameService) atomically(ctx context.Context, fn func(ctx context.Context, games repositories.IGameRepository) error) error {
+	return g.unitOfWork(ctx, func(ctx context.Context, repos repositories.Repositories) error {
+		return fn(ctx, repos.Games)
+	})
+}
 
-	userFound, err := g.GameRepository.GetUserByUserID(userId)
-	if err != nil {
-		log.Printf("Error retrieving user: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not retrieve user, please try again.")
-	}
-	if userFound == nil {
-		newUser := &models.User{
-			Id:     uuid.New(),
-			UserId: userId,
-			Name:   userName,
-		}
-		_, err = g.GameRepository.InsertUser(newUser)
-		userFound = newUser
+// changeGames runs fn like atomically and queues the events it returns for
+// the chat's webhooks in the same transaction, so they are saved with the
+// change they tell of or not at all. They are published on Events once it
+// committed, as it may be retried before.
+func (g *GameService) changeGames(ctx context.Context, fn func(ctx context.Context, games repositories.IGameRepository) ([]Event, error)) error {
+	var events []Event
+	err := g.unitOfWork(ctx, func(ctx context.Context, repos repositories.Repositories) error {
+		var err error
+		events, err = fn(ctx, repos.Games)
 		if err != nil {
-			log.Printf("Error creating user: %v", err)
-			return nil, nil, nil, fmt.Errorf("Could not create user, please try again.")
+			return err
 		}
-	}
-
-	prev_game, err := g.GameRepository.GetLatestGameByChatID(chatId)
+		if err := enqueueEvents(ctx, repos.Webhooks, events); err != nil {
+			logging.FromContext(ctx).Error("Could not queue events for webhooks", "error", err)
+			return Internal(ERR_WEBHOOKS, err)
+		}
+		return nil
+	})
 	if err != nil {
-		log.Printf("Could not find the latest game: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not find the latest game, please try again.")
+		return err
+	}
+	if g.Events != nil {
+		g.Events.Publish(ctx, events...)
 	}
+	return nil
+}
 
-	if prev_game != nil && prev_game.Date.After(time.Now()) {
-		return nil, nil, nil, fmt.Errorf("There is already a game scheduled on %s against %s",
-			prev_game.Date.Format("2006-01-02 15:04"),
-			prev_game.Opponent)
+func (g *GameService) unitOfWork(ctx context.Context, fn func(ctx context.Context, repos repositories.Repositories) error) error {
+	if g.UnitOfWork == nil {
+		return fn(ctx, repositories.Repositories{Games: g.GameRepository, Webhooks: g.WebhookRepository})
 	}
+	return g.UnitOfWork.Do(ctx, fn)
+}
 
-	dateStr, timeStr := gameData[0], gameData[1]
-	dateTimeStr := fmt.Sprintf("%s %s", dateStr, timeStr)
-	dateTime, err := time.Parse("2006-01-02 15:04", dateTimeStr)
+func (g *GameService) CreateNewGame(ctx context.Context, chatId int64, userId int64, userName string, gameData []string) (*models.Game, *[]models.User, *[]models.User, error) {
+	settings, err := g.SettingsService.GetSettings(ctx, chatId)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("Invalid date or time format. Please use YYYY-MM-DD HH:MM.")
+		return nil, nil, nil, err
 	}
 
-	location := gameData[2]
-	opponent := strings.Join(gameData[3:], " ")
-	game := &models.Game{
-		Id:        uuid.New(),
-		Date:      dateTime,
-		Location:  location,
-		Opponent:  opponent,
-		ChatId:    chatId,
-		CreatedBy: userFound.Id,
-	}
-	if len(gameData) > 4 {
-		priceStr := gameData[4]
-		price, err := strconv.ParseFloat(priceStr, 64)
+	var game *models.Game
+	var players *[]models.User
+	var absentees *[]models.User
+	err = g.changeGames(ctx, func(ctx context.Context, games repositories.IGameRepository) ([]Event, error) {
+		userFound, err := games.GetUserByUserID(ctx, userId)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("Invalid price format. Please provide a valid number.")
+			logging.FromContext(ctx).Error("Could not retrieve user", "error", err)
+			return nil, Internal(ERR_USER_RETRIEVE, fmt.Errorf("get user %d: %w", userId, err))
+		}
+		if userFound == nil {
+			newUser := &models.User{
+				Id:     uuid.New(),
+				UserId: userId,
+				Name:   userName,
+			}
+			_, err = games.InsertUser(ctx, newUser)
+			userFound = newUser
+			if err != nil {
+				logging.FromContext(ctx).Error("Could not create user", "error", err)
+				return nil, Internal(ERR_USER_CREATE, fmt.Errorf("insert user %d: %w", userId, err))
+			}
 		}
-		game.Price = price
-	}
 
-	_, err = g.GameRepository.InsertGame(game)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("Could not create game, please try again. %v", err)
-	}
+		now := time.Now().In(settings.Timezone)
 
-	var players *[]models.User
-	var absentees *[]models.User
+		prev_game, err := games.GetLatestGameByChatID(ctx, chatId)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not find the latest game", "error", err)
+			return nil, Internal(ERR_LATEST_GAME, fmt.Errorf("get latest game of chat %d: %w", chatId, err))
+		}
 
-	game, players, absentees, err = g.GetGameDetails(chatId)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("Could not retrieve game details, please try again.")
-	}
-	log.Printf("Game created successfully: %v", game)
-	return game, players, absentees, nil
+		if prev_game != nil && prev_game.Date.After(now) {
+			return nil, Conflict(ERR_GAME_ALREADY_SCHEDULED, prev_game.Date, prev_game.Opponent)
+		}
 
-}
+		dateTime, rest, err := parseKickoff(gameData, now)
+		if err != nil {
+			return nil, kickoffError(err)
+		}
 
-func (g *GameService) CancelGame(chatId int64) (*models.Game, error) {
-	game, err := g.GameRepository.GetLatestGameByChatID(chatId)
-	if err != nil {
-		log.Printf("Could not find the latest game: %v", err)
-		return nil, fmt.Errorf("Could not find the latest game, please try again.")
-	}
+		game = &models.Game{
+			Id:        uuid.New(),
+			Date:      dateTime,
+			ChatId:    chatId,
+			Price:     settings.DefaultPrice,
+			CreatedBy: userFound.Id,
+		}
+		if len(rest) > 0 {
+			game.Location = rest[0]
+		}
+		if len(rest) > 1 {
+			game.Opponent = rest[1]
+		}
+		if len(rest) > 2 {
+			priceStr := rest[2]
+			price, err := strconv.ParseFloat(priceStr, 64)
+			if err != nil || price < 0 {
+				return nil, Invalid("price", ERR_INVALID_PRICE, err)
+			}
+			game.Price = price
+		}
+
+		_, err = games.InsertGame(ctx, game)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not create game", "error", err)
+			return nil, Internal(ERR_GAME_CREATE, fmt.Errorf("insert game for chat %d: %w", chatId, err))
+		}
 
-	game, err = g.GameRepository.CancelGame(game)
+		game, players, absentees, err = loadGameDetails(ctx, games, chatId)
+		if err != nil {
+			return nil, err
+		}
+		return []Event{newEvent(EVENT_GAME_CREATED, game, nil)}, nil
+	})
 	if err != nil {
-		log.Printf("Could not cancel the game: %v", err)
-		return nil, fmt.Errorf("Could not cancel the game, please try again.")
+		return nil, nil, nil, err
 	}
-	return game, nil
+	logging.FromContext(ctx).Info("Game created", "game_id", game.Id, "kickoff", game.Date)
+	return game, players, absentees, nil
+
 }
 
-func (g *GameService) RegisterPlayer(chatID *int64, userId *int64, userName *string, status PlayerStatus) (*models.Game, *[]models.User, *[]models.User, error) {
+func (g *GameService) CancelGame(ctx context.Context, chatId int64) (*models.Game, error) {
+	var cancelled *models.Game
+	err := g.changeGames(ctx, func(ctx context.Context, games repositories.IGameRepository) ([]Event, error) {
+		game, err := games.GetLatestGameByChatID(ctx, chatId)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not find the latest game", "error", err)
+			return nil, Internal(ERR_LATEST_GAME, fmt.Errorf("get latest game of chat %d: %w", chatId, err))
+		}
+		if game == nil {
+			return nil, NotFound(ERR_NO_UPCOMING_GAME)
+		}
 
-	game, err := g.GameRepository.GetLatestGameByChatID(*chatID)
+		cancelled, err = games.CancelGame(ctx, game)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not cancel the game", "error", err)
+			return nil, Internal(ERR_GAME_CANCEL, fmt.Errorf("cancel game %s: %w", game.Id, err))
+		}
+		return []Event{newEvent(EVENT_GAME_CANCELLED, cancelled, nil)}, nil
+	})
 	if err != nil {
-		log.Printf("Could not find the latest game: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not find the latest game, please try again.")
-	}
-	if game == nil {
-		log.Printf("No existing game")
-		return nil, nil, nil, fmt.Errorf("No existing game.")
+		return nil, err
 	}
+	return cancelled, nil
+}
 
-	idFound, _ := g.GameRepository.GetUserByUserID(*userId)
-
-	var player *models.User
-	if idFound == nil {
-		player := &models.User{
-			Id:     uuid.New(),
-			UserId: *userId,
-			Name:   *userName,
-			Status: string(status),
+func (g *GameService) RegisterPlayer(ctx context.Context, chatID *int64, userId *int64, userName *string, status PlayerStatus) (*models.Game, *[]models.User, *[]models.User, error) {
+	var game *models.Game
+	var players *[]models.User
+	var absentees *[]models.User
+	err := g.changeGames(ctx, func(ctx context.Context, games repositories.IGameRepository) ([]Event, error) {
+		var err error
+		game, err = games.GetLatestGameByChatID(ctx, *chatID)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not find the latest game", "error", err)
+			return nil, Internal(ERR_LATEST_GAME, fmt.Errorf("get latest game of chat %d: %w", *chatID, err))
 		}
-		*userId, err = g.GameRepository.InsertUser(player)
+		if game == nil {
+			logging.FromContext(ctx).Debug("No existing game")
+			return nil, NotFound(ERR_NO_GAME)
+		}
+
+		player, err := games.GetUserByUserID(ctx, *userId)
 		if err != nil {
-			log.Printf("Error creating player: %v", err)
-			return nil, nil, nil, fmt.Errorf("Could not create player, please try again.")
+			logging.FromContext(ctx).Error("Could not retrieve player", "error", err)
+			return nil, Internal(ERR_PLAYER_RETRIEVE, fmt.Errorf("get user %d: %w", *userId, err))
+		}
+
+		if player == nil {
+			player = &models.User{
+				Id:     uuid.New(),
+				UserId: *userId,
+				Name:   *userName,
+			}
+			_, err = games.InsertUser(ctx, player)
+			if err != nil {
+				logging.FromContext(ctx).Error("Could not create player", "error", err)
+				return nil, Internal(ERR_PLAYER_CREATE, fmt.Errorf("insert user %d: %w", *userId, err))
+			}
 		}
-	} else {
-		player = idFound
 		player.Status = string(status)
-	}
 
-	playerForGameId, _ := g.GameRepository.GetPlayerForGame(player.Id, game.Id)
+		playerForGameId, err := games.GetPlayerForGame(ctx, player.Id, game.Id)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not retrieve player for game", "error", err)
+			return nil, Internal(ERR_PLAYER_GAME_RETRIEVE, fmt.Errorf("get player %s of game %s: %w", player.Id, game.Id, err))
+		}
 
-	if playerForGameId != nil {
-		g.GameRepository.UpdatePlayerGameStatus(game.Id, player.Id, string(status))
-		game, players, absentees, err := g.GetGameDetails(*chatID)
+		if playerForGameId == nil {
+			_, err = games.InsertGamePlayer(ctx, game, player)
+		}
+		// A player registered in between is updated instead, so two quick taps
+		// still end up with the latest status.
+		if playerForGameId != nil || errors.Is(err, repositories.ErrConflict) {
+			err = games.UpdatePlayerGameStatus(ctx, game.Id, player.Id, string(status))
+		}
 		if err != nil {
-			log.Printf("Error retrieving game details: %v", err)
-			return nil, nil, nil, fmt.Errorf("Could not retrieve game details, please try again.")
+			logging.FromContext(ctx).Error("Could not register player to game", "error", err)
+			return nil, Internal(ERR_PLAYER_REGISTER, fmt.Errorf("register player %s to game %s: %w", player.Id, game.Id, err))
 		}
-		return game, players, absentees, nil
-	}
 
-	_, err = g.GameRepository.InsertGamePlayer(game, player)
+		game, players, absentees, err = loadGameDetails(ctx, games, *chatID)
+		if err != nil {
+			return nil, err
+		}
+		eventType := EVENT_PLAYER_JOINED
+		if status == OUT {
+			eventType = EVENT_PLAYER_LEFT
+		}
+		return []Event{newEvent(eventType, game, findPlayer(players, absentees, player.Id))}, nil
+	})
 	if err != nil {
-		log.Printf("Error registering player to game: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not register player, please try again.")
+		return nil, nil, nil, err
 	}
-	game, players, absentees, err := g.GetGameDetails(*chatID)
+	return game, players, absentees, nil
+}
+
+func (g *GameService) GetGameDetails(ctx context.Context, chatID int64) (*models.Game, *[]models.User, *[]models.User, error) {
+	var game *models.Game
+	var players *[]models.User
+	var absentees *[]models.User
+	err := g.atomically(ctx, func(ctx context.Context, games repositories.IGameRepository) error {
+		var err error
+		game, players, absentees, err = loadGameDetails(ctx, games, chatID)
+		return err
+	})
 	if err != nil {
-		log.Printf("Error retrieving game details: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not retrieve game details, please try again.")
+		return nil, nil, nil, err
 	}
 	return game, players, absentees, nil
 }
 
-func (g *GameService) GetGameDetails(chatID int64) (*models.Game, *[]models.User, *[]models.User, error) {
-	game, err := g.GameRepository.GetLatestGameByChatID(chatID)
+// loadGameDetails returns the chat's latest game with its players split into
+// those attending and those out.
+func loadGameDetails(ctx context.Context, games repositories.IGameRepository, chatID int64) (*models.Game, *[]models.User, *[]models.User, error) {
+	game, err := games.GetLatestGameByChatID(ctx, chatID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not retrieve game details", "error", err)
+		return nil, nil, nil, Internal(ERR_GAME_DETAILS, fmt.Errorf("get latest game of chat %d: %w", chatID, err))
+	}
 	if game == nil {
-		return nil, nil, nil, fmt.Errorf("No upcoming game.")
+		return nil, nil, nil, NotFound(ERR_NO_UPCOMING_GAME)
 	}
+
+	allPlayers, err := games.GetGamePlayers(ctx, game.Id)
 	if err != nil {
-		log.Printf("Error retrieving game details: %v", err)
-		return nil, nil, nil, fmt.Errorf("No.")
+		logging.FromContext(ctx).Error("Could not retrieve game players", "error", err)
+		return nil, nil, nil, Internal(ERR_GAME_PLAYERS, fmt.Errorf("get players of game %s: %w", game.Id, err))
 	}
 
-	allPlayers, err := g.GameRepository.GetGamePlayers(game.Id)
+	players, absentees := splitPlayers(allPlayers)
+	return game, players, absentees, nil
+}
+
+// splitPlayers separates the players attending a game from the absentees.
+func splitPlayers(allPlayers []models.User) (*[]models.User, *[]models.User) {
 	var players []models.User
 	var absentees []models.User
 	for _, player := range allPlayers {
-		if player.Status == "OUT" {
+		if player.Status == string(OUT) {
 			absentees = append(absentees, player)
 		}
-		if player.Status == "ATTENDING" {
+		if player.Status == string(ATTENDING) {
 			players = append(players, player)
 		}
 	}
-
-	if err != nil {
-		log.Printf("Error retrieving game players: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not retrieve game players, please try again.")
-	}
-	return game, &players, &absentees, nil
+	return &players, &absentees
 }
 
-func (g *GameService) RepayGame(chatID *int64, userId *int64) (*models.Game, *[]models.User, *[]models.User, error) {
-	game, err := g.GameRepository.GetLatestGameByChatID(*chatID)
-	if err != nil {
-		log.Printf("Could not find the latest game: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not find the latest game, please try again.")
+// findPlayer returns the player with id among the players and absentees of a
+// game, nil if they are in neither.
+func findPlayer(players *[]models.User, absentees *[]models.User, id uuid.UUID) *models.User {
+	if players == nil || absentees == nil {
+		return nil
 	}
-	if game == nil {
-		return nil, nil, nil, fmt.Errorf("No upcoming game.")
+	for _, list := range [][]models.User{*players, *absentees} {
+		for _, player := range list {
+			if player.Id == id {
+				return &player
+			}
+		}
 	}
+	return nil
+}
 
-	player, err := g.GameRepository.GetUserByUserID(*userId)
-	if err != nil {
-		log.Printf("Could not find the player: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not find the player, please try again.")
-	}
+func (g *GameService) RepayGame(ctx context.Context, chatID *int64, userId *int64) (*models.Game, *[]models.User, *[]models.User, error) {
+	var game *models.Game
+	var players *[]models.User
+	var absentees *[]models.User
+	err := g.changeGames(ctx, func(ctx context.Context, games repositories.IGameRepository) ([]Event, error) {
+		var err error
+		game, err = games.GetLatestGameByChatID(ctx, *chatID)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not find the latest game", "error", err)
+			return nil, Internal(ERR_LATEST_GAME, fmt.Errorf("get latest game of chat %d: %w", *chatID, err))
+		}
+		if game == nil {
+			return nil, NotFound(ERR_NO_UPCOMING_GAME)
+		}
+
+		player, err := games.GetUserByUserID(ctx, *userId)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not find the player", "error", err)
+			return nil, Internal(ERR_PLAYER_RETRIEVE, fmt.Errorf("get user %d: %w", *userId, err))
+		}
+		if player == nil {
+			return nil, NotFound(ERR_SENDER_NOT_REGISTERED)
+		}
 
-	playerForGameId, err := g.GameRepository.GetPlayerForGame(player.Id, game.Id)
+		playerForGameId, err := games.GetPlayerForGame(ctx, player.Id, game.Id)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not find the player for the game", "error", err)
+			return nil, Internal(ERR_PLAYER_GAME_RETRIEVE, fmt.Errorf("get player %s of game %s: %w", player.Id, game.Id, err))
+		}
+
+		if playerForGameId == nil {
+			logging.FromContext(ctx).Debug("Player not registered for the game")
+			return nil, NotFound(ERR_PLAYER_NOT_REGISTERED, player.Name)
+		}
+
+		err = games.UpdatePlayerPayment(ctx, game.Id, player.Id)
+		if err != nil {
+			logging.FromContext(ctx).Error("Could not update player payment", "error", err)
+			return nil, Internal(ERR_PAYMENT_UPDATE, fmt.Errorf("mark player %s paid for game %s: %w", player.Id, game.Id, err))
+		}
+
+		game, players, absentees, err = loadGameDetails(ctx, games, *chatID)
+		if err != nil {
+			return nil, err
+		}
+		return []Event{newEvent(EVENT_PAYMENT_RECORDED, game, findPlayer(players, absentees, player.Id))}, nil
+	})
 	if err != nil {
-		log.Printf("Could not find the player for the game: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not find the player for the game, please try again.")
+		return nil, nil, nil, err
 	}
+	return game, players, absentees, nil
+}
 
-	if playerForGameId == nil {
-		log.Printf("Player not registered for the game")
-		return nil, nil, nil, fmt.Errorf("%s is not registered for the game.", player.Name)
+// kickoffError turns a dateparse error into one that echoes how the input was
+// read.
+func kickoffError(err error) error {
+	var parseErr *dateparse.ParseError
+	if !errors.As(err, &parseErr) {
+		return Invalid("kickoff", ERR_KICKOFF_INVALID, err, "")
 	}
-
-	err = g.GameRepository.UpdatePlayerPayment(game.Id, player.Id)
-	if err != nil {
-		log.Printf("Could not update player payment: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not update player payment, please try again.")
+	switch {
+	case errors.Is(err, dateparse.ErrMissingTime):
+		return Invalid("kickoff", ERR_KICKOFF_MISSING_TIME, err, parseErr.Input, parseErr.Interpreted.Weekday(), parseErr.Interpreted.Format("2006-01-02"))
+	case errors.Is(err, dateparse.ErrPast):
+		return Invalid("kickoff", ERR_KICKOFF_PAST, err, parseErr.Input, parseErr.Interpreted)
 	}
+	return Invalid("kickoff", ERR_KICKOFF_INVALID, err, parseErr.Input)
+}
 
-	game, players, absentees, err := g.GetGameDetails(*chatID)
-	if err != nil {
-		log.Printf("Error retrieving game details: %v", err)
-		return nil, nil, nil, fmt.Errorf("Could not retrieve game details, please try again.")
+// parseKickoff reads the kickoff from the start of gameData and returns the
+// remaining fields. The kickoff is either a single field ("sunday 11am") or a
+// date and a time split over two fields ("2024-10-10, 11:00").
+func parseKickoff(gameData []string, now time.Time) (time.Time, []string, error) {
+	if len(gameData) == 0 {
+		_, err := dateparse.Parse("", now)
+		return time.Time{}, nil, err
 	}
-	return game, players, absentees, nil
+	kickoff, err := dateparse.Parse(gameData[0], now)
+	if err == nil {
+		return kickoff, gameData[1:], nil
+	}
+	if len(gameData) > 1 && errors.Is(err, dateparse.ErrMissingTime) {
+		kickoff, joinedErr := dateparse.Parse(gameData[0]+" "+gameData[1], now)
+		if joinedErr == nil {
+			return kickoff, gameData[2:], nil
+		}
+		if errors.Is(joinedErr, dateparse.ErrPast) {
+			err = joinedErr
+		}
+	}
+	return time.Time{}, nil, err
 }
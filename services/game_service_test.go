@@ -0,0 +1,791 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const chatID int64 = -100
+
+var errDatabase = errors.New("database is down")
+
+// fixture holds the in-memory storage a GameService test runs against. setup
+// functions may swap repo, settingsRepo or webhookRepo for a failing wrapper.
+type fixture struct {
+	games        *repositories.MemoryGameRepository
+	settings     *repositories.MemorySettingsRepository
+	webhooks     *repositories.MemoryWebhookRepository
+	repo         repositories.IGameRepository
+	settingsRepo repositories.ISettingsRepository
+	webhookRepo  repositories.IWebhookRepository
+	unitOfWork   *repositories.MemoryUnitOfWork
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		games:    repositories.NewMemoryGameRepository(),
+		settings: repositories.NewMemorySettingsRepository(),
+		webhooks: repositories.NewMemoryWebhookRepository(),
+	}
+	f.repo = f.games
+	f.settingsRepo = f.settings
+	f.webhookRepo = f.webhooks
+	f.unitOfWork = repositories.NewMemoryUnitOfWork(f.games, f.settings, f.webhooks)
+	return f
+}
+
+func (f *fixture) service() *GameService {
+	return &GameService{
+		GameRepository:  f.repo,
+		SettingsService: &SettingsService{SettingsRepository: f.settingsRepo},
+		UnitOfWork:      f,
+	}
+}
+
+// Do runs fn in a unit of work over the memory storage, but hands it repo,
+// settingsRepo and webhookRepo so failing wrappers stay in place.
+func (f *fixture) Do(ctx context.Context, fn func(ctx context.Context, repos repositories.Repositories) error) error {
+	return f.unitOfWork.Do(ctx, func(ctx context.Context, _ repositories.Repositories) error {
+		return fn(ctx, repositories.Repositories{Games: f.repo, Settings: f.settingsRepo, Webhooks: f.webhookRepo})
+	})
+}
+
+// wantNoUser fails the test if the Telegram user was stored, as when a failed
+// operation is rolled back.
+func wantNoUser(t *testing.T, f *fixture, userID int64) {
+	t.Helper()
+	if user, _ := f.games.GetUserByUserID(context.Background(), userID); user != nil {
+		t.Errorf("user %d = %+v, want it rolled back", userID, user)
+	}
+}
+
+func (f *fixture) addGame(t *testing.T, date time.Time) *models.Game {
+	t.Helper()
+	game := &models.Game{Id: uuid.New(), ChatId: chatID, Date: date, Location: "Park", Opponent: "Rovers", Price: 10}
+	if _, err := f.games.InsertGame(context.Background(), game); err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+	return game
+}
+
+func (f *fixture) addUser(t *testing.T, userID int64, name string) *models.User {
+	t.Helper()
+	user := &models.User{Id: uuid.New(), UserId: userID, Name: name}
+	if _, err := f.games.InsertUser(context.Background(), user); err != nil {
+		t.Fatalf("InsertUser: %v", err)
+	}
+	return user
+}
+
+func (f *fixture) addPlayer(t *testing.T, game *models.Game, userID int64, name string, status PlayerStatus) *models.User {
+	t.Helper()
+	user := f.addUser(t, userID, name)
+	user.Status = string(status)
+	if _, err := f.games.InsertGamePlayer(context.Background(), game, user); err != nil {
+		t.Fatalf("InsertGamePlayer: %v", err)
+	}
+	return user
+}
+
+func (f *fixture) setSetting(t *testing.T, key SettingKey, value string) {
+	t.Helper()
+	if err := f.settings.UpsertChatSetting(context.Background(), chatID, string(key), value); err != nil {
+		t.Fatalf("UpsertChatSetting: %v", err)
+	}
+}
+
+// failingRepository fails the named method and delegates every other one.
+type failingRepository struct {
+	repositories.IGameRepository
+	method string
+}
+
+func (r *failingRepository) GetLatestGameByChatID(ctx context.Context, chatID int64) (*models.Game, error) {
+	if r.method == "GetLatestGameByChatID" {
+		return nil, errDatabase
+	}
+	return r.IGameRepository.GetLatestGameByChatID(ctx, chatID)
+}
+
+func (r *failingRepository) GetUserByUserID(ctx context.Context, userId int64) (*models.User, error) {
+	if r.method == "GetUserByUserID" {
+		return nil, errDatabase
+	}
+	return r.IGameRepository.GetUserByUserID(ctx, userId)
+}
+
+func (r *failingRepository) InsertUser(ctx context.Context, user *models.User) (int64, error) {
+	if r.method == "InsertUser" {
+		return 0, errDatabase
+	}
+	return r.IGameRepository.InsertUser(ctx, user)
+}
+
+func (r *failingRepository) InsertGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	if r.method == "InsertGame" {
+		return nil, errDatabase
+	}
+	return r.IGameRepository.InsertGame(ctx, game)
+}
+
+func (r *failingRepository) CancelGame(ctx context.Context, game *models.Game) (*models.Game, error) {
+	if r.method == "CancelGame" {
+		return nil, errDatabase
+	}
+	return r.IGameRepository.CancelGame(ctx, game)
+}
+
+func (r *failingRepository) GetPlayerForGame(ctx context.Context, playerId uuid.UUID, gameId uuid.UUID) (*uuid.UUID, error) {
+	switch r.method {
+	case "GetPlayerForGame":
+		return nil, errDatabase
+	case "GetPlayerForGameStale":
+		// Pretends the player is not registered yet, as when another
+		// registration lands between the lookup and the insert.
+		return nil, nil
+	}
+	return r.IGameRepository.GetPlayerForGame(ctx, playerId, gameId)
+}
+
+func (r *failingRepository) InsertGamePlayer(ctx context.Context, game *models.Game, player *models.User) (string, error) {
+	if r.method == "InsertGamePlayer" {
+		return "", errDatabase
+	}
+	return r.IGameRepository.InsertGamePlayer(ctx, game, player)
+}
+
+func (r *failingRepository) GetGamePlayers(ctx context.Context, gameId uuid.UUID) ([]models.User, error) {
+	if r.method == "GetGamePlayers" {
+		return nil, errDatabase
+	}
+	return r.IGameRepository.GetGamePlayers(ctx, gameId)
+}
+
+func (r *failingRepository) UpdatePlayerPayment(ctx context.Context, gameId uuid.UUID, playerId uuid.UUID) error {
+	if r.method == "UpdatePlayerPayment" {
+		return errDatabase
+	}
+	return r.IGameRepository.UpdatePlayerPayment(ctx, gameId, playerId)
+}
+
+func (r *failingRepository) SetFixtureGame(ctx context.Context, fixtureId uuid.UUID, gameId uuid.UUID) error {
+	if r.method == "SetFixtureGame" {
+		return errDatabase
+	}
+	return r.IGameRepository.SetFixtureGame(ctx, fixtureId, gameId)
+}
+
+type failingSettingsRepository struct {
+	repositories.ISettingsRepository
+}
+
+func (r *failingSettingsRepository) GetChatSettings(ctx context.Context, chatID int64) (map[string]string, error) {
+	return nil, errDatabase
+}
+
+func failing(method string) func(t *testing.T, f *fixture) {
+	return func(t *testing.T, f *fixture) {
+		f.repo = &failingRepository{IGameRepository: f.games, method: method}
+	}
+}
+
+// result is what a GameService call returned, whatever its signature.
+type result struct {
+	game      *models.Game
+	players   *[]models.User
+	absentees *[]models.User
+}
+
+type gameServiceTest struct {
+	name     string
+	setup    func(t *testing.T, f *fixture)
+	call     func(s *GameService) (result, error)
+	wantKind ErrorKind
+	wantCode ErrorCode
+	check    func(t *testing.T, f *fixture, got result)
+}
+
+func runGameServiceTests(t *testing.T, tests []gameServiceTest) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			if tt.setup != nil {
+				tt.setup(t, f)
+			}
+
+			got, err := tt.call(f.service())
+
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else {
+				serviceErr := AsError(err)
+				if serviceErr == nil {
+					t.Fatalf("error = %v, want %s %s", err, tt.wantKind, tt.wantCode)
+				}
+				if serviceErr.Kind != tt.wantKind || serviceErr.Code != tt.wantCode {
+					t.Fatalf("error = %s %s, want %s %s", serviceErr.Kind, serviceErr.Code, tt.wantKind, tt.wantCode)
+				}
+				if tt.wantKind == KIND_INTERNAL && !errors.Is(err, errDatabase) {
+					t.Errorf("internal error %v does not wrap the repository error", err)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, f, got)
+			}
+		})
+	}
+}
+
+func names(users *[]models.User) []string {
+	if users == nil {
+		return nil
+	}
+	var names []string
+	for _, u := range *users {
+		names = append(names, u.Name)
+	}
+	return names
+}
+
+func wantNames(t *testing.T, what string, users *[]models.User, want ...string) {
+	t.Helper()
+	got := names(users)
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s = %v, want %v", what, got, want)
+			return
+		}
+	}
+}
+
+func createGame(gameData ...string) func(s *GameService) (result, error) {
+	return func(s *GameService) (result, error) {
+		game, players, absentees, err := s.CreateNewGame(context.Background(), chatID, 1, "Ana", gameData)
+		return result{game, players, absentees}, err
+	}
+}
+
+func TestCreateNewGame(t *testing.T) {
+	future := time.Date(2099, time.January, 4, 11, 0, 0, 0, time.UTC)
+	var creator *models.User
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name: "creates game from all fields",
+			call: createGame("2099-01-04 11:00", "Park", "Rovers", "12.5"),
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game == nil {
+					t.Fatal("no game returned")
+				}
+				if !got.game.Date.Equal(future) || got.game.Location != "Park" || got.game.Opponent != "Rovers" || got.game.Price != 12.5 {
+					t.Errorf("game = %+v", got.game)
+				}
+				wantNames(t, "players", got.players)
+				creator, _ := f.games.GetUserByUserID(context.Background(), 1)
+				if creator == nil || got.game.CreatedBy != creator.Id {
+					t.Errorf("creator = %+v, game created by %s", creator, got.game.CreatedBy)
+				}
+			},
+		},
+		{
+			name: "reads date and time split over two fields",
+			call: createGame("2099-01-04", "11:00", "Park"),
+			check: func(t *testing.T, f *fixture, got result) {
+				if !got.game.Date.Equal(future) || got.game.Location != "Park" {
+					t.Errorf("game = %+v", got.game)
+				}
+			},
+		},
+		{
+			name: "reads kickoff in chat time zone",
+			setup: func(t *testing.T, f *fixture) {
+				f.setSetting(t, SETTING_TIMEZONE, "Asia/Singapore")
+			},
+			call: createGame("2099-01-04 19:00"),
+			check: func(t *testing.T, f *fixture, got result) {
+				if !got.game.Date.Equal(future) {
+					t.Errorf("kickoff = %v, want %v", got.game.Date, future)
+				}
+			},
+		},
+		{
+			name: "uses chat default price",
+			setup: func(t *testing.T, f *fixture) {
+				f.setSetting(t, SETTING_DEFAULT_PRICE, "15")
+			},
+			call: createGame("2099-01-04 11:00", "Park"),
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game.Price != 15 {
+					t.Errorf("price = %v, want 15", got.game.Price)
+				}
+			},
+		},
+		{
+			name: "reuses existing user",
+			setup: func(t *testing.T, f *fixture) {
+				creator = f.addUser(t, 1, "Ana")
+			},
+			call: createGame("2099-01-04 11:00"),
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game.CreatedBy != creator.Id {
+					t.Errorf("game created by %s, want existing user %s", got.game.CreatedBy, creator.Id)
+				}
+			},
+		},
+		{
+			name: "replaces a past game",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(-24*time.Hour))
+			},
+			call: createGame("2099-01-04 11:00"),
+			check: func(t *testing.T, f *fixture, got result) {
+				latest, _ := f.games.GetLatestGameByChatID(context.Background(), chatID)
+				if latest == nil || !latest.Date.Equal(future) {
+					t.Errorf("latest game = %+v", latest)
+				}
+			},
+		},
+		{
+			name: "rejects second upcoming game",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(24*time.Hour))
+			},
+			call:     createGame("2099-01-04 11:00"),
+			wantKind: KIND_CONFLICT,
+			wantCode: ERR_GAME_ALREADY_SCHEDULED,
+		},
+		{
+			name:     "rejects missing kickoff",
+			call:     createGame(),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_KICKOFF_INVALID,
+		},
+		{
+			name:     "rejects kickoff without time",
+			call:     createGame("tomorrow", "Park"),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_KICKOFF_MISSING_TIME,
+		},
+		{
+			name:     "rejects past kickoff",
+			call:     createGame("2001-01-04 11:00"),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_KICKOFF_PAST,
+		},
+		{
+			name:     "rejects unreadable kickoff",
+			call:     createGame("whenever"),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_KICKOFF_INVALID,
+		},
+		{
+			name:     "rejects bad price",
+			call:     createGame("2099-01-04 11:00", "Park", "Rovers", "free"),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_INVALID_PRICE,
+			check: func(t *testing.T, f *fixture, got result) {
+				if latest, _ := f.games.GetLatestGameByChatID(context.Background(), chatID); latest != nil {
+					t.Errorf("game created despite bad price: %+v", latest)
+				}
+			},
+		},
+		{
+			name:     "rejects negative price",
+			call:     createGame("2099-01-04 11:00", "Park", "Rovers", "-1"),
+			wantKind: KIND_VALIDATION,
+			wantCode: ERR_INVALID_PRICE,
+		},
+		{
+			name:     "fails when user lookup fails",
+			setup:    failing("GetUserByUserID"),
+			call:     createGame("2099-01-04 11:00"),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_USER_RETRIEVE,
+		},
+		{
+			name:     "fails when user cannot be created",
+			setup:    failing("InsertUser"),
+			call:     createGame("2099-01-04 11:00"),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_USER_CREATE,
+		},
+		{
+			name: "fails when settings cannot be read",
+			setup: func(t *testing.T, f *fixture) {
+				f.settingsRepo = &failingSettingsRepository{}
+			},
+			call:     createGame("2099-01-04 11:00"),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_SETTINGS_RETRIEVE,
+		},
+		{
+			name:     "fails when latest game cannot be read",
+			setup:    failing("GetLatestGameByChatID"),
+			call:     createGame("2099-01-04 11:00"),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_LATEST_GAME,
+		},
+		{
+			name:     "fails when game cannot be stored",
+			setup:    failing("InsertGame"),
+			call:     createGame("2099-01-04 11:00"),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_GAME_CREATE,
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNoUser(t, f, 1)
+			},
+		},
+	})
+}
+
+func cancelGame(s *GameService) (result, error) {
+	game, err := s.CancelGame(context.Background(), chatID)
+	return result{game: game}, err
+}
+
+func TestCancelGame(t *testing.T) {
+	var scheduled *models.Game
+
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name: "cancels latest game",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(-time.Hour))
+				scheduled = f.addGame(t, time.Now().Add(24*time.Hour))
+			},
+			call: cancelGame,
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game == nil || got.game.Id != scheduled.Id {
+					t.Fatalf("cancelled %+v, want %+v", got.game, scheduled)
+				}
+				latest, _ := f.games.GetLatestGameByChatID(context.Background(), chatID)
+				if latest == nil || latest.Id == scheduled.Id {
+					t.Errorf("latest game after cancelling = %+v", latest)
+				}
+			},
+		},
+		{
+			name:     "reports missing game",
+			call:     cancelGame,
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_NO_UPCOMING_GAME,
+		},
+		{
+			name:     "fails when latest game cannot be read",
+			setup:    failing("GetLatestGameByChatID"),
+			call:     cancelGame,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_LATEST_GAME,
+		},
+		{
+			name: "fails when game cannot be cancelled",
+			setup: func(t *testing.T, f *fixture) {
+				f.addGame(t, time.Now().Add(24*time.Hour))
+				failing("CancelGame")(t, f)
+			},
+			call:     cancelGame,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_GAME_CANCEL,
+		},
+	})
+}
+
+func registerPlayer(userID int64, name string, status PlayerStatus) func(s *GameService) (result, error) {
+	return func(s *GameService) (result, error) {
+		chat := chatID
+		game, players, absentees, err := s.RegisterPlayer(context.Background(), &chat, &userID, &name, status)
+		return result{game, players, absentees}, err
+	}
+}
+
+func withGame(t *testing.T, f *fixture) {
+	f.addGame(t, time.Now().Add(24*time.Hour))
+}
+
+func TestRegisterPlayer(t *testing.T) {
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name:  "registers new user as attending",
+			setup: withGame,
+			call:  registerPlayer(2, "Bea", ATTENDING),
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNames(t, "players", got.players, "Bea")
+				wantNames(t, "absentees", got.absentees)
+				if user, _ := f.games.GetUserByUserID(context.Background(), 2); user == nil || user.Name != "Bea" {
+					t.Errorf("user = %+v, want Bea stored", user)
+				}
+			},
+		},
+		{
+			name: "registers known user as out",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				f.addUser(t, 2, "Bea")
+			},
+			call: registerPlayer(2, "Bea", OUT),
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNames(t, "players", got.players)
+				wantNames(t, "absentees", got.absentees, "Bea")
+			},
+		},
+		{
+			name: "switches registered player to out",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, time.Now().Add(24*time.Hour))
+				f.addPlayer(t, game, 2, "Bea", ATTENDING)
+				f.addPlayer(t, game, 3, "Cy", ATTENDING)
+			},
+			call: registerPlayer(2, "Bea", OUT),
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNames(t, "players", got.players, "Cy")
+				wantNames(t, "absentees", got.absentees, "Bea")
+			},
+		},
+		{
+			name: "registering twice keeps one entry",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, time.Now().Add(24*time.Hour))
+				f.addPlayer(t, game, 2, "Bea", ATTENDING)
+			},
+			call: registerPlayer(2, "Bea", ATTENDING),
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNames(t, "players", got.players, "Bea")
+			},
+		},
+		{
+			name: "updates player registered concurrently",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, time.Now().Add(24*time.Hour))
+				f.addPlayer(t, game, 2, "Bea", ATTENDING)
+				failing("GetPlayerForGameStale")(t, f)
+			},
+			call: registerPlayer(2, "Bea", OUT),
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNames(t, "players", got.players)
+				wantNames(t, "absentees", got.absentees, "Bea")
+			},
+		},
+		{
+			name:     "reports missing game",
+			call:     registerPlayer(2, "Bea", ATTENDING),
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_NO_GAME,
+		},
+		{
+			name:     "fails when latest game cannot be read",
+			setup:    failing("GetLatestGameByChatID"),
+			call:     registerPlayer(2, "Bea", ATTENDING),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_LATEST_GAME,
+		},
+		{
+			name: "fails when player lookup fails",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				failing("GetUserByUserID")(t, f)
+			},
+			call:     registerPlayer(2, "Bea", ATTENDING),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_PLAYER_RETRIEVE,
+		},
+		{
+			name: "fails when player cannot be created",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				failing("InsertUser")(t, f)
+			},
+			call:     registerPlayer(2, "Bea", ATTENDING),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_PLAYER_CREATE,
+		},
+		{
+			name: "fails when registration cannot be read",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				failing("GetPlayerForGame")(t, f)
+			},
+			call:     registerPlayer(2, "Bea", ATTENDING),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_PLAYER_GAME_RETRIEVE,
+		},
+		{
+			name: "fails when registration cannot be stored",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				failing("InsertGamePlayer")(t, f)
+			},
+			call:     registerPlayer(2, "Bea", ATTENDING),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_PLAYER_REGISTER,
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNoUser(t, f, 2)
+			},
+		},
+	})
+}
+
+func gameDetails(s *GameService) (result, error) {
+	game, players, absentees, err := s.GetGameDetails(context.Background(), chatID)
+	return result{game, players, absentees}, err
+}
+
+func TestGetGameDetails(t *testing.T) {
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name: "splits players by status",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, time.Now().Add(24*time.Hour))
+				f.addPlayer(t, game, 2, "Bea", ATTENDING)
+				f.addPlayer(t, game, 3, "Cy", OUT)
+				f.addPlayer(t, game, 4, "Dee", ATTENDING)
+			},
+			call: gameDetails,
+			check: func(t *testing.T, f *fixture, got result) {
+				if got.game == nil || got.game.Opponent != "Rovers" {
+					t.Errorf("game = %+v", got.game)
+				}
+				wantNames(t, "players", got.players, "Bea", "Dee")
+				wantNames(t, "absentees", got.absentees, "Cy")
+			},
+		},
+		{
+			name: "shows only the chat's latest game",
+			setup: func(t *testing.T, f *fixture) {
+				old := f.addGame(t, time.Now().Add(-7*24*time.Hour))
+				f.addPlayer(t, old, 2, "Bea", ATTENDING)
+				withGame(t, f)
+			},
+			call: gameDetails,
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNames(t, "players", got.players)
+			},
+		},
+		{
+			name:     "reports missing game",
+			call:     gameDetails,
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_NO_UPCOMING_GAME,
+		},
+		{
+			name:     "fails when game cannot be read",
+			setup:    failing("GetLatestGameByChatID"),
+			call:     gameDetails,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_GAME_DETAILS,
+		},
+		{
+			name: "fails when players cannot be read",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				failing("GetGamePlayers")(t, f)
+			},
+			call:     gameDetails,
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_GAME_PLAYERS,
+		},
+	})
+}
+
+func repayGame(userID int64) func(s *GameService) (result, error) {
+	return func(s *GameService) (result, error) {
+		chat := chatID
+		game, players, absentees, err := s.RepayGame(context.Background(), &chat, &userID)
+		return result{game, players, absentees}, err
+	}
+}
+
+func TestRepayGame(t *testing.T) {
+	runGameServiceTests(t, []gameServiceTest{
+		{
+			name: "marks player paid",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, time.Now().Add(24*time.Hour))
+				f.addPlayer(t, game, 2, "Bea", ATTENDING)
+				f.addPlayer(t, game, 3, "Cy", ATTENDING)
+			},
+			call: repayGame(2),
+			check: func(t *testing.T, f *fixture, got result) {
+				wantNames(t, "players", got.players, "Bea", "Cy")
+				for _, p := range *got.players {
+					if p.HasPaid != (p.Name == "Bea") {
+						t.Errorf("%s paid = %v", p.Name, p.HasPaid)
+					}
+				}
+			},
+		},
+		{
+			name:     "reports missing game",
+			call:     repayGame(2),
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_NO_UPCOMING_GAME,
+		},
+		{
+			name:     "reports unknown sender",
+			setup:    withGame,
+			call:     repayGame(2),
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_SENDER_NOT_REGISTERED,
+		},
+		{
+			name: "reports sender not in game",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				f.addUser(t, 2, "Bea")
+			},
+			call:     repayGame(2),
+			wantKind: KIND_NOT_FOUND,
+			wantCode: ERR_PLAYER_NOT_REGISTERED,
+		},
+		{
+			name:     "fails when latest game cannot be read",
+			setup:    failing("GetLatestGameByChatID"),
+			call:     repayGame(2),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_LATEST_GAME,
+		},
+		{
+			name: "fails when sender cannot be read",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				failing("GetUserByUserID")(t, f)
+			},
+			call:     repayGame(2),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_PLAYER_RETRIEVE,
+		},
+		{
+			name: "fails when registration cannot be read",
+			setup: func(t *testing.T, f *fixture) {
+				withGame(t, f)
+				f.addUser(t, 2, "Bea")
+				failing("GetPlayerForGame")(t, f)
+			},
+			call:     repayGame(2),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_PLAYER_GAME_RETRIEVE,
+		},
+		{
+			name: "fails when payment cannot be stored",
+			setup: func(t *testing.T, f *fixture) {
+				game := f.addGame(t, time.Now().Add(24*time.Hour))
+				f.addPlayer(t, game, 2, "Bea", ATTENDING)
+				failing("UpdatePlayerPayment")(t, f)
+			},
+			call:     repayGame(2),
+			wantKind: KIND_INTERNAL,
+			wantCode: ERR_PAYMENT_UPDATE,
+		},
+	})
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+// SessionKind tells the one-time login links of the dashboard from the
+// sessions they are redeemed for.
+type SessionKind string
+
+const (
+	SESSION_LOGIN SessionKind = "login"
+	SESSION_WEB   SessionKind = "web"
+)
+
+// LOGIN_TTL is how long a login link works, if not used before.
+const LOGIN_TTL = 15 * time.Minute
+
+// SESSION_TTL is how long a user stays signed in to the dashboard.
+const SESSION_TTL = 30 * 24 * time.Hour
+
+type ISessionService interface {
+	NewLogin(ctx context.Context, chatId int64, userId int64) (string, error)
+	RedeemLogin(ctx context.Context, code string) (string, *models.Session, error)
+	Session(ctx context.Context, token string) (*models.Session, error)
+	EndSession(ctx context.Context, token string) error
+}
+
+// SessionService signs Telegram users in to the web dashboard of their chat.
+// The bot hands out login links, each redeemed once for a session.
+type SessionService struct {
+	SessionRepository repositories.ISessionRepository
+}
+
+// NewLogin returns the code of a login link signing the user in to the
+// chat's dashboard, which works once within LOGIN_TTL.
+func (s *SessionService) NewLogin(ctx context.Context, chatId int64, userId int64) (string, error) {
+	// Expired sessions are cleared as new ones come, which is often enough
+	// to keep the table small.
+	if err := s.SessionRepository.DeleteExpiredSessions(ctx, time.Now()); err != nil {
+		logging.FromContext(ctx).Warn("Could not delete expired sessions", "error", err)
+	}
+	return s.insert(ctx, SESSION_LOGIN, chatId, userId, LOGIN_TTL)
+}
+
+// RedeemLogin trades the code of a login link for a session token, returned
+// with the session.
+func (s *SessionService) RedeemLogin(ctx context.Context, code string) (string, *models.Session, error) {
+	login, err := s.find(ctx, SESSION_LOGIN, code)
+	if err != nil {
+		return "", nil, err
+	}
+	// Only the request deleting the login gets a session, when a link is
+	// opened twice at once.
+	deleted, err := s.SessionRepository.DeleteSession(ctx, login.TokenHash)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not delete login", "error", err)
+		return "", nil, Internal(ERR_SESSION, fmt.Errorf("delete login: %w", err))
+	}
+	if !deleted {
+		return "", nil, NotFound(ERR_LOGIN_EXPIRED)
+	}
+
+	token, err := s.insert(ctx, SESSION_WEB, login.ChatId, login.UserId, SESSION_TTL)
+	if err != nil {
+		return "", nil, err
+	}
+	logging.FromContext(ctx).Info("Signed in to the dashboard", "chat_id", login.ChatId, "user_id", login.UserId)
+	session, err := s.find(ctx, SESSION_WEB, token)
+	return token, session, err
+}
+
+// Session returns the session of token, if it has not expired.
+func (s *SessionService) Session(ctx context.Context, token string) (*models.Session, error) {
+	return s.find(ctx, SESSION_WEB, token)
+}
+
+// EndSession signs out of the session of token.
+func (s *SessionService) EndSession(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	if _, err := s.SessionRepository.DeleteSession(ctx, hashToken(token)); err != nil {
+		logging.FromContext(ctx).Error("Could not delete session", "error", err)
+		return Internal(ERR_SESSION, fmt.Errorf("delete session: %w", err))
+	}
+	return nil
+}
+
+func (s *SessionService) insert(ctx context.Context, kind SessionKind, chatId int64, userId int64, ttl time.Duration) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", Internal(ERR_SESSION, err)
+	}
+	session := &models.Session{
+		TokenHash: hashToken(token),
+		Kind:      string(kind),
+		ChatId:    chatId,
+		UserId:    userId,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.SessionRepository.InsertSession(ctx, session); err != nil {
+		logging.FromContext(ctx).Error("Could not create session", "kind", kind, "error", err)
+		return "", Internal(ERR_SESSION, fmt.Errorf("insert %s session of chat %d: %w", kind, chatId, err))
+	}
+	return token, nil
+}
+
+// find returns the session of kind with token. Unknown and expired ones are
+// not found alike.
+func (s *SessionService) find(ctx context.Context, kind SessionKind, token string) (*models.Session, error) {
+	notFound := NotFound(ERR_NOT_SIGNED_IN)
+	if kind == SESSION_LOGIN {
+		notFound = NotFound(ERR_LOGIN_EXPIRED)
+	}
+	if token == "" {
+		return nil, notFound
+	}
+	session, err := s.SessionRepository.GetSession(ctx, hashToken(token))
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not look up session", "kind", kind, "error", err)
+		return nil, Internal(ERR_SESSION, fmt.Errorf("get %s session: %w", kind, err))
+	}
+	if session == nil || session.Kind != string(kind) || !session.ExpiresAt.After(time.Now()) {
+		return nil, notFound
+	}
+	return session, nil
+}
+
+// hashToken is how tokens are stored, so they cannot be read back.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
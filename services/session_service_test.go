@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+func TestDashboardSessions(t *testing.T) {
+	ctx := context.Background()
+	sessions := repositories.NewMemorySessionRepository()
+	s := &SessionService{SessionRepository: sessions}
+
+	code, err := s.NewLogin(ctx, chatID, 20)
+	if err != nil || len(code) < 32 {
+		t.Fatalf("NewLogin = %q, %v; want a code", code, err)
+	}
+	if _, err := s.Session(ctx, code); !IsKind(err, KIND_NOT_FOUND) {
+		t.Errorf("Session of a login code = %v, want not found", err)
+	}
+
+	token, session, err := s.RedeemLogin(ctx, code)
+	if err != nil || token == "" || token == code || session.ChatId != chatID || session.UserId != 20 {
+		t.Fatalf("RedeemLogin = %q, %+v, %v; want a session of user 20", token, session, err)
+	}
+	if _, _, err := s.RedeemLogin(ctx, code); !IsKind(err, KIND_NOT_FOUND) {
+		t.Errorf("RedeemLogin again = %v, want not found", err)
+	}
+	if got, err := s.Session(ctx, token); err != nil || got.UserId != 20 {
+		t.Errorf("Session = %+v, %v; want user 20", got, err)
+	}
+	if stored, _ := sessions.GetSession(ctx, token); stored != nil {
+		t.Error("the token is stored as it is, want it hashed")
+	}
+
+	if err := s.EndSession(ctx, token); err != nil {
+		t.Fatalf("EndSession: %v", err)
+	}
+	if _, err := s.Session(ctx, token); !IsKind(err, KIND_NOT_FOUND) {
+		t.Errorf("Session after signing out = %v, want not found", err)
+	}
+}
+
+func TestExpiredLogin(t *testing.T) {
+	ctx := context.Background()
+	sessions := repositories.NewMemorySessionRepository()
+	s := &SessionService{SessionRepository: sessions}
+	expired := &models.Session{TokenHash: hashToken("old"), Kind: string(SESSION_LOGIN), ChatId: chatID, UserId: 20, ExpiresAt: time.Now().Add(-time.Second)}
+	if err := sessions.InsertSession(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := s.RedeemLogin(ctx, "old")
+	if serviceErr := AsError(err); serviceErr == nil || serviceErr.Code != ERR_LOGIN_EXPIRED {
+		t.Errorf("RedeemLogin of an expired code = %v, want %s", err, ERR_LOGIN_EXPIRED)
+	}
+	if _, err := s.NewLogin(ctx, chatID, 20); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := sessions.GetSession(ctx, hashToken("old")); stored != nil {
+		t.Error("expired login kept after a new one")
+	}
+}
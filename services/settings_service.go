@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"tg-sunday-league/i18n"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+type SettingKey string
+
+const (
+	SETTING_CURRENCY      SettingKey = "currency"
+	SETTING_DEFAULT_PRICE SettingKey = "default_price"
+	SETTING_SQUAD_SIZE    SettingKey = "squad_size"
+	SETTING_TIMEZONE      SettingKey = "timezone"
+	SETTING_LANGUAGE      SettingKey = "language"
+	SETTING_REMINDERS     SettingKey = "reminders"
+	SETTING_GAME_CREATORS SettingKey = "game_creators"
+)
+
+// SettingKeys lists every setting in the order they are shown to users.
+var SettingKeys = []SettingKey{
+	SETTING_CURRENCY,
+	SETTING_DEFAULT_PRICE,
+	SETTING_SQUAD_SIZE,
+	SETTING_TIMEZONE,
+	SETTING_LANGUAGE,
+	SETTING_REMINDERS,
+	SETTING_GAME_CREATORS,
+}
+
+type GameCreators string
+
+const (
+	ADMINS   GameCreators = "admins"
+	EVERYONE GameCreators = "everyone"
+)
+
+var SupportedLanguages = i18n.Languages()
+
+const (
+	maxSquadSize = 50
+	maxReminders = 5
+)
+
+var (
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$|^\p{Sc}$`)
+	reminderPattern = regexp.MustCompile(`^(\d+)([dhm])$`)
+)
+
+type ISettingsService interface {
+	GetSettings(ctx context.Context, chatId int64) (*models.ChatSettings, error)
+	Currency(ctx context.Context, chatId int64) (string, error)
+	DefaultPrice(ctx context.Context, chatId int64) (float64, error)
+	SquadSize(ctx context.Context, chatId int64) (int, error)
+	Timezone(ctx context.Context, chatId int64) (*time.Location, error)
+	Language(ctx context.Context, chatId int64) (string, error)
+	ReminderOffsets(ctx context.Context, chatId int64) ([]time.Duration, error)
+	GameCreators(ctx context.Context, chatId int64) (GameCreators, error)
+	UpdateSetting(ctx context.Context, chatId int64, key SettingKey, value string) (*models.ChatSettings, error)
+}
+
+type SettingsService struct {
+	SettingsRepository repositories.ISettingsRepository
+	// Defaults apply to every setting a chat has not changed.
+	Defaults models.ChatSettings
+}
+
+// GetSettings returns the chat's settings with defaults filled in for the ones
+// that were never changed.
+func (s *SettingsService) GetSettings(ctx context.Context, chatId int64) (*models.ChatSettings, error) {
+	stored, err := s.SettingsRepository.GetChatSettings(ctx, chatId)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not retrieve chat settings", "error", err)
+		return nil, Internal(ERR_SETTINGS_RETRIEVE, fmt.Errorf("get settings of chat %d: %w", chatId, err))
+	}
+
+	settings := s.Defaults
+	settings.ChatId = chatId
+	if settings.Timezone == nil {
+		settings.Timezone = time.UTC
+	}
+	if settings.Language == "" {
+		settings.Language = i18n.DefaultLanguage
+	}
+	if settings.GameCreators == "" {
+		settings.GameCreators = string(ADMINS)
+	}
+
+	for key, value := range stored {
+		if err := applySetting(&settings, SettingKey(key), value); err != nil {
+			// A value that no longer parses falls back to the default rather
+			// than breaking every command in the chat.
+			logging.FromContext(ctx).Warn("Ignoring stored setting", "setting", key, "value", value, "error", err)
+		}
+	}
+	return &settings, nil
+}
+
+func (s *SettingsService) Currency(ctx context.Context, chatId int64) (string, error) {
+	settings, err := s.GetSettings(ctx, chatId)
+	if err != nil {
+		return "", err
+	}
+	return settings.Currency, nil
+}
+
+func (s *SettingsService) DefaultPrice(ctx context.Context, chatId int64) (float64, error) {
+	settings, err := s.GetSettings(ctx, chatId)
+	if err != nil {
+		return 0, err
+	}
+	return settings.DefaultPrice, nil
+}
+
+func (s *SettingsService) SquadSize(ctx context.Context, chatId int64) (int, error) {
+	settings, err := s.GetSettings(ctx, chatId)
+	if err != nil {
+		return 0, err
+	}
+	return settings.SquadSize, nil
+}
+
+func (s *SettingsService) Timezone(ctx context.Context, chatId int64) (*time.Location, error) {
+	settings, err := s.GetSettings(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+	return settings.Timezone, nil
+}
+
+func (s *SettingsService) Language(ctx context.Context, chatId int64) (string, error) {
+	settings, err := s.GetSettings(ctx, chatId)
+	if err != nil {
+		return "", err
+	}
+	return settings.Language, nil
+}
+
+func (s *SettingsService) ReminderOffsets(ctx context.Context, chatId int64) ([]time.Duration, error) {
+	settings, err := s.GetSettings(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+	return settings.ReminderOffsets, nil
+}
+
+func (s *SettingsService) GameCreators(ctx context.Context, chatId int64) (GameCreators, error) {
+	settings, err := s.GetSettings(ctx, chatId)
+	if err != nil {
+		return "", err
+	}
+	return GameCreators(settings.GameCreators), nil
+}
+
+// UpdateSetting validates value for key, stores it in its canonical form and
+// returns the chat's updated settings.
+func (s *SettingsService) UpdateSetting(ctx context.Context, chatId int64, key SettingKey, value string) (*models.ChatSettings, error) {
+	settings, err := s.GetSettings(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = applySetting(settings, key, strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.SettingsRepository.UpsertChatSetting(ctx, chatId, string(key), FormatSetting(settings, key))
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not save chat setting", "setting", key, "error", err)
+		return nil, Internal(ERR_SETTINGS_SAVE, fmt.Errorf("save setting %s of chat %d: %w", key, chatId, err))
+	}
+	return settings, nil
+}
+
+// FormatSetting returns the value of key as it is stored and shown to users.
+func FormatSetting(settings *models.ChatSettings, key SettingKey) string {
+	switch key {
+	case SETTING_CURRENCY:
+		return settings.Currency
+	case SETTING_DEFAULT_PRICE:
+		return strconv.FormatFloat(settings.DefaultPrice, 'f', -1, 64)
+	case SETTING_SQUAD_SIZE:
+		return strconv.Itoa(settings.SquadSize)
+	case SETTING_TIMEZONE:
+		return settings.Timezone.String()
+	case SETTING_LANGUAGE:
+		return settings.Language
+	case SETTING_REMINDERS:
+		offsets := make([]string, len(settings.ReminderOffsets))
+		for i, offset := range settings.ReminderOffsets {
+			offsets[i] = formatOffset(offset)
+		}
+		return strings.Join(offsets, ", ")
+	case SETTING_GAME_CREATORS:
+		return settings.GameCreators
+	}
+	return ""
+}
+
+func applySetting(settings *models.ChatSettings, key SettingKey, value string) error {
+	switch key {
+	case SETTING_CURRENCY:
+		currency := strings.ToUpper(value)
+		if value != "" && !currencyPattern.MatchString(currency) {
+			return Invalid(string(key), ERR_INVALID_CURRENCY, nil, value)
+		}
+		settings.Currency = currency
+	case SETTING_DEFAULT_PRICE:
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return Invalid(string(key), ERR_INVALID_PRICE, err)
+		}
+		settings.DefaultPrice = price
+	case SETTING_SQUAD_SIZE:
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 || size > maxSquadSize {
+			return Invalid(string(key), ERR_INVALID_SQUAD_SIZE, err, maxSquadSize)
+		}
+		settings.SquadSize = size
+	case SETTING_TIMEZONE:
+		loc, err := time.LoadLocation(value)
+		if err != nil || value == "" || strings.EqualFold(value, "local") {
+			return Invalid(string(key), ERR_UNKNOWN_TIMEZONE, err, value)
+		}
+		settings.Timezone = loc
+	case SETTING_LANGUAGE:
+		language := strings.ToLower(value)
+		for _, supported := range SupportedLanguages {
+			if language == supported {
+				settings.Language = language
+				return nil
+			}
+		}
+		return Invalid(string(key), ERR_UNSUPPORTED_LANGUAGE, nil, value, strings.Join(SupportedLanguages, ", "))
+	case SETTING_REMINDERS:
+		offsets, err := parseOffsets(value)
+		if err != nil {
+			return err
+		}
+		settings.ReminderOffsets = offsets
+	case SETTING_GAME_CREATORS:
+		creators := GameCreators(strings.ToLower(value))
+		if creators != ADMINS && creators != EVERYONE {
+			return Invalid(string(key), ERR_INVALID_GAME_CREATORS, nil, value, string(ADMINS), string(EVERYONE))
+		}
+		settings.GameCreators = string(creators)
+	default:
+		return Invalid("key", ERR_UNKNOWN_SETTING, nil, string(key))
+	}
+	return nil
+}
+
+// parseOffsets reads a list such as "1d, 2h, 30m" into durations sorted from
+// the earliest reminder to the latest.
+func parseOffsets(value string) ([]time.Duration, error) {
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(fields) > maxReminders {
+		return nil, Invalid(string(SETTING_REMINDERS), ERR_TOO_MANY_REMINDERS, nil, maxReminders)
+	}
+
+	offsets := make([]time.Duration, 0, len(fields))
+	for _, field := range fields {
+		m := reminderPattern.FindStringSubmatch(field)
+		if m == nil {
+			return nil, Invalid(string(SETTING_REMINDERS), ERR_INVALID_REMINDER, nil, field)
+		}
+		n, _ := strconv.Atoi(m[1])
+		unit := time.Minute
+		switch m[2] {
+		case "d":
+			unit = 24 * time.Hour
+		case "h":
+			unit = time.Hour
+		}
+		offsets = append(offsets, time.Duration(n)*unit)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+	return offsets, nil
+}
+
+func formatOffset(offset time.Duration) string {
+	switch {
+	case offset%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", offset/(24*time.Hour))
+	case offset%time.Hour == 0:
+		return fmt.Sprintf("%dh", offset/time.Hour)
+	}
+	return fmt.Sprintf("%dm", offset/time.Minute)
+}
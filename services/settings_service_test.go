@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+func TestUpdateSetting(t *testing.T) {
+	tests := []struct {
+		key      SettingKey
+		value    string
+		want     string // value stored and shown, when accepted
+		wantCode ErrorCode
+	}{
+		{SETTING_SQUAD_SIZE, "0", "0", ""},
+		{SETTING_SQUAD_SIZE, " 14 ", "14", ""},
+		{SETTING_SQUAD_SIZE, "50", "50", ""},
+		{SETTING_SQUAD_SIZE, "51", "", ERR_INVALID_SQUAD_SIZE},
+		{SETTING_SQUAD_SIZE, "-1", "", ERR_INVALID_SQUAD_SIZE},
+		{SETTING_SQUAD_SIZE, "eleven", "", ERR_INVALID_SQUAD_SIZE},
+		{SETTING_SQUAD_SIZE, "", "", ERR_INVALID_SQUAD_SIZE},
+
+		{SETTING_CURRENCY, "eur", "EUR", ""},
+		{SETTING_CURRENCY, "€", "€", ""},
+		{SETTING_CURRENCY, "", "", ""},
+		{SETTING_CURRENCY, "EURO", "", ERR_INVALID_CURRENCY},
+		{SETTING_CURRENCY, "E", "", ERR_INVALID_CURRENCY},
+		{SETTING_CURRENCY, "$$", "", ERR_INVALID_CURRENCY},
+
+		{SETTING_DEFAULT_PRICE, "12", "12", ""},
+		{SETTING_DEFAULT_PRICE, "7.50", "7.5", ""},
+		{SETTING_DEFAULT_PRICE, "0", "0", ""},
+		{SETTING_DEFAULT_PRICE, "-1", "", ERR_INVALID_PRICE},
+		{SETTING_DEFAULT_PRICE, "free", "", ERR_INVALID_PRICE},
+
+		{SETTING_TIMEZONE, "Europe/Lisbon", "Europe/Lisbon", ""},
+		{SETTING_TIMEZONE, "UTC", "UTC", ""},
+		{SETTING_TIMEZONE, "Mars/Olympus", "", ERR_UNKNOWN_TIMEZONE},
+		{SETTING_TIMEZONE, "Local", "", ERR_UNKNOWN_TIMEZONE},
+		{SETTING_TIMEZONE, "", "", ERR_UNKNOWN_TIMEZONE},
+
+		{SETTING_LANGUAGE, "ES", "es", ""},
+		{SETTING_LANGUAGE, "pt", "pt", ""},
+		{SETTING_LANGUAGE, "fr", "", ERR_UNSUPPORTED_LANGUAGE},
+		{SETTING_LANGUAGE, "", "", ERR_UNSUPPORTED_LANGUAGE},
+
+		{SETTING_REMINDERS, "2h, 1d, 30m", "1d, 2h, 30m", ""},
+		{SETTING_REMINDERS, "48h 90m", "2d, 90m", ""},
+		{SETTING_REMINDERS, "1D", "1d", ""},
+		{SETTING_REMINDERS, "", "", ""},
+		{SETTING_REMINDERS, "1d,2d,3d,4d,5d", "5d, 4d, 3d, 2d, 1d", ""},
+		{SETTING_REMINDERS, "1d,2d,3d,4d,5d,6d", "", ERR_TOO_MANY_REMINDERS},
+		{SETTING_REMINDERS, "1w", "", ERR_INVALID_REMINDER},
+		{SETTING_REMINDERS, "2 h", "", ERR_INVALID_REMINDER},
+		{SETTING_REMINDERS, "-1h", "", ERR_INVALID_REMINDER},
+
+		{SETTING_GAME_CREATORS, "Everyone", "everyone", ""},
+		{SETTING_GAME_CREATORS, "admins", "admins", ""},
+		{SETTING_GAME_CREATORS, "players", "", ERR_INVALID_GAME_CREATORS},
+
+		{"kit_colour", "red", "", ERR_UNKNOWN_SETTING},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.key)+"="+tt.value, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repositories.NewMemorySettingsRepository()
+			s := &SettingsService{SettingsRepository: repo}
+
+			settings, err := s.UpdateSetting(ctx, chatID, tt.key, tt.value)
+			stored, _ := repo.GetChatSettings(ctx, chatID)
+			if tt.wantCode != "" {
+				serviceErr := AsError(err)
+				if serviceErr == nil || serviceErr.Kind != KIND_VALIDATION || serviceErr.Code != tt.wantCode {
+					t.Fatalf("UpdateSetting = %v, want %s", err, tt.wantCode)
+				}
+				if len(stored) != 0 {
+					t.Errorf("stored = %v, want nothing", stored)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateSetting: %v", err)
+			}
+			if got := FormatSetting(settings, tt.key); got != tt.want {
+				t.Errorf("FormatSetting = %q, want %q", got, tt.want)
+			}
+			if stored[string(tt.key)] != tt.want {
+				t.Errorf("stored = %q, want %q", stored[string(tt.key)], tt.want)
+			}
+
+			// What is stored reads back the same.
+			reread, err := s.GetSettings(ctx, chatID)
+			if err != nil {
+				t.Fatalf("GetSettings: %v", err)
+			}
+			if got := FormatSetting(reread, tt.key); got != tt.want {
+				t.Errorf("FormatSetting after GetSettings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateSettingParsesValues(t *testing.T) {
+	ctx := context.Background()
+	s := &SettingsService{SettingsRepository: repositories.NewMemorySettingsRepository()}
+
+	settings, err := s.UpdateSetting(ctx, chatID, SETTING_REMINDERS, "30m, 1d, 2h")
+	if err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	want := []time.Duration{24 * time.Hour, 2 * time.Hour, 30 * time.Minute}
+	if len(settings.ReminderOffsets) != len(want) {
+		t.Fatalf("ReminderOffsets = %v, want %v", settings.ReminderOffsets, want)
+	}
+	for i := range want {
+		if settings.ReminderOffsets[i] != want[i] {
+			t.Errorf("ReminderOffsets = %v, want %v, earliest first", settings.ReminderOffsets, want)
+		}
+	}
+
+	settings, err = s.UpdateSetting(ctx, chatID, SETTING_TIMEZONE, "Asia/Singapore")
+	if err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	if offset := time.Date(2024, 10, 13, 11, 0, 0, 0, settings.Timezone).UTC().Hour(); offset != 3 {
+		t.Errorf("11:00 in %s = %02d:00 UTC, want 03:00", settings.Timezone, offset)
+	}
+	if offsets, _ := s.ReminderOffsets(ctx, chatID); len(offsets) != 3 {
+		t.Errorf("reminders after changing the time zone = %v, want them kept", offsets)
+	}
+}
+
+func TestGetSettings(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewMemorySettingsRepository()
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &SettingsService{SettingsRepository: repo, Defaults: models.ChatSettings{Currency: "EUR", DefaultPrice: 5, Timezone: lisbon}}
+
+	settings, err := s.GetSettings(ctx, chatID)
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if settings.ChatId != chatID || settings.Currency != "EUR" || settings.DefaultPrice != 5 || settings.Timezone != lisbon ||
+		settings.Language != "en" || settings.GameCreators != string(ADMINS) || settings.SquadSize != 0 {
+		t.Errorf("defaults = %+v", settings)
+	}
+
+	// Values stored by older versions or by hand that no longer parse fall
+	// back to the defaults, and the others still apply.
+	repo.UpsertChatSetting(ctx, chatID, string(SETTING_SQUAD_SIZE), "lots")
+	repo.UpsertChatSetting(ctx, chatID, string(SETTING_TIMEZONE), "Nowhere/Town")
+	repo.UpsertChatSetting(ctx, chatID, string(SETTING_LANGUAGE), "pt")
+	repo.UpsertChatSetting(ctx, chatID, "retired_setting", "1")
+	settings, err = s.GetSettings(ctx, chatID)
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if settings.SquadSize != 0 || settings.Timezone != lisbon || settings.Language != "pt" {
+		t.Errorf("settings with broken values = %+v, want defaults for them and pt", settings)
+	}
+	if other, err := s.GetSettings(ctx, chatID+1); err != nil || other.Language != "en" {
+		t.Errorf("settings of another chat = %+v, %v; want the defaults", other, err)
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/repositories"
+)
+
+// TokenPurpose tells what a chat token gives access to. A chat has one token
+// per purpose, so revoking one leaves the others working.
+type TokenPurpose string
+
+const (
+	TOKEN_CALENDAR TokenPurpose = "calendar"
+	TOKEN_API      TokenPurpose = "api"
+)
+
+// hashed reports whether tokens for the purpose are stored hashed, as API
+// tokens are, since they manage the chat's games. Those cannot be read back,
+// so each one is shown once, when issued.
+func (p TokenPurpose) hashed() bool {
+	return p == TOKEN_API
+}
+
+// stored is how token is kept for the purpose.
+func (p TokenPurpose) stored(token string) string {
+	if p.hashed() {
+		return hashToken(token)
+	}
+	return token
+}
+
+// TOKEN_BYTES is how many random bytes a token is made of.
+const TOKEN_BYTES = 24
+
+type ITokenService interface {
+	ChatToken(ctx context.Context, chatId int64, purpose TokenPurpose) (string, error)
+	ResetChatToken(ctx context.Context, chatId int64, purpose TokenPurpose) (string, error)
+	ChatForToken(ctx context.Context, purpose TokenPurpose, token string) (int64, error)
+}
+
+// TokenService hands out the secret tokens in links to a chat's data, such as
+// its calendar, which are followed without Telegram to tell who is asking.
+type TokenService struct {
+	TokenRepository repositories.ITokenRepository
+}
+
+// ChatToken returns the chat's token for purpose, creating it on first use.
+// Tokens stored hashed cannot be returned again and are issued with
+// ResetChatToken instead.
+func (s *TokenService) ChatToken(ctx context.Context, chatId int64, purpose TokenPurpose) (string, error) {
+	if purpose.hashed() {
+		return "", Internal(ERR_TOKEN, fmt.Errorf("%s tokens are stored hashed and cannot be read back", purpose))
+	}
+	token, err := s.TokenRepository.GetChatToken(ctx, chatId, string(purpose))
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not retrieve chat token", "purpose", purpose, "error", err)
+		return "", Internal(ERR_TOKEN, fmt.Errorf("get %s token of chat %d: %w", purpose, chatId, err))
+	}
+	if token != "" {
+		return token, nil
+	}
+
+	token, err = newToken()
+	if err != nil {
+		return "", Internal(ERR_TOKEN, err)
+	}
+	// Another request may have created one meanwhile, which is then kept.
+	err = s.TokenRepository.InsertChatToken(ctx, chatId, string(purpose), token)
+	if err == nil {
+		token, err = s.TokenRepository.GetChatToken(ctx, chatId, string(purpose))
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not create chat token", "purpose", purpose, "error", err)
+		return "", Internal(ERR_TOKEN, fmt.Errorf("create %s token of chat %d: %w", purpose, chatId, err))
+	}
+	logging.FromContext(ctx).Info("Chat token created", "purpose", purpose)
+	return token, nil
+}
+
+// ResetChatToken replaces the chat's token for purpose, so links with the
+// previous one stop working, and returns the new one.
+func (s *TokenService) ResetChatToken(ctx context.Context, chatId int64, purpose TokenPurpose) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", Internal(ERR_TOKEN, err)
+	}
+	if err := s.TokenRepository.ReplaceChatToken(ctx, chatId, string(purpose), purpose.stored(token)); err != nil {
+		logging.FromContext(ctx).Error("Could not replace chat token", "purpose", purpose, "error", err)
+		return "", Internal(ERR_TOKEN, fmt.Errorf("replace %s token of chat %d: %w", purpose, chatId, err))
+	}
+	logging.FromContext(ctx).Info("Chat token reset", "purpose", purpose)
+	return token, nil
+}
+
+// ChatForToken returns the chat token was given to for purpose.
+func (s *TokenService) ChatForToken(ctx context.Context, purpose TokenPurpose, token string) (int64, error) {
+	if token == "" {
+		return 0, NotFound(ERR_UNKNOWN_TOKEN)
+	}
+	chatId, ok, err := s.TokenRepository.GetChatByToken(ctx, string(purpose), purpose.stored(token))
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not look up chat token", "purpose", purpose, "error", err)
+		return 0, Internal(ERR_TOKEN, fmt.Errorf("get chat of %s token: %w", purpose, err))
+	}
+	if !ok {
+		return 0, NotFound(ERR_UNKNOWN_TOKEN)
+	}
+	return chatId, nil
+}
+
+// newToken returns a random token safe to put in URLs.
+func newToken() (string, error) {
+	b := make([]byte, TOKEN_BYTES)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"tg-sunday-league/repositories"
+)
+
+func TestChatTokens(t *testing.T) {
+	ctx := context.Background()
+	s := &TokenService{TokenRepository: repositories.NewMemoryTokenRepository()}
+
+	token, err := s.ChatToken(ctx, chatID, TOKEN_CALENDAR)
+	if err != nil || len(token) < 32 {
+		t.Fatalf("ChatToken = %q, %v; want a new token", token, err)
+	}
+	if again, err := s.ChatToken(ctx, chatID, TOKEN_CALENDAR); err != nil || again != token {
+		t.Errorf("ChatToken again = %q, %v; want %q", again, err, token)
+	}
+	if chat, err := s.ChatForToken(ctx, TOKEN_CALENDAR, token); err != nil || chat != chatID {
+		t.Errorf("ChatForToken = %d, %v; want %d", chat, err, chatID)
+	}
+
+	reset, err := s.ResetChatToken(ctx, chatID, TOKEN_CALENDAR)
+	if err != nil || reset == token {
+		t.Fatalf("ResetChatToken = %q, %v; want another token", reset, err)
+	}
+	if _, err := s.ChatForToken(ctx, TOKEN_CALENDAR, token); !IsKind(err, KIND_NOT_FOUND) {
+		t.Errorf("ChatForToken of the reset token = %v, want not found", err)
+	}
+	if chat, err := s.ChatForToken(ctx, TOKEN_CALENDAR, reset); err != nil || chat != chatID {
+		t.Errorf("ChatForToken of the new token = %d, %v; want %d", chat, err, chatID)
+	}
+	if _, err := s.ChatForToken(ctx, TOKEN_CALENDAR, ""); !IsKind(err, KIND_NOT_FOUND) {
+		t.Errorf("ChatForToken of no token = %v, want not found", err)
+	}
+}
+
+func TestAPITokensAreStoredHashed(t *testing.T) {
+	ctx := context.Background()
+	tokens := repositories.NewMemoryTokenRepository()
+	s := &TokenService{TokenRepository: tokens}
+
+	if _, err := s.ChatToken(ctx, chatID, TOKEN_API); !IsKind(err, KIND_INTERNAL) {
+		t.Errorf("ChatToken of an API token = %v, want an internal error as it cannot be read back", err)
+	}
+	token, err := s.ResetChatToken(ctx, chatID, TOKEN_API)
+	if err != nil || len(token) < 32 {
+		t.Fatalf("ResetChatToken = %q, %v; want a new token", token, err)
+	}
+	if stored, err := tokens.GetChatToken(ctx, chatID, string(TOKEN_API)); err != nil || stored != hashToken(token) {
+		t.Errorf("stored token = %q, %v; want its hash %q", stored, err, hashToken(token))
+	}
+	if chat, err := s.ChatForToken(ctx, TOKEN_API, token); err != nil || chat != chatID {
+		t.Errorf("ChatForToken = %d, %v; want %d", chat, err, chatID)
+	}
+	if _, err := s.ChatForToken(ctx, TOKEN_API, hashToken(token)); !IsKind(err, KIND_NOT_FOUND) {
+		t.Errorf("ChatForToken of the stored hash = %v, want not found", err)
+	}
+
+	again, err := s.ResetChatToken(ctx, chatID, TOKEN_API)
+	if err != nil || again == token {
+		t.Fatalf("ResetChatToken again = %q, %v; want another token", again, err)
+	}
+	if _, err := s.ChatForToken(ctx, TOKEN_API, token); !IsKind(err, KIND_NOT_FOUND) {
+		t.Errorf("ChatForToken of the replaced token = %v, want not found", err)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MAX_WEBHOOKS is how many webhooks a chat may register.
+const MAX_WEBHOOKS = 5
+
+// MAX_WEBHOOK_URL is the length past which webhook URLs are refused.
+const MAX_WEBHOOK_URL = 2048
+
+type IWebhookService interface {
+	AddWebhook(ctx context.Context, chatId int64, rawUrl string) (*models.Webhook, error)
+	RemoveWebhook(ctx context.Context, chatId int64, rawUrl string) error
+	ListWebhooks(ctx context.Context, chatId int64) ([]models.Webhook, error)
+}
+
+// WebhookService keeps the URLs chats want their game events sent to. The
+// events are queued for them in the outbox by GameService, from where they
+// are delivered.
+type WebhookService struct {
+	WebhookRepository repositories.IWebhookRepository
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks for an event. Type is
+// one of the EventType values.
+type WebhookPayload struct {
+	Id         uuid.UUID      `json:"id"`
+	Type       EventType      `json:"type"`
+	ChatId     int64          `json:"chat_id"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Game       WebhookGame    `json:"game"`
+	Player     *WebhookPlayer `json:"player,omitempty"`
+}
+
+type WebhookGame struct {
+	Id        uuid.UUID `json:"id"`
+	Kickoff   time.Time `json:"kickoff"`
+	Location  string    `json:"location"`
+	Opponent  string    `json:"opponent"`
+	Price     float64   `json:"price"`
+	Cancelled bool      `json:"cancelled"`
+	Score     string    `json:"score,omitempty"`
+}
+
+type WebhookPlayer struct {
+	UserId int64  `json:"user_id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Paid   bool   `json:"paid"`
+}
+
+// AddWebhook registers rawUrl, which must be an https URL, to be sent the
+// chat's game events. The webhook is returned with the secret its payloads
+// are signed with.
+func (s *WebhookService) AddWebhook(ctx context.Context, chatId int64, rawUrl string) (*models.Webhook, error) {
+	webhookUrl, err := parseWebhookUrl(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.ListWebhooks(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MAX_WEBHOOKS {
+		return nil, Invalid("url", ERR_WEBHOOK_LIMIT, nil, MAX_WEBHOOKS)
+	}
+
+	secret, err := newToken()
+	if err != nil {
+		return nil, Internal(ERR_WEBHOOKS, err)
+	}
+	webhook := &models.Webhook{Id: uuid.New(), ChatId: chatId, Url: webhookUrl, Secret: secret}
+	err = s.WebhookRepository.InsertWebhook(ctx, webhook)
+	if errors.Is(err, repositories.ErrConflict) {
+		return nil, Conflict(ERR_WEBHOOK_EXISTS)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not create webhook", "error", err)
+		return nil, Internal(ERR_WEBHOOKS, fmt.Errorf("insert webhook of chat %d: %w", chatId, err))
+	}
+	logging.FromContext(ctx).Info("Webhook added", "webhook_id", webhook.Id)
+	return webhook, nil
+}
+
+// RemoveWebhook stops sending events to rawUrl, dropping those still waiting
+// for it.
+func (s *WebhookService) RemoveWebhook(ctx context.Context, chatId int64, rawUrl string) error {
+	deleted, err := s.WebhookRepository.DeleteWebhook(ctx, chatId, strings.TrimSpace(rawUrl))
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not delete webhook", "error", err)
+		return Internal(ERR_WEBHOOKS, fmt.Errorf("delete webhook of chat %d: %w", chatId, err))
+	}
+	if !deleted {
+		return NotFound(ERR_WEBHOOK_NOT_FOUND)
+	}
+	logging.FromContext(ctx).Info("Webhook removed")
+	return nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, chatId int64) ([]models.Webhook, error) {
+	webhooks, err := s.WebhookRepository.ListWebhooks(ctx, chatId)
+	if err != nil {
+		logging.FromContext(ctx).Error("Could not list webhooks", "error", err)
+		return nil, Internal(ERR_WEBHOOKS, fmt.Errorf("list webhooks of chat %d: %w", chatId, err))
+	}
+	return webhooks, nil
+}
+
+// enqueueEvents queues the events in the outbox for every webhook of their
+// chat, due right away. GameService calls it in the unit of work making the
+// changes, so a change is never saved without its events. webhooks may be
+// nil to queue nothing.
+func enqueueEvents(ctx context.Context, webhooks repositories.IWebhookRepository, events []Event) error {
+	if webhooks == nil {
+		return nil
+	}
+	for _, event := range events {
+		subscribed, err := webhooks.ListWebhooks(ctx, event.ChatId)
+		if err != nil {
+			return fmt.Errorf("list webhooks of chat %d: %w", event.ChatId, err)
+		}
+		if len(subscribed) == 0 {
+			continue
+		}
+		payload, err := json.Marshal(newWebhookPayload(event))
+		if err != nil {
+			return fmt.Errorf("encode event %s: %w", event.Id, err)
+		}
+		for _, webhook := range subscribed {
+			message := &models.OutboxMessage{
+				Id:            uuid.New(),
+				WebhookId:     webhook.Id,
+				EventId:       event.Id,
+				EventType:     string(event.Type),
+				Payload:       payload,
+				NextAttemptAt: event.OccurredAt,
+			}
+			if err := webhooks.InsertOutboxMessage(ctx, message); err != nil {
+				return fmt.Errorf("queue event %s for webhook %s: %w", event.Id, webhook.Id, err)
+			}
+		}
+	}
+	return nil
+}
+
+func newWebhookPayload(event Event) WebhookPayload {
+	game := event.Game
+	payload := WebhookPayload{
+		Id:         event.Id,
+		Type:       event.Type,
+		ChatId:     event.ChatId,
+		OccurredAt: event.OccurredAt.UTC(),
+		Game: WebhookGame{
+			Id:        game.Id,
+			Kickoff:   game.Date.UTC(),
+			Location:  game.Location,
+			Opponent:  game.Opponent,
+			Price:     game.Price,
+			Cancelled: game.Cancelled,
+			Score:     game.Score,
+		},
+	}
+	if player := event.Player; player != nil {
+		// Statuses are written as the API takes them.
+		status := "in"
+		if player.Status == string(OUT) {
+			status = "out"
+		}
+		payload.Player = &WebhookPlayer{UserId: player.UserId, Name: player.Name, Status: status, Paid: player.HasPaid}
+	}
+	return payload
+}
+
+// parseWebhookUrl checks rawUrl is an https URL to a host. Payloads are only
+// sent encrypted, as they name players.
+func parseWebhookUrl(rawUrl string) (string, error) {
+	rawUrl = strings.TrimSpace(rawUrl)
+	if rawUrl == "" || len(rawUrl) > MAX_WEBHOOK_URL {
+		return "", Invalid("url", ERR_WEBHOOK_URL, nil, rawUrl)
+	}
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return "", Invalid("url", ERR_WEBHOOK_URL, err, rawUrl)
+	}
+	return rawUrl, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"time"
+)
+
+func TestAddWebhook(t *testing.T) {
+	ctx := context.Background()
+	s := &WebhookService{WebhookRepository: repositories.NewMemoryWebhookRepository()}
+
+	webhook, err := s.AddWebhook(ctx, chatID, " https://example.com/hook ")
+	if err != nil || webhook.Url != "https://example.com/hook" || webhook.ChatId != chatID || len(webhook.Secret) < 32 {
+		t.Fatalf("AddWebhook = %+v, %v; want it with a secret", webhook, err)
+	}
+	if _, err := s.AddWebhook(ctx, chatID, "https://example.com/hook"); !IsKind(err, KIND_CONFLICT) {
+		t.Errorf("AddWebhook of the same URL = %v, want a conflict", err)
+	}
+	for _, url := range []string{"", "http://example.com/hook", "example.com/hook", "https:///hook", "https://example.com/" + string(make([]byte, MAX_WEBHOOK_URL))} {
+		if _, err := s.AddWebhook(ctx, chatID, url); AsError(err) == nil || AsError(err).Code != ERR_WEBHOOK_URL {
+			t.Errorf("AddWebhook(%.30q) = %v, want %s", url, err, ERR_WEBHOOK_URL)
+		}
+	}
+
+	for i := 1; i < MAX_WEBHOOKS; i++ {
+		if _, err := s.AddWebhook(ctx, chatID, fmt.Sprintf("https://example.com/hook/%d", i)); err != nil {
+			t.Fatalf("AddWebhook %d: %v", i, err)
+		}
+	}
+	if _, err := s.AddWebhook(ctx, chatID, "https://example.com/one-too-many"); AsError(err) == nil || AsError(err).Code != ERR_WEBHOOK_LIMIT {
+		t.Errorf("AddWebhook past the limit = %v, want %s", err, ERR_WEBHOOK_LIMIT)
+	}
+	if _, err := s.AddWebhook(ctx, chatID-1, "https://example.com/hook"); err != nil {
+		t.Errorf("AddWebhook of another chat: %v", err)
+	}
+}
+
+func TestRemoveWebhook(t *testing.T) {
+	ctx := context.Background()
+	s := &WebhookService{WebhookRepository: repositories.NewMemoryWebhookRepository()}
+	if _, err := s.AddWebhook(ctx, chatID, "https://example.com/hook"); err != nil {
+		t.Fatalf("AddWebhook: %v", err)
+	}
+
+	if err := s.RemoveWebhook(ctx, chatID-1, "https://example.com/hook"); !IsKind(err, KIND_NOT_FOUND) {
+		t.Errorf("RemoveWebhook of another chat = %v, want not found", err)
+	}
+	if err := s.RemoveWebhook(ctx, chatID, "https://example.com/hook"); err != nil {
+		t.Fatalf("RemoveWebhook: %v", err)
+	}
+	if webhooks, err := s.ListWebhooks(ctx, chatID); err != nil || len(webhooks) != 0 {
+		t.Errorf("ListWebhooks = %+v, %v; want none", webhooks, err)
+	}
+}
+
+func TestGameServiceQueuesEventsForWebhooks(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	webhooks := f.webhooks
+	s := &WebhookService{WebhookRepository: webhooks}
+	for _, url := range []string{"https://a.example.com", "https://b.example.com"} {
+		if _, err := s.AddWebhook(ctx, chatID, url); err != nil {
+			t.Fatalf("AddWebhook: %v", err)
+		}
+	}
+	games := f.service()
+
+	if _, _, _, err := games.CreateNewGame(ctx, chatID-1, 10, "Ana", []string{"2099-01-04 11:00"}); err != nil {
+		t.Fatalf("CreateNewGame in a chat without webhooks: %v", err)
+	}
+	if _, _, _, err := games.CreateNewGame(ctx, chatID, 10, "Ana", []string{"2099-01-04 11:00", "Kallang", "Rovers", "12"}); err != nil {
+		t.Fatalf("CreateNewGame: %v", err)
+	}
+	chat, user, name := chatID, int64(20), "Bea"
+	if _, _, _, err := games.RegisterPlayer(ctx, &chat, &user, &name, OUT); err != nil {
+		t.Fatalf("RegisterPlayer: %v", err)
+	}
+
+	messages, err := webhooks.ListDueMessages(ctx, time.Now(), 10)
+	if err != nil || len(messages) != 4 {
+		t.Fatalf("queued = %d messages, %v; want 2 events for 2 webhooks", len(messages), err)
+	}
+	byType := make(map[string][]models.OutboxMessage)
+	for _, message := range messages {
+		byType[message.EventType] = append(byType[message.EventType], message)
+	}
+	created, left := byType[string(EVENT_GAME_CREATED)], byType[string(EVENT_PLAYER_LEFT)]
+	if len(created) != 2 || created[0].Url == created[1].Url || created[0].EventId != created[1].EventId {
+		t.Fatalf("game.created messages = %+v, want the same event for both webhooks", created)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(created[0].Payload, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if payload.Id != created[0].EventId || payload.ChatId != chatID || payload.Game.Opponent != "Rovers" || payload.Game.Price != 12 || payload.Player != nil {
+		t.Errorf("game.created payload = %+v", payload)
+	}
+	if err := json.Unmarshal(left[0].Payload, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if payload.Player == nil || payload.Player.UserId != 20 || payload.Player.Name != "Bea" || payload.Player.Status != "out" {
+		t.Errorf("player.left payload player = %+v, want Bea out", payload.Player)
+	}
+}
+
+// failingOutbox fails to queue messages, as when the database fails halfway
+// through a unit of work.
+type failingOutbox struct {
+	repositories.IWebhookRepository
+}
+
+func (r *failingOutbox) InsertOutboxMessage(ctx context.Context, message *models.OutboxMessage) error {
+	return errDatabase
+}
+
+func TestGameServiceRollsBackWhenEventsCannotBeQueued(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	if _, err := (&WebhookService{WebhookRepository: f.webhooks}).AddWebhook(ctx, chatID, "https://example.com/hook"); err != nil {
+		t.Fatalf("AddWebhook: %v", err)
+	}
+	f.webhookRepo = &failingOutbox{IWebhookRepository: f.webhooks}
+	s := f.service()
+	events := recordEvents(s)
+
+	_, _, _, err := s.CreateNewGame(ctx, chatID, 10, "Ana", []string{"2099-01-04 11:00"})
+	if serviceErr := AsError(err); serviceErr == nil || serviceErr.Code != ERR_WEBHOOKS {
+		t.Fatalf("CreateNewGame = %v, want %s", err, ERR_WEBHOOKS)
+	}
+	if game, _ := f.games.GetLatestGameByChatID(ctx, chatID); game != nil {
+		t.Errorf("latest game = %+v, want the game rolled back", game)
+	}
+	wantNoUser(t, f, 10)
+	if messages, _ := f.webhooks.ListDueMessages(ctx, time.Now(), 10); len(messages) != 0 {
+		t.Errorf("queued = %+v, want none", messages)
+	}
+	if len(*events) != 0 {
+		t.Errorf("published = %+v, want none", *events)
+	}
+}
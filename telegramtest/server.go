@@ -0,0 +1,435 @@
+// Package telegramtest provides a local stand-in for the Telegram Bot API, so
+// the bot can be driven end to end without reaching Telegram. Incoming updates
+// are scripted with SendText, SendDocument and PressButton, and every call the bot makes is
+// recorded for the test to inspect.
+package telegramtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+// Request is one Bot API call made by the bot. Params holds the call's
+// parameters as strings, with nested objects such as reply_markup kept as
+// JSON. Files holds the files uploaded with it by parameter. Message is the
+// message sendMessage, sendDocument and editMessageText answered with.
+type Request struct {
+	Method  string
+	Params  map[string]string
+	Files   map[string]File
+	Message *telebot.Message
+}
+
+// File is a file uploaded by the bot, or sent to it with SendDocument.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Server answers the Bot API methods telebot uses: getMe, getUpdates,
+// sendMessage, sendDocument, editMessageText, answerCallbackQuery,
+// getChatAdministrators and getFile, and serves the files sent with
+// SendDocument for download. Other methods are recorded and answered with a
+// bare success.
+type Server struct {
+	URL string
+	// Me is the bot's own user, returned by getMe.
+	Me telebot.User
+
+	server *httptest.Server
+
+	mu            sync.Mutex
+	updates       []telebot.Update
+	nextUpdateID  int
+	nextMessageID int
+	requests      []Request
+	admins        map[int64][]telebot.User
+	// files are the files sent with SendDocument by file ID.
+	files map[string]File
+	// rejected are the methods answered with an error, with its description.
+	rejected map[string]string
+	// changed is closed and replaced whenever updates or requests change,
+	// waking long polls and waiters.
+	changed chan struct{}
+	closed  chan struct{}
+}
+
+// NewServer starts a fake Bot API server. Pass its URL as the API URL of the
+// bot under test and Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		Me:            telebot.User{ID: 1, FirstName: "Sunday League", Username: "sunday_league_bot", IsBot: true},
+		nextUpdateID:  1,
+		nextMessageID: 1,
+		admins:        make(map[int64][]telebot.User),
+		files:         make(map[string]File),
+		rejected:      make(map[string]string),
+		changed:       make(chan struct{}),
+		closed:        make(chan struct{}),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close releases pending long polls and shuts the server down.
+func (s *Server) Close() {
+	close(s.closed)
+	s.server.Close()
+}
+
+// SetAdmins sets the administrators returned for the chat.
+func (s *Server) SetAdmins(chatID int64, admins ...telebot.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.admins[chatID] = admins
+}
+
+// Reject makes the server answer every later call to method with an error
+// carrying description, as Telegram does for calls it refuses.
+func (s *Server) Reject(method string, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejected[method] = description
+}
+
+// SendText queues a text message from user in chat, as if typed in Telegram,
+// and returns it.
+func (s *Server) SendText(chat *telebot.Chat, from *telebot.User, text string) *telebot.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := &telebot.Message{
+		ID:       s.nextMessageID,
+		Sender:   from,
+		Chat:     chat,
+		Text:     text,
+		Unixtime: time.Now().Unix(),
+	}
+	s.nextMessageID++
+	s.queue(telebot.Update{Message: m})
+	return m
+}
+
+// SendDocument queues a file sent by user in chat with caption, as if
+// attached in Telegram, and returns the message. The bot can download it.
+func (s *Server) SendDocument(chat *telebot.Chat, from *telebot.User, file File, caption string) *telebot.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fileID := "upload-" + strconv.Itoa(s.nextMessageID)
+	s.files[fileID] = file
+	m := &telebot.Message{
+		ID:     s.nextMessageID,
+		Sender: from,
+		Chat:   chat,
+		Document: &telebot.Document{
+			File:     telebot.File{FileID: fileID, FileSize: len(file.Content)},
+			FileName: file.Name,
+		},
+		Caption:  caption,
+		Unixtime: time.Now().Unix(),
+	}
+	s.nextMessageID++
+	s.queue(telebot.Update{Message: m})
+	return m
+}
+
+// PressButton queues a press by user of the inline button carrying data under
+// message, as found in the reply_markup the bot sent.
+func (s *Server) PressButton(message *telebot.Message, from *telebot.User, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queue(telebot.Update{Callback: &telebot.Callback{
+		ID:      "callback-" + strconv.Itoa(s.nextUpdateID),
+		Sender:  from,
+		Message: message,
+		Data:    data,
+	}})
+}
+
+// Requests returns every call recorded so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// WaitForRequests waits until at least count calls to any of methods have
+// been recorded and returns those calls.
+func (s *Server) WaitForRequests(count int, timeout time.Duration, methods ...string) ([]Request, error) {
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		var matching []Request
+		for _, r := range s.requests {
+			for _, method := range methods {
+				if r.Method == method {
+					matching = append(matching, r)
+					break
+				}
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		if len(matching) >= count {
+			return matching, nil
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return matching, fmt.Errorf("got %d calls to %s after %v, want %d", len(matching), strings.Join(methods, "/"), timeout, count)
+		}
+	}
+}
+
+// queue must be called with mu held.
+func (s *Server) queue(update telebot.Update) {
+	update.ID = s.nextUpdateID
+	s.nextUpdateID++
+	s.updates = append(s.updates, update)
+	s.notify()
+}
+
+// notify must be called with mu held.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/file/") {
+		s.download(w, r)
+		return
+	}
+	// Paths look like /bot<token>/<method>.
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "bot") {
+		http.NotFound(w, r)
+		return
+	}
+	method := parts[1]
+	params, files := decodeParams(r)
+
+	if method == "getUpdates" {
+		s.getUpdates(w, params)
+		return
+	}
+
+	s.mu.Lock()
+	request := Request{Method: method, Params: params, Files: files}
+	var result interface{}
+	var err error
+	if description, rejected := s.rejected[method]; rejected {
+		s.requests = append(s.requests, request)
+		s.notify()
+		s.mu.Unlock()
+		reply(w, nil, errors.New(description))
+		return
+	}
+	switch method {
+	case "getMe":
+		result = s.Me
+	case "sendMessage", "editMessageText":
+		request.Message, err = s.message(method, params)
+		result = request.Message
+	case "sendDocument":
+		request.Message, err = s.message(method, params)
+		if err == nil {
+			request.Message.Caption = params["caption"]
+			request.Message.Document = &telebot.Document{
+				File:     telebot.File{FileID: "document-" + strconv.Itoa(request.Message.ID)},
+				FileName: files["document"].Name,
+			}
+		}
+		result = request.Message
+	case "getChatAdministrators":
+		result, err = s.chatAdministrators(params)
+	case "getFile":
+		result, err = s.file(params)
+	default:
+		result = true
+	}
+	s.requests = append(s.requests, request)
+	s.notify()
+	s.mu.Unlock()
+
+	reply(w, result, err)
+}
+
+// getUpdates long polls like Telegram: it returns the updates from offset on
+// as soon as there are any, or an empty list once the timeout passes.
+func (s *Server) getUpdates(w http.ResponseWriter, params map[string]string) {
+	offset, _ := strconv.Atoi(params["offset"])
+	timeout, _ := strconv.Atoi(params["timeout"])
+	deadline := time.After(time.Duration(timeout) * time.Second)
+
+	for {
+		s.mu.Lock()
+		var pending []telebot.Update
+		for _, u := range s.updates {
+			if u.ID >= offset {
+				pending = append(pending, u)
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		if len(pending) > 0 {
+			reply(w, pending, nil)
+			return
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			reply(w, []telebot.Update{}, nil)
+			return
+		case <-s.closed:
+			reply(w, []telebot.Update{}, nil)
+			return
+		}
+	}
+}
+
+// message must be called with mu held.
+func (s *Server) message(method string, params map[string]string) (*telebot.Message, error) {
+	chatID, err := strconv.ParseInt(params["chat_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Bad Request: chat not found")
+	}
+
+	m := &telebot.Message{
+		Sender:   &s.Me,
+		Chat:     &telebot.Chat{ID: chatID, Type: chatType(chatID)},
+		Text:     params["text"],
+		Unixtime: time.Now().Unix(),
+	}
+	if method == "editMessageText" {
+		m.ID, err = strconv.Atoi(params["message_id"])
+		if err != nil {
+			return nil, fmt.Errorf("Bad Request: message to edit not found")
+		}
+		return m, nil
+	}
+	m.ID = s.nextMessageID
+	s.nextMessageID++
+	return m, nil
+}
+
+// chatAdministrators must be called with mu held.
+func (s *Server) chatAdministrators(params map[string]string) ([]telebot.ChatMember, error) {
+	chatID, err := strconv.ParseInt(params["chat_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Bad Request: chat not found")
+	}
+	if chatType(chatID) == telebot.ChatPrivate {
+		return nil, fmt.Errorf("Bad Request: there are no administrators in the private chat")
+	}
+
+	members := []telebot.ChatMember{}
+	for i := range s.admins[chatID] {
+		members = append(members, telebot.ChatMember{User: &s.admins[chatID][i], Role: telebot.Administrator})
+	}
+	return members, nil
+}
+
+// file must be called with mu held. Files are downloaded from their ID.
+func (s *Server) file(params map[string]string) (*telebot.File, error) {
+	file, ok := s.files[params["file_id"]]
+	if !ok {
+		return nil, fmt.Errorf("Bad Request: invalid file_id")
+	}
+	return &telebot.File{FileID: params["file_id"], FileSize: len(file.Content), FilePath: params["file_id"]}, nil
+}
+
+// download serves a file sent with SendDocument. Paths look like
+// /file/bot<token>/<file path>.
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/file/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.Lock()
+	file, ok := s.files[parts[1]]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(file.Content)
+}
+
+// chatType follows Telegram's numbering: group chats have negative IDs.
+func chatType(chatID int64) telebot.ChatType {
+	if chatID < 0 {
+		return telebot.ChatGroup
+	}
+	return telebot.ChatPrivate
+}
+
+// decodeParams reads a JSON request body into strings, keeping nested values
+// as JSON, or a multipart one into strings and the files uploaded.
+func decodeParams(r *http.Request) (map[string]string, map[string]File) {
+	params := make(map[string]string)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return decodeMultipart(r, params)
+	}
+	var raw map[string]json.RawMessage
+	// getMe and friends are sent with a null body, which leaves raw empty.
+	json.NewDecoder(r.Body).Decode(&raw)
+	for key, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			params[key] = s
+			continue
+		}
+		params[key] = string(value)
+	}
+	return params, nil
+}
+
+func decodeMultipart(r *http.Request, params map[string]string) (map[string]string, map[string]File) {
+	files := make(map[string]File)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return params, files
+	}
+	for key, values := range r.MultipartForm.Value {
+		params[key] = values[0]
+	}
+	for key, headers := range r.MultipartForm.File {
+		f, err := headers[0].Open()
+		if err != nil {
+			continue
+		}
+		content, _ := io.ReadAll(f)
+		f.Close()
+		files[key] = File{Name: headers[0].Filename, Content: content}
+	}
+	return params, files
+}
+
+func reply(w http.ResponseWriter, result interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok":          false,
+			"error_code":  400,
+			"description": err.Error(),
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+}
@@ -0,0 +1,369 @@
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"tg-sunday-league/i18n"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API_PATH starts the routes of the JSON API, described by the OpenAPI spec
+// served at API_PATH + "/openapi.yaml".
+const API_PATH = "/api/v1"
+
+// MAX_API_BODY is the size in bytes past which request bodies are refused.
+const MAX_API_BODY = 64 << 10
+
+// Statuses a player may answer with in the API.
+const (
+	API_STATUS_IN  = "in"
+	API_STATUS_OUT = "out"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// apiGame is a game as the API returns it. Players and absentees are only
+// given with the details of one game.
+type apiGame struct {
+	Id        uuid.UUID    `json:"id"`
+	Kickoff   time.Time    `json:"kickoff"`
+	Location  string       `json:"location"`
+	Opponent  string       `json:"opponent"`
+	Price     float64      `json:"price"`
+	Currency  string       `json:"currency"`
+	Cancelled bool         `json:"cancelled"`
+	Score     string       `json:"score,omitempty"`
+	Players   *[]apiPlayer `json:"players,omitempty"`
+	Absentees *[]apiPlayer `json:"absentees,omitempty"`
+}
+
+type apiPlayer struct {
+	UserId int64  `json:"user_id"`
+	Name   string `json:"name"`
+	Paid   bool   `json:"paid"`
+}
+
+type apiGames struct {
+	Games []apiGame `json:"games"`
+}
+
+type apiNewGame struct {
+	Kickoff  string   `json:"kickoff"`
+	Location string   `json:"location"`
+	Opponent string   `json:"opponent"`
+	Price    *float64 `json:"price"`
+	UserId   int64    `json:"user_id"`
+	Name     string   `json:"name"`
+}
+
+type apiAnswer struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type apiError struct {
+	Error apiErrorBody `json:"error"`
+}
+
+type apiErrorBody struct {
+	Code    services.ErrorCode `json:"code"`
+	Field   string             `json:"field,omitempty"`
+	Message string             `json:"message"`
+}
+
+// ERR_UNKNOWN is the code of errors that are not service errors.
+const ERR_UNKNOWN services.ErrorCode = "error.unknown"
+
+// apiHandler serves an API request of the chat its token was given to.
+type apiHandler func(w http.ResponseWriter, r *http.Request, chatId int64, settings *models.ChatSettings)
+
+func (s *Server) apiRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+API_PATH+"/openapi.yaml", handleOpenAPISpec)
+	mux.HandleFunc("GET "+API_PATH+"/games", s.api(s.handleListGames))
+	mux.HandleFunc("POST "+API_PATH+"/games", s.api(s.handleCreateGame))
+	mux.HandleFunc("GET "+API_PATH+"/games/next", s.api(s.handleNextGame))
+	mux.HandleFunc("DELETE "+API_PATH+"/games/next", s.api(s.handleCancelGame))
+	mux.HandleFunc("PUT "+API_PATH+"/games/next/players/{user_id}", s.api(s.handleAnswer))
+	mux.HandleFunc("PUT "+API_PATH+"/games/next/players/{user_id}/paid", s.api(s.handlePaid))
+	mux.HandleFunc("GET "+API_PATH+"/games/{id}", s.api(s.handleGetGame))
+}
+
+// api authenticates requests by the chat's API token, sent as a bearer
+// token, before handing them to handler.
+func (s *Server) api(handler apiHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			writeUnauthorized(w)
+			return
+		}
+		chatId, err := s.Tokens.ChatForToken(ctx, services.TOKEN_API, strings.TrimSpace(token))
+		if services.IsKind(err, services.KIND_NOT_FOUND) {
+			writeUnauthorized(w)
+			return
+		}
+		if err != nil {
+			writeAPIError(w, r, err, nil)
+			return
+		}
+		settings, err := s.Settings.GetSettings(ctx, chatId)
+		if err != nil {
+			writeAPIError(w, r, err, nil)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, MAX_API_BODY)
+		handler(w, r, chatId, settings)
+	}
+}
+
+func handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+func (s *Server) handleListGames(w http.ResponseWriter, r *http.Request, chatId int64, settings *models.ChatSettings) {
+	games, err := s.Games.ListGames(r.Context(), chatId)
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	list := apiGames{Games: make([]apiGame, 0, len(games))}
+	for _, game := range games {
+		list.Games = append(list.Games, newAPIGame(&game, nil, nil, settings))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleGetGame(w http.ResponseWriter, r *http.Request, chatId int64, settings *models.ChatSettings) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeAPIError(w, r, services.NotFound(services.ERR_NO_GAME), settings)
+		return
+	}
+	game, players, absentees, err := s.Games.GetGame(r.Context(), id)
+	if err == nil && game.ChatId != chatId {
+		err = services.NotFound(services.ERR_NO_GAME)
+	}
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAPIGame(game, players, absentees, settings))
+}
+
+func (s *Server) handleNextGame(w http.ResponseWriter, r *http.Request, chatId int64, settings *models.ChatSettings) {
+	game, players, absentees, err := s.GameService.GetGameDetails(r.Context(), chatId)
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAPIGame(game, players, absentees, settings))
+}
+
+// handleCreateGame creates the chat's next game, reading its kickoff as /new
+// does.
+func (s *Server) handleCreateGame(w http.ResponseWriter, r *http.Request, chatId int64, settings *models.ChatSettings) {
+	var body apiNewGame
+	if !readJSON(w, r, &body, settings) {
+		return
+	}
+	if err := checkUser(body.UserId, body.Name); err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	if strings.TrimSpace(body.Kickoff) == "" {
+		writeAPIError(w, r, services.Invalid("kickoff", services.ERR_API_FIELD_MISSING, nil, "kickoff"), settings)
+		return
+	}
+	gameData := []string{strings.TrimSpace(body.Kickoff), strings.TrimSpace(body.Location), strings.TrimSpace(body.Opponent)}
+	if body.Price != nil {
+		gameData = append(gameData, strconv.FormatFloat(*body.Price, 'f', -1, 64))
+	}
+
+	game, players, absentees, err := s.GameService.CreateNewGame(r.Context(), chatId, body.UserId, strings.TrimSpace(body.Name), gameData)
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newAPIGame(game, players, absentees, settings))
+}
+
+func (s *Server) handleCancelGame(w http.ResponseWriter, r *http.Request, chatId int64, settings *models.ChatSettings) {
+	game, err := s.GameService.CancelGame(r.Context(), chatId)
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAPIGame(game, nil, nil, settings))
+}
+
+// handleAnswer answers for a player whether they play the next game, as /in
+// and /out do.
+func (s *Server) handleAnswer(w http.ResponseWriter, r *http.Request, chatId int64, settings *models.ChatSettings) {
+	userId, err := pathUserId(r)
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	var body apiAnswer
+	if !readJSON(w, r, &body, settings) {
+		return
+	}
+	if err := checkUser(userId, body.Name); err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	var status services.PlayerStatus
+	switch strings.ToLower(body.Status) {
+	case API_STATUS_IN:
+		status = services.ATTENDING
+	case API_STATUS_OUT:
+		status = services.OUT
+	default:
+		writeAPIError(w, r, services.Invalid("status", services.ERR_API_STATUS, nil, body.Status), settings)
+		return
+	}
+
+	name := strings.TrimSpace(body.Name)
+	game, players, absentees, err := s.GameService.RegisterPlayer(r.Context(), &chatId, &userId, &name, status)
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAPIGame(game, players, absentees, settings))
+}
+
+// handlePaid marks that a player paid for the next game, as /paid does.
+func (s *Server) handlePaid(w http.ResponseWriter, r *http.Request, chatId int64, settings *models.ChatSettings) {
+	userId, err := pathUserId(r)
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	game, players, absentees, err := s.GameService.RepayGame(r.Context(), &chatId, &userId)
+	if err != nil {
+		writeAPIError(w, r, err, settings)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAPIGame(game, players, absentees, settings))
+}
+
+func pathUserId(r *http.Request) (int64, error) {
+	userId, err := strconv.ParseInt(r.PathValue("user_id"), 10, 64)
+	if err != nil || userId <= 0 {
+		return 0, services.Invalid("user_id", services.ERR_API_USER_ID, err, r.PathValue("user_id"))
+	}
+	return userId, nil
+}
+
+// checkUser checks the Telegram user a request acts for, whose name is
+// needed in case they never played in the chat.
+func checkUser(userId int64, name string) error {
+	if userId <= 0 {
+		return services.Invalid("user_id", services.ERR_API_FIELD_MISSING, nil, "user_id")
+	}
+	if strings.TrimSpace(name) == "" {
+		return services.Invalid("name", services.ERR_API_FIELD_MISSING, nil, "name")
+	}
+	return nil
+}
+
+func newAPIGame(game *models.Game, players, absentees *[]models.User, settings *models.ChatSettings) apiGame {
+	out := apiGame{
+		Id:        game.Id,
+		Kickoff:   game.Date.In(settings.Timezone),
+		Location:  game.Location,
+		Opponent:  game.Opponent,
+		Price:     game.Price,
+		Currency:  settings.Currency,
+		Cancelled: game.Cancelled,
+		Score:     game.Score,
+	}
+	if players != nil && absentees != nil {
+		out.Players, out.Absentees = newAPIPlayers(*players), newAPIPlayers(*absentees)
+	}
+	return out
+}
+
+func newAPIPlayers(users []models.User) *[]apiPlayer {
+	players := make([]apiPlayer, 0, len(users))
+	for _, user := range users {
+		players = append(players, apiPlayer{UserId: user.UserId, Name: user.Name, Paid: user.HasPaid})
+	}
+	return &players
+}
+
+// readJSON decodes the request body into v, answering with an error if it
+// cannot.
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}, settings *models.ChatSettings) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, newAPIError(services.Invalid("body", services.ERR_API_BODY, err), settings))
+			return false
+		}
+		writeAPIError(w, r, services.Invalid("body", services.ERR_API_BODY, err), settings)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	writeJSON(w, http.StatusUnauthorized, newAPIError(services.NotFound(services.ERR_UNKNOWN_TOKEN), nil))
+}
+
+// writeAPIError answers with the status matching the kind of err and its
+// message in the chat's language, logging the failures that are not the
+// client's.
+func writeAPIError(w http.ResponseWriter, r *http.Request, err error, settings *models.ChatSettings) {
+	status := http.StatusInternalServerError
+	switch {
+	case services.IsKind(err, services.KIND_VALIDATION):
+		status = http.StatusBadRequest
+	case services.IsKind(err, services.KIND_NOT_FOUND):
+		status = http.StatusNotFound
+	case services.IsKind(err, services.KIND_CONFLICT):
+		status = http.StatusConflict
+	case services.IsKind(err, services.KIND_PERMISSION_DENIED):
+		status = http.StatusForbidden
+	default:
+		logging.FromContext(r.Context()).Error("Could not serve request", "pattern", r.Pattern, "error", err)
+	}
+	writeJSON(w, status, newAPIError(err, settings))
+}
+
+func newAPIError(err error, settings *models.ChatSettings) apiError {
+	l := i18n.For(i18n.DefaultLanguage, time.UTC)
+	if settings != nil {
+		l = i18n.For(settings.Language, settings.Timezone)
+	}
+	serviceErr := services.AsError(err)
+	if serviceErr == nil {
+		return apiError{Error: apiErrorBody{Code: ERR_UNKNOWN, Message: l.T(string(ERR_UNKNOWN))}}
+	}
+	if serviceErr.Kind == services.KIND_INTERNAL {
+		// The causes of internal errors are not the client's to see.
+		return apiError{Error: apiErrorBody{Code: serviceErr.Code, Message: l.T(string(serviceErr.Code))}}
+	}
+	return apiError{Error: apiErrorBody{Code: serviceErr.Code, Field: serviceErr.Field, Message: l.T(string(serviceErr.Code), serviceErr.Args...)}}
+}
@@ -0,0 +1,178 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/services"
+
+	"github.com/google/uuid"
+)
+
+// newAPI returns the server of a chat with an upcoming game, along with its
+// API token.
+func newAPI(t *testing.T) (*Server, *services.GameService, *models.Game, string) {
+	t.Helper()
+	s, gameService, _ := newServer(t)
+	token, err := s.Tokens.ResetChatToken(context.Background(), chatID, services.TOKEN_API)
+	if err != nil {
+		t.Fatalf("ResetChatToken: %v", err)
+	}
+	game, _, _, err := gameService.CreateNewGame(context.Background(), chatID, 10, "Ana", []string{"2099-01-04 11:00", "Kallang", "Rovers", "12"})
+	if err != nil {
+		t.Fatalf("CreateNewGame: %v", err)
+	}
+	return s, gameService, game, token
+}
+
+// call sends an API request with token, decoding the JSON response into out
+// if given.
+func call(t *testing.T, handler http.Handler, method string, path string, token string, body string, out interface{}) *http.Response {
+	t.Helper()
+	request := httptest.NewRequest(method, API_PATH+path, strings.NewReader(body))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	response := recorder.Result()
+	if out != nil {
+		content, _ := io.ReadAll(response.Body)
+		if err := json.Unmarshal(content, out); err != nil {
+			t.Fatalf("%s %s = %d %s, not JSON: %v", method, path, response.StatusCode, content, err)
+		}
+	}
+	return response
+}
+
+func TestAPIAnswersAndPays(t *testing.T) {
+	s, _, game, token := newAPI(t)
+	handler := s.Handler()
+
+	var details apiGame
+	response := call(t, handler, http.MethodPut, "/games/next/players/20", token, `{"name": "Bea", "status": "in"}`, &details)
+	if response.StatusCode != http.StatusOK || details.Id != game.Id || details.Players == nil || len(*details.Players) != 1 || (*details.Players)[0].Name != "Bea" {
+		t.Fatalf("PUT answer = %d %+v, want Bea playing", response.StatusCode, details)
+	}
+
+	response = call(t, handler, http.MethodPut, "/games/next/players/20/paid", token, "", &details)
+	if response.StatusCode != http.StatusOK || !(*details.Players)[0].Paid {
+		t.Errorf("PUT paid = %d %+v, want Bea paid", response.StatusCode, details)
+	}
+
+	response = call(t, handler, http.MethodGet, "/games/"+game.Id.String(), token, "", &details)
+	if response.StatusCode != http.StatusOK || details.Price != 12 || details.Opponent != "Rovers" || len(*details.Players) != 1 {
+		t.Errorf("GET game = %d %+v", response.StatusCode, details)
+	}
+
+	var list apiGames
+	response = call(t, handler, http.MethodGet, "/games", token, "", &list)
+	if response.StatusCode != http.StatusOK || len(list.Games) != 1 || list.Games[0].Players != nil {
+		t.Errorf("GET games = %d %+v, want the game without players", response.StatusCode, list)
+	}
+}
+
+func TestAPICreatesAndCancels(t *testing.T) {
+	s, gameService, _, token := newAPI(t)
+	handler := s.Handler()
+
+	var failure apiError
+	response := call(t, handler, http.MethodPost, "/games", token, `{"kickoff": "2099-01-11 11:00", "user_id": 10, "name": "Ana"}`, &failure)
+	if response.StatusCode != http.StatusConflict || failure.Error.Code != services.ERR_GAME_ALREADY_SCHEDULED || failure.Error.Message == "" {
+		t.Fatalf("POST with a game upcoming = %d %+v, want a conflict", response.StatusCode, failure)
+	}
+
+	var cancelled apiGame
+	response = call(t, handler, http.MethodDelete, "/games/next", token, "", &cancelled)
+	if response.StatusCode != http.StatusOK || !cancelled.Cancelled {
+		t.Fatalf("DELETE = %d %+v, want the game cancelled", response.StatusCode, cancelled)
+	}
+
+	var created apiGame
+	response = call(t, handler, http.MethodPost, "/games", token, `{"kickoff": "2099-01-11 11:00", "opponent": "United", "price": 0, "user_id": 10, "name": "Ana"}`, &created)
+	if response.StatusCode != http.StatusCreated || created.Opponent != "United" || created.Price != 0 || created.Players == nil {
+		t.Fatalf("POST = %d %+v, want the game against United for free", response.StatusCode, created)
+	}
+	if game, _, _, _ := gameService.GetGameDetails(context.Background(), chatID); game.Id != created.Id {
+		t.Errorf("latest game = %+v, want the one created", game)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	s, gameService, _, token := newAPI(t)
+	handler := s.Handler()
+	calendarToken, err := s.Tokens.ChatToken(context.Background(), chatID, services.TOKEN_CALENDAR)
+	if err != nil {
+		t.Fatalf("ChatToken: %v", err)
+	}
+	otherChat, _, _, err := gameService.CreateNewGame(context.Background(), chatID+1, 10, "Ana", []string{"2099-01-04 11:00"})
+	if err != nil {
+		t.Fatalf("CreateNewGame: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+		wantCode   services.ErrorCode
+	}{
+		{"without a token", http.MethodGet, "/games", "", "", http.StatusUnauthorized, services.ERR_UNKNOWN_TOKEN},
+		{"with an unknown token", http.MethodGet, "/games", "unknown", "", http.StatusUnauthorized, services.ERR_UNKNOWN_TOKEN},
+		{"with a calendar token", http.MethodGet, "/games", calendarToken, "", http.StatusUnauthorized, services.ERR_UNKNOWN_TOKEN},
+		{"of another chat", http.MethodGet, "/games/" + otherChat.Id.String(), token, "", http.StatusNotFound, services.ERR_NO_GAME},
+		{"of an unknown game", http.MethodGet, "/games/" + uuid.NewString(), token, "", http.StatusNotFound, services.ERR_NO_GAME},
+		{"with a malformed body", http.MethodPut, "/games/next/players/20", token, `{"name": "Bea", "status": "in", "extra": 1}`, http.StatusBadRequest, services.ERR_API_BODY},
+		{"with an unknown status", http.MethodPut, "/games/next/players/20", token, `{"name": "Bea", "status": "maybe"}`, http.StatusBadRequest, services.ERR_API_STATUS},
+		{"without a name", http.MethodPut, "/games/next/players/20", token, `{"status": "in"}`, http.StatusBadRequest, services.ERR_API_FIELD_MISSING},
+		{"with a bad user ID", http.MethodPut, "/games/next/players/bea/paid", token, "", http.StatusBadRequest, services.ERR_API_USER_ID},
+		{"paying without answering", http.MethodPut, "/games/next/players/10/paid", token, "", http.StatusNotFound, services.ERR_PLAYER_NOT_REGISTERED},
+		{"creating without a kickoff", http.MethodPost, "/games", token, `{"user_id": 10, "name": "Ana"}`, http.StatusBadRequest, services.ERR_API_FIELD_MISSING},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failure apiError
+			response := call(t, handler, tt.method, tt.path, tt.token, tt.body, &failure)
+			if response.StatusCode != tt.wantStatus || failure.Error.Code != tt.wantCode {
+				t.Errorf("%s %s = %d %+v, want %d %s", tt.method, tt.path, response.StatusCode, failure, tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && response.Header.Get("WWW-Authenticate") == "" {
+				t.Error("no WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestAPISpec(t *testing.T) {
+	s, _, _, _ := newAPI(t)
+	response := call(t, s.Handler(), http.MethodGet, "/openapi.yaml", "", "", nil)
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK || !strings.HasPrefix(string(body), "openapi: 3") {
+		t.Errorf("GET spec = %d %.40s", response.StatusCode, body)
+	}
+	// Every route served is documented.
+	for _, path := range []string{"/games:", "/games/next:", "/games/next/players/{user_id}:", "/games/next/players/{user_id}/paid:", "/games/{id}:"} {
+		if !strings.Contains(string(body), "\n  "+path+"\n") {
+			t.Errorf("spec lacks %s", path)
+		}
+	}
+}
+
+func TestAPIKickoffInChatTimezone(t *testing.T) {
+	s, _, game, token := newAPI(t)
+	if _, err := s.Settings.UpdateSetting(context.Background(), chatID, services.SETTING_TIMEZONE, "Asia/Singapore"); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	var details apiGame
+	call(t, s.Handler(), http.MethodGet, "/games/next", token, "", &details)
+	if _, offset := details.Kickoff.Zone(); !details.Kickoff.Equal(game.Date) || offset != 8*60*60 {
+		t.Errorf("kickoff = %v, want %v in Singapore", details.Kickoff, game.Date)
+	}
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"tg-sunday-league/i18n"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/services"
+	"time"
+)
+
+// DASHBOARD_PATH is where the dashboard of the chat a user signed in to is
+// served.
+const DASHBOARD_PATH = "/dashboard/"
+
+// SESSION_COOKIE holds the session token of the user signed in to the
+// dashboard.
+const SESSION_COOKIE = "dashboard_session"
+
+const (
+	loginPath  = DASHBOARD_PATH + "login"
+	logoutPath = DASHBOARD_PATH + "logout"
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).ParseFS(templateFiles, "templates/*.html"))
+
+// dashboardPage is what the dashboard templates render.
+type dashboardPage struct {
+	L          *i18n.Localizer
+	LoginPath  string
+	LogoutPath string
+	Code       string // Code of the login link being opened
+	Message    string // Why the user is not signed in
+	Currency   string
+	*services.Dashboard
+}
+
+func (s *Server) dashboardRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+DASHBOARD_PATH+"{$}", s.handleDashboard)
+	mux.HandleFunc("GET "+loginPath, s.handleLoginPage)
+	mux.HandleFunc("POST "+loginPath, s.handleLogin)
+	mux.HandleFunc("POST "+logoutPath, s.handleLogout)
+}
+
+// DashboardLoginURL is the login link with code on the server published at
+// publicUrl.
+func DashboardLoginURL(publicUrl string, code string) string {
+	return strings.TrimSuffix(publicUrl, "/") + loginPath + "?code=" + url.QueryEscape(code)
+}
+
+// handleLoginPage asks to sign in rather than doing it, as apps fetch the
+// links sent to them to preview them, which would use up the login.
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	l := i18n.For(i18n.DefaultLanguage, time.UTC)
+	writePage(w, r, http.StatusOK, "login", &dashboardPage{L: l, Code: r.URL.Query().Get("code")})
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	token, session, err := s.Sessions.RedeemLogin(ctx, r.PostFormValue("code"))
+	if services.IsKind(err, services.KIND_NOT_FOUND) {
+		s.writeSignedOut(w, r, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SESSION_COOKIE,
+		Value:    token,
+		Path:     DASHBOARD_PATH,
+		Expires:  session.ExpiresAt,
+		Secure:   s.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, DASHBOARD_PATH, http.StatusSeeOther)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(SESSION_COOKIE); err == nil {
+		if err := s.Sessions.EndSession(r.Context(), cookie.Value); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	http.SetCookie(w, &http.Cookie{Name: SESSION_COOKIE, Path: DASHBOARD_PATH, MaxAge: -1, Secure: s.Secure, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	http.Redirect(w, r, DASHBOARD_PATH, http.StatusSeeOther)
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cookie, err := r.Cookie(SESSION_COOKIE)
+	if err != nil {
+		s.writeSignedOut(w, r, http.StatusUnauthorized, services.NotFound(services.ERR_NOT_SIGNED_IN))
+		return
+	}
+	session, err := s.Sessions.Session(ctx, cookie.Value)
+	if services.IsKind(err, services.KIND_NOT_FOUND) {
+		s.writeSignedOut(w, r, http.StatusUnauthorized, err)
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	settings, err := s.Settings.GetSettings(ctx, session.ChatId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	dashboard, err := s.GameService.Dashboard(ctx, session.ChatId, time.Now())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writePage(w, r, http.StatusOK, "dashboard", &dashboardPage{
+		L:          i18n.For(settings.Language, settings.Timezone),
+		LogoutPath: logoutPath,
+		Currency:   settings.Currency,
+		Dashboard:  dashboard,
+	})
+}
+
+// writeSignedOut tells the user why they are not signed in, and how to.
+func (s *Server) writeSignedOut(w http.ResponseWriter, r *http.Request, status int, err error) {
+	l := i18n.For(i18n.DefaultLanguage, time.UTC)
+	writePage(w, r, status, "signed_out", &dashboardPage{L: l, Message: l.T(string(services.AsError(err).Code))})
+}
+
+// writePage renders the template name whole before answering, so a failure
+// is still an error response rather than half a page.
+func writePage(w http.ResponseWriter, r *http.Request, status int, name string, page *dashboardPage) {
+	page.LoginPath = loginPath
+	var body bytes.Buffer
+	if err := templates.ExecuteTemplate(&body, name, page); err != nil {
+		logging.FromContext(r.Context()).Error("Could not render page", "template", name, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Set("Cache-Control", "private, no-store")
+	// Login links carry their code in the URL, which no other site should
+	// see in a Referer.
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'; frame-ancestors 'none'")
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
+}
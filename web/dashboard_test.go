@@ -0,0 +1,141 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"tg-sunday-league/services"
+)
+
+// signIn opens a login link of the chat and redeems it, returning the
+// session cookie.
+func signIn(t *testing.T, s *Server) *http.Cookie {
+	t.Helper()
+	code, err := s.Sessions.NewLogin(context.Background(), chatID, 20)
+	if err != nil {
+		t.Fatalf("NewLogin: %v", err)
+	}
+	response, body := get(t, s.Handler(), strings.TrimPrefix(DashboardLoginURL("https://example.com", code), "https://example.com"))
+	if response.StatusCode != http.StatusOK || !strings.Contains(body, `value="`+code+`"`) {
+		t.Fatalf("GET login = %d, want a form to sign in:\n%s", response.StatusCode, body)
+	}
+
+	response, body = postForm(t, s.Handler(), loginPath, nil, url.Values{"code": {code}})
+	if response.StatusCode != http.StatusSeeOther || response.Header.Get("Location") != DASHBOARD_PATH {
+		t.Fatalf("POST login = %d, want a redirect to the dashboard:\n%s", response.StatusCode, body)
+	}
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == SESSION_COOKIE {
+			if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != DASHBOARD_PATH {
+				t.Errorf("cookie = %+v, want it HttpOnly, SameSite=Lax and kept to the dashboard", cookie)
+			}
+			return cookie
+		}
+	}
+	t.Fatalf("POST login set no %s cookie", SESSION_COOKIE)
+	return nil
+}
+
+func postForm(t *testing.T, handler http.Handler, path string, cookie *http.Cookie, form url.Values) (*http.Response, string) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != nil {
+		request.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	return response, string(body)
+}
+
+func getDashboard(t *testing.T, handler http.Handler, cookie *http.Cookie) (*http.Response, string) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodGet, DASHBOARD_PATH, nil)
+	if cookie != nil {
+		request.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	return response, string(body)
+}
+
+func TestDashboard(t *testing.T) {
+	s, gameService, _ := newServer(t)
+	ctx := context.Background()
+	if _, err := s.Settings.UpdateSetting(ctx, chatID, services.SETTING_LANGUAGE, "es"); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	if _, _, _, err := gameService.CreateNewGame(ctx, chatID, 10, "Ana", []string{"2099-01-04 11:00", "Kallang", "Rovers <FC>", "12"}); err != nil {
+		t.Fatalf("CreateNewGame: %v", err)
+	}
+	chat, user, name := chatID, int64(20), "Bea"
+	if _, _, _, err := gameService.RegisterPlayer(ctx, &chat, &user, &name, services.ATTENDING); err != nil {
+		t.Fatalf("RegisterPlayer: %v", err)
+	}
+	cookie := signIn(t, s)
+
+	response, body := getDashboard(t, s.Handler(), cookie)
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Fatalf("GET dashboard = %d %s, want the page", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	for _, want := range []string{`<html lang="es">`, "Próximo partido", "contra Rovers &lt;FC&gt;", "Kallang", "Jugadores (1)", "<td>Bea</td>", "Sin pagar"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("dashboard lacks %q:\n%s", want, body)
+		}
+	}
+	if response.Header.Get("Cache-Control") != "private, no-store" {
+		t.Errorf("Cache-Control = %q, want the page kept out of caches", response.Header.Get("Cache-Control"))
+	}
+}
+
+func TestDashboardSignsOut(t *testing.T) {
+	s, _, _ := newServer(t)
+	cookie := signIn(t, s)
+
+	response, _ := postForm(t, s.Handler(), logoutPath, cookie, nil)
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("POST logout = %d, want a redirect", response.StatusCode)
+	}
+	if response, body := getDashboard(t, s.Handler(), cookie); response.StatusCode != http.StatusUnauthorized || !strings.Contains(body, "/dashboard") {
+		t.Errorf("GET dashboard after signing out = %d, want to be told how to sign in:\n%s", response.StatusCode, body)
+	}
+}
+
+func TestDashboardRefusesStrangers(t *testing.T) {
+	s, _, _ := newServer(t)
+	handler := s.Handler()
+
+	if response, _ := getDashboard(t, handler, nil); response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET dashboard without a cookie = %d, want 401", response.StatusCode)
+	}
+	if response, _ := getDashboard(t, handler, &http.Cookie{Name: SESSION_COOKIE, Value: "guessed"}); response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET dashboard with a made up session = %d, want 401", response.StatusCode)
+	}
+}
+
+func TestDashboardLoginWorksOnce(t *testing.T) {
+	s, _, _ := newServer(t)
+	code, err := s.Sessions.NewLogin(context.Background(), chatID, 20)
+	if err != nil {
+		t.Fatalf("NewLogin: %v", err)
+	}
+	if response, _ := postForm(t, s.Handler(), loginPath, nil, url.Values{"code": {code}}); response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("first POST login = %d, want a redirect", response.StatusCode)
+	}
+
+	response, body := postForm(t, s.Handler(), loginPath, nil, url.Values{"code": {code}})
+	if response.StatusCode != http.StatusForbidden || !strings.Contains(body, "already used or has expired") {
+		t.Errorf("second POST login = %d, want it refused:\n%s", response.StatusCode, body)
+	}
+	if len(response.Cookies()) != 0 {
+		t.Errorf("second POST login set cookies %+v", response.Cookies())
+	}
+}
@@ -0,0 +1,97 @@
+// Package web serves the data of chats over HTTP to clients outside of
+// Telegram, such as calendar apps, behind the secret links the bot hands out.
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"tg-sunday-league/calendar"
+	"tg-sunday-league/logging"
+	"tg-sunday-league/services"
+	"time"
+)
+
+// CALENDAR_PATH is where the calendar of a chat is served, followed by its
+// calendar token and ".ics".
+const CALENDAR_PATH = "/calendar/"
+
+// Server serves:
+//
+//	/calendar/<token>.ics  the games of the chat the token was given to
+//	/api/v1/...            the JSON API, for the chat whose API token is sent
+//	/dashboard/            the dashboard of the chat the user signed in to
+type Server struct {
+	Games       services.IGameAdminService
+	GameService services.IGameService
+	Settings    services.ISettingsService
+	Tokens      services.ITokenService
+	Sessions    services.ISessionService
+	Secure      bool // Served over HTTPS, so session cookies are kept to it
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+CALENDAR_PATH+"{file}", s.handleCalendar)
+	s.apiRoutes(mux)
+	s.dashboardRoutes(mux)
+	return mux
+}
+
+// CalendarURL is the link to the calendar of the chat token was given to, on
+// the server published at publicUrl.
+func CalendarURL(publicUrl string, token string) string {
+	return strings.TrimSuffix(publicUrl, "/") + CALENDAR_PATH + token + ".ics"
+}
+
+// APIURL is where the API is served on the server published at publicUrl.
+func APIURL(publicUrl string) string {
+	return strings.TrimSuffix(publicUrl, "/") + API_PATH
+}
+
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	chatId, err := s.Tokens.ChatForToken(ctx, services.TOKEN_CALENDAR, token)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	settings, err := s.Settings.GetSettings(ctx, chatId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	games, err := s.Games.ListGames(ctx, chatId)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// The calendar is written whole first so a failure is still an error
+	// response rather than a truncated file.
+	var body bytes.Buffer
+	if err := calendar.Write(&body, games, settings, time.Now()); err != nil {
+		writeError(w, r, services.Internal(services.ERR_CALENDAR, err))
+		return
+	}
+	w.Header().Set("Content-Type", calendar.CONTENT_TYPE)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Write(body.Bytes())
+}
+
+// writeError answers with the status matching the kind of err, logging the
+// failures that are not the client's. Paths are left out of logs, as tokens
+// in them are secrets.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if services.IsKind(err, services.KIND_NOT_FOUND) {
+		http.NotFound(w, r)
+		return
+	}
+	logging.FromContext(r.Context()).Error("Could not serve request", "pattern", r.Pattern, "error", err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,77 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tg-sunday-league/models"
+	"tg-sunday-league/repositories"
+	"tg-sunday-league/services"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const chatID = int64(-1001)
+
+func newServer(t *testing.T) (*Server, *services.GameService, string) {
+	t.Helper()
+	games := repositories.NewMemoryGameRepository()
+	settings := &services.SettingsService{SettingsRepository: repositories.NewMemorySettingsRepository()}
+	gameService := &services.GameService{GameRepository: games, SettingsService: settings}
+	tokens := &services.TokenService{TokenRepository: repositories.NewMemoryTokenRepository()}
+	token, err := tokens.ChatToken(context.Background(), chatID, services.TOKEN_CALENDAR)
+	if err != nil {
+		t.Fatalf("ChatToken: %v", err)
+	}
+	sessions := &services.SessionService{SessionRepository: repositories.NewMemorySessionRepository()}
+	return &Server{Games: gameService, GameService: gameService, Settings: settings, Tokens: tokens, Sessions: sessions}, gameService, token
+}
+
+func get(t *testing.T, handler http.Handler, path string) (*http.Response, string) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	response := recorder.Result()
+	body, _ := io.ReadAll(response.Body)
+	return response, string(body)
+}
+
+func TestCalendar(t *testing.T) {
+	s, gameService, token := newServer(t)
+	game := &models.Game{Id: uuid.New(), ChatId: chatID, Date: time.Now().Add(24 * time.Hour), Opponent: "Rovers"}
+	if _, err := gameService.GameRepository.InsertGame(context.Background(), game); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gameService.CancelGame(context.Background(), chatID); err != nil {
+		t.Fatal(err)
+	}
+
+	response, body := get(t, s.Handler(), CALENDAR_PATH+token+".ics")
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("GET = %d %s, want the calendar", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	for _, want := range []string{"UID:" + game.Id.String() + "@", "SEQUENCE:1\r\n", "STATUS:CANCELLED\r\n", "SUMMARY:Cancelled: Football vs Rovers\r\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("calendar lacks %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestCalendarNotFound(t *testing.T) {
+	s, _, token := newServer(t)
+	for _, path := range []string{CALENDAR_PATH + "unknown.ics", CALENDAR_PATH + token, CALENDAR_PATH + ".ics"} {
+		if response, _ := get(t, s.Handler(), path); response.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, response.StatusCode)
+		}
+	}
+}
+
+func TestCalendarURL(t *testing.T) {
+	if got := CalendarURL("https://league.example/", "abc"); got != "https://league.example/calendar/abc.ics" {
+		t.Errorf("CalendarURL = %q", got)
+	}
+}